@@ -0,0 +1,248 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/opendatahub-io/distributed-workloads/pkg/baselines"
+)
+
+const (
+	// The performance baselines committed in the repository, read by the benchmark tests by default,
+	// relative to the root of the module
+	repositoryBaselines = "tests/common/support/baselines.json"
+	// The module the baselines are committed in
+	repositoryModule = "github.com/opendatahub-io/distributed-workloads"
+)
+
+const usage = `Manages the performance baselines the benchmark tests compare their measurements against.
+
+Usage:
+  dw-baseline list   [-metric METRIC] [-cluster CLUSTER] [-gpu GPU] [-history]
+  dw-baseline record -metric METRIC [-cluster CLUSTER] [-gpu GPU] -value VALUE [-unit UNIT] [-note NOTE]
+  dw-baseline update -metric METRIC [-cluster CLUSTER] [-gpu GPU] -value VALUE [-note NOTE]
+
+The baselines are read from and written to -location, a file path or a s3://bucket/key URI, defaulting to
+BASELINES_LOCATION or the baselines committed in the repository the command is run from. S3 is accessed with the
+AWS_DEFAULT_ENDPOINT, AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY and AWS_DEFAULT_REGION environment variables.
+`
+
+func main() {
+	if len(os.Args) < 2 {
+		fmt.Fprint(os.Stderr, usage)
+		os.Exit(2)
+	}
+
+	var err error
+	switch command, args := os.Args[1], os.Args[2:]; command {
+	case "list":
+		err = list(args)
+	case "record":
+		err = record(args)
+	case "update":
+		err = update(args)
+	case "-h", "-help", "--help", "help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "Unknown command %q\n\n%s", command, usage)
+		os.Exit(2)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+// baselineFlags are the flags identifying the baselines the commands apply to.
+type baselineFlags struct {
+	location string
+	metric   string
+	cluster  string
+	gpu      string
+}
+
+func newFlagSet(name string, flags *baselineFlags) *flag.FlagSet {
+	location, _ := baselines.Location()
+	set := flag.NewFlagSet(name, flag.ExitOnError)
+	set.StringVar(&flags.location, "location", location, "File path or s3://bucket/key URI of the baselines, "+
+		"the baselines committed in the repository the command is run from if not set")
+	set.StringVar(&flags.metric, "metric", "", "Metric of the baseline, i.e. "+baselines.MetricRcclBusBandwidth)
+	set.StringVar(&flags.cluster, "cluster", "", "Cluster the baseline applies to, any cluster if not set")
+	set.StringVar(&flags.gpu, "gpu", "", "GPU model the baseline applies to, matched as a substring of the GPU product name, any GPU if not set")
+	return set
+}
+
+func (f *baselineFlags) open() (baselines.Store, error) {
+	if f.location == "" {
+		root, err := moduleRoot()
+		if err != nil {
+			return nil, err
+		}
+		f.location = filepath.Join(root, repositoryBaselines)
+	}
+	return baselines.Open(f.location, baselines.S3ConfigFromEnv())
+}
+
+// moduleRoot returns the root directory of the repository module the current directory is in.
+func moduleRoot() (string, error) {
+	dir, err := os.Getwd()
+	if err != nil {
+		return "", err
+	}
+	for {
+		if data, err := os.ReadFile(filepath.Join(dir, "go.mod")); err == nil && modulePath(data) == repositoryModule {
+			return dir, nil
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", fmt.Errorf("not in the %s repository, set the baselines location with -location or %s", repositoryModule, baselines.LocationEnvVar)
+		}
+		dir = parent
+	}
+}
+
+func list(args []string) error {
+	var flags baselineFlags
+	set := newFlagSet("list", &flags)
+	history := set.Bool("history", false, "Print the previous values of the baselines")
+	set.Lookup("gpu").Usage = "GPU product name, only the baselines applying to it are listed, the ones for any GPU included"
+	_ = set.Parse(args)
+
+	store, err := flags.open()
+	if err != nil {
+		return err
+	}
+	all, err := store.Load(context.Background())
+	if err != nil {
+		return err
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 4, 2, ' ', 0)
+	fmt.Fprintln(w, "METRIC\tCLUSTER\tGPU\tVALUE\tUNIT\tUPDATED\tNOTE")
+	for _, baseline := range all.Baselines {
+		if flags.metric != "" && !strings.HasPrefix(baseline.Metric, flags.metric) ||
+			flags.cluster != "" && baseline.Cluster != flags.cluster ||
+			// The GPU of the baselines is matched as a substring of the product name, as the tests look them up
+			flags.gpu != "" && baseline.GPU != "" && !strings.Contains(flags.gpu, baseline.GPU) {
+			continue
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%v\t%s\t%s\t%s\n", baseline.Metric, orAny(baseline.Cluster), orAny(baseline.GPU),
+			baseline.Value, baseline.Unit, baseline.Updated.Format(time.DateOnly), baseline.Note)
+		if *history {
+			for _, revision := range baseline.History {
+				fmt.Fprintf(w, "\t\t\t%v\t\t%s\t%s\n", revision.Value, revision.Updated.Format(time.DateOnly), revision.Note)
+			}
+		}
+	}
+	return w.Flush()
+}
+
+func record(args []string) error {
+	var flags baselineFlags
+	set := newFlagSet("record", &flags)
+	value := set.Float64("value", 0, "Value of the baseline")
+	unit := set.String("unit", "", "Unit of the value, i.e. GB/s")
+	note := set.String("note", "", "Note about the baseline, i.e. the hardware and software versions it was measured with")
+	_ = set.Parse(args)
+	if err := requireFlags(set, "metric", "value"); err != nil {
+		return err
+	}
+
+	return modify(&flags, func(all *baselines.Baselines) error {
+		return all.Record(baselines.Baseline{
+			Metric:  flags.metric,
+			Cluster: flags.cluster,
+			GPU:     flags.gpu,
+			Unit:    *unit,
+			Value:   *value,
+			Updated: time.Now().UTC().Truncate(time.Second),
+			Note:    *note,
+		})
+	})
+}
+
+func update(args []string) error {
+	var flags baselineFlags
+	set := newFlagSet("update", &flags)
+	value := set.Float64("value", 0, "New value of the baseline")
+	note := set.String("note", "", "Note about the new value, i.e. why it changed")
+	_ = set.Parse(args)
+	if err := requireFlags(set, "metric", "value"); err != nil {
+		return err
+	}
+
+	return modify(&flags, func(all *baselines.Baselines) error {
+		return all.Update(flags.metric, flags.cluster, flags.gpu, *value, *note, time.Now().UTC().Truncate(time.Second))
+	})
+}
+
+// modify loads the baselines, applies the change and saves them back.
+func modify(flags *baselineFlags, change func(*baselines.Baselines) error) error {
+	ctx := context.Background()
+	store, err := flags.open()
+	if err != nil {
+		return err
+	}
+	all, err := store.Load(ctx)
+	if err != nil {
+		return err
+	}
+	if err := change(all); err != nil {
+		return err
+	}
+	if err := store.Save(ctx, all); err != nil {
+		return err
+	}
+	fmt.Printf("Saved baselines to %s\n", flags.location)
+	return nil
+}
+
+// modulePath returns the path of the module declared by the go.mod content.
+func modulePath(goMod []byte) string {
+	for _, line := range strings.Split(string(goMod), "\n") {
+		if path, ok := strings.CutPrefix(strings.TrimSpace(line), "module "); ok {
+			return strings.Trim(strings.TrimSpace(path), `"`)
+		}
+	}
+	return ""
+}
+
+func requireFlags(set *flag.FlagSet, names ...string) error {
+	visited := map[string]bool{}
+	set.Visit(func(f *flag.Flag) { visited[f.Name] = true })
+	for _, name := range names {
+		if !visited[name] {
+			return fmt.Errorf("flag -%s is required by %s", name, set.Name())
+		}
+	}
+	return nil
+}
+
+func orAny(value string) string {
+	if value == "" {
+		return "*"
+	}
+	return value
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/project-codeflare/codeflare-common/support"
+
+	"github.com/opendatahub-io/distributed-workloads/pkg/examples"
+)
+
+// Renders the example workloads into YAML manifests, using the default images of the e2e tests so the output
+// does not depend on the environment and can be checked against the committed manifests.
+func main() {
+	outputDir := flag.String("output-dir", "examples", "Directory the YAML manifests are written to")
+	localQueue := flag.String("local-queue", "", "Kueue LocalQueue the examples are queued in")
+	flag.Parse()
+
+	if err := render(*outputDir, *localQueue); err != nil {
+		fmt.Fprintf(os.Stderr, "Error rendering examples: %v\n", err)
+		os.Exit(1)
+	}
+}
+
+func render(outputDir, localQueue string) error {
+	catalog, err := examples.Catalog(examples.CatalogOptions{
+		RayVersion:      support.RayVersion,
+		RayImage:        support.RayImage,
+		PyTorchJobImage: examples.FmsHfTuningImage,
+		LocalQueue:      localQueue,
+	})
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(outputDir, 0755); err != nil {
+		return err
+	}
+	for _, example := range catalog {
+		manifest, err := examples.ToYAML(example.Object)
+		if err != nil {
+			return fmt.Errorf("error rendering example %s: %w", example.Name, err)
+		}
+		content := fmt.Sprintf("# %s\n# Generated by 'make examples', do not edit.\n%s", example.Description, manifest)
+		if err := os.WriteFile(filepath.Join(outputDir, example.Name+".yaml"), []byte(content), 0644); err != nil {
+			return err
+		}
+		fmt.Printf("Rendered example %s\n", example.Name)
+	}
+	return nil
+}
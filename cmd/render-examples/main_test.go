@@ -0,0 +1,46 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/onsi/gomega"
+)
+
+func TestRenderMatchesExamples(t *testing.T) {
+	g := gomega.NewWithT(t)
+
+	outputDir := t.TempDir()
+	g.Expect(render(outputDir, "")).To(gomega.Succeed())
+
+	rendered, err := os.ReadDir(outputDir)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	committed, err := filepath.Glob(filepath.Join("..", "..", "examples", "*.yaml"))
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(committed).To(gomega.HaveLen(len(rendered)), "The examples directory has manifests which aren't rendered anymore, run 'make examples'")
+
+	for _, entry := range rendered {
+		expected, err := os.ReadFile(filepath.Join(outputDir, entry.Name()))
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		actual, err := os.ReadFile(filepath.Join("..", "..", "examples", entry.Name()))
+		g.Expect(err).NotTo(gomega.HaveOccurred(), "Example %s isn't committed, run 'make examples'", entry.Name())
+		g.Expect(string(actual)).To(gomega.Equal(string(expected)), "Example %s is out of date, run 'make examples'", entry.Name())
+	}
+}
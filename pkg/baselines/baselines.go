@@ -0,0 +1,172 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package baselines manages the performance baselines the benchmark tests compare their measurements against,
+// recorded per cluster and GPU model and stored as versioned JSON, either in the repository or in an S3 bucket.
+package baselines
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Version is the version of the format of the baselines.
+const Version = 1
+
+// Metrics compared against the baselines by the benchmark tests. Metrics measured per subject, i.e. storage class,
+// are suffixed with the subject, i.e. rwx-data-loading/nfs-csi.
+const (
+	// Minimum RCCL all-reduce bus bandwidth in GB/s
+	MetricRcclBusBandwidth = "rccl-bus-bandwidth"
+	// Minimum bandwidth in Gbit/s between training pods
+	MetricNetworkBandwidth = "network-bandwidth"
+	// Maximum mean round-trip time in milliseconds between training pods
+	MetricNetworkLatency = "network-latency"
+	// Minimum samples/sec read from a RWX storage class
+	MetricRwxDataLoading = "rwx-data-loading"
+)
+
+// Baselines is the content of a baselines file.
+type Baselines struct {
+	Version   int        `json:"version"`
+	Baselines []Baseline `json:"baselines"`
+}
+
+// Baseline is the value of a metric on a cluster and GPU model. Empty cluster or GPU apply to any cluster or GPU.
+type Baseline struct {
+	Metric  string    `json:"metric"`
+	Cluster string    `json:"cluster,omitempty"`
+	GPU     string    `json:"gpu,omitempty"`
+	Unit    string    `json:"unit,omitempty"`
+	Value   float64   `json:"value"`
+	Updated time.Time `json:"updated"`
+	Note    string    `json:"note,omitempty"`
+	// History holds the previous values of the baseline, the latest first
+	History []Revision `json:"history,omitempty"`
+}
+
+// Revision is a previous value of a baseline.
+type Revision struct {
+	Value   float64   `json:"value"`
+	Updated time.Time `json:"updated"`
+	Note    string    `json:"note,omitempty"`
+}
+
+// Parse parses baselines, empty data being parsed as no baselines.
+func Parse(data []byte) (*Baselines, error) {
+	baselines := &Baselines{Version: Version}
+	if len(strings.TrimSpace(string(data))) == 0 {
+		return baselines, nil
+	}
+	if err := json.Unmarshal(data, baselines); err != nil {
+		return nil, fmt.Errorf("error parsing baselines: %w", err)
+	}
+	if baselines.Version != Version {
+		return nil, fmt.Errorf("unsupported baselines version %d, expected %d", baselines.Version, Version)
+	}
+	return baselines, nil
+}
+
+// Marshal returns the baselines as indented JSON, sorted so updates produce minimal diffs.
+func (b *Baselines) Marshal() ([]byte, error) {
+	sort.SliceStable(b.Baselines, func(i, j int) bool {
+		return b.Baselines[i].String() < b.Baselines[j].String()
+	})
+	data, err := json.MarshalIndent(b, "", "  ")
+	if err != nil {
+		return nil, err
+	}
+	return append(data, '\n'), nil
+}
+
+// Lookup returns the baseline of the metric that applies to the cluster and GPU product name, the most specific
+// first. The GPU of the baselines is matched as a substring of the product name, i.e. MI300 matches AMD_Instinct_MI300X_OAM.
+func (b *Baselines) Lookup(metric, cluster, gpuProduct string) (Baseline, bool) {
+	var found *Baseline
+	for i := range b.Baselines {
+		baseline := &b.Baselines[i]
+		if baseline.Metric != metric ||
+			baseline.Cluster != "" && baseline.Cluster != cluster ||
+			baseline.GPU != "" && (gpuProduct == "" || !strings.Contains(gpuProduct, baseline.GPU)) {
+			continue
+		}
+		if found == nil || baseline.specificity() > found.specificity() {
+			found = baseline
+		}
+	}
+	if found == nil {
+		return Baseline{}, false
+	}
+	return *found, true
+}
+
+// Record adds a baseline, failing if a baseline of the metric already exists for the cluster and GPU.
+func (b *Baselines) Record(baseline Baseline) error {
+	if existing := b.find(baseline.Metric, baseline.Cluster, baseline.GPU); existing != nil {
+		return fmt.Errorf("baseline %s already exists with value %v, update it instead", existing.String(), existing.Value)
+	}
+	b.Baselines = append(b.Baselines, baseline)
+	return nil
+}
+
+// Update sets the value of the baseline of the metric for the cluster and GPU, keeping the previous value in its history.
+func (b *Baselines) Update(metric, cluster, gpu string, value float64, note string, updated time.Time) error {
+	existing := b.find(metric, cluster, gpu)
+	if existing == nil {
+		return fmt.Errorf("baseline %s doesn't exist, record it instead", Baseline{Metric: metric, Cluster: cluster, GPU: gpu}.String())
+	}
+	existing.History = append([]Revision{{Value: existing.Value, Updated: existing.Updated, Note: existing.Note}}, existing.History...)
+	existing.Value = value
+	existing.Updated = updated
+	existing.Note = note
+	return nil
+}
+
+func (b *Baselines) find(metric, cluster, gpu string) *Baseline {
+	for i := range b.Baselines {
+		if baseline := &b.Baselines[i]; baseline.Metric == metric && baseline.Cluster == cluster && baseline.GPU == gpu {
+			return baseline
+		}
+	}
+	return nil
+}
+
+// String returns the metric, cluster and GPU identifying the baseline.
+func (b Baseline) String() string {
+	return fmt.Sprintf("%s[cluster=%s,gpu=%s]", b.Metric, orAny(b.Cluster), orAny(b.GPU))
+}
+
+// specificity ranks baselines for a cluster and GPU above baselines for a GPU, above baselines for a cluster.
+func (b Baseline) specificity() int {
+	specificity := 0
+	if b.GPU != "" {
+		specificity += 2
+	}
+	if b.Cluster != "" {
+		specificity++
+	}
+	return specificity
+}
+
+func orAny(value string) string {
+	if value == "" {
+		return "*"
+	}
+	return value
+}
@@ -0,0 +1,115 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baselines
+
+import (
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name      string
+		data      string
+		baselines int
+		err       string
+	}{
+		{name: "empty", data: " \n"},
+		{name: "baselines", data: `{"version": 1, "baselines": [{"metric": "network-bandwidth", "value": 90}]}`, baselines: 1},
+		{name: "unsupported version", data: `{"version": 2, "baselines": []}`, err: "unsupported baselines version 2"},
+		{name: "invalid", data: `{"version":`, err: "error parsing baselines"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+			baselines, err := Parse([]byte(tt.data))
+			if tt.err != "" {
+				g.Expect(err).To(gomega.MatchError(gomega.ContainSubstring(tt.err)))
+				return
+			}
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			g.Expect(baselines.Version).To(gomega.Equal(Version))
+			g.Expect(baselines.Baselines).To(gomega.HaveLen(tt.baselines))
+		})
+	}
+}
+
+func TestLookup(t *testing.T) {
+	baselines := &Baselines{Version: Version, Baselines: []Baseline{
+		{Metric: MetricRcclBusBandwidth, Value: 100},
+		{Metric: MetricRcclBusBandwidth, Cluster: "perf", Value: 200},
+		{Metric: MetricRcclBusBandwidth, GPU: "MI300", Value: 300},
+		{Metric: MetricRcclBusBandwidth, Cluster: "perf", GPU: "MI300", Value: 400},
+		{Metric: MetricRcclBusBandwidth, GPU: "MI210", Value: 500},
+		{Metric: MetricNetworkBandwidth, Cluster: "perf", Value: 90},
+	}}
+
+	tests := []struct {
+		name       string
+		metric     string
+		cluster    string
+		gpuProduct string
+		value      float64
+		ok         bool
+	}{
+		{name: "any cluster and GPU", metric: MetricRcclBusBandwidth, cluster: "dev", value: 100, ok: true},
+		{name: "cluster", metric: MetricRcclBusBandwidth, cluster: "perf", value: 200, ok: true},
+		{name: "GPU substring of the product name", metric: MetricRcclBusBandwidth, cluster: "dev", gpuProduct: "AMD_Instinct_MI300X_OAM", value: 300, ok: true},
+		{name: "GPU above cluster", metric: MetricRcclBusBandwidth, cluster: "perf", gpuProduct: "AMD_Instinct_MI210", value: 500, ok: true},
+		{name: "cluster and GPU", metric: MetricRcclBusBandwidth, cluster: "perf", gpuProduct: "AMD_Instinct_MI300X_OAM", value: 400, ok: true},
+		{name: "GPU not matching", metric: MetricRcclBusBandwidth, cluster: "dev", gpuProduct: "NVIDIA-A100-SXM4-80GB", value: 100, ok: true},
+		{name: "GPU baselines need a GPU", metric: MetricRcclBusBandwidth, cluster: "perf", value: 200, ok: true},
+		{name: "other cluster", metric: MetricNetworkBandwidth, cluster: "dev", ok: false},
+		{name: "unknown metric", metric: MetricNetworkLatency, cluster: "perf", ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+			baseline, ok := baselines.Lookup(tt.metric, tt.cluster, tt.gpuProduct)
+			g.Expect(ok).To(gomega.Equal(tt.ok))
+			g.Expect(baseline.Value).To(gomega.Equal(tt.value))
+		})
+	}
+}
+
+func TestRecordAndUpdate(t *testing.T) {
+	g := gomega.NewWithT(t)
+	baselines := &Baselines{Version: Version}
+	first := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.UTC)
+
+	g.Expect(baselines.Record(Baseline{Metric: MetricRcclBusBandwidth, GPU: "MI300", Value: 250, Updated: first, Note: "ROCm 6.0"})).To(gomega.Succeed())
+	g.Expect(baselines.Record(Baseline{Metric: MetricRcclBusBandwidth, Value: 100, Updated: first})).To(gomega.Succeed())
+	g.Expect(baselines.Record(Baseline{Metric: MetricRcclBusBandwidth, GPU: "MI300", Value: 260})).
+		To(gomega.MatchError(gomega.ContainSubstring("rccl-bus-bandwidth[cluster=*,gpu=MI300] already exists with value 250")))
+
+	g.Expect(baselines.Update(MetricRcclBusBandwidth, "", "MI300", 300, "ROCm 6.1", first.AddDate(0, 1, 0))).To(gomega.Succeed())
+	g.Expect(baselines.Update(MetricRcclBusBandwidth, "", "MI300", 320, "ROCm 6.2", first.AddDate(0, 2, 0))).To(gomega.Succeed())
+	g.Expect(baselines.Update(MetricRcclBusBandwidth, "perf", "MI300", 320, "", first)).
+		To(gomega.MatchError(gomega.ContainSubstring("rccl-bus-bandwidth[cluster=perf,gpu=MI300] doesn't exist")))
+
+	g.Expect(baselines.Baselines).To(gomega.Equal([]Baseline{
+		{Metric: MetricRcclBusBandwidth, GPU: "MI300", Value: 320, Updated: first.AddDate(0, 2, 0), Note: "ROCm 6.2",
+			// The history holds the previous values, the latest first
+			History: []Revision{
+				{Value: 300, Updated: first.AddDate(0, 1, 0), Note: "ROCm 6.1"},
+				{Value: 250, Updated: first, Note: "ROCm 6.0"},
+			}},
+		{Metric: MetricRcclBusBandwidth, Value: 100, Updated: first},
+	}))
+}
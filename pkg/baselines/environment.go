@@ -0,0 +1,52 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baselines
+
+import "os"
+
+const (
+	// The environment variable for location of the performance baselines, a file path or a s3://bucket/key URI
+	LocationEnvVar = "BASELINES_LOCATION"
+	// The environment variable for name of the cluster the performance baselines are looked up for
+	ClusterEnvVar = "BASELINES_CLUSTER"
+	// The environment variables for S3 compatible storage the baselines are stored in
+	s3EndpointEnvVar        = "AWS_DEFAULT_ENDPOINT"
+	s3AccessKeyIDEnvVar     = "AWS_ACCESS_KEY_ID"
+	s3SecretAccessKeyEnvVar = "AWS_SECRET_ACCESS_KEY"
+	s3RegionEnvVar          = "AWS_DEFAULT_REGION"
+)
+
+// Location returns the location of the baselines set with BASELINES_LOCATION, ok is false if not set.
+func Location() (string, bool) {
+	return os.LookupEnv(LocationEnvVar)
+}
+
+// Cluster returns the name of the cluster the baselines are looked up for set with BASELINES_CLUSTER, empty if not set.
+func Cluster() string {
+	return os.Getenv(ClusterEnvVar)
+}
+
+// S3ConfigFromEnv returns the S3 config set with the AWS_DEFAULT_ENDPOINT, AWS_ACCESS_KEY_ID, AWS_SECRET_ACCESS_KEY
+// and AWS_DEFAULT_REGION environment variables.
+func S3ConfigFromEnv() S3Config {
+	return S3Config{
+		Endpoint:        os.Getenv(s3EndpointEnvVar),
+		Region:          os.Getenv(s3RegionEnvVar),
+		AccessKeyID:     os.Getenv(s3AccessKeyIDEnvVar),
+		SecretAccessKey: os.Getenv(s3SecretAccessKeyEnvVar),
+	}
+}
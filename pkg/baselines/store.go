@@ -0,0 +1,230 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package baselines
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Store loads and saves baselines.
+type Store interface {
+	Load(ctx context.Context) (*Baselines, error)
+	Save(ctx context.Context, baselines *Baselines) error
+}
+
+// S3Config holds the endpoint and credentials of the S3 compatible storage baselines are stored in.
+type S3Config struct {
+	Endpoint        string
+	Region          string
+	AccessKeyID     string
+	SecretAccessKey string
+}
+
+// Open returns the store at the location, either a path of a file or a s3://bucket/key URI.
+func Open(location string, s3 S3Config) (Store, error) {
+	if !strings.HasPrefix(location, "s3://") {
+		return &FileStore{Path: location}, nil
+	}
+	bucket, key, ok := strings.Cut(strings.TrimPrefix(location, "s3://"), "/")
+	if !ok || bucket == "" || key == "" {
+		return nil, fmt.Errorf("invalid baselines location %q, expected s3://bucket/key", location)
+	}
+	if s3.Endpoint == "" {
+		return nil, fmt.Errorf("S3 endpoint isn't set for baselines location %q", location)
+	}
+	return &S3Store{S3Config: s3, Bucket: bucket, Key: key}, nil
+}
+
+// FileStore stores baselines in a file, typically committed in the repository.
+type FileStore struct {
+	Path string
+}
+
+var _ Store = &FileStore{}
+
+func (s *FileStore) Load(_ context.Context) (*Baselines, error) {
+	data, err := os.ReadFile(s.Path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return Parse(nil)
+	} else if err != nil {
+		return nil, err
+	}
+	return Parse(data)
+}
+
+func (s *FileStore) Save(_ context.Context, baselines *Baselines) error {
+	data, err := baselines.Marshal()
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(s.Path, data, 0644)
+}
+
+// S3Store stores baselines in an object of a S3 compatible storage bucket, addressed path-style.
+type S3Store struct {
+	S3Config
+	Bucket string
+	Key    string
+}
+
+var _ Store = &S3Store{}
+
+func (s *S3Store) Load(ctx context.Context) (*Baselines, error) {
+	response, err := s.do(ctx, http.MethodGet, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer response.Body.Close()
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, err
+	}
+	switch response.StatusCode {
+	case http.StatusOK:
+		return Parse(data)
+	case http.StatusNotFound:
+		return Parse(nil)
+	default:
+		return nil, fmt.Errorf("error getting baselines from s3://%s/%s: %s\n%s", s.Bucket, s.Key, response.Status, data)
+	}
+}
+
+func (s *S3Store) Save(ctx context.Context, baselines *Baselines) error {
+	data, err := baselines.Marshal()
+	if err != nil {
+		return err
+	}
+	response, err := s.do(ctx, http.MethodPut, data)
+	if err != nil {
+		return err
+	}
+	defer response.Body.Close()
+	if response.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(response.Body)
+		return fmt.Errorf("error putting baselines to s3://%s/%s: %s\n%s", s.Bucket, s.Key, response.Status, body)
+	}
+	return nil
+}
+
+// do sends the request for the object signed with AWS signature version 4.
+func (s *S3Store) do(ctx context.Context, method string, body []byte) (*http.Response, error) {
+	endpoint, err := url.Parse(s.Endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("invalid S3 endpoint %q: %w", s.Endpoint, err)
+	}
+	if endpoint.Scheme == "" {
+		endpoint, err = url.Parse("https://" + s.Endpoint)
+		if err != nil {
+			return nil, fmt.Errorf("invalid S3 endpoint %q: %w", s.Endpoint, err)
+		}
+	}
+
+	path := strings.TrimSuffix(endpoint.Path, "/") + "/" + s.Bucket + "/" + s.Key
+	request, err := http.NewRequestWithContext(ctx, method, endpoint.Scheme+"://"+endpoint.Host+escapePath(path), bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	if method == http.MethodPut {
+		request.Header.Set("Content-Type", "application/json")
+	}
+	s.sign(request, escapePath(path), body, time.Now().UTC())
+	return http.DefaultClient.Do(request)
+}
+
+// sign signs the request with AWS signature version 4, with the headers set on the request and the host.
+func (s *S3Store) sign(request *http.Request, canonicalURI string, body []byte, now time.Time) {
+	region := s.Region
+	if region == "" {
+		region = "us-east-1"
+	}
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	payloadHash := sha256Hex(body)
+
+	request.Header.Set("X-Amz-Date", amzDate)
+	request.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	// The headers set on the request are all signed, the ones added by the HTTP client when sending it aren't
+	headers := map[string]string{"host": request.URL.Host}
+	for name, values := range request.Header {
+		headers[strings.ToLower(name)] = strings.Join(values, ",")
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + strings.TrimSpace(headers[name]) + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{request.Method, canonicalURI, "", canonicalHeaders.String(), signedHeaders, payloadHash}, "\n")
+	scope := date + "/" + region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{"AWS4-HMAC-SHA256", amzDate, scope, sha256Hex([]byte(canonicalRequest))}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.SecretAccessKey), date)
+	key = hmacSHA256(key, region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	request.Header.Set("Authorization", fmt.Sprintf("AWS4-HMAC-SHA256 Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		s.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// escapePath escapes the segments of the path as required by AWS signature version 4.
+func escapePath(path string) string {
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		var escaped strings.Builder
+		for _, c := range []byte(segment) {
+			if 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z' || '0' <= c && c <= '9' || c == '-' || c == '_' || c == '.' || c == '~' {
+				escaped.WriteByte(c)
+			} else {
+				fmt.Fprintf(&escaped, "%%%02X", c)
+			}
+		}
+		segments[i] = escaped.String()
+	}
+	return strings.Join(segments, "/")
+}
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
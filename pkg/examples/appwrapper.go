@@ -0,0 +1,127 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package examples
+
+import (
+	"encoding/json"
+	"fmt"
+
+	kftov1 "github.com/kubeflow/training-operator/pkg/apis/kubeflow.org/v1"
+	awv1beta2 "github.com/project-codeflare/appwrapper/api/v1beta2"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	batchv1 "k8s.io/api/batch/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+type PodSet = awv1beta2.AppWrapperPodSet
+
+// AppWrapper returns an AppWrapper with the object as its only component, the pod sets describe the pods
+// created for the object. The Kueue LocalQueue label is moved from the object to the AppWrapper, so only the
+// AppWrapper is queued.
+func AppWrapper(name, namespace string, object runtime.Object, podSets []PodSet) (*awv1beta2.AppWrapper, error) {
+	component, localQueue, err := appWrapperComponent(object, podSets)
+	if err != nil {
+		return nil, err
+	}
+	return newAppWrapper(name, namespace, localQueue, component), nil
+}
+
+// AppWrapperOf returns an AppWrapper wrapping the objects as its components, in order, i.e. a Job with the Service
+// and ConfigMap it uses. The pod sets of the Jobs, PyTorchJobs and RayClusters are derived from their specs, so the
+// AppWrapper completes once their pods complete, the other objects create no pods. The Kueue LocalQueue labels are
+// moved from the objects to the AppWrapper, so only the AppWrapper is queued.
+func AppWrapperOf(name, namespace string, objects ...runtime.Object) (*awv1beta2.AppWrapper, error) {
+	var components []awv1beta2.AppWrapperComponent
+	var localQueue string
+	for _, object := range objects {
+		component, objectLocalQueue, err := appWrapperComponent(object, PodSetsOf(object))
+		if err != nil {
+			return nil, err
+		}
+		if objectLocalQueue != "" {
+			if localQueue != "" && localQueue != objectLocalQueue {
+				return nil, fmt.Errorf("objects of AppWrapper %s queued in different LocalQueues %s and %s", name, localQueue, objectLocalQueue)
+			}
+			localQueue = objectLocalQueue
+		}
+		components = append(components, component)
+	}
+	return newAppWrapper(name, namespace, localQueue, components...), nil
+}
+
+// PodSetsOf returns the AppWrapper pod sets of the Job, PyTorchJob or RayCluster, or none for the objects creating no pods.
+func PodSetsOf(object runtime.Object) []PodSet {
+	switch object := object.(type) {
+	case *batchv1.Job:
+		return JobPodSets(object)
+	case *kftov1.PyTorchJob:
+		return PyTorchJobPodSets(object)
+	case *rayv1.RayCluster:
+		return RayClusterPodSets(object)
+	default:
+		return nil
+	}
+}
+
+// appWrapperComponent returns the component wrapping the object, and the LocalQueue the object was queued in.
+func appWrapperComponent(object runtime.Object, podSets []PodSet) (awv1beta2.AppWrapperComponent, string, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(object)
+	if err != nil {
+		return awv1beta2.AppWrapperComponent{}, "", err
+	}
+	delete(content, "status")
+	localQueue, _, err := unstructured.NestedString(content, "metadata", "labels", kueueQueueNameLabel)
+	if err != nil {
+		return awv1beta2.AppWrapperComponent{}, "", err
+	}
+	unstructured.RemoveNestedField(content, "metadata", "labels", kueueQueueNameLabel)
+	template, err := json.Marshal(content)
+	if err != nil {
+		return awv1beta2.AppWrapperComponent{}, "", err
+	}
+	return awv1beta2.AppWrapperComponent{
+		PodSets:  podSets,
+		Template: runtime.RawExtension{Raw: template},
+	}, localQueue, nil
+}
+
+func newAppWrapper(name, namespace, localQueue string, components ...awv1beta2.AppWrapperComponent) *awv1beta2.AppWrapper {
+	appWrapper := &awv1beta2.AppWrapper{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: awv1beta2.GroupVersion.String(),
+			Kind:       "AppWrapper",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: awv1beta2.AppWrapperSpec{
+			Components: components,
+		},
+	}
+
+	if localQueue != "" {
+		appWrapper.Labels = map[string]string{
+			kueueQueueNameLabel: localQueue,
+		}
+	}
+
+	return appWrapper
+}
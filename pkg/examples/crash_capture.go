@@ -0,0 +1,59 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package examples
+
+import (
+	"fmt"
+)
+
+const (
+	// CrashCaptureMarker prefixes the lines the crash capture wrapper reports crashes with in the container logs
+	CrashCaptureMarker = "CRASH_CAPTURE"
+	// Largest compressed core dump reported in the container logs, kubelet rotates the logs at 10Mi by default
+	crashCaptureMaxCoreBytes = 8 * 1024 * 1024
+)
+
+// crashCaptureScript runs the command with Python faulthandler and core dumps enabled. Once the command is killed by
+// a signal, i.e. a segfault in a native library, it reports the signal and the core dumps written into the working
+// directory in the container logs, compressed and base64 encoded. Core dumps are only written into the working
+// directory when the kernel.core_pattern of the node is a relative path, faulthandler tracebacks are reported anyway.
+var crashCaptureScript = fmt.Sprintf(`ulimit -c unlimited 2> /dev/null || echo "%[1]s core dumps limited to $(ulimit -c) blocks"
+export PYTHONFAULTHANDLER=1
+"$@"
+code=$?
+if [ $code -gt 128 ]; then
+  echo "%[1]s BEGIN signal=$((code - 128)) exit=$code"
+  for core in core core.*; do
+    [ -f "$core" ] || continue
+    compressed=$(gzip -c "$core" | wc -c)
+    echo "%[1]s core $core $(wc -c < "$core") bytes, $compressed bytes compressed"
+    if [ "$compressed" -le %[2]d ]; then
+      echo "%[1]s CORE BEGIN $core"
+      gzip -c "$core" | base64
+      echo "%[1]s CORE END $core"
+    fi
+  done
+  echo "%[1]s END"
+fi
+exit $code
+`, CrashCaptureMarker, crashCaptureMaxCoreBytes)
+
+// CrashCaptureCommand wraps the command in the crash capture script, so crashes of native libraries, i.e. NCCL or
+// flash-attn, are debuggable from the container logs.
+func CrashCaptureCommand(command []string) []string {
+	return append([]string{"sh", "-c", crashCaptureScript, "crash-capture"}, command...)
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package examples builds the workloads exercised by the e2e tests, so the documentation and customer examples
+// are rendered from the same tested code rather than hand-written YAML.
+package examples
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+const (
+	// FmsHfTuningImage is the FMS HF Tuning image the PyTorchJob examples and e2e tests run by default
+	FmsHfTuningImage = "quay.io/modh/fms-hf-tuning:b71215c3ae202eab9da1d347f52b89feb3d0378c"
+
+	kueueQueueNameLabel     = "kueue.x-k8s.io/queue-name"
+	kueuePriorityClassLabel = "kueue.x-k8s.io/priority-class"
+)
+
+type Example struct {
+	// Name is the file name the example is rendered into, without extension
+	Name        string
+	Description string
+	Object      runtime.Object
+}
+
+type CatalogOptions struct {
+	RayVersion      string
+	RayImage        string
+	PyTorchJobImage string
+	LocalQueue      string
+}
+
+// Catalog returns the examples rendered into the documentation.
+func Catalog(options CatalogOptions) ([]Example, error) {
+	rayCluster := RayCluster(RayClusterOptions{
+		Name:       "raycluster",
+		RayVersion: options.RayVersion,
+		Image:      options.RayImage,
+		Workers:    2,
+		WorkerCPUs: "1",
+		LocalQueue: options.LocalQueue,
+	})
+
+	pytorchJob := PyTorchJob(PyTorchJobOptions{
+		Name:       "pytorchjob",
+		Image:      options.PyTorchJobImage,
+		Command:    []string{"python", "-c", "import torch.distributed as dist; dist.init_process_group('gloo'); print(f'Rank {dist.get_rank()} of {dist.get_world_size()}')"},
+		Workers:    1,
+		CPU:        "500m",
+		Memory:     "1Gi",
+		LocalQueue: options.LocalQueue,
+	})
+
+	rayClusterAppWrapper, err := AppWrapperOf("raycluster", "", rayCluster)
+	if err != nil {
+		return nil, err
+	}
+	pytorchJobAppWrapper, err := AppWrapperOf("pytorchjob", "", pytorchJob)
+	if err != nil {
+		return nil, err
+	}
+
+	return []Example{
+		{
+			Name:        "raycluster",
+			Description: "RayCluster with two workers",
+			Object:      rayCluster,
+		},
+		{
+			Name:        "pytorchjob",
+			Description: "PyTorchJob with a master and one worker",
+			Object:      pytorchJob,
+		},
+		{
+			Name:        "appwrapper-raycluster",
+			Description: "RayCluster wrapped in an AppWrapper",
+			Object:      rayClusterAppWrapper,
+		},
+		{
+			Name:        "appwrapper-pytorchjob",
+			Description: "PyTorchJob wrapped in an AppWrapper",
+			Object:      pytorchJobAppWrapper,
+		},
+	}, nil
+}
+
+func ptr[T any](v T) *T {
+	return &v
+}
@@ -0,0 +1,35 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package examples
+
+import (
+	batchv1 "k8s.io/api/batch/v1"
+)
+
+// JobPodSets returns the AppWrapper pod sets of the batch Job.
+func JobPodSets(job *batchv1.Job) []PodSet {
+	replicas := int32(1)
+	if job.Spec.Parallelism != nil {
+		replicas = *job.Spec.Parallelism
+	}
+	return []PodSet{
+		{
+			Replicas: ptr(replicas),
+			PodPath:  "template.spec.template",
+		},
+	}
+}
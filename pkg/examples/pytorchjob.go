@@ -0,0 +1,165 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package examples
+
+import (
+	kftov1 "github.com/kubeflow/training-operator/pkg/apis/kubeflow.org/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// PyTorchJobScriptsMountPath is the path the scripts ConfigMap is mounted at in the PyTorchJob pods.
+const PyTorchJobScriptsMountPath = "/etc/config"
+
+type PyTorchJobOptions struct {
+	// GenerateName is used when Name is not set
+	Name         string
+	GenerateName string
+	Namespace    string
+	Image        string
+	Command      []string
+	Env          []corev1.EnvVar
+	// Workers is the number of workers besides the master
+	Workers int32
+	CPU     string
+	Memory  string
+	// LocalQueue is the Kueue LocalQueue the PyTorchJob is queued in when set
+	LocalQueue string
+	// PriorityClass is the Kueue WorkloadPriorityClass the PyTorchJob is queued with when set
+	PriorityClass string
+	// ScriptsConfigMap is the ConfigMap mounted into all the pods when set
+	ScriptsConfigMap string
+	// CrashCapture wraps the command to report faulthandler tracebacks and core dumps of crashes in the logs
+	CrashCapture bool
+	// ElasticPolicy makes the PyTorchJob elastic when set, with only the workers and no master, the command
+	// being run with torchrun configured by the operator from the policy
+	ElasticPolicy *kftov1.ElasticPolicy
+}
+
+// PyTorchJob returns a PyTorchJob with a master and the requested number of workers, all running the same command,
+// or only with the workers when elastic.
+func PyTorchJob(options PyTorchJobOptions) *kftov1.PyTorchJob {
+	command := options.Command
+	if options.CrashCapture {
+		command = CrashCaptureCommand(command)
+	}
+
+	podTemplate := corev1.PodTemplateSpec{
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:            "pytorch",
+					Image:           options.Image,
+					ImagePullPolicy: corev1.PullIfNotPresent,
+					Command:         command,
+					Env:             options.Env,
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse(options.CPU),
+							corev1.ResourceMemory: resource.MustParse(options.Memory),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	if options.ScriptsConfigMap != "" {
+		podTemplate.Spec.Containers[0].VolumeMounts = []corev1.VolumeMount{
+			{
+				Name:      "config-volume",
+				MountPath: PyTorchJobScriptsMountPath,
+			},
+		}
+		podTemplate.Spec.Volumes = []corev1.Volume{
+			{
+				Name: "config-volume",
+				VolumeSource: corev1.VolumeSource{
+					ConfigMap: &corev1.ConfigMapVolumeSource{
+						LocalObjectReference: corev1.LocalObjectReference{
+							Name: options.ScriptsConfigMap,
+						},
+					},
+				},
+			},
+		}
+	}
+
+	job := &kftov1.PyTorchJob{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: kftov1.SchemeGroupVersion.String(),
+			Kind:       "PyTorchJob",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:         options.Name,
+			GenerateName: options.GenerateName,
+			Namespace:    options.Namespace,
+		},
+		Spec: kftov1.PyTorchJobSpec{
+			PyTorchReplicaSpecs: map[kftov1.ReplicaType]*kftov1.ReplicaSpec{},
+		},
+	}
+
+	if options.ElasticPolicy != nil {
+		job.Spec.ElasticPolicy = options.ElasticPolicy
+	} else {
+		job.Spec.PyTorchReplicaSpecs["Master"] = &kftov1.ReplicaSpec{
+			Replicas:      ptr(int32(1)),
+			RestartPolicy: "Never",
+			Template:      *podTemplate.DeepCopy(),
+		}
+	}
+
+	if options.Workers > 0 {
+		job.Spec.PyTorchReplicaSpecs["Worker"] = &kftov1.ReplicaSpec{
+			Replicas:      ptr(options.Workers),
+			RestartPolicy: "Never",
+			Template:      *podTemplate.DeepCopy(),
+		}
+	}
+
+	if options.LocalQueue != "" {
+		job.Labels = map[string]string{
+			kueueQueueNameLabel: options.LocalQueue,
+		}
+	}
+	if options.PriorityClass != "" {
+		if job.Labels == nil {
+			job.Labels = map[string]string{}
+		}
+		job.Labels[kueuePriorityClassLabel] = options.PriorityClass
+	}
+
+	return job
+}
+
+// PyTorchJobPodSets returns the AppWrapper pod sets of the PyTorchJob replicas.
+func PyTorchJobPodSets(job *kftov1.PyTorchJob) []PodSet {
+	var podSets []PodSet
+	for _, replicaType := range []kftov1.ReplicaType{"Master", "Worker"} {
+		if _, ok := job.Spec.PyTorchReplicaSpecs[replicaType]; !ok {
+			continue
+		}
+		podSets = append(podSets, PodSet{
+			ReplicaPath: "template.spec.pytorchReplicaSpecs." + string(replicaType) + ".replicas",
+			PodPath:     "template.spec.pytorchReplicaSpecs." + string(replicaType) + ".template",
+		})
+	}
+	return podSets
+}
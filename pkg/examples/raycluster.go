@@ -0,0 +1,228 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package examples
+
+import (
+	"fmt"
+
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// RayClusterScriptsMountPath is the path the scripts ConfigMap is mounted at in the RayCluster head.
+const RayClusterScriptsMountPath = "/home/ray/scripts"
+
+const nvidiaGpuResource corev1.ResourceName = "nvidia.com/gpu"
+
+type RayClusterOptions struct {
+	Name       string
+	Namespace  string
+	RayVersion string
+	Image      string
+	// Workers is the fixed number of workers in the worker group
+	Workers int32
+	// WorkerCPUs is the number of CPUs each worker advertises to Ray
+	WorkerCPUs string
+	// WorkerGPUs is the number of GPUs requested by each worker and advertised to Ray when set
+	WorkerGPUs int32
+	// WorkerGPUResource is the resource the GPUs of the workers are requested with, defaults to NVIDIA GPUs
+	WorkerGPUResource corev1.ResourceName
+	// WorkerTolerations are added to the workers when set, i.e. to tolerate the taint of GPU nodes
+	WorkerTolerations []corev1.Toleration
+	// LocalQueue is the Kueue LocalQueue the RayCluster is queued in when set
+	LocalQueue string
+	// ScriptsConfigMap is the ConfigMap mounted into the head when set
+	ScriptsConfigMap string
+	// NodeSelector constrains the head and worker pods, i.e. to nodes of a CPU architecture, when set
+	NodeSelector map[string]string
+	// DashboardHost is the address the Ray dashboard listens on, defaults to 0.0.0.0, i.e. use :: on IPv6 clusters
+	DashboardHost string
+}
+
+// RayCluster returns a RayCluster with a single worker group.
+func RayCluster(options RayClusterOptions) *rayv1.RayCluster {
+	rayCluster := &rayv1.RayCluster{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: rayv1.GroupVersion.String(),
+			Kind:       "RayCluster",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      options.Name,
+			Namespace: options.Namespace,
+		},
+		Spec: *RayClusterSpec(options),
+	}
+
+	if options.LocalQueue != "" {
+		rayCluster.Labels = map[string]string{
+			kueueQueueNameLabel: options.LocalQueue,
+		}
+	}
+
+	return rayCluster
+}
+
+// RayClusterSpec returns RayCluster specification with the scripts ConfigMap mounted into the head when set.
+// The head doesn't advertise any CPU to Ray, so Ray tasks and actors are scheduled on workers only.
+func RayClusterSpec(options RayClusterOptions) *rayv1.RayClusterSpec {
+	dashboardHost := options.DashboardHost
+	if dashboardHost == "" {
+		dashboardHost = "0.0.0.0"
+	}
+
+	rayClusterSpec := &rayv1.RayClusterSpec{
+		RayVersion: options.RayVersion,
+		HeadGroupSpec: rayv1.HeadGroupSpec{
+			RayStartParams: map[string]string{
+				"dashboard-host": dashboardHost,
+				"num-cpus":       "0",
+			},
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:  "ray-head",
+							Image: options.Image,
+							Ports: []corev1.ContainerPort{
+								{
+									ContainerPort: 6379,
+									Name:          "gcs",
+								},
+								{
+									ContainerPort: 8265,
+									Name:          "dashboard",
+								},
+								{
+									ContainerPort: 10001,
+									Name:          "client",
+								},
+							},
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									corev1.ResourceCPU:    resource.MustParse("300m"),
+									corev1.ResourceMemory: resource.MustParse("1G"),
+								},
+								Limits: corev1.ResourceList{
+									corev1.ResourceCPU:    resource.MustParse("1"),
+									corev1.ResourceMemory: resource.MustParse("2G"),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+		WorkerGroupSpecs: []rayv1.WorkerGroupSpec{
+			{
+				GroupName:   "small-group",
+				Replicas:    ptr(options.Workers),
+				MinReplicas: ptr(options.Workers),
+				MaxReplicas: ptr(options.Workers),
+				RayStartParams: map[string]string{
+					"num-cpus": options.WorkerCPUs,
+				},
+				Template: corev1.PodTemplateSpec{
+					Spec: corev1.PodSpec{
+						Containers: []corev1.Container{
+							{
+								Name:  "ray-worker",
+								Image: options.Image,
+								Resources: corev1.ResourceRequirements{
+									Requests: corev1.ResourceList{
+										corev1.ResourceCPU:    resource.MustParse("300m"),
+										corev1.ResourceMemory: resource.MustParse("1G"),
+									},
+									Limits: corev1.ResourceList{
+										corev1.ResourceCPU:    resource.MustParse("1"),
+										corev1.ResourceMemory: resource.MustParse("2G"),
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	if options.NodeSelector != nil {
+		rayClusterSpec.HeadGroupSpec.Template.Spec.NodeSelector = options.NodeSelector
+		for i := range rayClusterSpec.WorkerGroupSpecs {
+			rayClusterSpec.WorkerGroupSpecs[i].Template.Spec.NodeSelector = options.NodeSelector
+		}
+	}
+
+	if options.WorkerGPUs > 0 {
+		gpus := resource.MustParse(fmt.Sprint(options.WorkerGPUs))
+		gpuResource := options.WorkerGPUResource
+		if gpuResource == "" {
+			gpuResource = nvidiaGpuResource
+		}
+		for i := range rayClusterSpec.WorkerGroupSpecs {
+			workerGroupSpec := &rayClusterSpec.WorkerGroupSpecs[i]
+			workerGroupSpec.RayStartParams["num-gpus"] = fmt.Sprint(options.WorkerGPUs)
+			resources := &workerGroupSpec.Template.Spec.Containers[0].Resources
+			resources.Requests[gpuResource] = gpus
+			resources.Limits[gpuResource] = gpus
+		}
+	}
+
+	for i := range rayClusterSpec.WorkerGroupSpecs {
+		workerSpec := &rayClusterSpec.WorkerGroupSpecs[i].Template.Spec
+		workerSpec.Tolerations = append(workerSpec.Tolerations, options.WorkerTolerations...)
+	}
+
+	if options.ScriptsConfigMap != "" {
+		headSpec := &rayClusterSpec.HeadGroupSpec.Template.Spec
+		headSpec.Containers[0].VolumeMounts = append(headSpec.Containers[0].VolumeMounts, corev1.VolumeMount{
+			Name:      "scripts",
+			MountPath: RayClusterScriptsMountPath,
+		})
+		headSpec.Volumes = append(headSpec.Volumes, corev1.Volume{
+			Name: "scripts",
+			VolumeSource: corev1.VolumeSource{
+				ConfigMap: &corev1.ConfigMapVolumeSource{
+					LocalObjectReference: corev1.LocalObjectReference{
+						Name: options.ScriptsConfigMap,
+					},
+				},
+			},
+		})
+	}
+
+	return rayClusterSpec
+}
+
+// RayClusterPodSets returns the AppWrapper pod sets of the RayCluster head and worker groups.
+func RayClusterPodSets(rayCluster *rayv1.RayCluster) []PodSet {
+	podSets := []PodSet{
+		{
+			Replicas: ptr(int32(1)),
+			PodPath:  "template.spec.headGroupSpec.template",
+		},
+	}
+	for i := range rayCluster.Spec.WorkerGroupSpecs {
+		podSets = append(podSets, PodSet{
+			ReplicaPath: fmt.Sprintf("template.spec.workerGroupSpecs[%d].replicas", i),
+			PodPath:     fmt.Sprintf("template.spec.workerGroupSpecs[%d].template", i),
+		})
+	}
+	return podSets
+}
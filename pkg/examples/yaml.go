@@ -0,0 +1,52 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package examples
+
+import (
+	"k8s.io/apimachinery/pkg/runtime"
+	"sigs.k8s.io/yaml"
+)
+
+// ToYAML renders the object as YAML manifest, omitting the fields set by the cluster.
+func ToYAML(object runtime.Object) ([]byte, error) {
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(object)
+	if err != nil {
+		return nil, err
+	}
+	delete(content, "status")
+	removeNullCreationTimestamps(content)
+
+	return yaml.Marshal(content)
+}
+
+// removeNullCreationTimestamps removes the unset creation timestamps, serialized as null, from the object
+// and all the nested templates.
+func removeNullCreationTimestamps(value any) {
+	switch value := value.(type) {
+	case map[string]any:
+		if timestamp, ok := value["creationTimestamp"]; ok && timestamp == nil {
+			delete(value, "creationTimestamp")
+		}
+		for _, nested := range value {
+			removeNullCreationTimestamps(nested)
+		}
+	case []any:
+		for _, nested := range value {
+			removeNullCreationTimestamps(nested)
+		}
+	}
+}
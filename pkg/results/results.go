@@ -0,0 +1,278 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package results reads the results file the test suites append their summaries to when TEST_REPORT_FILE is set,
+// so tools gating a release, i.e. an approval bot, consume structured outcomes instead of parsing the console output.
+// The format of the file, one suite summary per line, is described by the JSON schema in Schema.
+package results
+
+import (
+	"bufio"
+	"bytes"
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Schema is the JSON schema of a line of the results file.
+//
+//go:embed schema.json
+var Schema []byte
+
+// Status is the status of a single run of a test.
+type Status string
+
+const (
+	StatusPassed  Status = "passed"
+	StatusFailed  Status = "failed"
+	StatusSkipped Status = "skipped"
+	// StatusXFailed and StatusXPassed are the statuses of tests marked as expected to fail which failed, or passed
+	StatusXFailed Status = "xfailed"
+	StatusXPassed Status = "xpassed"
+)
+
+// Suite is the summary of a run of a test suite, a line of the results file.
+type Suite struct {
+	Suite    string        `json:"suite"`
+	Duration time.Duration `json:"duration"`
+	Clusters []string      `json:"clusters,omitempty"`
+	// Images maps the images run by the pods of the tests to the image digests they resolved to
+	Images  map[string]string `json:"images,omitempty"`
+	Results []Result          `json:"results"`
+	// Leaked are the cluster-scoped objects created by the tests found left over once they ran
+	Leaked []string `json:"leaked,omitempty"`
+}
+
+// Result is the result of a single run of a test.
+type Result struct {
+	Name         string             `json:"name"`
+	Status       Status             `json:"status"`
+	Duration     time.Duration      `json:"duration"`
+	Labels       []string           `json:"labels,omitempty"`
+	Failures     []string           `json:"failures,omitempty"`
+	Issue        string             `json:"issue,omitempty"`
+	Measurements map[string]float64 `json:"measurements,omitempty"`
+	Contaminated []string           `json:"contaminated,omitempty"`
+	Timeout      time.Duration      `json:"timeout,omitempty"`
+	Cluster      string             `json:"cluster,omitempty"`
+}
+
+// Outcome is the outcome of a test over all its runs in a suite.
+type Outcome string
+
+const (
+	// OutcomePass is the outcome of a test which passed in all its runs
+	OutcomePass Outcome = "pass"
+	// OutcomeFlake is the outcome of a test which both failed and passed, i.e. when the suite is run with -count or retried
+	OutcomeFlake Outcome = "flake"
+	// OutcomeFail is the outcome of a test which failed in all its runs, or passed while expected to fail
+	OutcomeFail Outcome = "fail"
+	// OutcomeSkip is the outcome of a test which was skipped in all its runs
+	OutcomeSkip Outcome = "skip"
+	// OutcomeExpectedFail is the outcome of a test marked as expected to fail which failed
+	OutcomeExpectedFail Outcome = "expected-fail"
+)
+
+// TestOutcome is the outcome of a test of a suite run against a cluster.
+type TestOutcome struct {
+	Suite   string  `json:"suite"`
+	Name    string  `json:"name"`
+	Cluster string  `json:"cluster,omitempty"`
+	Outcome Outcome `json:"outcome"`
+	Runs    int     `json:"runs"`
+	// Issue is the issue tracking the bug the test is expected to fail with, if any
+	Issue string `json:"issue,omitempty"`
+}
+
+// String returns the test qualified with its suite and the cluster it ran against, if any.
+func (o TestOutcome) String() string {
+	name := o.Suite + "/" + o.Name
+	if o.Cluster != "" {
+		name = fmt.Sprintf("%s [%s]", name, o.Cluster)
+	}
+	return name
+}
+
+// Parse parses the content of a results file, empty lines being ignored.
+func Parse(data []byte) ([]Suite, error) {
+	return Read(bytes.NewReader(data))
+}
+
+// ReadFile reads the results file at the path.
+func ReadFile(path string) ([]Suite, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Read(f)
+}
+
+// Read reads the suite summaries from a results file, one per line.
+func Read(reader io.Reader) ([]Suite, error) {
+	var suites []Suite
+	scanner := bufio.NewScanner(reader)
+	// Summaries of suites with many results and failure messages are long lines
+	scanner.Buffer(make([]byte, 0, 64*1024), 64*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		var suite Suite
+		if err := json.Unmarshal(scanner.Bytes(), &suite); err != nil {
+			return nil, fmt.Errorf("error parsing results line %d: %w", line, err)
+		}
+		if suite.Suite == "" {
+			return nil, fmt.Errorf("error parsing results line %d: missing suite name", line)
+		}
+		suites = append(suites, suite)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading results: %w", err)
+	}
+	return suites, nil
+}
+
+// Outcomes returns the outcome of each test of the suite per cluster, sorted by cluster and name.
+func (s Suite) Outcomes() []TestOutcome {
+	type key struct{ name, cluster string }
+	statuses := map[key]map[Status]int{}
+	issues := map[key]string{}
+	for _, result := range s.Results {
+		k := key{result.Name, result.Cluster}
+		if statuses[k] == nil {
+			statuses[k] = map[Status]int{}
+		}
+		statuses[k][result.Status]++
+		if result.Issue != "" {
+			issues[k] = result.Issue
+		}
+	}
+
+	var outcomes []TestOutcome
+	for k, status := range statuses {
+		runs := 0
+		for _, count := range status {
+			runs += count
+		}
+		outcomes = append(outcomes, TestOutcome{
+			Suite:   s.Suite,
+			Name:    k.name,
+			Cluster: k.cluster,
+			Outcome: outcome(status),
+			Runs:    runs,
+			Issue:   issues[k],
+		})
+	}
+	sort.Slice(outcomes, func(i, j int) bool {
+		if outcomes[i].Cluster != outcomes[j].Cluster {
+			return outcomes[i].Cluster < outcomes[j].Cluster
+		}
+		return outcomes[i].Name < outcomes[j].Name
+	})
+	return outcomes
+}
+
+func outcome(status map[Status]int) Outcome {
+	switch {
+	case status[StatusPassed] > 0 && status[StatusFailed] > 0:
+		return OutcomeFlake
+	case status[StatusFailed] > 0 || status[StatusXPassed] > 0:
+		return OutcomeFail
+	case status[StatusPassed] > 0:
+		return OutcomePass
+	case status[StatusXFailed] > 0:
+		return OutcomeExpectedFail
+	default:
+		return OutcomeSkip
+	}
+}
+
+// Report is the outcome of all the suites of a results file.
+type Report struct {
+	// Suites are the names of the suites which ran, in the order they ran
+	Suites []string `json:"suites"`
+	// Images maps the images run by the suites to their digests
+	Images   map[string]string `json:"images,omitempty"`
+	Outcomes []TestOutcome     `json:"outcomes"`
+	Leaked   []string          `json:"leaked,omitempty"`
+}
+
+// NewReport combines the outcomes of the suites. A suite run more than once, i.e. by different jobs appending to the
+// same results file, has the results of all its runs combined, so a test failing in one run and passing in another
+// is a flake.
+func NewReport(suites []Suite) Report {
+	report := Report{Images: map[string]string{}}
+	combined := map[string]*Suite{}
+	for _, suite := range suites {
+		if existing, ok := combined[suite.Suite]; ok {
+			existing.Results = append(existing.Results, suite.Results...)
+		} else {
+			combined[suite.Suite] = &Suite{Suite: suite.Suite, Results: append([]Result(nil), suite.Results...)}
+			report.Suites = append(report.Suites, suite.Suite)
+		}
+		for image, digest := range suite.Images {
+			report.Images[image] = digest
+		}
+		report.Leaked = append(report.Leaked, suite.Leaked...)
+	}
+	for _, name := range report.Suites {
+		report.Outcomes = append(report.Outcomes, combined[name].Outcomes()...)
+	}
+	return report
+}
+
+// Count returns the number of tests with the outcome.
+func (r Report) Count(outcome Outcome) int {
+	count := 0
+	for _, o := range r.Outcomes {
+		if o.Outcome == outcome {
+			count++
+		}
+	}
+	return count
+}
+
+// With returns the tests with the outcome.
+func (r Report) With(outcome Outcome) []TestOutcome {
+	var outcomes []TestOutcome
+	for _, o := range r.Outcomes {
+		if o.Outcome == outcome {
+			outcomes = append(outcomes, o)
+		}
+	}
+	return outcomes
+}
+
+// Passed returns whether no test failed and no cluster-scoped object leaked. Flaky tests don't fail the report,
+// callers stricter about flakes check them with With(OutcomeFlake).
+func (r Report) Passed() bool {
+	return r.Count(OutcomeFail) == 0 && len(r.Leaked) == 0
+}
+
+// String returns a single line summary of the report.
+func (r Report) String() string {
+	return fmt.Sprintf("%d suites: %d passed, %d flaky, %d failed, %d skipped, %d expected failures, %d leaked objects",
+		len(r.Suites), r.Count(OutcomePass), r.Count(OutcomeFlake), r.Count(OutcomeFail), r.Count(OutcomeSkip),
+		r.Count(OutcomeExpectedFail), len(r.Leaked))
+}
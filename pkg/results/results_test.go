@@ -0,0 +1,139 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package results
+
+import (
+	"testing"
+
+	"github.com/onsi/gomega"
+)
+
+func TestSuiteOutcomes(t *testing.T) {
+	tests := []struct {
+		name     string
+		statuses []Status
+		outcome  Outcome
+		runs     int
+	}{
+		{name: "passed", statuses: []Status{StatusPassed}, outcome: OutcomePass, runs: 1},
+		{name: "passed in all runs", statuses: []Status{StatusPassed, StatusPassed}, outcome: OutcomePass, runs: 2},
+		{name: "failed", statuses: []Status{StatusFailed}, outcome: OutcomeFail, runs: 1},
+		{name: "failed in all runs", statuses: []Status{StatusFailed, StatusFailed}, outcome: OutcomeFail, runs: 2},
+		{name: "failed then passed", statuses: []Status{StatusFailed, StatusPassed}, outcome: OutcomeFlake, runs: 2},
+		{name: "passed then failed", statuses: []Status{StatusPassed, StatusFailed, StatusPassed}, outcome: OutcomeFlake, runs: 3},
+		{name: "skipped", statuses: []Status{StatusSkipped}, outcome: OutcomeSkip, runs: 1},
+		{name: "skipped then passed", statuses: []Status{StatusSkipped, StatusPassed}, outcome: OutcomePass, runs: 2},
+		{name: "skipped then failed", statuses: []Status{StatusSkipped, StatusFailed}, outcome: OutcomeFail, runs: 2},
+		{name: "failed as expected", statuses: []Status{StatusXFailed}, outcome: OutcomeExpectedFail, runs: 1},
+		{name: "passed while expected to fail", statuses: []Status{StatusXPassed}, outcome: OutcomeFail, runs: 1},
+		{name: "passed once while expected to fail", statuses: []Status{StatusXFailed, StatusXPassed}, outcome: OutcomeFail, runs: 2},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+			suite := Suite{Suite: "kueue"}
+			for _, status := range tt.statuses {
+				suite.Results = append(suite.Results, Result{Name: "TestKueue", Status: status, Issue: "RHOAIENG-1"})
+			}
+			g.Expect(suite.Outcomes()).To(gomega.Equal([]TestOutcome{
+				{Suite: "kueue", Name: "TestKueue", Outcome: tt.outcome, Runs: tt.runs, Issue: "RHOAIENG-1"},
+			}))
+		})
+	}
+}
+
+func TestSuiteOutcomesPerCluster(t *testing.T) {
+	g := gomega.NewWithT(t)
+	suite := Suite{Suite: "kueue", Results: []Result{
+		{Name: "TestB", Status: StatusPassed, Cluster: "gpu"},
+		{Name: "TestB", Status: StatusFailed, Cluster: "cpu"},
+		{Name: "TestA", Status: StatusPassed, Cluster: "cpu"},
+		{Name: "TestA", Status: StatusFailed, Cluster: "cpu"},
+	}}
+
+	// A test failing against one cluster and passing against another isn't flaky
+	g.Expect(suite.Outcomes()).To(gomega.Equal([]TestOutcome{
+		{Suite: "kueue", Name: "TestA", Cluster: "cpu", Outcome: OutcomeFlake, Runs: 2},
+		{Suite: "kueue", Name: "TestB", Cluster: "cpu", Outcome: OutcomeFail, Runs: 1},
+		{Suite: "kueue", Name: "TestB", Cluster: "gpu", Outcome: OutcomePass, Runs: 1},
+	}))
+}
+
+func TestNewReport(t *testing.T) {
+	g := gomega.NewWithT(t)
+	report := NewReport([]Suite{
+		{Suite: "kueue", Images: map[string]string{"busybox": "sha256:1"}, Results: []Result{
+			{Name: "TestA", Status: StatusFailed},
+			{Name: "TestB", Status: StatusPassed},
+		}},
+		{Suite: "ray", Results: []Result{{Name: "TestC", Status: StatusSkipped}}, Leaked: []string{"ClusterQueue/cq"}},
+		{Suite: "kueue", Images: map[string]string{"busybox": "sha256:2"}, Results: []Result{
+			{Name: "TestA", Status: StatusPassed},
+			{Name: "TestB", Status: StatusFailed},
+		}},
+	})
+
+	g.Expect(report.Suites).To(gomega.Equal([]string{"kueue", "ray"}))
+	g.Expect(report.Images).To(gomega.Equal(map[string]string{"busybox": "sha256:2"}))
+	// The runs of a suite appended by different jobs are combined
+	g.Expect(report.With(OutcomeFlake)).To(gomega.Equal([]TestOutcome{
+		{Suite: "kueue", Name: "TestA", Outcome: OutcomeFlake, Runs: 2},
+		{Suite: "kueue", Name: "TestB", Outcome: OutcomeFlake, Runs: 2},
+	}))
+	g.Expect(report.Count(OutcomeSkip)).To(gomega.Equal(1))
+	g.Expect(report.Passed()).To(gomega.BeFalse())
+	g.Expect(report.String()).To(gomega.Equal("2 suites: 0 passed, 2 flaky, 0 failed, 1 skipped, 0 expected failures, 1 leaked objects"))
+}
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		name   string
+		data   string
+		suites []Suite
+		err    string
+	}{
+		{
+			name: "empty lines are ignored",
+			data: "\n" + `{"suite":"kueue","duration":1,"results":[{"name":"TestA","status":"passed","duration":1}]}` + "\n\n",
+			suites: []Suite{
+				{Suite: "kueue", Duration: 1, Results: []Result{{Name: "TestA", Status: StatusPassed, Duration: 1}}},
+			},
+		},
+		{
+			name: "invalid line",
+			data: `{"suite":"kueue","results":[]}` + "\n" + `{"suite":`,
+			err:  "error parsing results line 2",
+		},
+		{
+			name: "missing suite name",
+			data: `{"results":[]}`,
+			err:  "error parsing results line 1: missing suite name",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+			suites, err := Parse([]byte(tt.data))
+			if tt.err != "" {
+				g.Expect(err).To(gomega.MatchError(gomega.ContainSubstring(tt.err)))
+				return
+			}
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			g.Expect(suites).To(gomega.Equal(tt.suites))
+		})
+	}
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"fmt"
+
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Accelerator is the vendor of the GPUs tests run on, with the resource the GPUs are requested with and
+// the runtime the images running on them must be built for.
+type Accelerator struct {
+	// Name is the name of the accelerator, nvidia or amd, as set with TEST_ACCELERATOR
+	Name string
+	// Resource is the extended resource the GPUs are advertised as by the device plugin
+	Resource corev1.ResourceName
+	// Runtime is the GPU runtime PyTorch must be built with, CUDA or ROCm
+	Runtime string
+}
+
+var (
+	NvidiaAccelerator = Accelerator{Name: "nvidia", Resource: NvidiaGpuResource, Runtime: "CUDA"}
+	AmdAccelerator    = Accelerator{Name: "amd", Resource: AmdGpuResource, Runtime: "ROCm"}
+)
+
+// GetAccelerator returns the accelerator configured with TEST_ACCELERATOR, or the vendor of the GPUs present
+// in the cluster, NVIDIA first. The test is skipped when there is no GPU in the cluster.
+func GetAccelerator(t Test) Accelerator {
+	t.T().Helper()
+	accelerator, ok := LookupAccelerator(t)
+	if !ok {
+		t.T().Skip("No node with NVIDIA or AMD GPUs available in the cluster")
+	}
+	return accelerator
+}
+
+// LookupAccelerator returns the accelerator configured with TEST_ACCELERATOR, or the vendor of the GPUs present
+// in the cluster, NVIDIA first, ok is false when there is no GPU in the cluster.
+func LookupAccelerator(t Test) (Accelerator, bool) {
+	t.T().Helper()
+	switch name := GetTestAccelerator(); {
+	case name == NvidiaAccelerator.Name:
+		return NvidiaAccelerator, true
+	case name == AmdAccelerator.Name:
+		return AmdAccelerator, true
+	case name != "":
+		t.T().Fatalf("Unsupported accelerator %s, supported accelerators are %s and %s", name, NvidiaAccelerator.Name, AmdAccelerator.Name)
+	case len(GetNvidiaGpuNodes(t)) > 0:
+		return NvidiaAccelerator, true
+	case len(GetAmdGpuNodes(t, 1)) > 0:
+		return AmdAccelerator, true
+	}
+	return Accelerator{}, false
+}
+
+// Nodes returns the nodes with at least the given number of allocatable GPUs of the accelerator.
+func (a Accelerator) Nodes(t Test, minGpus int64) []corev1.Node {
+	t.T().Helper()
+	var gpuNodes []corev1.Node
+	for _, node := range GetNodes(t) {
+		allocatable := node.Status.Allocatable[a.Resource]
+		if !allocatable.IsZero() && allocatable.Value() >= minGpus {
+			gpuNodes = append(gpuNodes, node)
+		}
+	}
+	return gpuNodes
+}
+
+// Resources returns the resource list requesting the number of GPUs of the accelerator, to be added to both the
+// requests and limits of a container, as extended resources can't be overcommitted.
+func (a Accelerator) Resources(gpus int64) corev1.ResourceList {
+	return corev1.ResourceList{
+		a.Resource: *resource.NewQuantity(gpus, resource.DecimalSI),
+	}
+}
+
+// Tolerations returns the tolerations of the taint GPU nodes are commonly tainted with, keyed by the GPU resource.
+func (a Accelerator) Tolerations() []corev1.Toleration {
+	return []corev1.Toleration{
+		{
+			Key:      a.Resource.String(),
+			Operator: corev1.TolerationOpExists,
+			Effect:   corev1.TaintEffectNoSchedule,
+		},
+	}
+}
+
+// PyTorchImage returns the PyTorch image to run on the accelerator, the CUDA image for NVIDIA GPUs,
+// and the image configured with ROCM_PYTORCH_IMAGE for AMD GPUs.
+func (a Accelerator) PyTorchImage(cudaImage string) string {
+	if a.Name == AmdAccelerator.Name {
+		return GetRocmPyTorchImage()
+	}
+	return cudaImage
+}
+
+// Metrics returns the metrics of the exporter deployed by the GPU operator of the accelerator.
+func (a Accelerator) Metrics() AcceleratorMetrics {
+	if a.Name == AmdAccelerator.Name {
+		return AmdDeviceMetrics
+	}
+	return NvidiaDcgmMetrics
+}
+
+func (a Accelerator) String() string {
+	return fmt.Sprintf("%s (%s)", a.Name, a.Runtime)
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+	prometheusv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// utilizationResolution is the resolution the utilization is evaluated at over windows, about the scrape interval of the exporters
+const utilizationResolution = 30 * time.Second
+
+// AcceleratorMetrics maps the accelerator metrics asserted by tests to the equivalent series of the vendor
+// metrics exporter, so the assertions are the same for NVIDIA and AMD GPUs.
+type AcceleratorMetrics struct {
+	// Exporter is the name of the exporter, either dcgm or amd
+	Exporter string
+	// Resource is the extended resource of the accelerators
+	Resource corev1.ResourceName
+	// Series of the utilization in percent, and of the used memory
+	utilizationSeries string
+	memoryUsedSeries  string
+	// Bytes per unit of the used memory series
+	memoryUnit float64
+}
+
+var (
+	// NVIDIA DCGM exporter, deployed by NVIDIA GPU operator
+	NvidiaDcgmMetrics = AcceleratorMetrics{
+		Exporter:          "dcgm",
+		Resource:          NvidiaGpuResource,
+		utilizationSeries: "DCGM_FI_DEV_GPU_UTIL",
+		memoryUsedSeries:  "DCGM_FI_DEV_FB_USED",
+		memoryUnit:        1024 * 1024,
+	}
+	// AMD device metrics exporter, deployed by AMD GPU operator
+	AmdDeviceMetrics = AcceleratorMetrics{
+		Exporter:          "amd",
+		Resource:          AmdGpuResource,
+		utilizationSeries: "gpu_gfx_activity",
+		memoryUsedSeries:  "gpu_used_vram",
+		memoryUnit:        1024 * 1024,
+	}
+)
+
+// GetAcceleratorMetrics returns the metrics of the exporter configured with ACCELERATOR_METRICS_EXPORTER,
+// or of the accelerator configured with TEST_ACCELERATOR, or of the vendor of the GPUs present in the cluster.
+// The test is skipped when there is no GPU in the cluster.
+func GetAcceleratorMetrics(t Test) AcceleratorMetrics {
+	t.T().Helper()
+	metrics, ok := lookupAcceleratorMetrics(t)
+	if !ok {
+		t.T().Skip("No node with NVIDIA or AMD GPUs available in the cluster")
+	}
+	return metrics
+}
+
+// lookupAcceleratorMetrics returns the metrics of the configured exporter, or of the accelerator returned by
+// LookupAccelerator, ok is false when there is no GPU in the cluster.
+func lookupAcceleratorMetrics(t Test) (AcceleratorMetrics, bool) {
+	t.T().Helper()
+	switch exporter := GetAcceleratorMetricsExporter(); {
+	case exporter == NvidiaDcgmMetrics.Exporter:
+		return NvidiaDcgmMetrics, true
+	case exporter == AmdDeviceMetrics.Exporter:
+		return AmdDeviceMetrics, true
+	case exporter != "":
+		t.T().Fatalf("Unsupported accelerator metrics exporter %s, supported exporters are %s and %s", exporter, NvidiaDcgmMetrics.Exporter, AmdDeviceMetrics.Exporter)
+	}
+	if accelerator, ok := LookupAccelerator(t); ok {
+		return accelerator.Metrics(), true
+	}
+	return AcceleratorMetrics{}, false
+}
+
+// UtilizationQuery returns the query of the average utilization, in percent, of the accelerators
+// used by the pods with the name prefix.
+func (m AcceleratorMetrics) UtilizationQuery(namespace, podPrefix string) string {
+	return fmt.Sprintf(`avg(%s{%s})`, m.utilizationSeries, workloadSelector(namespace, podPrefix))
+}
+
+// PeakUtilizationQuery returns the query of the peak of the average utilization, in percent, of the accelerators
+// used by the pods with the name prefix, over the window.
+func (m AcceleratorMetrics) PeakUtilizationQuery(namespace, podPrefix string, window time.Duration) string {
+	return fmt.Sprintf(`max_over_time(%s[%s:%s])`, m.UtilizationQuery(namespace, podPrefix),
+		model.Duration(max(window, utilizationResolution).Round(time.Second)), model.Duration(utilizationResolution))
+}
+
+// MemoryUsedQuery returns the query of the memory, in bytes, used on the accelerators by the pods with the name prefix.
+func (m AcceleratorMetrics) MemoryUsedQuery(namespace, podPrefix string) string {
+	return fmt.Sprintf(`sum(%s{%s}) * %v`, m.memoryUsedSeries, workloadSelector(namespace, podPrefix), m.memoryUnit)
+}
+
+// OtherNamespacesUtilizationQuery returns the query of the utilization, in percent, of all the accelerators of the
+// cluster by the pods of the other namespaces, the accelerators not allocated to any pod counting as idle.
+func (m AcceleratorMetrics) OtherNamespacesUtilizationQuery(namespace string) string {
+	return fmt.Sprintf(`sum(%s{exported_namespace!="",exported_namespace!=%q}) / count(%s)`, m.utilizationSeries, namespace, m.utilizationSeries)
+}
+
+// AcceleratorUtilization returns the average utilization, in percent, of the accelerators used by the pods with the name prefix.
+func AcceleratorUtilization(t Test, api prometheusv1.API, metrics AcceleratorMetrics, namespace, podPrefix string) func(g gomega.Gomega) float64 {
+	return PrometheusQueryValue(t, api, metrics.UtilizationQuery(namespace, podPrefix))
+}
+
+// AcceleratorPeakUtilization returns the peak of the average utilization, in percent, of the accelerators used by the
+// pods with the name prefix, over the window ending at the time.
+func AcceleratorPeakUtilization(t Test, api prometheusv1.API, metrics AcceleratorMetrics, namespace, podPrefix string, window time.Duration, end time.Time) func(g gomega.Gomega) float64 {
+	return PrometheusQueryValueAt(t, api, metrics.PeakUtilizationQuery(namespace, podPrefix, window), end)
+}
+
+// ExpectGPUUtilizationAbove asserts the accelerators used by the pods of the namespace were utilized above the percent
+// at some point of the window ending now, i.e. the duration of the training, so GPU tests make sure the accelerators
+// were actually exercised rather than only the workload completed. The samples scraped late are waited for.
+func ExpectGPUUtilizationAbove(t Test, api prometheusv1.API, namespace string, percent float64, window time.Duration) {
+	t.T().Helper()
+	t.Eventually(AcceleratorPeakUtilization(t, api, GetAcceleratorMetrics(t), namespace, "", window, time.Now()), TestTimeoutShort).
+		Should(gomega.BeNumerically(">", percent), "Peak utilization of the accelerators of namespace %s over the last %s", namespace, window)
+}
+
+// AcceleratorMemoryUsed returns the memory, in bytes, used on the accelerators by the pods with the name prefix.
+func AcceleratorMemoryUsed(t Test, api prometheusv1.API, metrics AcceleratorMetrics, namespace, podPrefix string) func(g gomega.Gomega) float64 {
+	return PrometheusQueryValue(t, api, metrics.MemoryUsedQuery(namespace, podPrefix))
+}
+
+// workloadSelector selects the series of the pods with the name prefix. The exporters report the pods the
+// accelerators are allocated to in the namespace and pod labels, which Prometheus renames as they collide
+// with the labels of the exporter pods.
+func workloadSelector(namespace, podPrefix string) string {
+	return fmt.Sprintf(`exported_namespace=%q,exported_pod=~%q`, namespace, regexp.QuoteMeta(podPrefix)+".*")
+}
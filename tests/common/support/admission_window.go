@@ -0,0 +1,165 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+	// Embed the timezone database, so the admission windows are computed on hosts without it
+	_ "time/tzdata"
+
+	. "github.com/project-codeflare/codeflare-common/support"
+	kueuev1beta1 "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// DailyAdmissionWindow is the time window of the day the workloads are admitted in, in the timezone of the
+// cluster users, i.e. off-peak hours from 22:00 to 06:00 in Europe/Paris. The window ends the next day when
+// it wraps around midnight.
+type DailyAdmissionWindow struct {
+	// Start is the time of the day the window opens at, as the duration since midnight
+	Start time.Duration
+	// Duration is the duration the window stays open for, less than a day
+	Duration time.Duration
+	// Location is the timezone of the window
+	Location *time.Location
+}
+
+// Next returns the time the window next opens at, and the time it closes at, or the current window when open.
+// The times follow the wall clock of the location, so the windows are shifted accordingly on daylight saving
+// time transitions.
+func (w DailyAdmissionWindow) Next(now time.Time) (time.Time, time.Time) {
+	local := now.In(w.Location)
+	// Start from the window opening the day before, which is still open when it wraps around midnight
+	day := time.Date(local.Year(), local.Month(), local.Day()-1, 0, 0, 0, 0, w.Location)
+	for {
+		opens := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, w.Location).Add(w.Start)
+		closes := opens.Add(w.Duration)
+		if closes.After(now) {
+			return opens, closes
+		}
+		day = day.AddDate(0, 0, 1)
+	}
+}
+
+func (w DailyAdmissionWindow) String() string {
+	return fmt.Sprintf("%s+%s %s", time.Time{}.Add(w.Start).Format("15:04"), w.Duration, w.Location)
+}
+
+// KueueAdmissionSchedule is the admission window schedule of a ClusterQueue, automating its stop policy as the
+// external cron jobs implementing time-windowed admission do.
+type KueueAdmissionSchedule struct {
+	Window DailyAdmissionWindow
+
+	mutex  sync.Mutex
+	opened []time.Time
+	closed []time.Time
+}
+
+// Opened returns the times the window opened at since it was scheduled.
+func (s *KueueAdmissionSchedule) Opened() []time.Time {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]time.Time(nil), s.opened...)
+}
+
+// Closed returns the times the window closed at since it was scheduled.
+func (s *KueueAdmissionSchedule) Closed() []time.Time {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return append([]time.Time(nil), s.closed...)
+}
+
+// ScheduleKueueAdmissionWindow holds the ClusterQueue outside the daily admission window and releases it within
+// the window, until the test finishes. The Hold stop policy keeps the pending Workloads from being admitted
+// without evicting the admitted ones, so the workloads started within the window run to completion.
+func ScheduleKueueAdmissionWindow(t Test, clusterQueueName string, window DailyAdmissionWindow) *KueueAdmissionSchedule {
+	t.T().Helper()
+
+	schedule := &KueueAdmissionSchedule{Window: window}
+	opens, closes := window.Next(time.Now())
+	open := !opens.After(time.Now())
+	SetKueueClusterQueueStopPolicy(t, clusterQueueName, admissionWindowStopPolicy(open))
+	t.T().Logf("Scheduled admission window %s of ClusterQueue %s, next opening at %s and closing at %s", window, clusterQueueName, opens, closes)
+
+	ctx, cancel := context.WithCancel(t.Ctx())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for {
+			next := opens
+			if open {
+				next = closes
+			}
+			timer := time.NewTimer(time.Until(next))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return
+			case <-timer.C:
+			}
+			open = !open
+			if err := setAdmissionWindowStopPolicy(t, ctx, clusterQueueName, open); err != nil {
+				if ctx.Err() == nil {
+					t.T().Errorf("Error toggling admission window of ClusterQueue %s: %v", clusterQueueName, err)
+				}
+				return
+			}
+			schedule.record(open, time.Now())
+			if open {
+				t.T().Logf("Opened admission window of ClusterQueue %s", clusterQueueName)
+			} else {
+				opens, closes = window.Next(time.Now())
+				t.T().Logf("Closed admission window of ClusterQueue %s, next opening at %s", clusterQueueName, opens)
+			}
+		}
+	}()
+
+	t.T().Cleanup(func() {
+		cancel()
+		<-done
+	})
+
+	return schedule
+}
+
+func (s *KueueAdmissionSchedule) record(open bool, at time.Time) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if open {
+		s.opened = append(s.opened, at)
+	} else {
+		s.closed = append(s.closed, at)
+	}
+}
+
+func setAdmissionWindowStopPolicy(t Test, ctx context.Context, clusterQueueName string, open bool) error {
+	patch := fmt.Sprintf(`{"spec":{"stopPolicy":%q}}`, admissionWindowStopPolicy(open))
+	_, err := t.Client().Kueue().KueueV1beta1().ClusterQueues().Patch(ctx, clusterQueueName, types.MergePatchType, []byte(patch), metav1.PatchOptions{})
+	return err
+}
+
+func admissionWindowStopPolicy(open bool) kueuev1beta1.StopPolicy {
+	if open {
+		return kueuev1beta1.None
+	}
+	return kueuev1beta1.Hold
+}
@@ -0,0 +1,125 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+)
+
+// AlertmanagerClient queries alerts received by Alertmanager through its v2 API.
+type AlertmanagerClient interface {
+	// Alerts returns the active alerts matching the filters, i.e. `alertname="KubeJobFailed"`
+	Alerts(filters ...string) ([]Alert, error)
+}
+
+type Alert struct {
+	Labels   map[string]string `json:"labels"`
+	StartsAt time.Time         `json:"startsAt"`
+	Status   struct {
+		State string `json:"state"`
+	} `json:"status"`
+}
+
+type alertmanagerClient struct {
+	endpoint   url.URL
+	token      string
+	httpClient *http.Client
+}
+
+var _ AlertmanagerClient = (*alertmanagerClient)(nil)
+
+// NewAlertmanagerClient returns a client of the Alertmanager API configured with ALERTMANAGER_URL,
+// defaulting to the Alertmanager route on OpenShift. The requests are authorized with a token of a service account
+// created in the namespace and bound to the cluster-monitoring-view ClusterRole.
+func NewAlertmanagerClient(t Test, namespace string) AlertmanagerClient {
+	t.T().Helper()
+
+	address, ok := GetAlertmanagerUrl()
+	address = monitoringEndpoint(t, address, ok, alertmanagerUrlEnvVar, alertmanagerRoute)
+	endpoint, err := url.Parse(address)
+	ExpectNoError(t, err, "parsing URL of", Ref("Alertmanager", "", address))
+	t.T().Logf("Querying Alertmanager API at %s", address)
+
+	return &alertmanagerClient{
+		endpoint: *endpoint,
+		token:    createMonitoringViewerToken(t, namespace),
+		httpClient: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
+		},
+	}
+}
+
+func (client *alertmanagerClient) Alerts(filters ...string) ([]Alert, error) {
+	location := client.endpoint
+	location.Path = strings.TrimSuffix(location.Path, "/") + "/api/v2/alerts"
+	location.RawQuery = url.Values{"filter": filters, "active": {"true"}, "silenced": {"false"}, "inhibited": {"false"}}.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, location.String(), nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Authorization", "Bearer "+client.token)
+
+	resp, err := client.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	respData, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("incorrect response code %d for alerts, response body: %s", resp.StatusCode, respData)
+	}
+
+	var alerts []Alert
+	if err := json.Unmarshal(respData, &alerts); err != nil {
+		return nil, err
+	}
+	return alerts, nil
+}
+
+// FiringAlerts returns the alerts with the name firing in Alertmanager, matching the labels.
+func FiringAlerts(client AlertmanagerClient, name string, labels map[string]string) func(g gomega.Gomega) []Alert {
+	filters := []string{fmt.Sprintf("alertname=%q", name)}
+	for label, value := range labels {
+		filters = append(filters, fmt.Sprintf("%s=%q", label, value))
+	}
+	return func(g gomega.Gomega) []Alert {
+		alerts, err := client.Alerts(filters...)
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		var firing []Alert
+		for _, alert := range alerts {
+			if alert.Status.State == "active" {
+				firing = append(firing, alert)
+			}
+		}
+		return firing
+	}
+}
@@ -0,0 +1,187 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"net/http/httputil"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/rest"
+)
+
+// APIServerDisruption is a proxy of the API server the clients of the test connect through, failing the requests
+// and terminating the watches while disrupted, as the API server does during rolling restarts of HyperShift control
+// planes or upgrades. The controllers connect to the API server directly, so only the test harness is disrupted.
+type APIServerDisruption struct {
+	server *httptest.Server
+
+	mutex     sync.Mutex
+	disrupted bool
+	streams   map[*http.Request]context.CancelFunc
+
+	failed atomic.Int64
+}
+
+// NewAPIServerDisruption starts the proxy of the API server the clients of the test are created from, stopped once
+// the test finishes.
+func NewAPIServerDisruption(t Test) *APIServerDisruption {
+	t.T().Helper()
+
+	cfg, err := restConfig(t)
+	t.Expect(err).NotTo(gomega.HaveOccurred(), "Error loading client configuration")
+	target, err := url.Parse(cfg.Host)
+	t.Expect(err).NotTo(gomega.HaveOccurred(), "Error parsing API server URL %s", cfg.Host)
+	// The proxy authenticates the requests with the credentials of the test
+	transport, err := rest.TransportFor(cfg)
+	t.Expect(err).NotTo(gomega.HaveOccurred(), "Error creating API server transport")
+
+	proxy := httputil.NewSingleHostReverseProxy(target)
+	proxy.Transport = transport
+	// Stream the watch events as they come
+	proxy.FlushInterval = -1
+
+	disruption := &APIServerDisruption{streams: map[*http.Request]context.CancelFunc{}}
+	disruption.server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithCancel(r.Context())
+		defer cancel()
+		if !disruption.track(r, cancel) {
+			disruption.failed.Add(1)
+			http.Error(w, "API server disrupted by the test", http.StatusServiceUnavailable)
+			return
+		}
+		defer disruption.untrack(r)
+		proxy.ServeHTTP(w, r.WithContext(ctx))
+	}))
+	t.T().Cleanup(disruption.server.Close)
+	t.T().Logf("Proxying API server %s at %s", cfg.Host, disruption.server.URL)
+
+	return disruption
+}
+
+// Test returns the test with its clients connecting to the API server through the proxy, decorated with the hooks
+// registered by RunSuite like WithHooks.
+func (d *APIServerDisruption) Test(t Test) Test {
+	return applyTestHooks(withRestConfig(t.T(), &rest.Config{Host: d.server.URL}))
+}
+
+// Disrupt fails the requests and terminates the watches of the clients for the duration, in the background.
+func (d *APIServerDisruption) Disrupt(t Test, duration time.Duration) {
+	t.T().Helper()
+	d.mutex.Lock()
+	d.disrupted = true
+	for _, cancel := range d.streams {
+		cancel()
+	}
+	d.mutex.Unlock()
+	t.T().Logf("Disrupting API server for %s", duration)
+
+	timer := time.AfterFunc(duration, func() {
+		d.mutex.Lock()
+		defer d.mutex.Unlock()
+		d.disrupted = false
+	})
+	t.T().Cleanup(func() {
+		timer.Stop()
+	})
+}
+
+// Disrupted returns whether the requests are currently failed.
+func (d *APIServerDisruption) Disrupted() bool {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return d.disrupted
+}
+
+// FailedRequests returns the number of requests failed by the disruptions.
+func (d *APIServerDisruption) FailedRequests() int64 {
+	return d.failed.Load()
+}
+
+// track registers the in-flight request, so it's terminated once disrupted, returns false when disrupted.
+func (d *APIServerDisruption) track(r *http.Request, cancel context.CancelFunc) bool {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	if d.disrupted {
+		return false
+	}
+	d.streams[r] = cancel
+	return true
+}
+
+func (d *APIServerDisruption) untrack(r *http.Request) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	delete(d.streams, r)
+}
+
+var openShiftKubeAPIServerResource = schema.GroupVersionResource{Group: "operator.openshift.io", Version: "v1", Resource: "kubeapiservers"}
+
+// RollOutOpenShiftAPIServer forces a new revision of the OpenShift kube-apiserver, rolled out to the control plane
+// nodes one at a time, and returns the revision.
+func RollOutOpenShiftAPIServer(t Test) int64 {
+	t.T().Helper()
+	revision := openShiftAPIServerLatestRevision(t)(t)
+	patch := fmt.Sprintf(`{"spec":{"forceRedeploymentReason":"distributed-workloads %s %s"}}`, t.T().Name(), time.Now().UTC().Format(time.RFC3339))
+	_, err := t.Client().Dynamic().Resource(openShiftKubeAPIServerResource).Patch(t.Ctx(), "cluster", types.MergePatchType, []byte(patch), metav1.PatchOptions{})
+	ExpectNoError(t, err, "forcing redeployment of", Ref("KubeAPIServer", "", "cluster"))
+
+	t.Eventually(openShiftAPIServerLatestRevision(t), TestTimeoutMedium).Should(gomega.BeNumerically(">", revision))
+	revision = openShiftAPIServerLatestRevision(t)(t)
+	t.T().Logf("Rolling out kube-apiserver revision %d", revision)
+	return revision
+}
+
+// OpenShiftAPIServerRolledOut returns whether all the control plane nodes run the kube-apiserver revision.
+func OpenShiftAPIServerRolledOut(t Test, revision int64) func(g gomega.Gomega) bool {
+	return func(g gomega.Gomega) bool {
+		kubeAPIServer, err := t.Client().Dynamic().Resource(openShiftKubeAPIServerResource).Get(t.Ctx(), "cluster", metav1.GetOptions{})
+		g.Expect(WrapError(err, "getting", Ref("KubeAPIServer", "", "cluster"))).NotTo(gomega.HaveOccurred())
+		nodeStatuses, _, err := unstructured.NestedSlice(kubeAPIServer.Object, "status", "nodeStatuses")
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		for _, nodeStatus := range nodeStatuses {
+			currentRevision, _, _ := unstructured.NestedInt64(nodeStatus.(map[string]any), "currentRevision")
+			if currentRevision < revision {
+				return false
+			}
+		}
+		return len(nodeStatuses) > 0
+	}
+}
+
+func openShiftAPIServerLatestRevision(t Test) func(g gomega.Gomega) int64 {
+	return func(g gomega.Gomega) int64 {
+		kubeAPIServer, err := t.Client().Dynamic().Resource(openShiftKubeAPIServerResource).Get(t.Ctx(), "cluster", metav1.GetOptions{})
+		g.Expect(WrapError(err, "getting", Ref("KubeAPIServer", "", "cluster"))).NotTo(gomega.HaveOccurred())
+		revision, _, err := unstructured.NestedInt64(kubeAPIServer.Object, "status", "latestAvailableRevision")
+		g.Expect(WrapError(err, "getting latest revision of", Ref("KubeAPIServer", "", "cluster"))).NotTo(gomega.HaveOccurred())
+		return revision
+	}
+}
@@ -0,0 +1,70 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	"k8s.io/client-go/rest"
+)
+
+// TestAPIServerDisruption makes sure the proxy forwards to the API server of the test, and fails the requests while
+// disrupted.
+func TestAPIServerDisruption(t *testing.T) {
+	g := gomega.NewWithT(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"major": "1", "minor": "29", "gitVersion": "v1.29.0"}`))
+	}))
+	t.Cleanup(server.Close)
+
+	test := withRestConfig(t, &rest.Config{Host: server.URL})
+	disruption := NewAPIServerDisruption(test)
+	proxied := disruption.Test(test)
+
+	cfg, err := restConfig(proxied)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(cfg.Host).NotTo(gomega.Equal(server.URL))
+
+	version, err := proxied.Client().Core().Discovery().ServerVersion()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(version.GitVersion).To(gomega.Equal("v1.29.0"))
+
+	disruption.Disrupt(test, TestTimeoutLong)
+	g.Expect(disruption.Disrupted()).To(gomega.BeTrue())
+	_, err = proxied.Client().Core().Discovery().ServerVersion()
+	g.Expect(err).To(gomega.HaveOccurred())
+	g.Expect(disruption.FailedRequests()).To(gomega.BeNumerically(">", 0))
+}
+
+// TestAPIServerDisruptionEnds makes sure the requests succeed again once the disruption is over.
+func TestAPIServerDisruptionEnds(t *testing.T) {
+	g := gomega.NewWithT(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	t.Cleanup(server.Close)
+
+	test := withRestConfig(t, &rest.Config{Host: server.URL})
+	disruption := NewAPIServerDisruption(test)
+	disruption.Disrupt(test, 100*time.Millisecond)
+	g.Eventually(disruption.Disrupted).Should(gomega.BeFalse())
+}
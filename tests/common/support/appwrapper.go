@@ -0,0 +1,79 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"github.com/onsi/gomega"
+	awv1beta2 "github.com/project-codeflare/appwrapper/api/v1beta2"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Label set by the AppWrapper controller on the wrapped resources and their pods
+const AppWrapperNameLabel = "workload.codeflare.dev/appwrapper"
+
+var appWrapperResource = awv1beta2.GroupVersion.WithResource("appwrappers")
+
+func CreateAppWrapper(t Test, appWrapper *awv1beta2.AppWrapper) *awv1beta2.AppWrapper {
+	t.T().Helper()
+
+	content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(appWrapper)
+	ExpectNoError(t, err, "converting", Ref("AppWrapper", appWrapper.Namespace, appWrapper.Name))
+	created, err := t.Client().Dynamic().Resource(appWrapperResource).Namespace(appWrapper.Namespace).
+		Create(t.Ctx(), &unstructured.Unstructured{Object: content}, metav1.CreateOptions{})
+	ExpectNoError(t, err, "creating", Ref("AppWrapper", appWrapper.Namespace, appWrapper.Name))
+
+	appWrapper = &awv1beta2.AppWrapper{}
+	t.Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(created.UnstructuredContent(), appWrapper)).To(gomega.Succeed())
+	t.T().Logf("Created AppWrapper %s/%s successfully", appWrapper.Namespace, appWrapper.Name)
+
+	// Summarize the AppWrapper for triage when the test fails
+	namespace, name := appWrapper.Namespace, appWrapper.Name
+	t.T().Cleanup(func() {
+		if !t.T().Failed() {
+			return
+		}
+		latest, err := t.Client().Dynamic().Resource(appWrapperResource).Namespace(namespace).Get(t.Ctx(), name, metav1.GetOptions{})
+		if err != nil {
+			t.T().Logf("Error getting AppWrapper %s/%s to describe it: %v", namespace, name, err)
+			return
+		}
+		described := &awv1beta2.AppWrapper{}
+		if err := runtime.DefaultUnstructuredConverter.FromUnstructured(latest.UnstructuredContent(), described); err == nil {
+			storeWorkloadDescription(t, described)
+		}
+	})
+
+	return appWrapper
+}
+
+// AppWrapperUID returns UID of the AppWrapper, or empty string if the AppWrapper or the AppWrapper API doesn't exist.
+func AppWrapperUID(t Test, namespace, name string) types.UID {
+	t.T().Helper()
+	appWrapper, err := t.Client().Dynamic().Resource(appWrapperResource).Namespace(namespace).Get(t.Ctx(), name, metav1.GetOptions{})
+	if errors.IsNotFound(err) || meta.IsNoMatchError(err) {
+		return ""
+	}
+	ExpectNoError(t, err, "getting", Ref("AppWrapper", namespace, name))
+	return appWrapper.GetUID()
+}
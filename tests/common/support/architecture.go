@@ -0,0 +1,93 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"os"
+	"strings"
+
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const (
+	// The environment variable for CPU architecture of the nodes the tests run their pods on, i.e. arm64
+	testArchitectureEnvVar = "TEST_ARCHITECTURE"
+	// The environment variable for Ray image used by Ray tests, as defined by CodeFlare common test support
+	rayImageEnvVar = "CODEFLARE_TEST_RAY_IMAGE"
+)
+
+// GetTestArchitecture returns the CPU architecture the test pods run on, empty string stands for any architecture.
+func GetTestArchitecture() string {
+	return lookupEnvOrDefault(testArchitectureEnvVar, "")
+}
+
+// ArchitectureNodeSelector returns the node selector scheduling pods on nodes of the test architecture,
+// or nil if no architecture is configured.
+func ArchitectureNodeSelector() map[string]string {
+	architecture := GetTestArchitecture()
+	if architecture == "" {
+		return nil
+	}
+	return map[string]string{corev1.LabelArchStable: architecture}
+}
+
+// SetArchitectureNodeSelector adds the architecture node selector to the pod, unless the pod is bound to a node
+// or already selects an architecture.
+func SetArchitectureNodeSelector(spec *corev1.PodSpec) {
+	architectureSelector := ArchitectureNodeSelector()
+	if architectureSelector == nil || spec.NodeName != "" {
+		return
+	}
+	if _, ok := spec.NodeSelector[corev1.LabelArchStable]; ok {
+		return
+	}
+	if spec.NodeSelector == nil {
+		spec.NodeSelector = map[string]string{}
+	}
+	for key, value := range architectureSelector {
+		spec.NodeSelector[key] = value
+	}
+}
+
+// GetRayImageForArchitecture returns the Ray image published for the test architecture, configured with
+// CODEFLARE_TEST_RAY_IMAGE_<ARCH>, i.e. CODEFLARE_TEST_RAY_IMAGE_ARM64, defaulting to the Ray image.
+func GetRayImageForArchitecture() string {
+	if image, ok := lookupArchitectureEnv(rayImageEnvVar); ok {
+		return image
+	}
+	return GetRayImage()
+}
+
+// lookupImageOrDefault returns the image configured for the test architecture with <KEY>_<ARCH>,
+// falling back to the image configured with the key and then to the default, which is expected to be a multi-arch image.
+func lookupImageOrDefault(key, value string) string {
+	if image, ok := lookupArchitectureEnv(key); ok {
+		return image
+	}
+	return lookupEnvOrDefault(key, value)
+}
+
+func lookupArchitectureEnv(key string) (string, bool) {
+	architecture := GetTestArchitecture()
+	if architecture == "" {
+		return "", false
+	}
+	value, ok := os.LookupEnv(key + "_" + strings.ToUpper(architecture))
+	return value, ok && value != ""
+}
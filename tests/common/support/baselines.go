@@ -0,0 +1,67 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	_ "embed"
+	"sync"
+
+	"github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	"github.com/opendatahub-io/distributed-workloads/pkg/baselines"
+)
+
+// The performance baselines committed in the repository, managed with the dw-baseline command
+//
+//go:embed baselines.json
+var repositoryBaselines []byte
+
+var (
+	loadBaselines   sync.Once
+	loadedBaselines *baselines.Baselines
+	baselinesErr    error
+)
+
+// GetPerformanceBaseline returns the baseline of the metric for the cluster set with BASELINES_CLUSTER and the GPU
+// product name, empty for metrics not depending on the GPU, ok is false if there is no baseline for them.
+// The baselines are read from BASELINES_LOCATION when set, and from the baselines committed in the repository otherwise.
+func GetPerformanceBaseline(t Test, metric, gpuProduct string) (float64, bool) {
+	t.T().Helper()
+	loadBaselines.Do(func() {
+		loadedBaselines, baselinesErr = readBaselines(t)
+	})
+	t.Expect(baselinesErr).NotTo(gomega.HaveOccurred(), "Error reading performance baselines")
+
+	baseline, ok := loadedBaselines.Lookup(metric, baselines.Cluster(), gpuProduct)
+	if ok {
+		t.T().Logf("Using baseline %s of %v %s", baseline, baseline.Value, baseline.Unit)
+	}
+	return baseline.Value, ok
+}
+
+func readBaselines(t Test) (*baselines.Baselines, error) {
+	location, ok := baselines.Location()
+	if !ok {
+		return baselines.Parse(repositoryBaselines)
+	}
+	store, err := baselines.Open(location, baselines.S3ConfigFromEnv())
+	if err != nil {
+		return nil, err
+	}
+	return store.Load(t.Ctx())
+}
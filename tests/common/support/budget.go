@@ -0,0 +1,151 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	. "github.com/project-codeflare/codeflare-common/support"
+)
+
+// Time kept from the go test -timeout deadline for the cleanup of the scenario, i.e. storing its artifacts
+const budgetCleanupGrace = 30 * time.Second
+
+// BudgetPhase is a phase of a scenario with the minimum duration it takes, i.e. the time to pull the image and run
+// the fastest successful training, reserved in the budget while the previous phases run, and the maximum duration
+// its waits are capped at, i.e. the timeout they had before being budgeted, so a phase stuck early doesn't burn the
+// time left by the following phases.
+type BudgetPhase struct {
+	Name        string
+	MinDuration time.Duration
+	MaxDuration time.Duration
+}
+
+// ScenarioBudget is the time budget of a scenario made of successive phases. Each phase gets the time left in the
+// budget minus the minimum durations of the following phases, so waiting on a phase which can no longer complete in
+// time for the following phases to fit aborts early, i.e. when GPU provisioning hasn't completed after 30 minutes and
+// the training needs at least 15 of the 40 minutes left, rather than the scenario burning its full timeout.
+type ScenarioBudget struct {
+	t        Test
+	start    time.Time
+	deadline time.Time
+	phases   []BudgetPhase
+	// Index of the running phase, -1 before the first phase starts
+	current int
+	// Start times of the phases, by index of the phase
+	started map[int]time.Time
+}
+
+// NewScenarioBudget returns the budget of the scenario made of the phases, lasting the total duration from now,
+// capped by the go test -timeout deadline. Once the test finishes, the durations of the phases are logged, and the
+// failure of the test is attributed to the phase running when it failed.
+func NewScenarioBudget(t Test, total time.Duration, phases ...BudgetPhase) *ScenarioBudget {
+	t.T().Helper()
+	now := time.Now()
+	budget := &ScenarioBudget{t: t, start: now, deadline: now.Add(total), phases: phases, current: -1, started: map[int]time.Time{}}
+	if deadline, ok := t.T().Deadline(); ok && deadline.Add(-budgetCleanupGrace).Before(budget.deadline) {
+		budget.deadline = deadline.Add(-budgetCleanupGrace)
+	}
+	t.T().Cleanup(budget.report)
+	return budget
+}
+
+// Remaining returns the time left in the budget.
+func (b *ScenarioBudget) Remaining() time.Duration {
+	return time.Until(b.deadline)
+}
+
+// Phase starts the phase, ending the previous one, and returns the timeout of the waits of the phase, leaving the
+// minimum durations of the following phases in the budget. The test fails right away if there is no time left for the phase.
+func (b *ScenarioBudget) Phase(name string) time.Duration {
+	b.t.T().Helper()
+	index := -1
+	for i, phase := range b.phases {
+		if phase.Name == name {
+			index = i
+		}
+	}
+	if index < 0 {
+		b.t.T().Fatalf("Phase %q isn't part of the scenario budget", name)
+	}
+	if index <= b.current {
+		b.t.T().Fatalf("Phase %q started after phase %q, phases of the scenario budget run in order", name, b.phases[b.current].Name)
+	}
+
+	b.current = index
+	b.started[index] = time.Now()
+	Phase(b.t.T(), name)
+
+	timeout, err := b.timeout(index, b.Remaining())
+	if err != nil {
+		b.t.T().Fatal(err)
+	}
+	b.t.T().Logf("Phase %q has %s, %s left in the scenario budget", name, timeout.Round(time.Second), b.Remaining().Round(time.Second))
+	return timeout
+}
+
+// timeout returns the timeout of the phase with the time remaining in the budget, leaving the minimum durations of
+// the following phases, and capped at the maximum duration of the phase.
+func (b *ScenarioBudget) timeout(index int, remaining time.Duration) (time.Duration, error) {
+	phase := b.phases[index]
+	reserved := b.reserved(index)
+	timeout := remaining - reserved
+	if timeout <= 0 {
+		return 0, fmt.Errorf("no time left for phase %q: %s left in the scenario budget, the following phases need at least %s",
+			phase.Name, remaining.Round(time.Second), reserved)
+	}
+	if phase.MaxDuration > 0 {
+		timeout = min(timeout, phase.MaxDuration)
+	}
+	return timeout, nil
+}
+
+// reserved returns the minimum duration of the phases following the phase.
+func (b *ScenarioBudget) reserved(index int) time.Duration {
+	var reserved time.Duration
+	for _, phase := range b.phases[index+1:] {
+		reserved += phase.MinDuration
+	}
+	return reserved
+}
+
+// report logs the durations of the phases, attributing the failure of the test to the running phase.
+func (b *ScenarioBudget) report() {
+	if b.current < 0 {
+		return
+	}
+	end := time.Now()
+	var lines []string
+	for i := b.current; i >= 0; i-- {
+		// Phases may be skipped, i.e. by tests depending on the cluster setup
+		start, ok := b.started[i]
+		if !ok {
+			continue
+		}
+		lines = append([]string{fmt.Sprintf("  %s: %s", b.phases[i].Name, end.Sub(start).Round(time.Second))}, lines...)
+		end = start
+	}
+	b.t.T().Logf("Scenario budget of %s, used %s:\n%s", b.deadline.Sub(b.start).Round(time.Second), time.Since(b.start).Round(time.Second), strings.Join(lines, "\n"))
+
+	if b.t.T().Failed() {
+		running := b.phases[b.current]
+		b.t.T().Logf("Scenario failed in phase %q after %s, with %s left in the budget and %s needed by the following phases",
+			running.Name, time.Since(b.started[b.current]).Round(time.Second), b.Remaining().Round(time.Second), b.reserved(b.current))
+	}
+}
@@ -0,0 +1,103 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+)
+
+func TestScenarioBudgetPhaseTimeout(t *testing.T) {
+	budget := &ScenarioBudget{phases: []BudgetPhase{
+		{Name: "Admission", MaxDuration: 10 * time.Minute},
+		{Name: "Training", MinDuration: 5 * time.Minute, MaxDuration: 30 * time.Minute},
+		{Name: "Evaluation", MinDuration: time.Minute},
+	}}
+
+	tests := []struct {
+		name      string
+		phase     int
+		remaining time.Duration
+		timeout   time.Duration
+		err       string
+	}{
+		{
+			name:      "capped at the maximum duration of the phase",
+			phase:     0,
+			remaining: time.Hour,
+			timeout:   10 * time.Minute,
+		},
+		{
+			name:      "minimum durations of the following phases are reserved",
+			phase:     0,
+			remaining: 8 * time.Minute,
+			timeout:   2 * time.Minute,
+		},
+		{
+			name:      "not capped without maximum duration",
+			phase:     2,
+			remaining: time.Hour,
+			timeout:   time.Hour,
+		},
+		{
+			name:      "no time left for the following phases",
+			phase:     0,
+			remaining: 6 * time.Minute,
+			err:       `no time left for phase "Admission": 6m0s left in the scenario budget, the following phases need at least 6m0s`,
+		},
+		{
+			name:      "budget exhausted",
+			phase:     2,
+			remaining: -time.Second,
+			err:       `no time left for phase "Evaluation"`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+			timeout, err := budget.timeout(tt.phase, tt.remaining)
+			if tt.err != "" {
+				g.Expect(err).To(gomega.MatchError(gomega.ContainSubstring(tt.err)))
+				return
+			}
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			g.Expect(timeout).To(gomega.Equal(tt.timeout))
+		})
+	}
+}
+
+// TestScenarioBudgetBlockedAdmission makes sure a blocked admission fails once its original timeout elapses, rather
+// than waiting for the time left in the budget by the following phases.
+func TestScenarioBudgetBlockedAdmission(t *testing.T) {
+	g := gomega.NewWithT(t)
+	total := 2*TestTimeoutLong + 2*TestTimeoutShort
+	budget := NewScenarioBudget(With(t), total,
+		BudgetPhase{Name: "Admission", MaxDuration: TestTimeoutLong},
+		BudgetPhase{Name: "Training", MinDuration: time.Minute, MaxDuration: TestTimeoutShort},
+		BudgetPhase{Name: "Evaluation", MinDuration: time.Minute, MaxDuration: TestTimeoutLong},
+	)
+
+	g.Expect(budget.Phase("Admission")).To(gomega.Equal(TestTimeoutLong))
+
+	// The training still fits once the admission timed out
+	timeout, err := budget.timeout(1, total-TestTimeoutLong)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(timeout).To(gomega.Equal(TestTimeoutShort))
+}
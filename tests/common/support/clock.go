@@ -0,0 +1,107 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"bufio"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Durations asserted by tests are measured with the monotonic clock of the test process. Timestamps set on
+// resources, i.e. creation timestamps or condition transition times, come from the API server or node clocks
+// which may be skewed, so they must not be used to measure durations.
+
+// Stopwatch measures the time elapsed since it was started, using the monotonic clock of the test process.
+type Stopwatch struct {
+	start time.Time
+}
+
+func StartStopwatch() Stopwatch {
+	return Stopwatch{start: time.Now()}
+}
+
+func (s Stopwatch) Elapsed() time.Duration {
+	return time.Since(s.start)
+}
+
+// Number of node clock samples the skew is estimated from
+const clockSkewSamples = 5
+
+// NodeClockSkew estimates the offset of the node clock from the test process clock, positive when the node
+// clock is ahead. The node clock is sampled by a pod streaming its time, the sample received with the lowest
+// delivery latency gives the estimate.
+func NodeClockSkew(t Test, namespace string, node corev1.Node) time.Duration {
+	t.T().Helper()
+
+	pod := CreatePod(t, &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "clock-",
+			Namespace:    namespace,
+		},
+		Spec: corev1.PodSpec{
+			NodeName:      node.Name,
+			RestartPolicy: corev1.RestartPolicyNever,
+			Tolerations: []corev1.Toleration{
+				{
+					Operator: corev1.TolerationOpExists,
+				},
+			},
+			Containers: []corev1.Container{
+				{
+					Name:    "clock",
+					Image:   GetToolsImage(),
+					Command: []string{"sh", "-c", "while true; do date +%s.%N; sleep 1; done"},
+				},
+			},
+		},
+	})
+	defer func() {
+		_ = t.Client().Core().CoreV1().Pods(namespace).Delete(t.Ctx(), pod.Name, metav1.DeleteOptions{GracePeriodSeconds: Ptr(int64(0))})
+	}()
+	EventuallyOf(t, Pod(t, namespace, pod.Name), TestTimeoutMedium).
+		Should(Field(PodPhase).Equal(corev1.PodRunning))
+
+	stream, err := t.Client().Core().CoreV1().Pods(namespace).GetLogs(pod.Name, &corev1.PodLogOptions{Follow: true, SinceSeconds: Ptr(int64(1))}).Stream(t.Ctx())
+	ExpectNoError(t, err, "streaming logs of", Ref("Pod", namespace, pod.Name))
+	defer stream.Close()
+
+	skew := time.Duration(math.MinInt64)
+	scanner := bufio.NewScanner(stream)
+	for samples := 0; samples < clockSkewSamples && scanner.Scan(); samples++ {
+		received := time.Now()
+		seconds, err := strconv.ParseFloat(strings.TrimSpace(scanner.Text()), 64)
+		t.Expect(err).NotTo(gomega.HaveOccurred(), "Error parsing time reported by node %s", node.Name)
+		nodeTime := time.Unix(0, int64(seconds*float64(time.Second)))
+		// The delivery latency lowers the difference, so the highest one is the closest to the actual skew
+		if difference := nodeTime.Sub(received); difference > skew {
+			skew = difference
+		}
+	}
+	t.Expect(scanner.Err()).NotTo(gomega.HaveOccurred())
+	t.Expect(skew).NotTo(gomega.Equal(time.Duration(math.MinInt64)), "No time reported by node %s", node.Name)
+
+	return skew
+}
@@ -0,0 +1,214 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sync"
+	"time"
+
+	. "github.com/project-codeflare/codeflare-common/support"
+	kueuev1beta1 "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/dynamic"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// Time the cluster-scoped objects being deleted once the suite finishes are given to go, before their finalizers are removed
+const clusterScopedDeletionGracePeriod = time.Minute
+
+var (
+	kueueResourceFlavorResource = kueuev1beta1.GroupVersion.WithResource("resourceflavors")
+	kueueClusterQueueResource   = kueuev1beta1.GroupVersion.WithResource("clusterqueues")
+	kueuePriorityClassResource  = kueuev1beta1.GroupVersion.WithResource("workloadpriorityclasses")
+)
+
+// clusterScopedObject is a cluster-scoped object created by a test, which isn't deleted along with the test namespaces.
+type clusterScopedObject struct {
+	resource schema.GroupVersionResource
+	name     string
+	test     string
+	cluster  string
+}
+
+func (o clusterScopedObject) String() string {
+	return fmt.Sprintf("%s %s created by %s", o.resource.Resource, o.name, o.test)
+}
+
+// clusterScopedObjects records the cluster-scoped objects created by the tests of the suite, and the ones found left
+// over once the suite finished, reported in the suite summary.
+var clusterScopedObjects = struct {
+	sync.Mutex
+	tracked []clusterScopedObject
+	leaked  []string
+}{}
+
+// TrackClusterScoped registers the cluster-scoped object created by the test, i.e. a ClusterQueue or a ClusterRole.
+// The test is expected to delete it once it finishes, the suite asserts all the registered objects are gone once
+// its tests ran, removing the left over ones and failing the suite, so aborted runs don't drift the configuration of
+// long-lived clusters.
+func TrackClusterScoped(t Test, resource schema.GroupVersionResource, object metav1.Object) {
+	clusterScopedObjects.Lock()
+	defer clusterScopedObjects.Unlock()
+	clusterScopedObjects.tracked = append(clusterScopedObjects.tracked, clusterScopedObject{
+		resource: resource,
+		name:     object.GetName(),
+		test:     t.T().Name(),
+		cluster:  currentCluster,
+	})
+}
+
+// CreateTrackedKueueResourceFlavor creates the ResourceFlavor, registered with TrackClusterScoped.
+func CreateTrackedKueueResourceFlavor(t Test, spec kueuev1beta1.ResourceFlavorSpec) *kueuev1beta1.ResourceFlavor {
+	t.T().Helper()
+	resourceFlavor := CreateKueueResourceFlavor(t, spec)
+	TrackClusterScoped(t, kueueResourceFlavorResource, resourceFlavor)
+	return resourceFlavor
+}
+
+// CreateTrackedKueueClusterQueue creates the ClusterQueue, registered with TrackClusterScoped.
+func CreateTrackedKueueClusterQueue(t Test, spec kueuev1beta1.ClusterQueueSpec) *kueuev1beta1.ClusterQueue {
+	t.T().Helper()
+	clusterQueue := CreateKueueClusterQueue(t, spec)
+	TrackClusterScoped(t, kueueClusterQueueResource, clusterQueue)
+	return clusterQueue
+}
+
+// CreateTrackedKueueWorkloadPriorityClass creates the WorkloadPriorityClass, registered with TrackClusterScoped.
+func CreateTrackedKueueWorkloadPriorityClass(t Test, value int32) *kueuev1beta1.WorkloadPriorityClass {
+	t.T().Helper()
+	priorityClass := CreateKueueWorkloadPriorityClass(t, value)
+	TrackClusterScoped(t, kueuePriorityClassResource, priorityClass)
+	return priorityClass
+}
+
+// sweepClusterScoped checks the cluster-scoped objects registered by the tests run against the current cluster are
+// gone. Objects still being deleted are given a grace period, then their finalizers are removed. Objects not deleted
+// by their test are deleted. It returns the left over objects, which are recorded for the suite summary.
+func sweepClusterScoped() []string {
+	clusterScopedObjects.Lock()
+	var objects, remaining []clusterScopedObject
+	for _, object := range clusterScopedObjects.tracked {
+		if object.cluster == currentCluster {
+			objects = append(objects, object)
+		} else {
+			remaining = append(remaining, object)
+		}
+	}
+	clusterScopedObjects.tracked = remaining
+	clusterScopedObjects.Unlock()
+	if len(objects) == 0 {
+		return nil
+	}
+
+	cfg, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		clientcmd.NewDefaultClientConfigLoadingRules(),
+		&clientcmd.ConfigOverrides{},
+	).ClientConfig()
+	if err != nil {
+		return []string{fmt.Sprintf("cluster-scoped objects not checked, error loading client configuration: %v", err)}
+	}
+	client, err := dynamic.NewForConfig(cfg)
+	if err != nil {
+		return []string{fmt.Sprintf("cluster-scoped objects not checked, error creating client: %v", err)}
+	}
+
+	ctx := context.Background()
+	var leaked []string
+	for _, object := range objects {
+		if leak := sweepClusterScopedObject(ctx, client, object); leak != "" {
+			leaked = append(leaked, leak)
+		}
+	}
+	if len(leaked) > 0 {
+		fmt.Printf("Cluster-scoped objects left over by the tests:\n")
+		for _, leak := range leaked {
+			fmt.Printf("  %s\n", leak)
+		}
+	}
+
+	clusterScopedObjects.Lock()
+	defer clusterScopedObjects.Unlock()
+	clusterScopedObjects.leaked = append(clusterScopedObjects.leaked, leaked...)
+	return leaked
+}
+
+// sweepClusterScopedObject removes the object if it's still there, and returns why it was left over, if it was.
+func sweepClusterScopedObject(ctx context.Context, client dynamic.Interface, object clusterScopedObject) string {
+	resource := client.Resource(object.resource)
+	current, err := resource.Get(ctx, object.name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return ""
+	} else if err != nil {
+		return fmt.Sprintf("%s, error getting it: %v", object, err)
+	}
+
+	// Objects deleted by their test may still be finalized by their controller
+	deleting := current.GetDeletionTimestamp() != nil
+	leak := fmt.Sprintf("%s, not deleted by the test", object)
+	if deleting {
+		leak = fmt.Sprintf("%s, stuck deleting with finalizers %v", object, current.GetFinalizers())
+	} else if err := resource.Delete(ctx, object.name, metav1.DeleteOptions{}); err != nil && !errors.IsNotFound(err) {
+		return fmt.Sprintf("%s, error deleting it: %v", leak, err)
+	}
+	if clusterScopedObjectDeleted(ctx, resource, object.name, clusterScopedDeletionGracePeriod) {
+		if deleting {
+			return ""
+		}
+		return leak + ", deleted"
+	}
+
+	// Force the deletion, the controllers owning the finalizers didn't remove them in time
+	patch := []byte(`{"metadata":{"finalizers":null}}`)
+	if _, err := resource.Patch(ctx, object.name, types.MergePatchType, patch, metav1.PatchOptions{}); err != nil && !errors.IsNotFound(err) {
+		return fmt.Sprintf("%s, error removing its finalizers: %v", leak, err)
+	}
+	if clusterScopedObjectDeleted(ctx, resource, object.name, clusterScopedDeletionGracePeriod) {
+		return leak + ", force deleted"
+	}
+	return leak + ", not deleted, delete it manually"
+}
+
+func clusterScopedObjectDeleted(ctx context.Context, resource dynamic.NamespaceableResourceInterface, name string, timeout time.Duration) bool {
+	err := wait.PollUntilContextTimeout(ctx, time.Second, timeout, true, func(ctx context.Context) (bool, error) {
+		_, err := resource.Get(ctx, name, metav1.GetOptions{})
+		return errors.IsNotFound(err), nil
+	})
+	return err == nil
+}
+
+// clusterScopedLeaks returns the cluster-scoped objects found left over so far, leaving them to takeClusterScopedLeaks.
+func clusterScopedLeaks() []string {
+	clusterScopedObjects.Lock()
+	defer clusterScopedObjects.Unlock()
+	return slices.Clone(clusterScopedObjects.leaked)
+}
+
+func takeClusterScopedLeaks() []string {
+	clusterScopedObjects.Lock()
+	defer clusterScopedObjects.Unlock()
+	leaked := clusterScopedObjects.leaked
+	clusterScopedObjects.leaked = nil
+	return leaked
+}
@@ -0,0 +1,109 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const (
+	// The environment variable for comma separated list of kubeconfig contexts the suites are run against one after another
+	testClustersEnvVar = "TEST_CLUSTERS"
+	// The environment variable for comma separated list of suites run against each of the TEST_CLUSTERS, defaults to all suites
+	testClustersSuitesEnvVar = "TEST_CLUSTERS_SUITES"
+	// The environment variable for file the suite summaries are appended to as JSON lines, so the suites share a combined report
+	testReportFileEnvVar = "TEST_REPORT_FILE"
+)
+
+// currentCluster is the kubeconfig context the suite is currently run against, empty when TEST_CLUSTERS isn't set.
+var currentCluster string
+
+// suiteClusters returns the kubeconfig contexts the suite is run against, nil when the suite is run against the current context only.
+func suiteClusters(suiteName string) []string {
+	clusters := splitEnvList(lookupEnvOrDefault(testClustersEnvVar, ""))
+	if suites := splitEnvList(lookupEnvOrDefault(testClustersSuitesEnvVar, "")); len(suites) > 0 && !slices.Contains(suites, suiteName) {
+		return nil
+	}
+	return clusters
+}
+
+// useCluster points the test clients to the kubeconfig context, by setting KUBECONFIG to a copy of the kubeconfig
+// with the context as current context. The returned function restores the original KUBECONFIG.
+func useCluster(context string) (func(), error) {
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	config, err := loadingRules.Load()
+	if err != nil {
+		return nil, fmt.Errorf("error loading kubeconfig: %w", err)
+	}
+	if _, ok := config.Contexts[context]; !ok {
+		return nil, fmt.Errorf("context %q not found in kubeconfig", context)
+	}
+	config.CurrentContext = context
+
+	dir, err := os.MkdirTemp("", "kubeconfig-")
+	if err != nil {
+		return nil, err
+	}
+	kubeconfig := filepath.Join(dir, "config")
+	if err := clientcmd.WriteToFile(*config, kubeconfig); err != nil {
+		os.RemoveAll(dir)
+		return nil, fmt.Errorf("error writing kubeconfig for context %q: %w", context, err)
+	}
+
+	original, set := os.LookupEnv(clientcmd.RecommendedConfigPathEnvVar)
+	os.Setenv(clientcmd.RecommendedConfigPathEnvVar, kubeconfig)
+	currentCluster = context
+
+	return func() {
+		if set {
+			os.Setenv(clientcmd.RecommendedConfigPathEnvVar, original)
+		} else {
+			os.Unsetenv(clientcmd.RecommendedConfigPathEnvVar)
+		}
+		currentCluster = ""
+		os.RemoveAll(dir)
+	}, nil
+}
+
+// appendSuiteReport appends the suite summary to the configured report file, it does nothing if no report file is configured.
+func appendSuiteReport(summary SuiteSummary) {
+	reportFile, ok := os.LookupEnv(testReportFileEnvVar)
+	if !ok || reportFile == "" {
+		return
+	}
+
+	line, err := json.Marshal(summary)
+	if err != nil {
+		fmt.Printf("Error marshalling suite summary: %v\n", err)
+		return
+	}
+	f, err := os.OpenFile(reportFile, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		fmt.Printf("Error opening report file %s: %v\n", reportFile, err)
+		return
+	}
+	defer f.Close()
+	if _, err := f.Write(append(line, '\n')); err != nil {
+		fmt.Printf("Error writing report file %s: %v\n", reportFile, err)
+	}
+}
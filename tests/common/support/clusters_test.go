@@ -0,0 +1,214 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+
+	"github.com/opendatahub-io/distributed-workloads/pkg/results"
+)
+
+// TestAppendSuiteReport makes sure the results file written by the suites is read by the results package, and
+// conforms to its schema, so the fields added to the suite summary aren't missed by the tools reading it.
+func TestAppendSuiteReport(t *testing.T) {
+	g := gomega.NewWithT(t)
+	summary := SuiteSummary{
+		Suite:    "kueue",
+		Duration: time.Minute,
+		Clusters: []string{"cpu", "gpu"},
+		Images:   map[string]string{"busybox": "busybox@sha256:1"},
+		Results: []TestResult{{
+			Name:         "TestKueue/GPU",
+			Status:       TestXPassed,
+			Duration:     time.Second,
+			Labels:       []string{LabelKueue},
+			Failures:     []string{"error creating Pod test-ns/pod: denied"},
+			Issue:        "RHOAIENG-1",
+			Measurements: map[string]float64{"bandwidth": 1.5},
+			Contaminated: []string{"noisy neighbor"},
+			Timeout:      time.Hour,
+			Cluster:      "gpu",
+		}},
+		Leaked: []string{"ClusterQueue/cq"},
+	}
+	// The summary has all its fields set, so any field missed by the results package or its schema is caught
+	g.Expect(zeroFields(summary)).To(gomega.BeEmpty())
+	g.Expect(zeroFields(summary.Results[0])).To(gomega.BeEmpty())
+
+	reportFile := filepath.Join(t.TempDir(), "results.jsonl")
+	t.Setenv(testReportFileEnvVar, reportFile)
+	appendSuiteReport(summary)
+	appendSuiteReport(summary.ForCluster("cpu"))
+
+	suites, err := results.ReadFile(reportFile)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(suites).To(gomega.HaveLen(2))
+	g.Expect(suites[0]).To(gomega.Equal(results.Suite{
+		Suite:    "kueue",
+		Duration: time.Minute,
+		Clusters: []string{"cpu", "gpu"},
+		Images:   map[string]string{"busybox": "busybox@sha256:1"},
+		Results: []results.Result{{
+			Name:         "TestKueue/GPU",
+			Status:       results.StatusXPassed,
+			Duration:     time.Second,
+			Labels:       []string{LabelKueue},
+			Failures:     []string{"error creating Pod test-ns/pod: denied"},
+			Issue:        "RHOAIENG-1",
+			Measurements: map[string]float64{"bandwidth": 1.5},
+			Contaminated: []string{"noisy neighbor"},
+			Timeout:      time.Hour,
+			Cluster:      "gpu",
+		}},
+		Leaked: []string{"ClusterQueue/cq"},
+	}))
+	g.Expect(suites[1].Results).To(gomega.BeNil())
+
+	var schema map[string]any
+	g.Expect(json.Unmarshal(results.Schema, &schema)).To(gomega.Succeed())
+	data, err := os.ReadFile(reportFile)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	lines := strings.Split(strings.TrimSpace(string(data)), "\n")
+	for _, line := range lines {
+		var value any
+		g.Expect(json.Unmarshal([]byte(line), &value)).To(gomega.Succeed())
+		g.Expect(validateSchema(schema, schema, value, "")).To(gomega.BeEmpty())
+	}
+
+	// The summary with all its fields set has all the properties of the schema
+	var written struct {
+		Results []map[string]any `json:"results"`
+	}
+	var properties map[string]any
+	g.Expect(json.Unmarshal([]byte(lines[0]), &properties)).To(gomega.Succeed())
+	g.Expect(json.Unmarshal([]byte(lines[0]), &written)).To(gomega.Succeed())
+	g.Expect(properties).To(gomega.HaveLen(len(schema["properties"].(map[string]any))))
+	result := schema["$defs"].(map[string]any)["result"].(map[string]any)
+	g.Expect(written.Results[0]).To(gomega.HaveLen(len(result["properties"].(map[string]any))))
+}
+
+// zeroFields returns the names of the fields of the struct with zero values.
+func zeroFields(object any) []string {
+	var zero []string
+	value := reflect.ValueOf(object)
+	for i := 0; i < value.NumField(); i++ {
+		if value.Field(i).IsZero() {
+			zero = append(zero, value.Type().Field(i).Name)
+		}
+	}
+	return zero
+}
+
+// validateSchema returns the violations of the schema by the JSON value, it supports the subset of JSON schema the
+// results schema uses: types, required and known properties, enums and references to its definitions.
+func validateSchema(root, schema map[string]any, value any, path string) []string {
+	if ref, ok := schema["$ref"].(string); ok {
+		definition := root
+		for _, segment := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+			definition = definition[segment].(map[string]any)
+		}
+		return validateSchema(root, definition, value, path)
+	}
+
+	if !schemaTypeMatches(schema["type"], value) {
+		return []string{fmt.Sprintf("%s: %v isn't of type %v", path, value, schema["type"])}
+	}
+	if enum, ok := schema["enum"].([]any); ok && !slices.Contains(enum, value) {
+		return []string{fmt.Sprintf("%s: %v isn't one of %v", path, value, enum)}
+	}
+
+	var violations []string
+	switch value := value.(type) {
+	case map[string]any:
+		required, _ := schema["required"].([]any)
+		for _, name := range required {
+			if _, ok := value[name.(string)]; !ok {
+				violations = append(violations, fmt.Sprintf("%s: missing required property %s", path, name))
+			}
+		}
+		properties, _ := schema["properties"].(map[string]any)
+		additional, _ := schema["additionalProperties"].(map[string]any)
+		for name, property := range value {
+			propertySchema, ok := properties[name].(map[string]any)
+			if !ok {
+				propertySchema = additional
+			}
+			if propertySchema == nil {
+				violations = append(violations, fmt.Sprintf("%s: unknown property %s", path, name))
+				continue
+			}
+			violations = append(violations, validateSchema(root, propertySchema, property, path+"/"+name)...)
+		}
+	case []any:
+		if items, ok := schema["items"].(map[string]any); ok {
+			for i, item := range value {
+				violations = append(violations, validateSchema(root, items, item, fmt.Sprintf("%s/%d", path, i))...)
+			}
+		}
+	}
+	return violations
+}
+
+// schemaTypeMatches returns whether the JSON value is of the type, or one of the types, of a schema.
+func schemaTypeMatches(types any, value any) bool {
+	if types == nil {
+		return true
+	}
+	names, ok := types.([]any)
+	if !ok {
+		names = []any{types}
+	}
+	for _, name := range names {
+		switch name {
+		case "object":
+			if _, ok := value.(map[string]any); ok {
+				return true
+			}
+		case "array":
+			if _, ok := value.([]any); ok {
+				return true
+			}
+		case "string":
+			if _, ok := value.(string); ok {
+				return true
+			}
+		case "number":
+			if _, ok := value.(float64); ok {
+				return true
+			}
+		case "integer":
+			if number, ok := value.(float64); ok && number == float64(int64(number)) {
+				return true
+			}
+		case "null":
+			if value == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func Pod(t Test, namespace, name string) func(g gomega.Gomega) *corev1.Pod {
+	return func(g gomega.Gomega) *corev1.Pod {
+		pod, err := t.Client().Core().CoreV1().Pods(namespace).Get(t.Ctx(), name, metav1.GetOptions{})
+		g.Expect(WrapError(err, "getting", Ref("Pod", namespace, name))).NotTo(gomega.HaveOccurred())
+		return pod
+	}
+}
+
+func GetPod(t Test, namespace, name string) *corev1.Pod {
+	t.T().Helper()
+	return Pod(t, namespace, name)(t)
+}
+
+func PodPhase(pod *corev1.Pod) corev1.PodPhase {
+	return pod.Status.Phase
+}
+
+func CreatePod(t Test, pod *corev1.Pod) *corev1.Pod {
+	t.T().Helper()
+	SetArchitectureNodeSelector(&pod.Spec)
+	created, err := t.Client().Core().CoreV1().Pods(pod.Namespace).Create(t.Ctx(), pod, metav1.CreateOptions{})
+	ExpectNoError(t, err, "creating", Ref("Pod", pod.Namespace, pod.Name+pod.GenerateName))
+	pod = created
+	t.T().Logf("Created Pod %s/%s successfully", pod.Namespace, pod.Name)
+	return pod
+}
+
+// PodRunningAndReady returns true if the pod is running, isn't being deleted and all its containers are ready.
+func PodRunningAndReady(pod corev1.Pod) bool {
+	if pod.Status.Phase != corev1.PodRunning || pod.DeletionTimestamp != nil {
+		return false
+	}
+	for _, condition := range pod.Status.Conditions {
+		if condition.Type == corev1.PodReady {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+// JobPods returns pods created for the batch Job.
+func JobPods(t Test, namespace, jobName string) func(g gomega.Gomega) []corev1.Pod {
+	return func(g gomega.Gomega) []corev1.Pod {
+		pods, err := t.Client().Core().CoreV1().Pods(namespace).List(t.Ctx(), metav1.ListOptions{LabelSelector: "job-name=" + jobName})
+		g.Expect(WrapError(err, "listing pods of", Ref("Job", namespace, jobName))).NotTo(gomega.HaveOccurred())
+		return pods.Items
+	}
+}
+
+// JobConditionStatus returns the accessor of the status of the Job condition, Unknown when the condition isn't set.
+// Unlike ConditionStatus, it only accepts Jobs, so it can be used with Field.
+func JobConditionStatus(conditionType batchv1.JobConditionType) func(*batchv1.Job) corev1.ConditionStatus {
+	status := ConditionStatus(conditionType)
+	return func(job *batchv1.Job) corev1.ConditionStatus {
+		return status(job)
+	}
+}
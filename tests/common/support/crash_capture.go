@@ -0,0 +1,84 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/opendatahub-io/distributed-workloads/pkg/examples"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// storeCrashArtifacts stores the crash reported by the crash capture wrapper in the container logs with the test
+// output, i.e. the faulthandler traceback and the signal in a crash log, and the core dumps as gzipped files.
+func storeCrashArtifacts(t Test, pod *corev1.Pod, container string, logs []byte) {
+	t.T().Helper()
+
+	lines := strings.Split(string(logs), "\n")
+	crashed := false
+	for _, line := range lines {
+		crashed = crashed || strings.HasPrefix(line, examples.CrashCaptureMarker+" BEGIN")
+	}
+	if !crashed {
+		return
+	}
+
+	var report []string
+	var core []string
+	inTraceback, inCore := false, ""
+	for _, line := range lines {
+		switch {
+		case strings.HasPrefix(line, "Fatal Python error"):
+			inTraceback = true
+			report = append(report, line)
+		case strings.HasPrefix(line, examples.CrashCaptureMarker+" CORE BEGIN "):
+			inCore = strings.TrimPrefix(line, examples.CrashCaptureMarker+" CORE BEGIN ")
+			core = nil
+		case strings.HasPrefix(line, examples.CrashCaptureMarker+" CORE END"):
+			storeCoreDump(t, pod, container, inCore, strings.Join(core, ""))
+			inCore = ""
+		case inCore != "":
+			core = append(core, line)
+		case strings.HasPrefix(line, examples.CrashCaptureMarker):
+			inTraceback = false
+			report = append(report, line)
+		case inTraceback:
+			report = append(report, line)
+		}
+	}
+	WriteToOutputDir(t, fmt.Sprintf("crash-%s-%s", pod.Name, container), Log, []byte(strings.Join(report, "\n")+"\n"))
+	t.T().Logf("Container %s of pod %s/%s crashed, the crash is stored with the test output", container, pod.Namespace, pod.Name)
+}
+
+func storeCoreDump(t Test, pod *corev1.Pod, container, name, encoded string) {
+	t.T().Helper()
+	core, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		t.T().Logf("Error decoding core dump %s of container %s of pod %s/%s: %v", name, container, pod.Namespace, pod.Name, err)
+		return
+	}
+	file := filepath.Join(t.OutputDir(), fmt.Sprintf("core-%s-%s-%s.gz", pod.Name, container, filepath.Base(name)))
+	if err := os.WriteFile(file, core, 0o644); err != nil {
+		t.T().Logf("Error storing core dump %s: %v", file, err)
+	}
+}
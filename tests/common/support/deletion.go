@@ -0,0 +1,120 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+// finalizerControllers maps the finalizers of the resources exercised by the tests to the controllers removing them.
+var finalizerControllers = map[string]string{
+	metav1.FinalizerOrphanDependents:        "garbage collector, orphaning the dependents",
+	metav1.FinalizerDeleteDependents:        "garbage collector, waiting for the dependents to be deleted",
+	"batch.kubernetes.io/job-tracking":      "Job controller of kube-controller-manager",
+	"kubernetes.io/pvc-protection":          "PVC protection controller of kube-controller-manager, waiting for the pods using the PVC",
+	"kubernetes.io/pv-protection":           "PV protection controller of kube-controller-manager",
+	"kueue.x-k8s.io/resource-in-use":        "Kueue, waiting for the workloads using the resource",
+	"kueue.x-k8s.io/managed":                "Kueue",
+	"ray.io/gcs-ft-redis-cleanup-finalizer": "KubeRay operator, cleaning up the GCS fault tolerance Redis storage",
+	"ray.io/rayjob-finalizer":               "KubeRay operator, stopping the Ray job",
+	"workload.codeflare.dev/finalizer":      "AppWrapper controller, deleting the wrapped resources",
+}
+
+// WaitForDeletion waits until the object is deleted. On timeout, the test fails reporting whether the deletion of the
+// object was requested, and the finalizers blocking it with the controllers likely responsible for removing them.
+func WaitForDeletion(t Test, resource schema.GroupVersionResource, object metav1.Object, timeout time.Duration) {
+	t.T().Helper()
+	waitForDeletion(t, timeout, func(g gomega.Gomega) []unstructured.Unstructured {
+		remaining, err := t.Client().Dynamic().Resource(resource).Namespace(object.GetNamespace()).Get(t.Ctx(), object.GetName(), metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			return nil
+		}
+		g.Expect(WrapError(err, "getting", Ref(resource.Resource, object.GetNamespace(), object.GetName()))).NotTo(gomega.HaveOccurred())
+		return []unstructured.Unstructured{*remaining}
+	})
+}
+
+// WaitForDeletionOf waits until all the objects of the resource matching the list options are deleted, i.e. the pods
+// of a workload, with the same diagnostics as WaitForDeletion for the remaining objects on timeout.
+func WaitForDeletionOf(t Test, resource schema.GroupVersionResource, namespace string, options metav1.ListOptions, timeout time.Duration) {
+	t.T().Helper()
+	waitForDeletion(t, timeout, func(g gomega.Gomega) []unstructured.Unstructured {
+		remaining, err := t.Client().Dynamic().Resource(resource).Namespace(namespace).List(t.Ctx(), options)
+		g.Expect(WrapError(err, "listing", Ref(resource.Resource, namespace, options.LabelSelector))).NotTo(gomega.HaveOccurred())
+		return remaining.Items
+	})
+}
+
+func waitForDeletion(t Test, timeout time.Duration, remaining func(g gomega.Gomega) []unstructured.Unstructured) {
+	t.T().Helper()
+	var objects []unstructured.Unstructured
+	t.Eventually(func(g gomega.Gomega) []unstructured.Unstructured {
+		objects = remaining(g)
+		return objects
+	}, timeout).Should(gomega.BeEmpty(), func() string {
+		return "Objects not deleted in " + timeout.String() + ":\n" + deletionDiagnostics(objects, time.Now())
+	})
+}
+
+// deletionDiagnostics describes why the deletion of each of the objects is stuck.
+func deletionDiagnostics(objects []unstructured.Unstructured, now time.Time) string {
+	var b strings.Builder
+	for _, object := range objects {
+		fmt.Fprintf(&b, "    %s: ", Ref(object.GetKind(), object.GetNamespace(), object.GetName()))
+		deletionTimestamp := object.GetDeletionTimestamp()
+		if deletionTimestamp == nil {
+			b.WriteString("deletion not requested")
+			for _, owner := range object.GetOwnerReferences() {
+				fmt.Fprintf(&b, ", deleted by the garbage collector once its owner %s %s is deleted", owner.Kind, owner.Name)
+			}
+			b.WriteString("\n")
+			continue
+		}
+		fmt.Fprintf(&b, "deletion requested %s ago", now.Sub(deletionTimestamp.Time).Round(time.Second))
+		finalizers := object.GetFinalizers()
+		if len(finalizers) == 0 {
+			b.WriteString(", no finalizer left\n")
+			continue
+		}
+		b.WriteString(", blocked by finalizers:\n")
+		for _, finalizer := range finalizers {
+			fmt.Fprintf(&b, "        %s, removed by %s\n", finalizer, finalizerController(finalizer))
+		}
+	}
+	return b.String()
+}
+
+// finalizerController returns the controller likely responsible for removing the finalizer.
+func finalizerController(finalizer string) string {
+	if controller, ok := finalizerControllers[finalizer]; ok {
+		return controller
+	}
+	if domain, _, ok := strings.Cut(finalizer, "/"); ok {
+		return "the controller of " + domain
+	}
+	return "an unknown controller"
+}
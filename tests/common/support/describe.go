@@ -0,0 +1,240 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	awv1beta2 "github.com/project-codeflare/appwrapper/api/v1beta2"
+	awutils "github.com/project-codeflare/appwrapper/pkg/utils"
+	. "github.com/project-codeflare/codeflare-common/support"
+	kueuev1beta1 "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+)
+
+const (
+	// Label of the Kueue LocalQueue the workload is queued in
+	kueueQueueNameLabel = "kueue.x-k8s.io/queue-name"
+	// Label of the Kueue WorkloadPriorityClass the workload is queued with
+	kueuePriorityClassLabel = "kueue.x-k8s.io/priority-class"
+)
+
+// DescribeWorkload returns a concise human-readable summary of the AppWrapper or Kueue Workload, with its queue,
+// priority, requested resources, pod breakdown and condition timeline. It's meant for logs and test artifacts,
+// where it's quicker to read than the YAML of the object.
+func DescribeWorkload(obj any) string {
+	var b strings.Builder
+	switch workload := obj.(type) {
+	case *awv1beta2.AppWrapper:
+		describeAppWrapper(&b, workload)
+	case *kueuev1beta1.Workload:
+		describeKueueWorkload(&b, workload)
+	case metav1.Object:
+		fmt.Fprintf(&b, "%T %s/%s\n", obj, workload.GetNamespace(), workload.GetName())
+	default:
+		fmt.Fprintf(&b, "%T isn't a workload\n", obj)
+	}
+	return b.String()
+}
+
+func describeAppWrapper(b *strings.Builder, appWrapper *awv1beta2.AppWrapper) {
+	fmt.Fprintf(b, "AppWrapper %s/%s\n", appWrapper.Namespace, appWrapper.Name)
+	fmt.Fprintf(b, "  Queue:      %s\n", valueOrNone(appWrapper.Labels[kueueQueueNameLabel]))
+
+	var pods []string
+	var priorityClass string
+	total := corev1.ResourceList{}
+	for _, component := range appWrapper.Spec.Components {
+		object := &unstructured.Unstructured{}
+		if err := object.UnmarshalJSON(component.Template.Raw); err != nil {
+			pods = append(pods, fmt.Sprintf("invalid component template: %v", err))
+			continue
+		}
+		for _, podSet := range component.PodSets {
+			template, err := awutils.GetPodTemplateSpec(object, podSet.PodPath)
+			if err != nil {
+				pods = append(pods, fmt.Sprintf("%s/%s %s: %v", object.GetKind(), object.GetName(), podSet.PodPath, err))
+				continue
+			}
+			replicas := awutils.Replicas(podSet)
+			if podSet.ReplicaPath != "" {
+				if replicas, err = awutils.GetReplicas(object, podSet.ReplicaPath); err != nil {
+					pods = append(pods, fmt.Sprintf("%s/%s %s: %v", object.GetKind(), object.GetName(), podSet.ReplicaPath, err))
+					continue
+				}
+			}
+			requests := podRequests(template.Spec)
+			addResources(total, requests, replicas)
+			if priorityClass == "" {
+				priorityClass = template.Spec.PriorityClassName
+			}
+			pods = append(pods, fmt.Sprintf("%s/%s %s: %d x %s", object.GetKind(), object.GetName(), podSet.PodPath, replicas, formatResources(requests)))
+		}
+	}
+	if priority, ok := appWrapper.Labels[kueuePriorityClassLabel]; ok {
+		priorityClass = priority
+	}
+
+	fmt.Fprintf(b, "  Priority:   %s\n", valueOrNone(priorityClass))
+	fmt.Fprintf(b, "  Phase:      %s (%d retries)\n", valueOrNone(string(appWrapper.Status.Phase)), appWrapper.Status.Retries)
+	fmt.Fprintf(b, "  Resources:  %s\n", formatResources(total))
+	describeList(b, "Pods", pods)
+	describeConditions(b, appWrapper.Status.Conditions)
+}
+
+func describeKueueWorkload(b *strings.Builder, workload *kueuev1beta1.Workload) {
+	fmt.Fprintf(b, "Workload %s/%s\n", workload.Namespace, workload.Name)
+	queue := workload.Spec.QueueName
+	if admission := workload.Status.Admission; admission != nil {
+		queue = fmt.Sprintf("%s (admitted to ClusterQueue %s)", queue, admission.ClusterQueue)
+	}
+	fmt.Fprintf(b, "  Queue:      %s\n", valueOrNone(queue))
+	priority := "-"
+	if workload.Spec.Priority != nil {
+		priority = fmt.Sprintf("%d", *workload.Spec.Priority)
+	}
+	fmt.Fprintf(b, "  Priority:   %s (%s)\n", priority, valueOrNone(workload.Spec.PriorityClassName))
+
+	var pods []string
+	total := corev1.ResourceList{}
+	for _, podSet := range workload.Spec.PodSets {
+		requests := podRequests(podSet.Template.Spec)
+		addResources(total, requests, podSet.Count)
+		pod := fmt.Sprintf("%s: %d x %s", podSet.Name, podSet.Count, formatResources(requests))
+		if workload.Status.Admission != nil {
+			for _, assignment := range workload.Status.Admission.PodSetAssignments {
+				if assignment.Name == podSet.Name && len(assignment.Flavors) > 0 {
+					var flavors []string
+					for resourceName, flavor := range assignment.Flavors {
+						flavors = append(flavors, fmt.Sprintf("%s=%s", resourceName, flavor))
+					}
+					sort.Strings(flavors)
+					pod += fmt.Sprintf(" [flavors %s]", strings.Join(flavors, ", "))
+				}
+			}
+		}
+		pods = append(pods, pod)
+	}
+	fmt.Fprintf(b, "  Active:     %t\n", workload.Spec.Active == nil || *workload.Spec.Active)
+	fmt.Fprintf(b, "  Resources:  %s\n", formatResources(total))
+	describeList(b, "Pods", pods)
+	describeConditions(b, workload.Status.Conditions)
+}
+
+// describeConditions lists the conditions ordered by their last transition, so they read as a timeline.
+func describeConditions(b *strings.Builder, conditions []metav1.Condition) {
+	conditions = append([]metav1.Condition(nil), conditions...)
+	sort.SliceStable(conditions, func(i, j int) bool {
+		return conditions[i].LastTransitionTime.Before(&conditions[j].LastTransitionTime)
+	})
+	var timeline []string
+	for _, condition := range conditions {
+		entry := fmt.Sprintf("%s %s=%s", condition.LastTransitionTime.UTC().Format(time.RFC3339), condition.Type, condition.Status)
+		if condition.Reason != "" {
+			entry += fmt.Sprintf(" (%s)", condition.Reason)
+		}
+		if condition.Message != "" {
+			entry += ": " + condition.Message
+		}
+		timeline = append(timeline, entry)
+	}
+	describeList(b, "Conditions", timeline)
+}
+
+func describeList(b *strings.Builder, title string, items []string) {
+	if len(items) == 0 {
+		fmt.Fprintf(b, "  %-11s -\n", title+":")
+		return
+	}
+	fmt.Fprintf(b, "  %s:\n", title)
+	for _, item := range items {
+		fmt.Fprintf(b, "    %s\n", item)
+	}
+}
+
+// podRequests returns the resources requested by the pod containers, limits stand for requests which aren't set.
+func podRequests(spec corev1.PodSpec) corev1.ResourceList {
+	requests := corev1.ResourceList{}
+	for _, container := range spec.Containers {
+		for name, quantity := range container.Resources.Limits {
+			if _, ok := container.Resources.Requests[name]; !ok {
+				addQuantity(requests, name, quantity)
+			}
+		}
+		for name, quantity := range container.Resources.Requests {
+			addQuantity(requests, name, quantity)
+		}
+	}
+	return requests
+}
+
+func addResources(total, resources corev1.ResourceList, count int32) {
+	for name, quantity := range resources {
+		for i := int32(0); i < count; i++ {
+			addQuantity(total, name, quantity)
+		}
+	}
+}
+
+func addQuantity(resources corev1.ResourceList, name corev1.ResourceName, quantity resource.Quantity) {
+	sum := resources[name]
+	sum.Add(quantity)
+	resources[name] = sum
+}
+
+func formatResources(resources corev1.ResourceList) string {
+	if len(resources) == 0 {
+		return "-"
+	}
+	var formatted []string
+	for name, quantity := range resources {
+		formatted = append(formatted, fmt.Sprintf("%s=%s", name, quantity.String()))
+	}
+	sort.Strings(formatted)
+	return strings.Join(formatted, ", ")
+}
+
+func valueOrNone(value string) string {
+	if value == "" {
+		return "-"
+	}
+	return value
+}
+
+// storeWorkloadDescription writes the description of the AppWrapper and of its Kueue Workload into the test output directory.
+func storeWorkloadDescription(t Test, appWrapper *awv1beta2.AppWrapper) {
+	t.T().Helper()
+
+	description := DescribeWorkload(appWrapper)
+	workloads, err := t.Client().Kueue().KueueV1beta1().Workloads(appWrapper.Namespace).List(t.Ctx(), metav1.ListOptions{})
+	if err == nil {
+		for i := range workloads.Items {
+			if metav1.IsControlledBy(&workloads.Items[i], appWrapper) {
+				description += DescribeWorkload(&workloads.Items[i])
+			}
+		}
+	}
+	t.T().Logf("Workload summary:\n%s", description)
+	WriteToOutputDir(t, "workload-"+appWrapper.Name, Log, []byte(description))
+}
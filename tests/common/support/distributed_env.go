@@ -0,0 +1,194 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/onsi/gomega"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+
+	kftov1 "github.com/kubeflow/training-operator/pkg/apis/kubeflow.org/v1"
+)
+
+// DistributedEnv is the environment the distributed training frameworks are configured with in a pod, i.e. the
+// rendezvous injected by the operators, and the NCCL and Gloo tuning, by variable name.
+type DistributedEnv map[string]string
+
+// distributedEnvVars are the variables injected by the training operator and KubeRay into the pods they create.
+var distributedEnvVars = map[string]bool{
+	"MASTER_ADDR":                    true,
+	"MASTER_PORT":                    true,
+	"WORLD_SIZE":                     true,
+	"RANK":                           true,
+	"LOCAL_RANK":                     true,
+	"RAY_ADDRESS":                    true,
+	"RAY_PORT":                       true,
+	"RAY_IP":                         true,
+	"FQ_RAY_IP":                      true,
+	"RAY_CLUSTER_NAME":               true,
+	"RAY_CLOUD_INSTANCE_ID":          true,
+	"RAY_USAGE_STATS_KUBERAY_IN_USE": true,
+}
+
+// distributedEnvPrefixes are the prefixes of the torchrun, NCCL and Gloo variables, any of them being set silently
+// changes how the processes communicate.
+var distributedEnvPrefixes = []string{"PET_", "NCCL_", "GLOO_", "TORCH_NCCL_", "TORCH_DISTRIBUTED_"}
+
+func isDistributedEnvVar(name string) bool {
+	if distributedEnvVars[name] {
+		return true
+	}
+	for _, prefix := range distributedEnvPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// distributedEnvOf returns the distributed variables of the environment, later duplicates overriding earlier ones
+// as they do in the container. Variables set from a field of the pod are reported with the path of the field.
+func distributedEnvOf(env []corev1.EnvVar) DistributedEnv {
+	distributedEnv := DistributedEnv{}
+	for _, variable := range env {
+		if !isDistributedEnvVar(variable.Name) {
+			continue
+		}
+		if variable.ValueFrom != nil && variable.ValueFrom.FieldRef != nil {
+			distributedEnv[variable.Name] = "fieldRef:" + variable.ValueFrom.FieldRef.FieldPath
+		} else {
+			distributedEnv[variable.Name] = variable.Value
+		}
+	}
+	return distributedEnv
+}
+
+// PodDistributedEnv returns the distributed environment of the first container of the pod, the one the training
+// operator and KubeRay inject it into.
+func PodDistributedEnv(pod corev1.Pod) DistributedEnv {
+	if len(pod.Spec.Containers) == 0 {
+		return DistributedEnv{}
+	}
+	return distributedEnvOf(pod.Spec.Containers[0].Env)
+}
+
+// HaveDistributedEnv succeeds when the distributed environment of the pod is exactly the expected one, reporting
+// the variables missing, unexpected or with another value otherwise, i.e.
+// ExpectOf(test, pod).To(HaveDistributedEnv(PyTorchJobDistributedEnv(job, pod))).
+func HaveDistributedEnv(expected DistributedEnv) TypedMatcher[corev1.Pod] {
+	return Field(PodDistributedEnv).Matches(gomega.BeComparableTo(expected))
+}
+
+// PyTorchJobDistributedEnv returns the distributed environment the training operator is expected to inject into
+// the pod of the non-elastic PyTorchJob, with the NCCL and Gloo tuning of its replica template.
+func PyTorchJobDistributedEnv(job *kftov1.PyTorchJob, pod corev1.Pod) DistributedEnv {
+	replicaType := pod.Labels[kftov1.ReplicaTypeLabel]
+	index, _ := strconv.Atoi(pod.Labels[kftov1.ReplicaIndexLabel])
+
+	var replicas int32
+	var template *corev1.PodTemplateSpec
+	for specType, spec := range job.Spec.PyTorchReplicaSpecs {
+		if spec.Replicas != nil {
+			replicas += *spec.Replicas
+		}
+		if strings.EqualFold(string(specType), replicaType) {
+			template = &spec.Template
+		}
+	}
+
+	expected := DistributedEnv{}
+	if template != nil && len(template.Spec.Containers) > 0 {
+		expected = distributedEnvOf(template.Spec.Containers[0].Env)
+	}
+
+	rank := index
+	if strings.EqualFold(replicaType, string(kftov1.PyTorchJobReplicaTypeWorker)) {
+		rank++
+	}
+	nprocPerNode := "auto"
+	if job.Spec.NprocPerNode != nil {
+		nprocPerNode = *job.Spec.NprocPerNode
+	}
+	// The world size counts a process per pod unless the number of processes per node is set
+	processes, err := strconv.Atoi(nprocPerNode)
+	if err != nil {
+		processes = 1
+	}
+
+	expected["MASTER_ADDR"] = fmt.Sprintf("%s-master-0", job.Name)
+	expected["MASTER_PORT"] = strconv.Itoa(pytorchJobMasterPort(job))
+	expected["WORLD_SIZE"] = strconv.Itoa(int(replicas) * processes)
+	expected["RANK"] = strconv.Itoa(rank)
+	expected["PET_NPROC_PER_NODE"] = nprocPerNode
+	expected["PET_NODE_RANK"] = strconv.Itoa(rank)
+	expected["PET_NNODES"] = strconv.Itoa(int(replicas))
+	return expected
+}
+
+// pytorchJobMasterPort returns the port the master of the PyTorchJob listens on for the rendezvous.
+func pytorchJobMasterPort(job *kftov1.PyTorchJob) int {
+	if master := job.Spec.PyTorchReplicaSpecs[kftov1.PyTorchJobReplicaTypeMaster]; master != nil {
+		for _, container := range master.Template.Spec.Containers {
+			if container.Name != kftov1.PyTorchJobDefaultContainerName {
+				continue
+			}
+			for _, port := range container.Ports {
+				if port.Name == kftov1.PyTorchJobDefaultPortName {
+					return int(port.ContainerPort)
+				}
+			}
+		}
+	}
+	return kftov1.PyTorchJobDefaultPort
+}
+
+// RayClusterDistributedEnv returns the distributed environment KubeRay is expected to inject into the head or the
+// worker pod of the RayCluster, with the NCCL and Gloo tuning of its group template. Workers connect to the GCS
+// through the head Service in the default cluster domain.
+func RayClusterDistributedEnv(cluster *rayv1.RayCluster, pod corev1.Pod) DistributedEnv {
+	template := &cluster.Spec.HeadGroupSpec.Template
+	for i, group := range cluster.Spec.WorkerGroupSpecs {
+		if group.GroupName == pod.Labels["ray.io/group"] {
+			template = &cluster.Spec.WorkerGroupSpecs[i].Template
+		}
+	}
+
+	expected := DistributedEnv{}
+	if len(template.Spec.Containers) > 0 {
+		expected = distributedEnvOf(template.Spec.Containers[0].Env)
+	}
+
+	const gcsPort = "6379"
+	address := "127.0.0.1"
+	if pod.Labels["ray.io/node-type"] == string(rayv1.WorkerNode) {
+		headService := fmt.Sprintf("%s-head-svc", cluster.Name)
+		address = fmt.Sprintf("%s.%s.svc.cluster.local", headService, cluster.Namespace)
+		expected["FQ_RAY_IP"] = address
+		expected["RAY_IP"] = headService
+	}
+	expected["RAY_ADDRESS"] = address + ":" + gcsPort
+	expected["RAY_PORT"] = gcsPort
+	expected["RAY_CLUSTER_NAME"] = "fieldRef:metadata.labels['ray.io/cluster']"
+	expected["RAY_CLOUD_INSTANCE_ID"] = "fieldRef:metadata.name"
+	expected["RAY_USAGE_STATS_KUBERAY_IN_USE"] = "1"
+	return expected
+}
@@ -0,0 +1,160 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"sync/atomic"
+
+	"github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// dryRunFirstResources are the resources of the workloads, and of what they are templated from, created with a
+// server-side dry-run first. Other creates, i.e. of tokens or access reviews, are sent as is.
+var dryRunFirstResources = map[string]bool{
+	"pods":         true,
+	"jobs":         true,
+	"cronjobs":     true,
+	"deployments":  true,
+	"statefulsets": true,
+	"configmaps":   true,
+	"pytorchjobs":  true,
+	"rayclusters":  true,
+	"rayjobs":      true,
+	"appwrappers":  true,
+	"notebooks":    true,
+}
+
+// DryRunFirst returns the test with its clients creating the workloads with a server-side dry-run first, so the
+// builder and template mistakes fail the test within seconds with the validation or admission webhook error,
+// rather than once the workload is reconciled. The rendered spec rejected by the dry-run is stored into the output
+// directory of the test. RunSuite registers it as a hook of WithHooks with TEST_DRY_RUN_FIRST.
+func DryRunFirst(t Test) Test {
+	return &dryRunFirstTest{Test: t}
+}
+
+type dryRunFirstTest struct {
+	Test
+	once   sync.Once
+	client Client
+}
+
+func (t *dryRunFirstTest) unwrap() Test {
+	return t.Test
+}
+
+func (t *dryRunFirstTest) Client() Client {
+	t.T().Helper()
+	t.once.Do(func() {
+		cfg, err := restConfig(t.Test)
+		t.Expect(err).NotTo(gomega.HaveOccurred(), "Error loading client configuration")
+		cfg.Wrap(func(next http.RoundTripper) http.RoundTripper {
+			return &dryRunFirstTransport{t: t, next: next}
+		})
+		t.client = WithConfig(t.T(), cfg).Client()
+	})
+	return t.client
+}
+
+// dryRunFirstTransport sends the creates of the workloads with dryRun=All first, and only sends the create
+// once the dry-run succeeded. The response of the rejected dry-run is returned to the client otherwise.
+type dryRunFirstTransport struct {
+	t        Test
+	next     http.RoundTripper
+	rejected atomic.Int32
+}
+
+func (d *dryRunFirstTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	resource, ok := createdResource(req)
+	if !ok || req.Body == nil {
+		return d.next.RoundTrip(req)
+	}
+
+	body, err := io.ReadAll(req.Body)
+	req.Body.Close()
+	if err != nil {
+		return nil, err
+	}
+	req.Body = io.NopCloser(bytes.NewReader(body))
+	req.GetBody = func() (io.ReadCloser, error) {
+		return io.NopCloser(bytes.NewReader(body)), nil
+	}
+
+	dryRun := req.Clone(req.Context())
+	query := dryRun.URL.Query()
+	query.Set("dryRun", metav1.DryRunAll)
+	dryRun.URL.RawQuery = query.Encode()
+	dryRun.Body = io.NopCloser(bytes.NewReader(body))
+	resp, err := d.next.RoundTrip(dryRun)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode >= http.StatusMultipleChoices {
+		d.storeRejected(req, resource, body)
+		return resp, nil
+	}
+	io.Copy(io.Discard, resp.Body)
+	resp.Body.Close()
+
+	return d.next.RoundTrip(req)
+}
+
+// storeRejected stores the rendered spec rejected by the dry-run into the output directory of the test.
+func (d *dryRunFirstTransport) storeRejected(req *http.Request, resource string, body []byte) {
+	spec, err := yaml.JSONToYAML(body)
+	if err != nil {
+		// The body isn't JSON, i.e. protobuf, store it as is
+		spec = body
+	}
+	dir := filepath.Join(d.t.OutputDir(), "dry-run")
+	path := filepath.Join(dir, fmt.Sprintf("%s-%d.yaml", resource, d.rejected.Add(1)))
+	if err := os.MkdirAll(dir, 0o755); err == nil {
+		err = os.WriteFile(path, spec, 0o644)
+	}
+	if err != nil {
+		d.t.T().Logf("Server-side dry-run rejected create of %s, error storing its spec: %v", req.URL.Path, err)
+		return
+	}
+	d.t.T().Logf("Server-side dry-run rejected create of %s, spec stored in %s", req.URL.Path, path)
+}
+
+// createdResource returns the resource created by the request, ok is false if it isn't the create of a workload,
+// or already a dry-run.
+func createdResource(req *http.Request) (string, bool) {
+	if req.Method != http.MethodPost || req.URL.Query().Has("dryRun") {
+		return "", false
+	}
+	// Creates of namespaced resources are POSTed to /api/v1/namespaces/<namespace>/<resource>
+	// or /apis/<group>/<version>/namespaces/<namespace>/<resource>
+	segments := strings.Split(strings.Trim(req.URL.Path, "/"), "/")
+	if len(segments) < 3 || segments[len(segments)-3] != "namespaces" {
+		return "", false
+	}
+	resource := segments[len(segments)-1]
+	return resource, dryRunFirstResources[resource]
+}
@@ -0,0 +1,86 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/onsi/gomega"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/rest"
+)
+
+// TestDryRunFirstConfig makes sure the clients decorated with DryRunFirst connect to the cluster of the test,
+// and create the workloads with a server-side dry-run first.
+func TestDryRunFirstConfig(t *testing.T) {
+	g := gomega.NewWithT(t)
+	var mutex sync.Mutex
+	var requests []string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mutex.Lock()
+		requests = append(requests, r.Method+" "+r.URL.Path+"?"+r.URL.RawQuery)
+		mutex.Unlock()
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusCreated)
+		w.Write(body)
+	}))
+	t.Cleanup(server.Close)
+
+	test := DryRunFirst(withRestConfig(t, &rest.Config{Host: server.URL}))
+	pod := &corev1.Pod{ObjectMeta: metav1.ObjectMeta{Name: "pod", Namespace: "test-ns"}}
+	_, err := test.Client().Core().CoreV1().Pods("test-ns").Create(test.Ctx(), pod, metav1.CreateOptions{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(requests).To(gomega.Equal([]string{
+		"POST /api/v1/namespaces/test-ns/pods?dryRun=All",
+		"POST /api/v1/namespaces/test-ns/pods?",
+	}))
+}
+
+func TestCreatedResource(t *testing.T) {
+	tests := []struct {
+		method   string
+		url      string
+		resource string
+		ok       bool
+	}{
+		{method: http.MethodPost, url: "/api/v1/namespaces/test-ns/pods", resource: "pods", ok: true},
+		{method: http.MethodPost, url: "/apis/ray.io/v1/namespaces/test-ns/rayclusters", resource: "rayclusters", ok: true},
+		{method: http.MethodPost, url: "/api/v1/namespaces/test-ns/pods?dryRun=All", ok: false},
+		{method: http.MethodPost, url: "/api/v1/namespaces/test-ns/serviceaccounts/default/token", ok: false},
+		{method: http.MethodPost, url: "/api/v1/namespaces/test-ns/secrets", resource: "secrets", ok: false},
+		{method: http.MethodPost, url: "/api/v1/namespaces", ok: false},
+		{method: http.MethodPut, url: "/api/v1/namespaces/test-ns/pods", ok: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.method+" "+tt.url, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+			req := httptest.NewRequest(tt.method, tt.url, nil)
+			resource, ok := createdResource(req)
+			g.Expect(ok).To(gomega.Equal(tt.ok))
+			if tt.resource != "" {
+				g.Expect(resource).To(gomega.Equal(tt.resource))
+			}
+		})
+	}
+}
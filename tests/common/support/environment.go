@@ -0,0 +1,453 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	"github.com/opendatahub-io/distributed-workloads/pkg/baselines"
+)
+
+const (
+	// The environment variable for CUDA vectorAdd sample image used by GPU pre-flight
+	cudaVectorAddImageEnvVar = "CUDA_VECTOR_ADD_IMAGE"
+	// The environment variable for workbench image used by Notebook tests
+	notebookImageEnvVar = "NOTEBOOK_IMAGE"
+	// The environment variable for workbench image the Notebook is updated to by Notebook update tests
+	notebookUpdateImageEnvVar = "NOTEBOOK_UPDATE_IMAGE"
+	// The environment variable for training profile of the hyperparameters injected into the Notebooks, smoke or nightly
+	notebookTrainingProfileEnvVar = "NOTEBOOK_TRAINING_PROFILE"
+	// The environment variable for namespace Kueue is deployed in, holding the kubeconfigs of MultiKueue worker clusters
+	kueueNamespaceEnvVar = "KUEUE_NAMESPACE"
+	// The environment variable for path of the kubeconfig of the worker cluster MultiKueue tests dispatch workloads to
+	multiKueueWorkerKubeconfigEnvVar = "MULTIKUEUE_WORKER_KUBECONFIG"
+	// The environment variable for name of the ClusterQueue managed by the platform
+	kueueDefaultClusterQueueEnvVar = "KUEUE_DEFAULT_CLUSTER_QUEUE"
+	// The environment variable for name of the LocalQueue created by the platform in managed namespaces
+	kueueDefaultLocalQueueEnvVar = "KUEUE_DEFAULT_LOCAL_QUEUE"
+	// The environment variable for image with basic command line tools, i.e. dd, used by helper pods
+	toolsImageEnvVar = "TOOLS_IMAGE"
+	// The environment variable for comma separated list of storage classes, the first one is used by PVC-based tests
+	storageClassesEnvVar = "STORAGE_CLASSES"
+	// The environment variable for comma separated list of storage classes supporting ReadWriteMany access mode
+	rwxStorageClassesEnvVar = "RWX_STORAGE_CLASSES"
+	// The environment variable for maximum duration for PVC to get bound
+	storageMaxBindingLatencyEnvVar = "STORAGE_MAX_BINDING_LATENCY"
+	// The environment variable for minimum sequential write throughput of PVC in MB/s
+	storageMinWriteThroughputEnvVar = "STORAGE_MIN_WRITE_THROUGHPUT"
+	// The environment variable for comma separated list of minimum samples/sec read from RWX storage classes, i.e. nfs-csi=500
+	rwxDataLoadingBaselinesEnvVar = "RWX_DATA_LOADING_BASELINES"
+	// The environment variable for maximum clock skew of the nodes accepted by the clock pre-flight check
+	clockMaxSkewEnvVar = "CLOCK_MAX_SKEW"
+	// The environment variable for image with iperf3 used by the network pre-flight check
+	iperfImageEnvVar = "IPERF_IMAGE"
+	// The environment variable for comma separated list of nodes designated for multi-node training, probed by the network pre-flight check
+	networkPreflightNodesEnvVar = "NETWORK_PREFLIGHT_NODES"
+	// The environment variables for minimum bandwidth in Gbit/s and maximum round-trip time between training pods
+	networkMinBandwidthEnvVar = "NETWORK_MIN_BANDWIDTH"
+	networkMaxLatencyEnvVar   = "NETWORK_MAX_LATENCY"
+	// The environment variable for image with grpcurl used to query the kubelet pod resources API
+	grpcurlImageEnvVar = "GRPCURL_IMAGE"
+	// The environment variables for S3 compatible storage used by tests storing data in object storage
+	s3EndpointEnvVar        = "AWS_DEFAULT_ENDPOINT"
+	s3AccessKeyIDEnvVar     = "AWS_ACCESS_KEY_ID"
+	s3SecretAccessKeyEnvVar = "AWS_SECRET_ACCESS_KEY"
+	s3BucketEnvVar          = "AWS_STORAGE_BUCKET"
+	s3RegionEnvVar          = "AWS_DEFAULT_REGION"
+	// The environment variable for pip requirement CodeFlare SDK is installed from by SDK tests not running in a Notebook
+	codeFlareSdkPackageEnvVar = "CODEFLARE_SDK_PACKAGE"
+	// The environment variable for comma separated list of CodeFlare SDK wheel files served to the tests by a local package index
+	codeFlareSdkWheelsEnvVar = "CODEFLARE_SDK_WHEELS"
+	// The environment variable for image with Python running the local package index, devpi is installed when missing
+	devpiImageEnvVar = "DEVPI_IMAGE"
+	// The environment variable for URL of Prometheus API queried by metrics tests, defaults to Thanos querier on OpenShift
+	prometheusUrlEnvVar = "PROMETHEUS_URL"
+	// The environment variable for URL of Alertmanager API queried by alerting tests, defaults to Alertmanager route on OpenShift
+	alertmanagerUrlEnvVar = "ALERTMANAGER_URL"
+	// The environment variables for names of the alerts asserted by alerting tests
+	kueueAdmissionBlockedAlertEnvVar = "KUEUE_ADMISSION_BLOCKED_ALERT"
+	jobFailureAlertEnvVar            = "JOB_FAILURE_ALERT"
+	// The environment variable for exporter of accelerator metrics, dcgm or amd, defaults to the vendor of GPUs in the cluster
+	acceleratorMetricsExporterEnvVar = "ACCELERATOR_METRICS_EXPORTER"
+	// The environment variable for accelerator GPU tests run on, nvidia or amd, defaults to the vendor of GPUs in the cluster
+	testAcceleratorEnvVar = "TEST_ACCELERATOR"
+	// The environment variables for namespace of the Leases of the GPU slots, and number of tests holding GPUs at the same time
+	gpuLeaseNamespaceEnvVar = "TEST_GPU_LEASE_NAMESPACE"
+	gpuLeaseSlotsEnvVar     = "TEST_GPU_LEASE_SLOTS"
+	// The environment variable for MinIO image deployed as ephemeral object storage, also running the mc client
+	minioImageEnvVar = "MINIO_IMAGE"
+	// The environment variable for name of the pre-provisioned RWX PersistentVolume shared as Hugging Face cache by the tests
+	huggingFaceCachePVEnvVar = "HF_CACHE_PV"
+	// The environment variable for image of the Job populating the Hugging Face cache
+	huggingFaceCacheImageEnvVar = "HF_CACHE_POPULATE_IMAGE"
+	// The environment variable for ROCm PyTorch image used by AMD GPU tests
+	rocmPyTorchImageEnvVar = "ROCM_PYTORCH_IMAGE"
+	// The environment variable enabling warm standby mode, reusing namespaces and RayClusters across runs of tests in development
+	warmStandbyEnvVar = "TEST_WARM_STANDBY"
+	// The environment variable for tolerance of metrics compared to the expected values, as a fraction of the expected value
+	metricsToleranceEnvVar = "METRICS_TOLERANCE"
+	// The environment variables for maximum utilization of the GPUs and CPUs of the cluster by other namespaces, in percent,
+	// above which the results of performance tests are marked as contaminated by noisy neighbors
+	noisyNeighborsMaxGpuUtilizationEnvVar = "NOISY_NEIGHBORS_MAX_GPU_UTILIZATION"
+	noisyNeighborsMaxCpuUtilizationEnvVar = "NOISY_NEIGHBORS_MAX_CPU_UTILIZATION"
+	// The environment variable for IP family of the cluster network, IPv4, IPv6 or DualStack
+	ipFamilyEnvVar = "TEST_IP_FAMILY"
+	// The environment variable enabling writing of the golden files with the specs generated by the tests
+	updateGoldenFilesEnvVar = "UPDATE_GOLDEN_FILES"
+	// The environment variable for queue manager dispatching the workloads of the scenarios written against QueueManager, kueue or mcad
+	queueManagerEnvVar = "QUEUE_MANAGER"
+	// The environment variable enabling capture of faulthandler tracebacks and core dumps of crashed training processes
+	crashCaptureEnvVar = "TEST_CRASH_CAPTURE"
+	// The environment variable for polling interval of Eventually and Consistently assertions
+	pollingIntervalEnvVar = "TEST_POLLING_INTERVAL"
+	// The environment variable for directory the state of the namespaces of failed tests is gathered into, a directory per test
+	mustGatherDirEnvVar = "TEST_MUST_GATHER_DIR"
+	// The environment variable enabling tests rolling out the OpenShift kube-apiserver, disrupting the whole cluster
+	apiServerRolloutEnvVar = "TEST_API_SERVER_ROLLOUT"
+	// The environment variable enabling the server-side dry-run of the workloads before they are created
+	dryRunFirstEnvVar = "TEST_DRY_RUN_FIRST"
+	// The environment variable for period after which the platform releases the GPUs of idle RayClusters, as configured in the cluster
+	idleRayClusterPeriodEnvVar = "IDLE_RAYCLUSTER_PERIOD"
+	// The environment variable for comma separated list of reasons of the events emitted when idle RayClusters are released
+	idleRayClusterEventsEnvVar = "IDLE_RAYCLUSTER_EVENTS"
+)
+
+// S3Bucket holds the location and credentials of a bucket in S3 compatible storage.
+type S3Bucket struct {
+	Endpoint        string
+	AccessKeyID     string
+	SecretAccessKey string
+	Bucket          string
+	Region          string
+}
+
+func GetCudaVectorAddImage() string {
+	return lookupEnvOrDefault(cudaVectorAddImageEnvVar, "nvcr.io/nvidia/k8s/cuda-sample:vectoradd-cuda11.7.1-ubi8")
+}
+
+func GetNotebookImage() string {
+	return lookupImageOrDefault(notebookImageEnvVar, "quay.io/modh/odh-minimal-notebook-container:v2-2024a")
+}
+
+func GetNotebookUpdateImage() string {
+	return lookupImageOrDefault(notebookUpdateImageEnvVar, GetNotebookImage())
+}
+
+// GetNotebookHyperparameters returns the hyperparameters of the notebook training profile, the smoke one by default.
+func GetNotebookHyperparameters(t Test) NotebookHyperparameters {
+	t.T().Helper()
+	profile := lookupEnvOrDefault(notebookTrainingProfileEnvVar, NotebookTrainingProfileSmoke)
+	hyperparameters, ok := notebookTrainingProfiles[profile]
+	if !ok {
+		t.T().Fatalf("Unknown %s %q, expected %s or %s", notebookTrainingProfileEnvVar, profile, NotebookTrainingProfileSmoke, NotebookTrainingProfileNightly)
+	}
+	return hyperparameters
+}
+
+func GetKueueNamespace() string {
+	return lookupEnvOrDefault(kueueNamespaceEnvVar, "opendatahub")
+}
+
+// GetMultiKueueWorkerKubeconfig returns the path of the kubeconfig of the MultiKueue worker cluster, ok is false if not set.
+func GetMultiKueueWorkerKubeconfig() (string, bool) {
+	return os.LookupEnv(multiKueueWorkerKubeconfigEnvVar)
+}
+
+func GetKueueDefaultClusterQueue() string {
+	return lookupEnvOrDefault(kueueDefaultClusterQueueEnvVar, "default")
+}
+
+func GetKueueDefaultLocalQueue() string {
+	return lookupEnvOrDefault(kueueDefaultLocalQueueEnvVar, "default")
+}
+
+func GetToolsImage() string {
+	return lookupImageOrDefault(toolsImageEnvVar, "registry.access.redhat.com/ubi9/ubi-minimal:latest")
+}
+
+// GetStorageClasses returns the configured storage classes, empty string stands for the cluster default storage class.
+func GetStorageClasses() []string {
+	storageClasses := splitEnvList(lookupEnvOrDefault(storageClassesEnvVar, ""))
+	if len(storageClasses) == 0 {
+		return []string{""}
+	}
+	return storageClasses
+}
+
+// GetStorageClass returns the storage class used by PVC-based tests, empty string stands for the cluster default storage class.
+func GetStorageClass() string {
+	return GetStorageClasses()[0]
+}
+
+func GetRwxStorageClasses() []string {
+	return splitEnvList(lookupEnvOrDefault(rwxStorageClassesEnvVar, ""))
+}
+
+func GetStorageMaxBindingLatency(t Test) time.Duration {
+	t.T().Helper()
+	latency, err := time.ParseDuration(lookupEnvOrDefault(storageMaxBindingLatencyEnvVar, "2m"))
+	t.Expect(err).NotTo(gomega.HaveOccurred(), "Error parsing %s", storageMaxBindingLatencyEnvVar)
+	return latency
+}
+
+func GetStorageMinWriteThroughput(t Test) float64 {
+	t.T().Helper()
+	throughput, err := strconv.ParseFloat(lookupEnvOrDefault(storageMinWriteThroughputEnvVar, "20"), 64)
+	t.Expect(err).NotTo(gomega.HaveOccurred(), "Error parsing %s", storageMinWriteThroughputEnvVar)
+	return throughput
+}
+
+// GetRwxDataLoadingBaseline returns the minimum samples/sec the data loading probe must read from the RWX storage class,
+// falling back to the performance baseline, ok is false if there is no baseline for the storage class.
+func GetRwxDataLoadingBaseline(t Test, storageClass string) (float64, bool) {
+	t.T().Helper()
+	for _, baseline := range splitEnvList(lookupEnvOrDefault(rwxDataLoadingBaselinesEnvVar, "")) {
+		class, value, ok := strings.Cut(baseline, "=")
+		t.Expect(ok).To(gomega.BeTrue(), "Error parsing %s, expected STORAGE_CLASS=SAMPLES_PER_SECOND entries", rwxDataLoadingBaselinesEnvVar)
+		if class != storageClass {
+			continue
+		}
+		samplesPerSecond, err := strconv.ParseFloat(value, 64)
+		t.Expect(err).NotTo(gomega.HaveOccurred(), "Error parsing %s", rwxDataLoadingBaselinesEnvVar)
+		return samplesPerSecond, true
+	}
+	return GetPerformanceBaseline(t, baselines.MetricRwxDataLoading+"/"+storageClass, "")
+}
+
+func GetClockMaxSkew(t Test) time.Duration {
+	t.T().Helper()
+	skew, err := time.ParseDuration(lookupEnvOrDefault(clockMaxSkewEnvVar, "5s"))
+	t.Expect(err).NotTo(gomega.HaveOccurred(), "Error parsing %s", clockMaxSkewEnvVar)
+	return skew
+}
+
+func GetIperfImage() string {
+	return lookupImageOrDefault(iperfImageEnvVar, "docker.io/networkstatic/iperf3:latest")
+}
+
+func GetGrpcurlImage() string {
+	return lookupImageOrDefault(grpcurlImageEnvVar, "docker.io/fullstorydev/grpcurl:v1.9.1")
+}
+
+func GetNetworkPreflightNodes() []string {
+	return splitEnvList(lookupEnvOrDefault(networkPreflightNodesEnvVar, ""))
+}
+
+// GetNetworkMinBandwidth returns the minimum bandwidth in Gbit/s between training pods, falling back to the performance
+// baseline, ok is false if neither is set.
+func GetNetworkMinBandwidth(t Test) (float64, bool) {
+	t.T().Helper()
+	value, ok := os.LookupEnv(networkMinBandwidthEnvVar)
+	if !ok {
+		return GetPerformanceBaseline(t, baselines.MetricNetworkBandwidth, "")
+	}
+	bandwidth, err := strconv.ParseFloat(value, 64)
+	t.Expect(err).NotTo(gomega.HaveOccurred(), "Error parsing %s", networkMinBandwidthEnvVar)
+	return bandwidth, true
+}
+
+// GetNetworkMaxLatency returns the maximum mean round-trip time between training pods, falling back to the performance
+// baseline, ok is false if neither is set.
+func GetNetworkMaxLatency(t Test) (time.Duration, bool) {
+	t.T().Helper()
+	value, ok := os.LookupEnv(networkMaxLatencyEnvVar)
+	if !ok {
+		milliseconds, ok := GetPerformanceBaseline(t, baselines.MetricNetworkLatency, "")
+		return time.Duration(milliseconds * float64(time.Millisecond)), ok
+	}
+	latency, err := time.ParseDuration(value)
+	t.Expect(err).NotTo(gomega.HaveOccurred(), "Error parsing %s", networkMaxLatencyEnvVar)
+	return latency, true
+}
+
+// GetS3Bucket returns the S3 bucket configured for the tests, ok is false if any of the environment variables isn't set.
+func GetS3Bucket() (S3Bucket, bool) {
+	bucket := S3Bucket{
+		Endpoint:        lookupEnvOrDefault(s3EndpointEnvVar, ""),
+		AccessKeyID:     lookupEnvOrDefault(s3AccessKeyIDEnvVar, ""),
+		SecretAccessKey: lookupEnvOrDefault(s3SecretAccessKeyEnvVar, ""),
+		Bucket:          lookupEnvOrDefault(s3BucketEnvVar, ""),
+		Region:          lookupEnvOrDefault(s3RegionEnvVar, ""),
+	}
+	ok := bucket.Endpoint != "" && bucket.AccessKeyID != "" && bucket.SecretAccessKey != "" && bucket.Bucket != ""
+	return bucket, ok
+}
+
+func GetCodeFlareSdkPackage() string {
+	return lookupEnvOrDefault(codeFlareSdkPackageEnvVar, "codeflare-sdk")
+}
+
+func GetCodeFlareSdkWheels() []string {
+	return splitEnvList(lookupEnvOrDefault(codeFlareSdkWheelsEnvVar, ""))
+}
+
+func GetDevpiImage() string {
+	return lookupImageOrDefault(devpiImageEnvVar, "registry.access.redhat.com/ubi9/python-311:latest")
+}
+
+func GetPrometheusUrl() (string, bool) {
+	url := lookupEnvOrDefault(prometheusUrlEnvVar, "")
+	return url, url != ""
+}
+
+func GetAlertmanagerUrl() (string, bool) {
+	url := lookupEnvOrDefault(alertmanagerUrlEnvVar, "")
+	return url, url != ""
+}
+
+func GetKueueAdmissionBlockedAlert() string {
+	return lookupEnvOrDefault(kueueAdmissionBlockedAlertEnvVar, "KueueAdmissionBlocked")
+}
+
+func GetJobFailureAlert() string {
+	return lookupEnvOrDefault(jobFailureAlertEnvVar, "KubeJobFailed")
+}
+
+func GetAcceleratorMetricsExporter() string {
+	return lookupEnvOrDefault(acceleratorMetricsExporterEnvVar, "")
+}
+
+func GetTestAccelerator() string {
+	return lookupEnvOrDefault(testAcceleratorEnvVar, "")
+}
+
+func GetMinioImage() string {
+	return lookupImageOrDefault(minioImageEnvVar, "quay.io/minio/minio:latest")
+}
+
+func GetHuggingFaceCachePV() (string, bool) {
+	pv := lookupEnvOrDefault(huggingFaceCachePVEnvVar, "")
+	return pv, pv != ""
+}
+
+func GetHuggingFaceCacheImage() string {
+	return lookupImageOrDefault(huggingFaceCacheImageEnvVar, "registry.access.redhat.com/ubi9/python-311:latest")
+}
+
+func GetRocmPyTorchImage() string {
+	return lookupImageOrDefault(rocmPyTorchImageEnvVar, "docker.io/rocm/pytorch:latest")
+}
+
+func GetGpuLeaseNamespace() string {
+	return lookupEnvOrDefault(gpuLeaseNamespaceEnvVar, "default")
+}
+
+func GetGpuLeaseSlots(t Test) int {
+	t.T().Helper()
+	slots, err := strconv.Atoi(lookupEnvOrDefault(gpuLeaseSlotsEnvVar, "1"))
+	t.Expect(err).NotTo(gomega.HaveOccurred(), "Error parsing %s", gpuLeaseSlotsEnvVar)
+	t.Expect(slots).To(gomega.BeNumerically(">=", 0), "%s mustn't be negative", gpuLeaseSlotsEnvVar)
+	return slots
+}
+
+func GetMetricsTolerance(t Test) float64 {
+	t.T().Helper()
+	tolerance, err := strconv.ParseFloat(lookupEnvOrDefault(metricsToleranceEnvVar, "0.2"), 64)
+	t.Expect(err).NotTo(gomega.HaveOccurred(), "Error parsing %s", metricsToleranceEnvVar)
+	return tolerance
+}
+
+func GetNoisyNeighborsMaxGpuUtilization(t Test) float64 {
+	t.T().Helper()
+	utilization, err := strconv.ParseFloat(lookupEnvOrDefault(noisyNeighborsMaxGpuUtilizationEnvVar, "10"), 64)
+	t.Expect(err).NotTo(gomega.HaveOccurred(), "Error parsing %s", noisyNeighborsMaxGpuUtilizationEnvVar)
+	return utilization
+}
+
+func GetNoisyNeighborsMaxCpuUtilization(t Test) float64 {
+	t.T().Helper()
+	utilization, err := strconv.ParseFloat(lookupEnvOrDefault(noisyNeighborsMaxCpuUtilizationEnvVar, "50"), 64)
+	t.Expect(err).NotTo(gomega.HaveOccurred(), "Error parsing %s", noisyNeighborsMaxCpuUtilizationEnvVar)
+	return utilization
+}
+
+func IsWarmStandby() bool {
+	warmStandby, _ := strconv.ParseBool(lookupEnvOrDefault(warmStandbyEnvVar, "false"))
+	return warmStandby
+}
+
+func GetIPFamilyMode() IPFamilyMode {
+	return IPFamilyMode(lookupEnvOrDefault(ipFamilyEnvVar, string(IPFamilyModeIPv4)))
+}
+
+func IsUpdateGoldenFiles() bool {
+	update, _ := strconv.ParseBool(lookupEnvOrDefault(updateGoldenFilesEnvVar, "false"))
+	return update
+}
+
+func GetQueueManager() string {
+	return lookupEnvOrDefault(queueManagerEnvVar, QueueManagerKueue)
+}
+
+func IsCrashCapture() bool {
+	crashCapture, _ := strconv.ParseBool(lookupEnvOrDefault(crashCaptureEnvVar, "false"))
+	return crashCapture
+}
+
+func GetMustGatherDir() (string, bool) {
+	dir := lookupEnvOrDefault(mustGatherDirEnvVar, "")
+	return dir, dir != ""
+}
+
+func IsAPIServerRollout() bool {
+	rollout, _ := strconv.ParseBool(lookupEnvOrDefault(apiServerRolloutEnvVar, "false"))
+	return rollout
+}
+
+func IsDryRunFirst() bool {
+	dryRunFirst, _ := strconv.ParseBool(lookupEnvOrDefault(dryRunFirstEnvVar, "false"))
+	return dryRunFirst
+}
+
+// GetIdleRayClusterPeriod returns the period after which idle RayClusters are released, ok is false if not set.
+func GetIdleRayClusterPeriod(t Test) (time.Duration, bool) {
+	t.T().Helper()
+	value, ok := os.LookupEnv(idleRayClusterPeriodEnvVar)
+	if !ok {
+		return 0, false
+	}
+	period, err := time.ParseDuration(value)
+	t.Expect(err).NotTo(gomega.HaveOccurred(), "Error parsing %s", idleRayClusterPeriodEnvVar)
+	return period, true
+}
+
+func GetIdleRayClusterEvents() []string {
+	return splitEnvList(lookupEnvOrDefault(idleRayClusterEventsEnvVar, ""))
+}
+
+func splitEnvList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
+
+func lookupEnvOrDefault(key, value string) string {
+	if v, ok := os.LookupEnv(key); ok {
+		return v
+	}
+	return value
+}
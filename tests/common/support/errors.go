@@ -0,0 +1,194 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
+	. "github.com/project-codeflare/codeflare-common/support"
+)
+
+// ObjectRef identifies the object an operation is performed on in failure messages.
+type ObjectRef struct {
+	Kind      string
+	Namespace string
+	Name      string
+}
+
+func Ref(kind, namespace, name string) ObjectRef {
+	return ObjectRef{Kind: kind, Namespace: namespace, Name: name}
+}
+
+func (r ObjectRef) String() string {
+	name := r.Name
+	if r.Namespace != "" {
+		name = r.Namespace + "/" + name
+	}
+	return strings.TrimSpace(r.Kind + " " + name)
+}
+
+// OperationError annotates an error with the operation and the object it was performed on.
+type OperationError struct {
+	Operation string
+	Object    ObjectRef
+	Err       error
+}
+
+func (e *OperationError) Error() string {
+	return fmt.Sprintf("error %s %s: %v", e.Operation, e.Object, e.Err)
+}
+
+func (e *OperationError) Unwrap() error {
+	return e.Err
+}
+
+// WrapError annotates the error with the operation, i.e. "creating", and the object, it returns nil if err is nil.
+func WrapError(err error, operation string, object ObjectRef) error {
+	if err == nil {
+		return nil
+	}
+	return &OperationError{Operation: operation, Object: object, Err: err}
+}
+
+// ExpectNoError fails the test if err is set, the failure is annotated with the operation and the object
+// and recorded into the failure summary of the test.
+func ExpectNoError(t Test, err error, operation string, object ObjectRef) {
+	t.T().Helper()
+	if err == nil {
+		return
+	}
+	err = WrapError(err, operation, object)
+	recordFailure(t.T().Name(), err.Error())
+	t.Expect(err).To(succeed())
+}
+
+// succeed is the equivalent of gomega.Succeed failing with the message of the error alone, rather than its dump,
+// so the assertion failure matches the annotated failure recorded in the failure summary.
+func succeed() types.GomegaMatcher {
+	return &succeedMatcher{}
+}
+
+type succeedMatcher struct{}
+
+func (m *succeedMatcher) Match(actual any) (bool, error) {
+	if actual == nil {
+		return true, nil
+	}
+	err, ok := actual.(error)
+	if !ok {
+		return false, fmt.Errorf("succeed expects an error, got %T", actual)
+	}
+	return err == nil, nil
+}
+
+func (m *succeedMatcher) FailureMessage(actual any) string {
+	return actual.(error).Error()
+}
+
+func (m *succeedMatcher) NegatedFailureMessage(any) string {
+	return "Expected failure, got none"
+}
+
+// failures records the annotated failures per test, they are reported in the test result.
+var failures = struct {
+	sync.Mutex
+	byTest map[string][]string
+}{byTest: map[string][]string{}}
+
+func recordFailure(testName, message string) {
+	failures.Lock()
+	defer failures.Unlock()
+	// The annotated failures are recorded right before failing the assertion, recorded again by recordingTestingT
+	if recorded := failures.byTest[testName]; len(recorded) > 0 && recorded[len(recorded)-1] == message {
+		return
+	}
+	failures.byTest[testName] = append(failures.byTest[testName], message)
+}
+
+// recordAssertionFailures makes the failed assertions of the Test, i.e. a timed out Eventually, recorded into
+// the failure summary of the test along with the annotated failures. It is registered as a hook by RunSuite.
+func recordAssertionFailures(t Test) Test {
+	return &assertingTest{Test: t, g: gomega.NewWithT(&recordingTestingT{t: t})}
+}
+
+// recordingTestingT records the failure message before failing the test with it.
+type recordingTestingT struct {
+	t Test
+}
+
+var _ types.GomegaTestingT = (*recordingTestingT)(nil)
+
+func (r *recordingTestingT) Helper() {
+	r.t.T().Helper()
+}
+
+func (r *recordingTestingT) Fatalf(format string, args ...any) {
+	r.t.T().Helper()
+	recordFailure(r.t.T().Name(), strings.TrimSpace(fmt.Sprintf(format, args...)))
+	r.t.T().Fatalf(format, args...)
+}
+
+func takeFailures(testName string) []string {
+	failures.Lock()
+	defer failures.Unlock()
+	// Failures of subtests are reported with their parent test, in the order of the test names
+	var names []string
+	for name := range failures.byTest {
+		if name == testName || strings.HasPrefix(name, testName+"/") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	var messages []string
+	for _, name := range names {
+		messages = append(messages, failures.byTest[name]...)
+		delete(failures.byTest, name)
+	}
+	return messages
+}
+
+// formatFailureSummary lists the failed tests with their annotated failures, so the cause of failures is found
+// at the end of the output rather than among the logs of the whole suite.
+func formatFailureSummary(summary SuiteSummary) string {
+	var b strings.Builder
+	for _, result := range summary.Results {
+		if result.Status == TestXPassed {
+			fmt.Fprintf(&b, "--- XPASS: %s, expected to fail with %s\n", result.DisplayName(), result.Issue)
+		}
+		if result.Status != TestFailed {
+			continue
+		}
+		fmt.Fprintf(&b, "--- FAIL: %s (%s)\n", result.DisplayName(), result.Duration.Round(time.Second))
+		for _, failure := range result.Failures {
+			// The failures of the assertions span multiple lines, i.e. the expected and actual values
+			fmt.Fprintf(&b, "    %s\n", strings.ReplaceAll(failure, "\n", "\n    "))
+		}
+	}
+	for _, leak := range summary.Leaked {
+		fmt.Fprintf(&b, "--- LEAK: %s\n", leak)
+	}
+	if b.Len() == 0 {
+		return ""
+	}
+	return "Failure summary:\n" + b.String()
+}
@@ -0,0 +1,39 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"testing"
+
+	"github.com/onsi/gomega"
+)
+
+// TestTakeFailures makes sure the failures of the subtests are taken with their parent test, in the order of their names.
+func TestTakeFailures(t *testing.T) {
+	g := gomega.NewWithT(t)
+	for _, name := range []string{"TestFailures/c", "TestFailures", "TestFailures/a", "TestFailures/b", "TestFailuresOther"} {
+		recordFailure(name, name+" failed")
+	}
+	t.Cleanup(func() {
+		takeFailures("TestFailuresOther")
+	})
+
+	g.Expect(takeFailures("TestFailures")).To(gomega.Equal([]string{
+		"TestFailures failed", "TestFailures/a failed", "TestFailures/b failed", "TestFailures/c failed",
+	}))
+	g.Expect(takeFailures("TestFailures")).To(gomega.BeEmpty())
+}
@@ -0,0 +1,166 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// EventRecorder records the Kubernetes events of a namespace observed by a watch, so events expiring or
+// being compacted before the test asserts on them are kept, i.e. scheduler preemptions or failed mounts.
+type EventRecorder struct {
+	namespace string
+	mutex     sync.Mutex
+	// Events keyed by UID, updated as the API server aggregates repeated events
+	events map[string]corev1.Event
+}
+
+// RecordEvents starts watching events in the namespace and records them until the test finishes. The timeline of
+// the recorded events is written to the test artifacts once the test finishes.
+func RecordEvents(t Test, namespace string) *EventRecorder {
+	t.T().Helper()
+
+	recorder := &EventRecorder{namespace: namespace, events: map[string]corev1.Event{}}
+	ctx, cancel := context.WithCancel(t.Ctx())
+	t.T().Cleanup(func() {
+		cancel()
+		WriteToOutputDir(t, "events-"+namespace, Log, []byte(recorder.Timeline()))
+	})
+
+	client := t.Client().Core().CoreV1().Events(namespace)
+	options := metav1.ListOptions{}
+	w, err := client.Watch(ctx, options)
+	ExpectNoError(t, err, "watching events in", Ref("Namespace", "", namespace))
+
+	go func() {
+		for {
+			for event := range w.ResultChan() {
+				switch event.Type {
+				case watch.Added, watch.Modified:
+					object := event.Object.(*corev1.Event)
+					recorder.record(*object)
+					options.ResourceVersion = object.ResourceVersion
+				case watch.Error:
+					// Resume from the current events when the resource version is too old
+					if errors.IsResourceExpired(errors.FromObject(event.Object)) || errors.IsGone(errors.FromObject(event.Object)) {
+						options.ResourceVersion = ""
+					}
+				}
+			}
+			// The watch is closed by the API server periodically, resume it until the test finishes
+			for {
+				if ctx.Err() != nil {
+					return
+				}
+				if w, err = client.Watch(ctx, options); err == nil {
+					break
+				}
+				select {
+				case <-ctx.Done():
+				case <-time.After(time.Second):
+				}
+			}
+		}
+	}()
+
+	return recorder
+}
+
+func (r *EventRecorder) record(event corev1.Event) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	r.events[string(event.UID)] = event
+}
+
+// Events returns the recorded events in the order they last occurred.
+func (r *EventRecorder) Events() []corev1.Event {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	events := make([]corev1.Event, 0, len(r.events))
+	for _, event := range r.events {
+		events = append(events, event)
+	}
+	sort.SliceStable(events, func(i, j int) bool {
+		return eventTime(events[i]).Before(eventTime(events[j]))
+	})
+	return events
+}
+
+// EventsOf returns the recorded events of the workload objects, i.e. the object named after the workload and the objects
+// created for it, as pods, services or Kueue workloads, which are named with the name of the workload as prefix.
+func (r *EventRecorder) EventsOf(kind, name string) func() []corev1.Event {
+	return func() []corev1.Event {
+		var events []corev1.Event
+		for _, event := range r.Events() {
+			involved := event.InvolvedObject
+			if (involved.Kind == kind && involved.Name == name) || strings.Contains(involved.Name, name+"-") {
+				events = append(events, event)
+			}
+		}
+		return events
+	}
+}
+
+// Timeline returns the recorded events formatted one per line, in the order they last occurred.
+func (r *EventRecorder) Timeline() string {
+	var b strings.Builder
+	for _, event := range r.Events() {
+		fmt.Fprintf(&b, "%s %-7s %-24s %s/%s: %s", eventTime(event).Format(time.RFC3339), event.Type, event.Reason,
+			event.InvolvedObject.Kind, event.InvolvedObject.Name, event.Message)
+		if event.Count > 1 {
+			fmt.Fprintf(&b, " (x%d)", event.Count)
+		}
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// HaveEvent succeeds when the events contain an event with the reason, and a message containing the substrings when set.
+func HaveEvent(reason string, messageSubstrings ...string) TypedMatcher[[]corev1.Event] {
+	matcher := Field(func(event corev1.Event) string { return event.Reason }).Equal(reason)
+	for _, substring := range messageSubstrings {
+		matcher = matcher.And(Field(func(event corev1.Event) string { return event.Message }).Matches(gomega.ContainSubstring(substring)))
+	}
+	return ContainElementOf(matcher)
+}
+
+// eventTime returns the time the event last occurred, falling back to the older time fields not set by all the reporters.
+func eventTime(event corev1.Event) time.Time {
+	switch {
+	case !event.LastTimestamp.IsZero():
+		return event.LastTimestamp.Time
+	case !event.EventTime.IsZero():
+		return event.EventTime.Time
+	case !event.FirstTimestamp.IsZero():
+		return event.FirstTimestamp.Time
+	default:
+		return event.CreationTimestamp.Time
+	}
+}
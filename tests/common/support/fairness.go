@@ -0,0 +1,303 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Resolution of the admission of the replayed workloads, in the time of the test process
+const replayPollingInterval = time.Second
+
+// SyntheticTenant is a team submitting workloads to the shared cluster over a simulated day.
+type SyntheticTenant struct {
+	Name string
+	// Submissions is the number of workloads the team submits over the day
+	Submissions int
+	// PeakHour is the hour of the day the submissions of the team are centered on
+	PeakHour int
+	// MeanRuntime is the mean runtime of the workloads of the team, in simulated time
+	MeanRuntime time.Duration
+	// Request is the resources requested by each workload of the team
+	Request corev1.ResourceList
+}
+
+// TraceSubmission is a workload submitted by a tenant, at an offset from the start of the simulated day.
+type TraceSubmission struct {
+	Tenant  string
+	At      time.Duration
+	Runtime time.Duration
+	Request corev1.ResourceList
+}
+
+// SyntheticDayTrace returns the submissions of the tenants over a day, sorted by time. The submissions of each tenant
+// are spread around its peak hour with a standard deviation of three hours, and their runtimes are exponentially
+// distributed around its mean runtime. The same seed returns the same trace, so reports of runs are comparable.
+func SyntheticDayTrace(seed int64, tenants ...SyntheticTenant) []TraceSubmission {
+	random := rand.New(rand.NewSource(seed))
+	day := 24 * time.Hour
+
+	var trace []TraceSubmission
+	for _, tenant := range tenants {
+		for i := 0; i < tenant.Submissions; i++ {
+			at := time.Duration(tenant.PeakHour)*time.Hour + time.Duration(random.NormFloat64()*float64(3*time.Hour))
+			at = ((at % day) + day) % day
+			runtime := time.Duration(random.ExpFloat64() * float64(tenant.MeanRuntime))
+			runtime = min(max(runtime, time.Minute), 4*tenant.MeanRuntime)
+			trace = append(trace, TraceSubmission{Tenant: tenant.Name, At: at.Truncate(time.Minute), Runtime: runtime.Truncate(time.Minute), Request: tenant.Request})
+		}
+	}
+	sort.SliceStable(trace, func(i, j int) bool { return trace[i].At < trace[j].At })
+	return trace
+}
+
+// FairnessTenant is a tenant the trace is replayed for, with the namespace and the queue manager of its workloads.
+type FairnessTenant struct {
+	Name      string
+	Namespace string
+	Queue     QueueManager
+	// Share is the share of the cluster the tenant is entitled to, i.e. its nominal quota over the quota of the cohort
+	Share float64
+}
+
+// TenantFairness is the service a tenant got over the replayed trace, wait times and usage in simulated time.
+type TenantFairness struct {
+	Tenant        string
+	Share         float64
+	Submissions   int
+	MeanWait      time.Duration
+	P95Wait       time.Duration
+	MaxWait       time.Duration
+	ResourceHours float64
+}
+
+// FairnessReport is the service each tenant got over the replayed trace, with the usage of the resource accounted.
+type FairnessReport struct {
+	Resource corev1.ResourceName
+	Tenants  []TenantFairness
+}
+
+type replayedSubmission struct {
+	TraceSubmission
+	tenant    FairnessTenant
+	workload  *QueuedWorkload
+	submitted time.Duration
+	wait      time.Duration
+	admitted  bool
+}
+
+// ReplayTrace submits the trace to the queue managers of the tenants as batch Jobs sleeping for their runtime, with the
+// simulated time compressed by the time scale, i.e. a day replayed in ten minutes with a time scale of 144. Once all
+// the Jobs are admitted and completed, it returns the report of the wait times and usage of the resource of each
+// tenant, scaled back to simulated time. Wait times are measured with the clock of the test process, with a
+// resolution of a second of replay time.
+func ReplayTrace(t Test, tenants []FairnessTenant, trace []TraceSubmission, timeScale float64, resource corev1.ResourceName, timeout time.Duration) *FairnessReport {
+	t.T().Helper()
+
+	tenantsByName := map[string]FairnessTenant{}
+	for _, tenant := range tenants {
+		tenantsByName[tenant.Name] = tenant
+	}
+	replay := func(simulated time.Duration) time.Duration { return time.Duration(float64(simulated) / timeScale) }
+	simulate := func(real time.Duration) time.Duration { return time.Duration(float64(real) * timeScale) }
+
+	var submissions []*replayedSubmission
+	var next, pending int
+	stopwatch := StartStopwatch()
+	deadline := replay(24*time.Hour) + timeout
+	for next < len(trace) || pending > 0 {
+		elapsed := stopwatch.Elapsed()
+		for ; next < len(trace) && replay(trace[next].At) <= elapsed; next++ {
+			submission := trace[next]
+			tenant, ok := tenantsByName[submission.Tenant]
+			if !ok {
+				t.T().Fatalf("Tenant %s of the trace submission at %s not replayed", submission.Tenant, submission.At)
+			}
+			job := newReplayedJob(tenant.Namespace, submission.Tenant, replay(submission.Runtime), submission.Request)
+			submissions = append(submissions, &replayedSubmission{
+				TraceSubmission: submission,
+				tenant:          tenant,
+				workload:        tenant.Queue.Submit(t, job),
+				submitted:       stopwatch.Elapsed(),
+			})
+			pending++
+		}
+		for _, submission := range submissions {
+			if !submission.admitted && submission.tenant.Queue.Admitted(t, submission.workload) {
+				submission.admitted = true
+				submission.wait = simulate(stopwatch.Elapsed() - submission.submitted)
+				pending--
+			}
+		}
+		if elapsed > deadline {
+			var queued []string
+			for _, submission := range submissions {
+				if !submission.admitted {
+					queued = append(queued, fmt.Sprintf("%s/%s", submission.workload.Namespace, submission.workload.Name))
+				}
+			}
+			t.T().Fatalf("Replayed trace not admitted within %s, still queued: %s", deadline, strings.Join(queued, ", "))
+		}
+		time.Sleep(replayPollingInterval)
+	}
+	t.T().Logf("Replayed %d submissions of %d tenants in %s", len(submissions), len(tenants), stopwatch.Elapsed().Round(time.Second))
+
+	for _, submission := range submissions {
+		submission.tenant.Queue.WaitCompleted(t, submission.workload, timeout)
+	}
+
+	report := &FairnessReport{Resource: resource}
+	for _, tenant := range tenants {
+		fairness := TenantFairness{Tenant: tenant.Name, Share: tenant.Share}
+		var waits []time.Duration
+		for _, submission := range submissions {
+			if submission.Tenant != tenant.Name {
+				continue
+			}
+			waits = append(waits, submission.wait)
+			quantity := submission.Request[resource]
+			fairness.ResourceHours += quantity.AsApproximateFloat64() * submission.Runtime.Hours()
+		}
+		fairness.Submissions = len(waits)
+		if len(waits) > 0 {
+			sort.Slice(waits, func(i, j int) bool { return waits[i] < waits[j] })
+			var total time.Duration
+			for _, wait := range waits {
+				total += wait
+			}
+			fairness.MeanWait = total / time.Duration(len(waits))
+			fairness.P95Wait = waits[int(math.Ceil(0.95*float64(len(waits))))-1]
+			fairness.MaxWait = waits[len(waits)-1]
+		}
+		report.Tenants = append(report.Tenants, fairness)
+	}
+	return report
+}
+
+// newReplayedJob returns the batch Job of a trace submission, sleeping for its runtime in replay time.
+func newReplayedJob(namespace, tenant string, runtime time.Duration, request corev1.ResourceList) *batchv1.Job {
+	// Extended resources, i.e. GPUs, can only be requested with the same limits
+	limits := corev1.ResourceList{}
+	for name, quantity := range request {
+		if name != corev1.ResourceCPU && name != corev1.ResourceMemory {
+			limits[name] = quantity
+		}
+	}
+	return &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: batchv1.SchemeGroupVersion.String(),
+			Kind:       "Job",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: tenant + "-",
+			Namespace:    namespace,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: Ptr(int32(0)),
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers: []corev1.Container{
+						{
+							Name:    "job",
+							Image:   GetToolsImage(),
+							Command: []string{"sleep", fmt.Sprintf("%.0f", math.Max(1, runtime.Seconds()))},
+							Resources: corev1.ResourceRequirements{
+								Requests: request,
+								Limits:   limits,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+// Guidance returns the tuning suggestions for the tenants served unfairly: those waiting more than twice the mean wait
+// of all the tenants, and those using less than half of their share of the resource while waiting longer than the
+// mean, both likely needing a higher nominal quota or weight.
+func (r *FairnessReport) Guidance() []string {
+	var totalWait time.Duration
+	var totalHours float64
+	var submissions int
+	for _, tenant := range r.Tenants {
+		totalWait += tenant.MeanWait * time.Duration(tenant.Submissions)
+		totalHours += tenant.ResourceHours
+		submissions += tenant.Submissions
+	}
+	if submissions == 0 {
+		return nil
+	}
+	meanWait := totalWait / time.Duration(submissions)
+
+	var guidance []string
+	for _, tenant := range r.Tenants {
+		usage := 0.0
+		if totalHours > 0 {
+			usage = tenant.ResourceHours / totalHours
+		}
+		switch {
+		case meanWait > 0 && tenant.MeanWait > 2*meanWait:
+			guidance = append(guidance, fmt.Sprintf("%s waits %s on average, more than twice the mean wait of %s, consider raising its nominal quota",
+				tenant.Tenant, tenant.MeanWait.Round(time.Minute), meanWait.Round(time.Minute)))
+		case usage < tenant.Share/2 && tenant.MeanWait > meanWait:
+			guidance = append(guidance, fmt.Sprintf("%s uses %.0f%% of the %s-hours for a share of %.0f%% while waiting longer than the mean, consider raising its weight in the cohort",
+				tenant.Tenant, 100*usage, r.Resource, 100*tenant.Share))
+		}
+	}
+	return guidance
+}
+
+func (r *FairnessReport) String() string {
+	var b strings.Builder
+	w := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "TENANT\tSHARE\tSUBMISSIONS\tMEAN WAIT\tP95 WAIT\tMAX WAIT\t%s-HOURS\n", strings.ToUpper(string(r.Resource)))
+	for _, tenant := range r.Tenants {
+		fmt.Fprintf(w, "%s\t%.0f%%\t%d\t%s\t%s\t%s\t%.1f\n", tenant.Tenant, 100*tenant.Share, tenant.Submissions,
+			tenant.MeanWait.Round(time.Minute), tenant.P95Wait.Round(time.Minute), tenant.MaxWait.Round(time.Minute), tenant.ResourceHours)
+	}
+	_ = w.Flush()
+	for _, suggestion := range r.Guidance() {
+		fmt.Fprintf(&b, "- %s\n", suggestion)
+	}
+	return b.String()
+}
+
+// Record stores the report with the test output and records the mean wait and usage of each tenant as measurements
+// of the test, so they are compared across runs.
+func (r *FairnessReport) Record(t Test) {
+	t.T().Helper()
+	t.T().Logf("Fairness report:\n%s", r)
+	WriteToOutputDir(t, "fairness-report", Log, []byte(r.String()))
+	for _, tenant := range r.Tenants {
+		RecordMeasurement(t, tenant.Tenant+"/mean_wait_minutes", tenant.MeanWait.Minutes())
+		RecordMeasurement(t, tenant.Tenant+"/"+strings.ReplaceAll(string(r.Resource), "/", "_")+"_hours", tenant.ResourceHours)
+	}
+}
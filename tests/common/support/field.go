@@ -0,0 +1,125 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
+	. "github.com/project-codeflare/codeflare-common/support"
+)
+
+// FieldAccessor is a typed accessor of a value of type V of objects of type T, i.e. the state of a RayCluster.
+type FieldAccessor[T, V any] func(T) V
+
+// Field returns the typed accessor of a transform like RayClusterState, so matching it against objects of another
+// type or against values of another type fails at compile time, rather than with a Gomega error once polled, i.e.
+// EventuallyOf(test, RayCluster(test, namespace, name), TestTimeoutLong).Should(Field(RayClusterState).Equal(rayv1.Ready)).
+func Field[T, V any](accessor func(T) V) FieldAccessor[T, V] {
+	return accessor
+}
+
+// Equal succeeds when the value of the field equals the expected value.
+func (f FieldAccessor[T, V]) Equal(expected V) TypedMatcher[T] {
+	return f.Matches(gomega.Equal(expected))
+}
+
+// OneOf succeeds when the value of the field equals any of the expected values.
+func (f FieldAccessor[T, V]) OneOf(expected ...V) TypedMatcher[T] {
+	elements := make([]interface{}, len(expected))
+	for i, value := range expected {
+		elements[i] = value
+	}
+	return f.Matches(gomega.BeElementOf(elements...))
+}
+
+// Matches succeeds when the value of the field matches the matcher, for assertions not expressible with Equal,
+// i.e. Field(KueueClusterQueueReservingWorkloads).Matches(BeNumerically(">=", 1)).
+func (f FieldAccessor[T, V]) Matches(matcher types.GomegaMatcher) TypedMatcher[T] {
+	return TypedMatcher[T]{GomegaMatcher: gomega.WithTransform(func(actual T) V { return f(actual) }, matcher)}
+}
+
+// TypedMatcher is a matcher of objects of type T. It can be used as any Gomega matcher, though only the typed
+// assertions returned by EventuallyOf, ConsistentlyOf and ExpectOf check its type at compile time.
+type TypedMatcher[T any] struct {
+	types.GomegaMatcher
+}
+
+// And succeeds when both the matcher and the other matcher succeed.
+func (m TypedMatcher[T]) And(other TypedMatcher[T]) TypedMatcher[T] {
+	return TypedMatcher[T]{GomegaMatcher: gomega.And(m.GomegaMatcher, other.GomegaMatcher)}
+}
+
+// Or succeeds when either the matcher or the other matcher succeeds.
+func (m TypedMatcher[T]) Or(other TypedMatcher[T]) TypedMatcher[T] {
+	return TypedMatcher[T]{GomegaMatcher: gomega.Or(m.GomegaMatcher, other.GomegaMatcher)}
+}
+
+// HaveLenOf succeeds when there are exactly count objects.
+func HaveLenOf[T any](count int) TypedMatcher[[]T] {
+	return TypedMatcher[[]T]{GomegaMatcher: gomega.HaveLen(count)}
+}
+
+// ContainElementOf succeeds when any of the objects matches the matcher.
+func ContainElementOf[T any](matcher TypedMatcher[T]) TypedMatcher[[]T] {
+	return TypedMatcher[[]T]{GomegaMatcher: gomega.ContainElement(matcher.GomegaMatcher)}
+}
+
+// HaveEachOf succeeds when there is at least one object and all the objects match the matcher.
+func HaveEachOf[T any](matcher TypedMatcher[T]) TypedMatcher[[]T] {
+	return TypedMatcher[[]T]{GomegaMatcher: gomega.HaveEach(matcher.GomegaMatcher)}
+}
+
+// TypedAsyncAssertion is an Eventually or Consistently assertion on objects of type T, only accepting matchers of T.
+type TypedAsyncAssertion[T any] struct {
+	assertion types.AsyncAssertion
+}
+
+// EventuallyOf returns the Eventually assertion on the objects returned by the function.
+func EventuallyOf[T any](t Test, actual func(g gomega.Gomega) T, intervals ...interface{}) TypedAsyncAssertion[T] {
+	return TypedAsyncAssertion[T]{assertion: t.Eventually(actual, intervals...)}
+}
+
+// ConsistentlyOf returns the Consistently assertion on the objects returned by the function.
+func ConsistentlyOf[T any](t Test, actual func(g gomega.Gomega) T, intervals ...interface{}) TypedAsyncAssertion[T] {
+	return TypedAsyncAssertion[T]{assertion: t.Consistently(actual, intervals...)}
+}
+
+func (a TypedAsyncAssertion[T]) Should(matcher TypedMatcher[T], optionalDescription ...interface{}) bool {
+	return a.assertion.Should(matcher, optionalDescription...)
+}
+
+func (a TypedAsyncAssertion[T]) ShouldNot(matcher TypedMatcher[T], optionalDescription ...interface{}) bool {
+	return a.assertion.ShouldNot(matcher, optionalDescription...)
+}
+
+// TypedAssertion is an assertion on an object of type T, only accepting matchers of T.
+type TypedAssertion[T any] struct {
+	assertion types.Assertion
+}
+
+// ExpectOf returns the assertion on the object.
+func ExpectOf[T any](t Test, actual T) TypedAssertion[T] {
+	return TypedAssertion[T]{assertion: t.Expect(actual)}
+}
+
+func (a TypedAssertion[T]) To(matcher TypedMatcher[T], optionalDescription ...interface{}) bool {
+	return a.assertion.To(matcher, optionalDescription...)
+}
+
+func (a TypedAssertion[T]) NotTo(matcher TypedMatcher[T], optionalDescription ...interface{}) bool {
+	return a.assertion.NotTo(matcher, optionalDescription...)
+}
@@ -0,0 +1,98 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"strings"
+
+	"github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"sigs.k8s.io/yaml"
+)
+
+// The fuzz helpers map the arbitrary inputs generated by Go fuzzing to randomized-but-valid values of builder options,
+// so the fuzz targets assert the specs rendered for any valid options pass the validation of the API server, i.e.:
+//
+//	f.Fuzz(func(t *testing.T, name string, workers uint8) {
+//		Track(t)
+//		test := WithHooks(t)
+//		rayCluster := NewRayClusterBuilder().WithName(FuzzNamespace, FuzzName(name, 40)).WithWorkers(FuzzCount(workers, 0, 8)).Build()
+//		_, err := test.Client().Ray().RayV1().RayClusters(FuzzNamespace).Create(test.Ctx(), rayCluster, DryRunCreate)
+//		ExpectDryRunValid(test, rayCluster, err)
+//	})
+//
+// The seed inputs added with f.Add run along with the other tests of the suite, run go test -fuzz to explore more inputs.
+
+// FuzzNamespace is the namespace the fuzzed objects are validated in, as nothing is persisted by server-side
+// dry-run, it doesn't need a test namespace per input.
+const FuzzNamespace = metav1.NamespaceDefault
+
+// DryRunCreate creates objects with server-side dry-run, running the validation and admission webhooks without
+// persisting the objects.
+var DryRunCreate = metav1.CreateOptions{DryRun: []string{metav1.DryRunAll}}
+
+// FuzzName returns a valid DNS-1035 label of at most maxLength characters derived from the raw input, i.e. a name
+// of an object or the value of a label.
+func FuzzName(raw string, maxLength int) string {
+	var name strings.Builder
+	name.WriteString("fuzz-")
+	for _, r := range strings.ToLower(raw) {
+		if name.Len() >= maxLength {
+			break
+		}
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9':
+			name.WriteRune(r)
+		default:
+			name.WriteByte('-')
+		}
+	}
+	return strings.TrimRight(name.String(), "-")
+}
+
+// FuzzMilliCPUs returns a CPU quantity between 100m and 8 derived from the raw input, i.e. 1500m.
+func FuzzMilliCPUs(raw uint16) string {
+	return resource.NewMilliQuantity(100+int64(raw)%7901, resource.DecimalSI).String()
+}
+
+// FuzzMemory returns a memory quantity between 64Mi and 16Gi derived from the raw input, i.e. 1536Mi.
+func FuzzMemory(raw uint16) string {
+	return resource.NewQuantity((64+int64(raw)%16321)*1024*1024, resource.BinarySI).String()
+}
+
+// FuzzCount returns a count between minimum and maximum derived from the raw input.
+func FuzzCount(raw uint8, minimum, maximum int32) int32 {
+	return minimum + int32(raw)%(maximum-minimum+1)
+}
+
+// ExpectDryRunValid asserts the object created with server-side dry-run was accepted, printing the object otherwise
+// so the failing input is easy to reproduce.
+func ExpectDryRunValid(t Test, object any, err error) {
+	t.T().Helper()
+	t.Expect(err).NotTo(gomega.HaveOccurred(), "Rendered spec rejected by the API server:\n%s", formatFuzzObject(object))
+}
+
+func formatFuzzObject(object any) string {
+	data, err := yaml.Marshal(object)
+	if err != nil {
+		return err.Error()
+	}
+	return string(data)
+}
@@ -0,0 +1,188 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+const (
+	// Field manager ArgoCD applies the resources of its Applications with, when syncing with server-side apply
+	gitOpsFieldManager = "argocd-controller"
+	// Label and annotation ArgoCD tracks the resources of its Applications with
+	argoCDInstanceLabel      = "app.kubernetes.io/instance"
+	argoCDTrackingAnnotation = "argocd.argoproj.io/tracking-id"
+	// GitOpsApplyInterval is the interval the GitOps controller re-applies the resources, self-healing their drifts
+	GitOpsApplyInterval = 5 * time.Second
+)
+
+// GitOpsResource is a cluster-scoped resource owned by a GitOps Application, with its desired state,
+// the object having its TypeMeta set.
+type GitOpsResource struct {
+	Resource schema.GroupVersionResource
+	Object   runtime.Object
+}
+
+// GitOpsDrift is a change of a field owned by the GitOps controller made by another field manager.
+type GitOpsDrift struct {
+	Object  ObjectRef
+	Time    time.Time
+	Message string
+}
+
+// GitOpsApplication simulates an ArgoCD Application with automated sync and self-heal, owning cluster-scoped
+// resources, i.e. ClusterQueues and ResourceFlavors, the way most customers manage the queue configuration.
+type GitOpsApplication struct {
+	Name      string
+	resources []gitOpsResource
+	mutex     sync.Mutex
+	drifts    []GitOpsDrift
+}
+
+type gitOpsResource struct {
+	resource schema.GroupVersionResource
+	desired  *unstructured.Unstructured
+}
+
+// ApplyGitOpsApplication applies the desired state of the resources with server-side apply, labelled and annotated
+// as ArgoCD tracks them, and keeps re-applying it until the test finishes. The changes of the fields owned by the
+// Application made by other field managers, i.e. operators or the test itself, are recorded as drifts before being
+// reverted, as ArgoCD does. The resources are pruned once the test finishes.
+func ApplyGitOpsApplication(t Test, name string, resources ...GitOpsResource) *GitOpsApplication {
+	t.T().Helper()
+
+	application := &GitOpsApplication{Name: name}
+	for _, resource := range resources {
+		if resource.Object.GetObjectKind().GroupVersionKind().Kind == "" {
+			t.T().Fatalf("GitOps resource %s has no kind set", resource.Resource.Resource)
+		}
+		content, err := runtime.DefaultUnstructuredConverter.ToUnstructured(resource.Object)
+		ExpectNoError(t, err, "converting", Ref(resource.Resource.Resource, "", ""))
+		desired := &unstructured.Unstructured{Object: content}
+		desired.SetAPIVersion(resource.Resource.GroupVersion().String())
+		desired.SetKind(resource.Object.GetObjectKind().GroupVersionKind().Kind)
+		desired.SetLabels(map[string]string{argoCDInstanceLabel: name})
+		desired.SetAnnotations(map[string]string{
+			argoCDTrackingAnnotation: fmt.Sprintf("%s:%s/%s:/%s", name, resource.Resource.Group, desired.GetKind(), desired.GetName()),
+		})
+		unstructured.RemoveNestedField(desired.Object, "metadata", "creationTimestamp")
+		unstructured.RemoveNestedField(desired.Object, "status")
+		application.resources = append(application.resources, gitOpsResource{resource: resource.Resource, desired: desired})
+	}
+
+	// Sync the Application once, then keep self-healing it
+	for _, resource := range application.resources {
+		_, err := application.apply(t, t.Ctx(), resource, true)
+		ExpectNoError(t, err, "applying", resource.ref())
+	}
+	t.T().Logf("Applied GitOps Application %s with %d resources", name, len(application.resources))
+
+	ctx, cancel := context.WithCancel(t.Ctx())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(GitOpsApplyInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			for _, resource := range application.resources {
+				if err := application.selfHeal(t, ctx, resource); err != nil && ctx.Err() == nil {
+					t.T().Logf("Error applying %s of GitOps Application %s: %v", resource.ref(), name, err)
+				}
+			}
+		}
+	}()
+
+	t.T().Cleanup(func() {
+		cancel()
+		<-done
+		// Prune the resources in reverse order, so the resources referencing the others are deleted first
+		for i := len(application.resources) - 1; i >= 0; i-- {
+			resource := application.resources[i]
+			err := t.Client().Dynamic().Resource(resource.resource).Delete(t.Ctx(), resource.desired.GetName(), metav1.DeleteOptions{})
+			if err != nil && !errors.IsNotFound(err) {
+				t.T().Errorf("Failed to prune %s of GitOps Application %s: %v", resource.ref(), name, err)
+			}
+		}
+	})
+
+	return application
+}
+
+// Drifts returns the changes of the fields owned by the Application made by other field managers so far.
+func (a *GitOpsApplication) Drifts() []GitOpsDrift {
+	a.mutex.Lock()
+	defer a.mutex.Unlock()
+	return append([]GitOpsDrift(nil), a.drifts...)
+}
+
+// ManagedFields returns the fields owned by the Application in the live resources, by resource, as the field sets
+// of the server-side apply entries of its field manager. The fields taken over by other field managers, i.e. by an
+// update of the whole object, are missing from them.
+func (a *GitOpsApplication) ManagedFields(t Test) map[ObjectRef]string {
+	t.T().Helper()
+	fields := map[ObjectRef]string{}
+	for _, resource := range a.resources {
+		live, err := t.Client().Dynamic().Resource(resource.resource).Get(t.Ctx(), resource.desired.GetName(), metav1.GetOptions{})
+		ExpectNoError(t, err, "getting", resource.ref())
+		for _, entry := range live.GetManagedFields() {
+			if entry.Manager == gitOpsFieldManager && entry.Operation == metav1.ManagedFieldsOperationApply && entry.FieldsV1 != nil {
+				fields[resource.ref()] = string(entry.FieldsV1.Raw)
+			}
+		}
+	}
+	return fields
+}
+
+// selfHeal applies the desired state without forcing it, so the fields of the Application changed by other
+// field managers are reported as conflicts, recorded as drifts and reverted by a forced apply.
+func (a *GitOpsApplication) selfHeal(t Test, ctx context.Context, resource gitOpsResource) error {
+	_, err := a.apply(t, ctx, resource, false)
+	if !errors.IsConflict(err) {
+		return err
+	}
+	a.mutex.Lock()
+	a.drifts = append(a.drifts, GitOpsDrift{Object: resource.ref(), Time: time.Now(), Message: err.Error()})
+	a.mutex.Unlock()
+	t.T().Logf("GitOps Application %s reverting drift of %s: %v", a.Name, resource.ref(), err)
+	_, err = a.apply(t, ctx, resource, true)
+	return err
+}
+
+func (a *GitOpsApplication) apply(t Test, ctx context.Context, resource gitOpsResource, force bool) (*unstructured.Unstructured, error) {
+	return t.Client().Dynamic().Resource(resource.resource).Apply(ctx, resource.desired.GetName(), resource.desired,
+		metav1.ApplyOptions{FieldManager: gitOpsFieldManager, Force: force})
+}
+
+func (r gitOpsResource) ref() ObjectRef {
+	return Ref(r.desired.GetKind(), "", r.desired.GetName())
+}
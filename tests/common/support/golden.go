@@ -0,0 +1,122 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	"sigs.k8s.io/yaml"
+)
+
+// Metadata fields set by the API server or depending on the cluster, removed from the specs compared with golden files
+var dynamicMetadataFields = []string{"uid", "resourceVersion", "generation", "creationTimestamp", "managedFields", "ownerReferences", "selfLink"}
+
+// NormalizeSpec returns the YAML of the resource spec without the fields that differ between runs, i.e. the status and
+// the metadata fields set by the API server, with the keys sorted. The values of the replacements, i.e. the random test
+// namespace, are replaced with their placeholder keys wherever they appear.
+func NormalizeSpec(spec []byte, replacements map[string]string) ([]byte, error) {
+	var object any
+	if err := yaml.Unmarshal(spec, &object); err != nil {
+		return nil, err
+	}
+	return yaml.Marshal(normalizeValue(object, replacements, true))
+}
+
+func normalizeValue(value any, replacements map[string]string, resource bool) any {
+	switch v := value.(type) {
+	case map[string]any:
+		// Embedded resources, i.e. AppWrapper components, have metadata too
+		_, embedded := v["apiVersion"]
+		normalized := map[string]any{}
+		for key, field := range v {
+			if resource && key == "status" {
+				continue
+			}
+			if key == "metadata" && embedded {
+				if metadata, ok := field.(map[string]any); ok {
+					field = normalizeMetadata(metadata)
+				}
+			}
+			normalized[key] = normalizeValue(field, replacements, key == "template" || key == "items")
+		}
+		return normalized
+	case []any:
+		normalized := make([]any, 0, len(v))
+		for _, item := range v {
+			normalized = append(normalized, normalizeValue(item, replacements, resource))
+		}
+		return normalized
+	case string:
+		for placeholder, dynamic := range replacements {
+			if dynamic != "" {
+				v = strings.ReplaceAll(v, dynamic, placeholder)
+			}
+		}
+		return v
+	default:
+		return value
+	}
+}
+
+func normalizeMetadata(metadata map[string]any) map[string]any {
+	normalized := map[string]any{}
+	for key, field := range metadata {
+		normalized[key] = field
+	}
+	for _, field := range dynamicMetadataFields {
+		delete(normalized, field)
+	}
+	return normalized
+}
+
+// ExpectGoldenSpec compares the normalized resource spec with the golden file, reporting the difference so it's reviewed.
+// The golden file is written instead when UPDATE_GOLDEN_FILES is set, and the test is skipped when the golden file
+// doesn't exist yet. The normalized spec is stored with the test output either way.
+func ExpectGoldenSpec(t Test, goldenFile string, spec []byte, replacements map[string]string) {
+	t.T().Helper()
+
+	actual, err := NormalizeSpec(spec, replacements)
+	t.Expect(err).NotTo(gomega.HaveOccurred(), "Error normalizing spec compared with golden file %s", goldenFile)
+	WriteToOutputDir(t, "golden-"+strings.TrimSuffix(filepath.Base(goldenFile), filepath.Ext(goldenFile)), Log, actual)
+
+	if IsUpdateGoldenFiles() {
+		t.Expect(os.MkdirAll(filepath.Dir(goldenFile), 0o755)).To(gomega.Succeed())
+		t.Expect(os.WriteFile(goldenFile, actual, 0o644)).To(gomega.Succeed())
+		t.T().Logf("Updated golden file %s", goldenFile)
+		return
+	}
+
+	expected, err := os.ReadFile(goldenFile)
+	if os.IsNotExist(err) {
+		t.T().Skipf("Golden file %s doesn't exist, run the test with %s=true to create it", goldenFile, updateGoldenFilesEnvVar)
+	}
+	t.Expect(err).NotTo(gomega.HaveOccurred(), "Error reading golden file %s", goldenFile)
+
+	var expectedObject, actualObject any
+	t.Expect(yaml.Unmarshal(expected, &expectedObject)).To(gomega.Succeed())
+	t.Expect(yaml.Unmarshal(actual, &actualObject)).To(gomega.Succeed())
+	if diff := cmp.Diff(expectedObject, actualObject); diff != "" {
+		t.T().Errorf("Spec differs from golden file %s, review the change and run the test with %s=true to accept it (-expected +actual):\n%s",
+			goldenFile, updateGoldenFilesEnvVar, diff)
+	}
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"strconv"
+	"time"
+
+	"github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	NvidiaGpuResource = corev1.ResourceName("nvidia.com/gpu")
+	AmdGpuResource    = corev1.ResourceName("amd.com/gpu")
+
+	// Node label set by AMD GPU node labeller with the GPU model, i.e. MI210 or MI300X
+	AmdGpuProductNameLabel = "amd.com/gpu.product-name"
+
+	// Node label set by NVIDIA GPU feature discovery with the number of GPUs physically present on the node
+	nvidiaGpuCountLabel = "nvidia.com/gpu.count"
+	// Label of the DaemonSet pods deployed by NVIDIA GPU operator
+	nvidiaDevicePluginLabelSelector = "app=nvidia-device-plugin-daemonset"
+
+	// The vectorAdd sample completes within seconds once started, it only hangs when the GPU is unusable
+	cudaVectorAddTimeout = 30 * time.Second
+)
+
+// GetNvidiaGpuNodes returns the nodes advertising NVIDIA GPUs, either as allocatable resource or through GPU feature discovery labels.
+func GetNvidiaGpuNodes(t Test) []corev1.Node {
+	t.T().Helper()
+	var gpuNodes []corev1.Node
+	for _, node := range GetNodes(t) {
+		_, labeled := node.Labels[nvidiaGpuCountLabel]
+		allocatable := node.Status.Allocatable[NvidiaGpuResource]
+		if labeled || !allocatable.IsZero() {
+			gpuNodes = append(gpuNodes, node)
+		}
+	}
+	return gpuNodes
+}
+
+// GetAmdGpuNodes returns the nodes with at least the given number of allocatable AMD GPUs.
+func GetAmdGpuNodes(t Test, minGpus int64) []corev1.Node {
+	t.T().Helper()
+	var gpuNodes []corev1.Node
+	for _, node := range GetNodes(t) {
+		allocatable := node.Status.Allocatable[AmdGpuResource]
+		if !allocatable.IsZero() && allocatable.Value() >= minGpus {
+			gpuNodes = append(gpuNodes, node)
+		}
+	}
+	return gpuNodes
+}
+
+// NvidiaGpuPreflight verifies the cluster is able to run GPU workloads, so GPU test failures caused by the cluster
+// are reported as such instead of being attributed to the tested workloads or images.
+func NvidiaGpuPreflight(t Test, namespace string) {
+	t.T().Helper()
+	AssertNvidiaDevicePluginHealthy(t)
+	AssertNvidiaGpuAllocatableMatchesHardware(t)
+	RunCudaVectorAdd(t, namespace)
+}
+
+func AssertNvidiaDevicePluginHealthy(t Test) {
+	t.T().Helper()
+	daemonSets, err := t.Client().Core().AppsV1().DaemonSets(metav1.NamespaceAll).List(t.Ctx(), metav1.ListOptions{LabelSelector: nvidiaDevicePluginLabelSelector})
+	ExpectNoError(t, err, "listing", Ref("DaemonSet", metav1.NamespaceAll, nvidiaDevicePluginLabelSelector))
+	t.Expect(daemonSets.Items).NotTo(gomega.BeEmpty(), "GPU pre-flight: cluster problem, NVIDIA device plugin DaemonSet not found")
+
+	for _, daemonSet := range daemonSets.Items {
+		status := daemonSet.Status
+		t.Expect(status.DesiredNumberScheduled).To(gomega.BeNumerically(">", 0),
+			"GPU pre-flight: cluster problem, NVIDIA device plugin DaemonSet %s/%s isn't scheduled on any node", daemonSet.Namespace, daemonSet.Name)
+		t.Expect(status.NumberReady).To(gomega.Equal(status.DesiredNumberScheduled),
+			"GPU pre-flight: cluster problem, NVIDIA device plugin DaemonSet %s/%s has %d of %d pods ready", daemonSet.Namespace, daemonSet.Name, status.NumberReady, status.DesiredNumberScheduled)
+		t.T().Logf("NVIDIA device plugin DaemonSet %s/%s is healthy", daemonSet.Namespace, daemonSet.Name)
+	}
+}
+
+func AssertNvidiaGpuAllocatableMatchesHardware(t Test) {
+	t.T().Helper()
+	gpuNodes := GetNvidiaGpuNodes(t)
+	t.Expect(gpuNodes).NotTo(gomega.BeEmpty(), "GPU pre-flight: cluster problem, no node with NVIDIA GPU found")
+
+	for _, node := range gpuNodes {
+		allocatable := node.Status.Allocatable[NvidiaGpuResource]
+		count, ok := node.Labels[nvidiaGpuCountLabel]
+		if !ok {
+			t.T().Logf("Node %s doesn't have %s label, only checking it has allocatable GPUs", node.Name, nvidiaGpuCountLabel)
+			t.Expect(allocatable.IsZero()).To(gomega.BeFalse(), "GPU pre-flight: cluster problem, node %s has no allocatable GPU", node.Name)
+			continue
+		}
+		hardware, err := strconv.ParseInt(count, 10, 64)
+		ExpectNoError(t, err, "parsing "+nvidiaGpuCountLabel+" label of", Ref("Node", "", node.Name))
+		t.Expect(allocatable.Value()).To(gomega.Equal(hardware),
+			"GPU pre-flight: cluster problem, node %s has %d allocatable GPUs out of %d present", node.Name, allocatable.Value(), hardware)
+		t.T().Logf("Node %s has all its %d GPUs allocatable", node.Name, hardware)
+	}
+}
+
+// RunCudaVectorAdd runs the CUDA vectorAdd sample on a single GPU and asserts the NVIDIA environment is injected.
+func RunCudaVectorAdd(t Test, namespace string) {
+	t.T().Helper()
+	pod := CreatePod(t, &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "cuda-vectoradd-",
+			Namespace:    namespace,
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			Containers: []corev1.Container{
+				{
+					Name:    "cuda-vectoradd",
+					Image:   GetCudaVectorAddImage(),
+					Command: []string{"sh", "-c", "echo NVIDIA_VISIBLE_DEVICES=$NVIDIA_VISIBLE_DEVICES && /cuda-samples/vectorAdd"},
+					Resources: corev1.ResourceRequirements{
+						Limits: corev1.ResourceList{
+							NvidiaGpuResource: resource.MustParse("1"),
+						},
+					},
+				},
+			},
+			Tolerations: []corev1.Toleration{
+				{
+					Key:      string(NvidiaGpuResource),
+					Operator: corev1.TolerationOpExists,
+				},
+			},
+		},
+	})
+
+	// The image is pulled and the pod scheduled within the medium timeout, the sample itself runs within seconds
+	EventuallyOf(t, Pod(t, namespace, pod.Name), TestTimeoutMedium).
+		Should(Field(PodPhase).OneOf(corev1.PodRunning, corev1.PodSucceeded, corev1.PodFailed))
+	EventuallyOf(t, Pod(t, namespace, pod.Name), cudaVectorAddTimeout).
+		Should(Field(PodPhase).OneOf(corev1.PodSucceeded, corev1.PodFailed), "GPU pre-flight: CUDA vectorAdd sample didn't complete within %s", cudaVectorAddTimeout)
+
+	logs := string(GetPodLogs(t, GetPod(t, namespace, pod.Name), corev1.PodLogOptions{}))
+	ExpectOf(t, GetPod(t, namespace, pod.Name)).To(Field(PodPhase).Equal(corev1.PodSucceeded),
+		"GPU pre-flight: CUDA vectorAdd sample failed, logs:\n%s", logs)
+	t.Expect(logs).To(gomega.MatchRegexp(`NVIDIA_VISIBLE_DEVICES=\S+`), "GPU pre-flight: NVIDIA_VISIBLE_DEVICES isn't injected into GPU containers")
+	t.Expect(logs).To(gomega.ContainSubstring("Test PASSED"))
+	t.T().Logf("CUDA vectorAdd sample ran successfully")
+}
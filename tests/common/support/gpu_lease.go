@@ -0,0 +1,239 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// gpuLeasePrefix prefixes the names of the Leases of the GPU slots, suffixed with the slot index
+	gpuLeasePrefix = "distributed-workloads-test-gpu-"
+	// gpuLeaseDuration is the time a Lease isn't renewed after which it's considered abandoned, i.e. by a killed test binary
+	gpuLeaseDuration = 2 * time.Minute
+)
+
+// AcquireGpuLease waits for one of the GPU slots shared by the test runs against the cluster to be free and holds it
+// until the test finishes, so parallel test runs don't oversubscribe the few GPUs of the cluster and time out waiting
+// for them. The slots are Leases in TEST_GPU_LEASE_NAMESPACE, their number set with TEST_GPU_LEASE_SLOTS, 0 disabling
+// the leases. A Lease is renewed while the test runs, and taken over by another test once it expires.
+// Acquire the lease once the test is known not to be skipped, before creating the pods requesting GPUs.
+func AcquireGpuLease(t Test) {
+	t.T().Helper()
+	slots := GetGpuLeaseSlots(t)
+	if slots == 0 {
+		return
+	}
+	namespace := GetGpuLeaseNamespace()
+	holder := gpuLeaseHolder(t)
+
+	var acquired *coordinationv1.Lease
+	stopwatch := StartStopwatch()
+	t.Eventually(func(g gomega.Gomega) bool {
+		for slot := 0; slot < slots; slot++ {
+			lease, err := tryAcquireGpuLease(t, namespace, fmt.Sprintf("%s%d", gpuLeasePrefix, slot), holder)
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			if lease != nil {
+				acquired = lease
+				return true
+			}
+		}
+		return false
+	}, TestTimeoutGpuProvisioning, 5*time.Second).Should(gomega.BeTrue(), func() string {
+		// The holders are only listed once the polling timed out
+		return fmt.Sprintf("No GPU slot got free, held by: %v", gpuLeaseHolders(t, namespace, slots))
+	})
+	t.T().Logf("Acquired GPU lease %s/%s after %s", namespace, acquired.Name, stopwatch.Elapsed().Round(time.Second))
+
+	stop, stopped := make(chan struct{}), make(chan struct{})
+	go func() {
+		defer close(stopped)
+		renewGpuLease(t, namespace, acquired.Name, holder, stop)
+	}()
+	t.T().Cleanup(func() {
+		// Wait for the renewal to stop, as the test can't log once it finished
+		close(stop)
+		<-stopped
+		releaseGpuLease(t, namespace, acquired.Name, holder)
+	})
+}
+
+// tryAcquireGpuLease creates the Lease of the slot, or takes it over once expired, and returns it if acquired.
+// Losing a race with another test isn't an error, the concurrent update is rejected as a conflict.
+func tryAcquireGpuLease(t Test, namespace, name, holder string) (*coordinationv1.Lease, error) {
+	leases := t.Client().Core().CoordinationV1().Leases(namespace)
+	now := metav1.NewMicroTime(time.Now())
+	lease, err := leases.Get(t.Ctx(), name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		lease, err = leases.Create(t.Ctx(), &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{
+				Name:      name,
+				Namespace: namespace,
+			},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       &holder,
+				LeaseDurationSeconds: Ptr(int32(gpuLeaseDuration.Seconds())),
+				AcquireTime:          &now,
+				RenewTime:            &now,
+			},
+		}, metav1.CreateOptions{})
+		if errors.IsAlreadyExists(err) {
+			return nil, nil
+		}
+		return lease, WrapError(err, "creating", Ref("Lease", namespace, name))
+	} else if err != nil {
+		return nil, WrapError(err, "getting", Ref("Lease", namespace, name))
+	}
+
+	if gpuLeases.held(lease, time.Now()) {
+		return nil, nil
+	}
+	lease.Spec.HolderIdentity = &holder
+	lease.Spec.LeaseDurationSeconds = Ptr(int32(gpuLeaseDuration.Seconds()))
+	lease.Spec.AcquireTime = &now
+	lease.Spec.RenewTime = &now
+	lease, err = leases.Update(t.Ctx(), lease, metav1.UpdateOptions{})
+	if errors.IsConflict(err) {
+		return nil, nil
+	}
+	return lease, WrapError(err, "updating", Ref("Lease", namespace, name))
+}
+
+// gpuLeases observes the GPU Leases held by the other test runs.
+var gpuLeases = &gpuLeaseObserver{observations: map[string]gpuLeaseObservation{}}
+
+// gpuLeaseObserver tells the expired Leases like the leader election of client-go: a Lease expires once it isn't seen
+// renewed for its duration on the local clock, rather than once its RenewTime, set on the clock of the holder, is older
+// than its duration, so clocks skewed between the runners don't make the Leases expire early, or never.
+type gpuLeaseObserver struct {
+	sync.Mutex
+	observations map[string]gpuLeaseObservation
+}
+
+// gpuLeaseObservation is the last renewal of a Lease seen, with the local time it was seen at.
+type gpuLeaseObservation struct {
+	holder    string
+	renewTime time.Time
+	// observed carries the monotonic clock reading, so it isn't affected by changes of the wall clock either
+	observed time.Time
+}
+
+// held returns whether the Lease has a holder which was seen renewing it within its duration. A Lease seen for
+// the first time is considered renewed when seen, so an abandoned Lease is taken over once its duration elapsed.
+func (o *gpuLeaseObserver) held(lease *coordinationv1.Lease, now time.Time) bool {
+	if lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity == "" || lease.Spec.RenewTime == nil {
+		return false
+	}
+	duration := gpuLeaseDuration
+	if lease.Spec.LeaseDurationSeconds != nil {
+		duration = time.Duration(*lease.Spec.LeaseDurationSeconds) * time.Second
+	}
+
+	o.Lock()
+	defer o.Unlock()
+	key := lease.Namespace + "/" + lease.Name
+	observation, ok := o.observations[key]
+	if !ok || observation.holder != *lease.Spec.HolderIdentity || !observation.renewTime.Equal(lease.Spec.RenewTime.Time) {
+		observation = gpuLeaseObservation{holder: *lease.Spec.HolderIdentity, renewTime: lease.Spec.RenewTime.Time, observed: now}
+		o.observations[key] = observation
+	}
+	return now.Sub(observation.observed) < duration
+}
+
+// renewGpuLease renews the Lease until stopped, so it doesn't expire while the test runs.
+func renewGpuLease(t Test, namespace, name, holder string, stop <-chan struct{}) {
+	ticker := time.NewTicker(gpuLeaseDuration / 4)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-stop:
+			return
+		case <-ticker.C:
+			leases := t.Client().Core().CoordinationV1().Leases(namespace)
+			lease, err := leases.Get(t.Ctx(), name, metav1.GetOptions{})
+			if err != nil {
+				t.T().Logf("Error getting GPU lease %s/%s to renew it: %v", namespace, name, err)
+				continue
+			}
+			if lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity != holder {
+				t.T().Logf("GPU lease %s/%s was taken over by %s", namespace, name, ptrString(lease.Spec.HolderIdentity))
+				return
+			}
+			lease.Spec.RenewTime = Ptr(metav1.NewMicroTime(time.Now()))
+			if _, err := leases.Update(t.Ctx(), lease, metav1.UpdateOptions{}); err != nil {
+				t.T().Logf("Error renewing GPU lease %s/%s: %v", namespace, name, err)
+			}
+		}
+	}
+}
+
+// releaseGpuLease deletes the Lease if still held by the test, so the next test doesn't wait for it to expire.
+func releaseGpuLease(t Test, namespace, name, holder string) {
+	leases := t.Client().Core().CoordinationV1().Leases(namespace)
+	lease, err := leases.Get(t.Ctx(), name, metav1.GetOptions{})
+	if err != nil {
+		t.T().Logf("Error getting GPU lease %s/%s to release it: %v", namespace, name, err)
+		return
+	}
+	if lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity != holder {
+		return
+	}
+	err = leases.Delete(t.Ctx(), name, metav1.DeleteOptions{
+		Preconditions: &metav1.Preconditions{UID: &lease.UID, ResourceVersion: &lease.ResourceVersion},
+	})
+	if err != nil && !errors.IsNotFound(err) && !errors.IsConflict(err) {
+		t.T().Logf("Error releasing GPU lease %s/%s: %v", namespace, name, err)
+		return
+	}
+	t.T().Logf("Released GPU lease %s/%s", namespace, name)
+}
+
+// gpuLeaseHolders returns the holders of the GPU slots, to report who the test waited for.
+func gpuLeaseHolders(t Test, namespace string, slots int) []string {
+	var holders []string
+	for slot := 0; slot < slots; slot++ {
+		lease, err := t.Client().Core().CoordinationV1().Leases(namespace).Get(t.Ctx(), fmt.Sprintf("%s%d", gpuLeasePrefix, slot), metav1.GetOptions{})
+		if err == nil && gpuLeases.held(lease, time.Now()) {
+			holders = append(holders, *lease.Spec.HolderIdentity)
+		}
+	}
+	return holders
+}
+
+// gpuLeaseHolder identifies the test run holding a lease, i.e. kfto/TestPytorchjobRcclAllReduce@runner-1:4242.
+func gpuLeaseHolder(t Test) string {
+	hostname, _ := os.Hostname()
+	return fmt.Sprintf("%s/%s@%s:%s", suiteName(), t.T().Name(), hostname, strconv.Itoa(os.Getpid()))
+}
+
+func ptrString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,100 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGpuLeaseObserverHeld(t *testing.T) {
+	now := time.Now()
+	lease := func(holder string, renewTime time.Time) *coordinationv1.Lease {
+		return &coordinationv1.Lease{
+			ObjectMeta: metav1.ObjectMeta{Namespace: "default", Name: "gpu-0"},
+			Spec: coordinationv1.LeaseSpec{
+				HolderIdentity:       Ptr(holder),
+				LeaseDurationSeconds: Ptr(int32(60)),
+				RenewTime:            &metav1.MicroTime{Time: renewTime},
+			},
+		}
+	}
+
+	type observation struct {
+		lease *coordinationv1.Lease
+		after time.Duration
+		held  bool
+	}
+	tests := []struct {
+		name         string
+		observations []observation
+	}{
+		{
+			name: "renewal from a clock behind is held",
+			observations: []observation{
+				{lease: lease("runner-a", now.Add(-time.Hour)), held: true},
+				{lease: lease("runner-a", now.Add(-time.Hour)), after: 59 * time.Second, held: true},
+			},
+		},
+		{
+			name: "renewal from a clock ahead expires",
+			observations: []observation{
+				{lease: lease("runner-a", now.Add(time.Hour)), held: true},
+				{lease: lease("runner-a", now.Add(time.Hour)), after: time.Minute, held: false},
+			},
+		},
+		{
+			name: "renewal resets the expiry",
+			observations: []observation{
+				{lease: lease("runner-a", now), held: true},
+				{lease: lease("runner-a", now.Add(50*time.Second)), after: 50 * time.Second, held: true},
+				{lease: lease("runner-a", now.Add(50*time.Second)), after: 100 * time.Second, held: true},
+				{lease: lease("runner-a", now.Add(50*time.Second)), after: 110 * time.Second, held: false},
+			},
+		},
+		{
+			name: "new holder resets the expiry",
+			observations: []observation{
+				{lease: lease("runner-a", now), held: true},
+				{lease: lease("runner-b", now), after: 50 * time.Second, held: true},
+				{lease: lease("runner-b", now), after: 100 * time.Second, held: true},
+			},
+		},
+		{
+			name: "released lease isn't held",
+			observations: []observation{
+				{lease: lease("runner-a", now), held: true},
+				{lease: lease("", now), after: time.Second, held: false},
+			},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+			observer := &gpuLeaseObserver{observations: map[string]gpuLeaseObservation{}}
+			for _, o := range tt.observations {
+				g.Expect(observer.held(o.lease, now.Add(o.after))).To(gomega.Equal(o.held), "after %s", o.after)
+			}
+		})
+	}
+}
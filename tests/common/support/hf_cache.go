@@ -0,0 +1,256 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"fmt"
+	"strings"
+
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// HuggingFaceCacheMountPath is the path the cache is mounted read-only at into the workloads
+	HuggingFaceCacheMountPath = "/mnt/hf-cache"
+	huggingFaceCacheVolume    = "hf-cache"
+	huggingFaceCacheSize      = "20Gi"
+)
+
+// huggingFaceCacheScript downloads the repositories listed in HF_REPOS as TYPE:ID[:PATTERN|PATTERN...] entries into
+// the hub cache, skipping the ones already populated, i.e. by previous runs when the cache is shared.
+const huggingFaceCacheScript = `
+import hashlib
+import os
+from huggingface_hub import snapshot_download
+
+for entry in os.environ["HF_REPOS"].split(","):
+    repo_type, repo_id, patterns = (entry.split(":", 2) + [""])[:3]
+    marker = os.path.join("/cache", ".populated", repo_type + "--" + repo_id.replace("/", "--") + "--" + hashlib.sha256(patterns.encode()).hexdigest()[:12])
+    if os.path.exists(marker):
+        print(f"{repo_type} {repo_id} already cached", flush=True)
+        continue
+    snapshot_download(repo_id, repo_type=repo_type, cache_dir="/cache/hub", allow_patterns=patterns.split("|") if patterns else None)
+    os.makedirs(os.path.dirname(marker), exist_ok=True)
+    open(marker, "w").close()
+    print(f"Cached {repo_type} {repo_id}", flush=True)
+`
+
+// HuggingFaceRepo is a model or dataset repository of the Hugging Face Hub populated into the cache.
+type HuggingFaceRepo struct {
+	ID string
+	// Type is the type of the repository, model or dataset
+	Type string
+	// AllowPatterns restrict the files downloaded, i.e. to the tokenizer files of a large model, all files when empty
+	AllowPatterns []string
+}
+
+// HuggingFaceModel returns the model repository, restricted to the files matching the patterns if any.
+func HuggingFaceModel(id string, allowPatterns ...string) HuggingFaceRepo {
+	return HuggingFaceRepo{ID: id, Type: "model", AllowPatterns: allowPatterns}
+}
+
+// HuggingFaceDataset returns the dataset repository, restricted to the files matching the patterns if any.
+func HuggingFaceDataset(id string, allowPatterns ...string) HuggingFaceRepo {
+	return HuggingFaceRepo{ID: id, Type: "dataset", AllowPatterns: allowPatterns}
+}
+
+func (r HuggingFaceRepo) String() string {
+	// The patterns use | as separator, as , separates the repositories in HF_REPOS and : the fields of each one
+	return fmt.Sprintf("%s:%s:%s", r.Type, r.ID, strings.Join(r.AllowPatterns, "|"))
+}
+
+// HuggingFaceCache is a PVC holding a Hugging Face Hub cache populated with models and datasets, mounted read-only
+// into workloads so they don't download them from the internet. The zero value stands for no cache.
+type HuggingFaceCache struct {
+	ClaimName string
+}
+
+// Apply mounts the cache read-only into the containers of the pod and points the Hugging Face libraries at it
+// in offline mode, it does nothing for the zero value.
+func (c HuggingFaceCache) Apply(spec *corev1.PodSpec) {
+	if c.ClaimName == "" {
+		return
+	}
+	spec.Volumes = append(spec.Volumes, corev1.Volume{
+		Name: huggingFaceCacheVolume,
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: c.ClaimName,
+				ReadOnly:  true,
+			},
+		},
+	})
+	for i := range spec.Containers {
+		container := &spec.Containers[i]
+		container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+			Name:      huggingFaceCacheVolume,
+			MountPath: HuggingFaceCacheMountPath,
+			ReadOnly:  true,
+		})
+		container.Env = append(container.Env,
+			corev1.EnvVar{Name: "HF_HUB_CACHE", Value: HuggingFaceCacheMountPath + "/hub"},
+			corev1.EnvVar{Name: "HF_HUB_OFFLINE", Value: "1"},
+			corev1.EnvVar{Name: "TRANSFORMERS_OFFLINE", Value: "1"},
+		)
+	}
+}
+
+// ProvisionHuggingFaceCache provisions the Hugging Face cache into the namespace, and populates it with the repositories
+// once. With HF_CACHE_PV set, the cache is the pre-provisioned RWX PersistentVolume shared by all the tests and runs,
+// bound to the namespace through a PersistentVolume with the same source, retaining the cached files once deleted.
+// Otherwise, a RWX PVC of the first of RWX_STORAGE_CLASSES is provisioned for the test. Without any of them, the zero
+// value is returned and the workloads download the repositories as usual.
+func ProvisionHuggingFaceCache(t Test, namespace string, repos ...HuggingFaceRepo) HuggingFaceCache {
+	t.T().Helper()
+
+	var pvc *corev1.PersistentVolumeClaim
+	if pvName, ok := GetHuggingFaceCachePV(); ok {
+		pvc = bindSharedPersistentVolume(t, namespace, pvName)
+	} else if storageClasses := GetRwxStorageClasses(); len(storageClasses) > 0 {
+		pvc = CreatePersistentVolumeClaimWithStorageClass(t, namespace, huggingFaceCacheSize, storageClasses[0], corev1.ReadWriteMany)
+	} else {
+		t.T().Logf("Hugging Face cache isn't configured with HF_CACHE_PV nor RWX_STORAGE_CLASSES, downloading %v in each workload", repos)
+		return HuggingFaceCache{}
+	}
+
+	var entries []string
+	for _, repo := range repos {
+		entries = append(entries, repo.String())
+	}
+	populateHuggingFaceCache(t, namespace, pvc.Name, strings.Join(entries, ","))
+	t.T().Logf("Populated Hugging Face cache %s/%s with %v", namespace, pvc.Name, repos)
+	return HuggingFaceCache{ClaimName: pvc.Name}
+}
+
+// bindSharedPersistentVolume binds a PVC of the namespace to a copy of the shared PersistentVolume, as a PersistentVolume
+// is bound to a single PVC. The copy is retained when deleted once the test finishes, so the files are kept.
+func bindSharedPersistentVolume(t Test, namespace, pvName string) *corev1.PersistentVolumeClaim {
+	t.T().Helper()
+
+	shared, err := t.Client().Core().CoreV1().PersistentVolumes().Get(t.Ctx(), pvName, metav1.GetOptions{})
+	ExpectNoError(t, err, "getting", Ref("PersistentVolume", "", pvName))
+
+	spec := *shared.Spec.DeepCopy()
+	spec.ClaimRef = nil
+	spec.PersistentVolumeReclaimPolicy = corev1.PersistentVolumeReclaimRetain
+	pv, err := t.Client().Core().CoreV1().PersistentVolumes().Create(t.Ctx(), &corev1.PersistentVolume{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: fmt.Sprintf("%s-%s", pvName, namespace),
+		},
+		Spec: spec,
+	}, metav1.CreateOptions{})
+	ExpectNoError(t, err, "creating", Ref("PersistentVolume", "", pvName+"-"+namespace))
+	TrackClusterScoped(t, corev1.SchemeGroupVersion.WithResource("persistentvolumes"), pv)
+	t.T().Cleanup(func() {
+		if err := t.Client().Core().CoreV1().PersistentVolumes().Delete(t.Ctx(), pv.Name, metav1.DeleteOptions{}); err != nil {
+			t.T().Logf("Error deleting PersistentVolume %s: %v", pv.Name, err)
+		}
+	})
+
+	pvc, err := t.Client().Core().CoreV1().PersistentVolumeClaims(namespace).Create(t.Ctx(), &corev1.PersistentVolumeClaim{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "hf-cache-",
+			Namespace:    namespace,
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes:      []corev1.PersistentVolumeAccessMode{corev1.ReadWriteMany},
+			StorageClassName: Ptr(spec.StorageClassName),
+			VolumeName:       pv.Name,
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: spec.Capacity[corev1.ResourceStorage],
+				},
+			},
+		},
+	}, metav1.CreateOptions{})
+	ExpectNoError(t, err, "creating", Ref("PersistentVolumeClaim", namespace, "hf-cache-"))
+	t.T().Logf("Bound PersistentVolumeClaim %s/%s to shared Hugging Face cache %s", namespace, pvc.Name, pvName)
+	return pvc
+}
+
+// populateHuggingFaceCache runs the Job downloading the repositories into the cache, and waits for it to complete.
+func populateHuggingFaceCache(t Test, namespace, claimName, repos string) {
+	t.T().Helper()
+
+	podSpec := corev1.PodSpec{
+		RestartPolicy: corev1.RestartPolicyNever,
+		Containers: []corev1.Container{
+			{
+				Name:  "populate",
+				Image: GetHuggingFaceCacheImage(),
+				Command: []string{"sh", "-c", "pip install --quiet --target /tmp/lib huggingface_hub && " +
+					"PYTHONPATH=/tmp/lib python -c \"$SCRIPT\""},
+				Env: []corev1.EnvVar{
+					{Name: "HOME", Value: "/tmp"},
+					{Name: "SCRIPT", Value: huggingFaceCacheScript},
+					{Name: "HF_REPOS", Value: repos},
+					{Name: "PIP_INDEX_URL", Value: GetPipIndexURL()},
+				},
+				Resources: corev1.ResourceRequirements{
+					Requests: corev1.ResourceList{
+						corev1.ResourceCPU:    resource.MustParse("500m"),
+						corev1.ResourceMemory: resource.MustParse("1Gi"),
+					},
+				},
+				VolumeMounts: []corev1.VolumeMount{
+					{
+						Name:      huggingFaceCacheVolume,
+						MountPath: "/cache",
+					},
+				},
+			},
+		},
+		Volumes: []corev1.Volume{
+			{
+				Name: huggingFaceCacheVolume,
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+						ClaimName: claimName,
+					},
+				},
+			},
+		},
+	}
+	SetArchitectureNodeSelector(&podSpec)
+	job, err := t.Client().Core().BatchV1().Jobs(namespace).Create(t.Ctx(), &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "hf-cache-populate-",
+			Namespace:    namespace,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: Ptr(int32(2)),
+			Template:     corev1.PodTemplateSpec{Spec: podSpec},
+		},
+	}, metav1.CreateOptions{})
+	ExpectNoError(t, err, "creating", Ref("Job", namespace, "hf-cache-populate-"))
+
+	EventuallyOf(t, Job(t, namespace, job.Name), TestTimeoutLong).Should(
+		Field(JobConditionStatus(batchv1.JobComplete)).Equal(corev1.ConditionTrue).
+			Or(Field(JobConditionStatus(batchv1.JobFailed)).Equal(corev1.ConditionTrue)),
+	)
+	var logs string
+	for _, pod := range GetPods(t, namespace, metav1.ListOptions{LabelSelector: "job-name=" + job.Name}) {
+		logs += string(GetPodLogs(t, &pod, corev1.PodLogOptions{}))
+	}
+	ExpectOf(t, GetJob(t, namespace, job.Name)).To(Field(JobConditionStatus(batchv1.JobComplete)).Equal(corev1.ConditionTrue),
+		"Populating Hugging Face cache failed, logs:\n%s", logs)
+}
@@ -0,0 +1,210 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// The environment variable for location the test duration history is persisted at, either a local JSON file
+	// or a http(s) URL the history is read from with GET and written to with PUT
+	durationHistoryEnvVar = "TEST_DURATION_HISTORY"
+
+	// Number of the latest runs kept in the history for each test
+	durationHistorySize = 20
+	// Number of the latest runs a test must consistently under- or over-use its timeout in to get a suggestion
+	durationSuggestionRuns = 5
+	// Fractions of the timeout below and above which the timeout is suggested to be adjusted
+	durationUnderuseRatio = 0.3
+	durationOveruseRatio  = 0.8
+	// Fraction of the timeout the suggested timeout makes the slowest of the latest runs use
+	durationTargetRatio = 0.6
+)
+
+// DurationRecord is a run of a test in the duration history.
+type DurationRecord struct {
+	Time     time.Time     `json:"time"`
+	Duration time.Duration `json:"duration"`
+	Timeout  time.Duration `json:"timeout"`
+}
+
+// DurationHistory holds the latest durations of passed tests by test name.
+type DurationHistory map[string][]DurationRecord
+
+// recordDurationHistory adds the passed tests of the summary into the persisted duration history and prints
+// the suggested timeout adjustments, it does nothing if no history location is configured.
+func recordDurationHistory(summary SuiteSummary) {
+	location, ok := os.LookupEnv(durationHistoryEnvVar)
+	if !ok || location == "" {
+		return
+	}
+
+	history, err := loadDurationHistory(location)
+	if err != nil {
+		fmt.Printf("Error loading test duration history from %s: %v\n", location, err)
+		return
+	}
+	now := time.Now()
+	for _, result := range summary.Results {
+		// Only passed runs tell how long the test needs, failed runs may have been cut short or waited in vain
+		if result.Status != TestPassed || result.Timeout == 0 {
+			continue
+		}
+		key := summary.Suite + "/" + result.DisplayName()
+		records := append(history[key], DurationRecord{Time: now, Duration: result.Duration, Timeout: result.Timeout})
+		if len(records) > durationHistorySize {
+			records = records[len(records)-durationHistorySize:]
+		}
+		history[key] = records
+	}
+	if err := saveDurationHistory(location, history); err != nil {
+		fmt.Printf("Error saving test duration history to %s: %v\n", location, err)
+	}
+
+	fmt.Print(formatTimeoutSuggestions(history.TimeoutSuggestions()))
+}
+
+// TimeoutSuggestion suggests a timeout for a test which consistently under- or over-used its timeout.
+type TimeoutSuggestion struct {
+	Test      string
+	Timeout   time.Duration
+	Slowest   time.Duration
+	Suggested time.Duration
+}
+
+// TimeoutSuggestions returns suggestions for the tests using less than 30% or more than 80% of their timeout
+// in each of their latest runs. The suggested timeout makes the slowest of the latest runs use 60% of it.
+func (h DurationHistory) TimeoutSuggestions() []TimeoutSuggestion {
+	var suggestions []TimeoutSuggestion
+	for test, records := range h {
+		if len(records) < durationSuggestionRuns {
+			continue
+		}
+		latest := records[len(records)-durationSuggestionRuns:]
+		underused, overused := true, true
+		var slowest time.Duration
+		for _, record := range latest {
+			ratio := float64(record.Duration) / float64(record.Timeout)
+			underused = underused && ratio < durationUnderuseRatio
+			overused = overused && ratio > durationOveruseRatio
+			slowest = max(slowest, record.Duration)
+		}
+		if !underused && !overused {
+			continue
+		}
+		suggested := time.Duration(math.Ceil(float64(slowest)/durationTargetRatio/float64(time.Minute))) * time.Minute
+		suggestions = append(suggestions, TimeoutSuggestion{
+			Test:      test,
+			Timeout:   latest[len(latest)-1].Timeout,
+			Slowest:   slowest,
+			Suggested: suggested,
+		})
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		return suggestions[i].Test < suggestions[j].Test
+	})
+	return suggestions
+}
+
+func formatTimeoutSuggestions(suggestions []TimeoutSuggestion) string {
+	if len(suggestions) == 0 {
+		return ""
+	}
+	var b strings.Builder
+	b.WriteString("Timeout suggestions:\n")
+	for _, suggestion := range suggestions {
+		fmt.Fprintf(&b, "    %s: slowest of the last %d runs took %s of %s timeout, suggested timeout is %s\n",
+			suggestion.Test, durationSuggestionRuns, suggestion.Slowest.Round(time.Second),
+			suggestion.Timeout.Round(time.Second), suggestion.Suggested)
+	}
+	return b.String()
+}
+
+func loadDurationHistory(location string) (DurationHistory, error) {
+	var data []byte
+	if isURL(location) {
+		resp, err := http.Get(location)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode == http.StatusNotFound {
+			return DurationHistory{}, nil
+		}
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("incorrect response code %d", resp.StatusCode)
+		}
+		if data, err = io.ReadAll(resp.Body); err != nil {
+			return nil, err
+		}
+	} else {
+		var err error
+		data, err = os.ReadFile(location)
+		if errors.Is(err, os.ErrNotExist) {
+			return DurationHistory{}, nil
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	history := DurationHistory{}
+	if len(data) == 0 {
+		return history, nil
+	}
+	return history, json.Unmarshal(data, &history)
+}
+
+func saveDurationHistory(location string, history DurationHistory) error {
+	data, err := json.MarshalIndent(history, "", "  ")
+	if err != nil {
+		return err
+	}
+	if !isURL(location) {
+		return os.WriteFile(location, data, 0644)
+	}
+
+	req, err := http.NewRequest(http.MethodPut, location, bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("incorrect response code %d", resp.StatusCode)
+	}
+	return nil
+}
+
+func isURL(location string) bool {
+	return strings.HasPrefix(location, "http://") || strings.HasPrefix(location, "https://")
+}
@@ -0,0 +1,205 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/version"
+	"sigs.k8s.io/yaml"
+)
+
+const (
+	// Pseudo packages with the version of the Python interpreter and the CUDA version PyTorch is built with
+	pythonPackage = "python"
+	cudaPackage   = "cuda"
+)
+
+//go:embed image_compatibility.yaml
+var imageCompatibilityRules []byte
+
+// CompatibilityRule requires the packages in Require to match their version constraints when the packages in When match theirs.
+type CompatibilityRule struct {
+	Description string            `json:"description"`
+	When        map[string]string `json:"when"`
+	Require     map[string]string `json:"require"`
+}
+
+// imagePackagesScript prints the versions of the packages passed as arguments which are installed in the image, as JSON
+const imagePackagesScript = `import importlib.metadata, json, platform, sys
+versions = {"python": platform.python_version()}
+for name in sys.argv[1:]:
+    try:
+        versions[name] = importlib.metadata.version(name)
+    except importlib.metadata.PackageNotFoundError:
+        pass
+try:
+    import torch
+    if torch.version.cuda:
+        versions["cuda"] = torch.version.cuda
+except ImportError:
+    pass
+print(json.dumps(versions))
+`
+
+// GetImageCompatibilityRules returns the compatibility rules asserted against the runtime images.
+func GetImageCompatibilityRules(t Test) []CompatibilityRule {
+	t.T().Helper()
+	rules := struct {
+		Rules []CompatibilityRule `json:"rules"`
+	}{}
+	t.Expect(yaml.UnmarshalStrict(imageCompatibilityRules, &rules)).To(gomega.Succeed())
+	return rules.Rules
+}
+
+// ExpectImageCompatibility extracts the versions of the packages the compatibility rules refer to from the image,
+// stores them with the test output, and asserts the versions satisfy the rules.
+func ExpectImageCompatibility(t Test, namespace, image string) {
+	t.T().Helper()
+
+	rules := GetImageCompatibilityRules(t)
+	versions := GetImagePackageVersions(t, namespace, image, rulesPackages(rules)...)
+	manifest, err := json.MarshalIndent(versions, "", "  ")
+	t.Expect(err).NotTo(gomega.HaveOccurred())
+	WriteToOutputDir(t, "image-packages-"+image[strings.LastIndex(image, "/")+1:], Log, manifest)
+	t.T().Logf("Packages in image %s: %s", image, manifest)
+
+	var violations []string
+	for _, rule := range rules {
+		applies, err := matchConstraints(versions, rule.When)
+		t.Expect(err).NotTo(gomega.HaveOccurred(), "Invalid compatibility rule %q", rule.Description)
+		if !applies {
+			continue
+		}
+		compatible, err := matchConstraints(versions, rule.Require)
+		t.Expect(err).NotTo(gomega.HaveOccurred(), "Invalid compatibility rule %q", rule.Description)
+		if !compatible {
+			violations = append(violations, fmt.Sprintf("%s: requires %s, found %s", rule.Description,
+				formatPackages(rule.Require, rule.Require), formatPackages(rule.Require, versions)))
+		}
+	}
+	t.Expect(violations).To(gomega.BeEmpty(), "Image %s has incompatible packages", image)
+}
+
+// GetImagePackageVersions returns the versions of the Python interpreter, the CUDA version PyTorch is built with,
+// and the versions of the given Python distributions installed in the image. The ones not installed are omitted.
+func GetImagePackageVersions(t Test, namespace, image string, packages ...string) map[string]string {
+	t.T().Helper()
+
+	script := fmt.Sprintf("python -c '%s' %s", imagePackagesScript, strings.Join(packages, " "))
+	pod := runImagePod(t, namespace, "image-packages-", image, script)
+	logs := strings.TrimSpace(string(GetPodLogs(t, pod, corev1.PodLogOptions{})))
+	ExpectOf(t, pod).To(Field(PodPhase).Equal(corev1.PodSucceeded),
+		"Extracting package versions from image %s failed, logs:\n%s", image, logs)
+
+	versions := map[string]string{}
+	ExpectNoError(t, json.Unmarshal([]byte(logs[strings.LastIndex(logs, "\n")+1:]), &versions),
+		"parsing package versions of", Ref("Pod", namespace, pod.Name))
+	return versions
+}
+
+func rulesPackages(rules []CompatibilityRule) []string {
+	set := map[string]bool{}
+	for _, rule := range rules {
+		for _, constraints := range []map[string]string{rule.When, rule.Require} {
+			for name := range constraints {
+				if name != pythonPackage && name != cudaPackage {
+					set[name] = true
+				}
+			}
+		}
+	}
+	var packages []string
+	for name := range set {
+		packages = append(packages, name)
+	}
+	sort.Strings(packages)
+	return packages
+}
+
+// matchConstraints returns whether all the packages are installed in versions matching their constraints.
+func matchConstraints(versions, constraints map[string]string) (bool, error) {
+	for name, constraint := range constraints {
+		installed, ok := versions[name]
+		if !ok {
+			return false, nil
+		}
+		if matches, err := matchConstraint(installed, constraint); err != nil || !matches {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// matchConstraint returns whether the version matches all the comma separated comparisons, i.e. ">=2.1, <2.4".
+func matchConstraint(installed, constraint string) (bool, error) {
+	v, err := version.ParseGeneric(installed)
+	if err != nil {
+		return false, err
+	}
+	for _, comparison := range strings.Split(constraint, ",") {
+		comparison = strings.TrimSpace(comparison)
+		i := strings.IndexAny(comparison, "0123456789")
+		if i < 0 {
+			return false, fmt.Errorf("missing version in comparison %q of constraint %q", comparison, constraint)
+		}
+		operator := strings.TrimSpace(comparison[:i])
+		result, err := v.Compare(comparison[i:])
+		if err != nil {
+			return false, err
+		}
+		var matches bool
+		switch operator {
+		case ">=":
+			matches = result >= 0
+		case ">":
+			matches = result > 0
+		case "<=":
+			matches = result <= 0
+		case "<":
+			matches = result < 0
+		default:
+			return false, fmt.Errorf("unsupported comparison %q in constraint %q", comparison, constraint)
+		}
+		if !matches {
+			return false, nil
+		}
+	}
+	return true, nil
+}
+
+// formatPackages formats the values of the packages in sorted order, i.e. "cuda 12.1, torch 2.3.1".
+func formatPackages(packages, values map[string]string) string {
+	var formatted []string
+	for name := range packages {
+		value, ok := values[name]
+		if !ok {
+			value = "not installed"
+		}
+		formatted = append(formatted, name+" "+value)
+	}
+	sort.Strings(formatted)
+	return strings.Join(formatted, ", ")
+}
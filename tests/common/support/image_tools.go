@@ -0,0 +1,108 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Markers delimiting the output of each tool in the logs of the image tools pod
+const (
+	imageToolStartMarker = "=== TOOL "
+	imageToolExitMarker  = "=== EXIT "
+)
+
+// ImageTool is a command line tool or entrypoint expected in a runtime image.
+type ImageTool struct {
+	Name string
+	// Command runs the tool and prints its version
+	Command string
+	// Version is the regular expression the output of the command is expected to match
+	Version string
+}
+
+// SemanticVersion matches versions printed by the tools, i.e. 2.1.2 or 0.30.1
+const SemanticVersion = `\b\d+\.\d+(\.\d+)?\b`
+
+// ExpectImageTools runs the image standalone in a pod and asserts each tool exits successfully, printing a sane version.
+// All the tools are run even if some fail, so a single run reports all the missing tools.
+func ExpectImageTools(t Test, namespace, image string, tools ...ImageTool) {
+	t.T().Helper()
+
+	var script strings.Builder
+	for _, tool := range tools {
+		fmt.Fprintf(&script, "echo '%s%s'; (%s) 2>&1; echo \"%s$?\"\n", imageToolStartMarker, tool.Name, tool.Command, imageToolExitMarker)
+	}
+	pod := runImagePod(t, namespace, "image-tools-", image, script.String())
+
+	outputs, exitCodes := parseImageToolsLogs(string(GetPodLogs(t, pod, corev1.PodLogOptions{})))
+	for _, tool := range tools {
+		output := strings.TrimSpace(outputs[tool.Name])
+		t.Expect(exitCodes).To(gomega.HaveKeyWithValue(tool.Name, "0"), "%s failed in image %s, output:\n%s", tool.Name, image, output)
+		t.Expect(output).To(gomega.MatchRegexp(tool.Version), "%s printed an unexpected version in image %s", tool.Name, image)
+		t.T().Logf("%s in image %s: %s", tool.Name, image, regexp.MustCompile(tool.Version).FindString(output))
+	}
+}
+
+// runImagePod runs the shell script in a pod with the image standalone, and returns the pod once it completes.
+func runImagePod(t Test, namespace, generateName, image, script string) *corev1.Pod {
+	t.T().Helper()
+	pod := CreatePod(t, &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: generateName,
+			Namespace:    namespace,
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			Containers: []corev1.Container{
+				{
+					Name:    strings.TrimSuffix(generateName, "-"),
+					Image:   image,
+					Command: []string{"sh", "-c", script},
+				},
+			},
+		},
+	})
+	EventuallyOf(t, Pod(t, namespace, pod.Name), TestTimeoutMedium).
+		Should(Field(PodPhase).OneOf(corev1.PodSucceeded, corev1.PodFailed))
+	return GetPod(t, namespace, pod.Name)
+}
+
+// parseImageToolsLogs returns the output and exit code of each tool from the logs of the image tools pod.
+func parseImageToolsLogs(logs string) (map[string]string, map[string]string) {
+	outputs, exitCodes := map[string]string{}, map[string]string{}
+	var tool string
+	for _, line := range strings.Split(logs, "\n") {
+		switch {
+		case strings.HasPrefix(line, imageToolStartMarker):
+			tool = strings.TrimPrefix(line, imageToolStartMarker)
+		case strings.HasPrefix(line, imageToolExitMarker):
+			exitCodes[tool] = strings.TrimPrefix(line, imageToolExitMarker)
+		case tool != "":
+			outputs[tool] += line + "\n"
+		}
+	}
+	return outputs, exitCodes
+}
@@ -0,0 +1,63 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"fmt"
+	"slices"
+)
+
+// The environment variable for comma separated list of runtime images and operators changed since the last run,
+// only the suites depending on any of them are run
+const testChangedComponentsEnvVar = "TEST_CHANGED_COMPONENTS"
+
+// suiteComponents registers the runtime images and operators each suite depends on, so the differential mode only runs
+// the suites affected by the changed components. Keep it in sync when a suite starts exercising another component.
+var suiteComponents = map[string][]string{
+	"kfto":      {"fms-hf-tuning", "rocm-pytorch", "training-operator", "kueue", "katib"},
+	"kueue":     {"fms-hf-tuning", "rocm-pytorch", "training-operator", "kueue", "gpu-operator"},
+	"odh":       {"notebook", "codeflare-sdk", "ray", "kuberay", "kueue", "appwrapper", "notebook-controller"},
+	"preflight": {"cuda-vectoradd", "tools", "iperf", "grpcurl", "gpu-operator"},
+	"ray":       {"ray", "kuberay", "kueue"},
+}
+
+// suiteAffected returns whether the suite depends on any of the changed components. A component unknown to all the suites
+// affects every suite, so a typo or a component missing in the registry doesn't skip the tests it should trigger.
+func suiteAffected(suiteName string, changed []string) (bool, string) {
+	components, registered := suiteComponents[suiteName]
+	if !registered {
+		return true, fmt.Sprintf("suite %s isn't registered with its components", suiteName)
+	}
+	for _, component := range changed {
+		if slices.Contains(components, component) {
+			return true, fmt.Sprintf("suite %s depends on changed component %s", suiteName, component)
+		}
+		if !knownComponent(component) {
+			return true, fmt.Sprintf("changed component %s isn't registered with any suite", component)
+		}
+	}
+	return false, fmt.Sprintf("suite %s doesn't depend on any of the changed components %v", suiteName, changed)
+}
+
+func knownComponent(component string) bool {
+	for _, components := range suiteComponents {
+		if slices.Contains(components, component) {
+			return true
+		}
+	}
+	return false
+}
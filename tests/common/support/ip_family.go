@@ -0,0 +1,121 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"fmt"
+	"net"
+	"net/url"
+
+	"github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// IPFamilyMode is the IP family configuration of the cluster network the suite runs on.
+type IPFamilyMode string
+
+const (
+	IPFamilyModeIPv4      IPFamilyMode = "IPv4"
+	IPFamilyModeIPv6      IPFamilyMode = "IPv6"
+	IPFamilyModeDualStack IPFamilyMode = "DualStack"
+)
+
+// IPFamilies returns the IP families pods and services are expected to get addresses of.
+func (m IPFamilyMode) IPFamilies() []corev1.IPFamily {
+	switch m {
+	case IPFamilyModeIPv6:
+		return []corev1.IPFamily{corev1.IPv6Protocol}
+	case IPFamilyModeDualStack:
+		return []corev1.IPFamily{corev1.IPv4Protocol, corev1.IPv6Protocol}
+	default:
+		return []corev1.IPFamily{corev1.IPv4Protocol}
+	}
+}
+
+// WildcardAddress returns the address servers run by the tests listen on to be reachable over all the IP families,
+// IPv4 only sockets aren't reachable on IPv6-only clusters.
+func WildcardAddress() string {
+	if GetIPFamilyMode() == IPFamilyModeIPv4 {
+		return "0.0.0.0"
+	}
+	return "::"
+}
+
+// IPFamilyOf returns the IP family of the address, or an empty family when it isn't an IP address.
+func IPFamilyOf(address string) corev1.IPFamily {
+	ip := net.ParseIP(address)
+	switch {
+	case ip == nil:
+		return ""
+	case ip.To4() != nil:
+		return corev1.IPv4Protocol
+	default:
+		return corev1.IPv6Protocol
+	}
+}
+
+// PodIPFamilies returns the IP families of the addresses assigned to the pod.
+func PodIPFamilies(pod corev1.Pod) []corev1.IPFamily {
+	var families []corev1.IPFamily
+	for _, podIP := range pod.Status.PodIPs {
+		families = append(families, IPFamilyOf(podIP.IP))
+	}
+	return families
+}
+
+// PodIP returns the address of the IP family assigned to the pod.
+func PodIP(pod corev1.Pod, family corev1.IPFamily) (string, bool) {
+	for _, podIP := range pod.Status.PodIPs {
+		if IPFamilyOf(podIP.IP) == family {
+			return podIP.IP, true
+		}
+	}
+	return "", false
+}
+
+// HTTPURL returns the URL of the HTTP server listening on the address and port, enclosing IPv6 addresses in brackets.
+func HTTPURL(address string, port int32) url.URL {
+	return url.URL{Scheme: "http", Host: net.JoinHostPort(address, fmt.Sprint(port))}
+}
+
+// GetClusterIPFamilies returns the IP families of the cluster service network, as assigned to the kubernetes service.
+// The first family is the primary one, used by single-stack services by default.
+func GetClusterIPFamilies(t Test) []corev1.IPFamily {
+	t.T().Helper()
+	service, err := t.Client().Core().CoreV1().Services("default").Get(t.Ctx(), "kubernetes", metav1.GetOptions{})
+	ExpectNoError(t, err, "getting", Ref("Service", "default", "kubernetes"))
+	return service.Spec.IPFamilies
+}
+
+// ExpectPodIPFamilies asserts the pod got an address of each IP family expected with TEST_IP_FAMILY.
+func ExpectPodIPFamilies(t Test, pod corev1.Pod) {
+	t.T().Helper()
+	t.Expect(PodIPFamilies(pod)).To(gomega.ContainElements(GetIPFamilyMode().IPFamilies()),
+		"Pod %s/%s got addresses %v, expected %s addresses", pod.Namespace, pod.Name, pod.Status.PodIPs, GetIPFamilyMode())
+}
+
+// ExpectReachableFromPod asserts the HTTP endpoint responds to a request sent from a pod in the namespace, i.e. to probe
+// servers over the pod addresses of a given IP family, which aren't reachable from outside the cluster.
+func ExpectReachableFromPod(t Test, namespace string, endpoint url.URL) {
+	t.T().Helper()
+	pod := runImagePod(t, namespace, "reachability-probe-", GetToolsImage(), fmt.Sprintf("curl -sSf -g --max-time 10 -o /dev/null '%s'", endpoint.String()))
+	ExpectOf(t, pod).To(Field(PodPhase).Equal(corev1.PodSucceeded),
+		"%s isn't reachable from namespace %s, logs:\n%s", endpoint.String(), namespace, GetPodLogs(t, pod, corev1.PodLogOptions{}))
+}
@@ -0,0 +1,376 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"bytes"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"net/url"
+	"slices"
+	"strings"
+	"time"
+
+	"golang.org/x/net/websocket"
+)
+
+// JupyterClient drives code execution in a live Jupyter server through the Jupyter REST kernel API,
+// so tests can assert on each step executed inside the notebook environment.
+type JupyterClient interface {
+	StartKernel(kernelName string) (string, error)
+	Execute(kernelID, code string, timeout time.Duration) (*JupyterExecutionResult, error)
+	// ExecuteStreaming executes the code like Execute, writing the stdout and stderr outputs to the writer as they come
+	ExecuteStreaming(kernelID, code string, timeout time.Duration, output io.Writer) (*JupyterExecutionResult, error)
+	ShutdownKernel(kernelID string) error
+	// UploadNotebook saves the notebook, in the nbformat JSON format, at the path through the Jupyter contents API
+	UploadNotebook(path string, notebook []byte) error
+	// NotebookCodeCells returns the sources of the code cells of the notebook at the path, in order
+	NotebookCodeCells(path string) ([]string, error)
+}
+
+// JupyterExecutionResult holds outputs of executed code, Error is set when the code raised an exception.
+type JupyterExecutionResult struct {
+	Stdout string
+	Stderr string
+	Result string
+	Error  *JupyterExecutionError
+}
+
+type JupyterExecutionError struct {
+	Name      string
+	Value     string
+	Traceback []string
+}
+
+func (e *JupyterExecutionError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Name, e.Value)
+}
+
+type jupyterClient struct {
+	endpoint    url.URL
+	token       string
+	bearerToken string
+	httpClient  *http.Client
+	session     string
+}
+
+type jupyterMessageHeader struct {
+	MsgID    string `json:"msg_id"`
+	MsgType  string `json:"msg_type"`
+	Username string `json:"username"`
+	Session  string `json:"session"`
+	Date     string `json:"date"`
+	Version  string `json:"version"`
+}
+
+type jupyterMessage struct {
+	Header       jupyterMessageHeader `json:"header"`
+	ParentHeader jupyterMessageHeader `json:"parent_header"`
+	Metadata     map[string]any       `json:"metadata"`
+	Content      json.RawMessage      `json:"content"`
+	Channel      string               `json:"channel"`
+	Buffers      []any                `json:"buffers"`
+}
+
+var _ JupyterClient = (*jupyterClient)(nil)
+
+// NewJupyterClient creates a client for the Jupyter server served at the endpoint, including the server base URL.
+// The token is sent as Jupyter token when set.
+func NewJupyterClient(endpoint url.URL, token string) JupyterClient {
+	jar, _ := cookiejar.New(nil)
+	return &jupyterClient{
+		endpoint: endpoint,
+		token:    token,
+		httpClient: &http.Client{
+			Jar:       jar,
+			Timeout:   30 * time.Second,
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
+		},
+		session: newJupyterID(),
+	}
+}
+
+// NewJupyterClientWithBearerToken creates a client for the Jupyter server served behind the OAuth proxy at the endpoint,
+// including the server base URL. The bearer token, i.e. of a ServiceAccount, is validated by the OAuth proxy.
+func NewJupyterClientWithBearerToken(endpoint url.URL, bearerToken string) JupyterClient {
+	client := NewJupyterClient(endpoint, "").(*jupyterClient)
+	client.bearerToken = bearerToken
+	return client
+}
+
+func (client *jupyterClient) StartKernel(kernelName string) (string, error) {
+	body, err := json.Marshal(map[string]string{"name": kernelName})
+	if err != nil {
+		return "", err
+	}
+	respData, err := client.request(http.MethodPost, "/api/kernels", body, http.StatusCreated)
+	if err != nil {
+		return "", err
+	}
+	kernel := struct {
+		ID string `json:"id"`
+	}{}
+	if err := json.Unmarshal(respData, &kernel); err != nil {
+		return "", err
+	}
+	return kernel.ID, nil
+}
+
+func (client *jupyterClient) ShutdownKernel(kernelID string) error {
+	_, err := client.request(http.MethodDelete, "/api/kernels/"+kernelID, nil, http.StatusNoContent)
+	return err
+}
+
+func (client *jupyterClient) Execute(kernelID, code string, timeout time.Duration) (*JupyterExecutionResult, error) {
+	return client.ExecuteStreaming(kernelID, code, timeout, io.Discard)
+}
+
+func (client *jupyterClient) ExecuteStreaming(kernelID, code string, timeout time.Duration, output io.Writer) (*JupyterExecutionResult, error) {
+	ws, err := client.dialKernel(kernelID)
+	if err != nil {
+		return nil, err
+	}
+	defer ws.Close()
+	if err := ws.SetDeadline(time.Now().Add(timeout)); err != nil {
+		return nil, err
+	}
+
+	content, err := json.Marshal(map[string]any{
+		"code":             code,
+		"silent":           false,
+		"store_history":    true,
+		"user_expressions": map[string]any{},
+		"allow_stdin":      false,
+		"stop_on_error":    true,
+	})
+	if err != nil {
+		return nil, err
+	}
+	request := jupyterMessage{
+		Header: jupyterMessageHeader{
+			MsgID:    newJupyterID(),
+			MsgType:  "execute_request",
+			Username: "test",
+			Session:  client.session,
+			Date:     time.Now().UTC().Format(time.RFC3339),
+			Version:  "5.3",
+		},
+		Metadata: map[string]any{},
+		Content:  content,
+		Channel:  "shell",
+		Buffers:  []any{},
+	}
+	if err := websocket.JSON.Send(ws, request); err != nil {
+		return nil, err
+	}
+
+	// Collect the outputs until the kernel gets idle after processing the request
+	result := &JupyterExecutionResult{}
+	for {
+		var message jupyterMessage
+		if err := websocket.JSON.Receive(ws, &message); err != nil {
+			return result, fmt.Errorf("error receiving message from kernel %s: %w", kernelID, err)
+		}
+		if message.ParentHeader.MsgID != request.Header.MsgID {
+			continue
+		}
+		switch message.Header.MsgType {
+		case "stream":
+			stream := struct {
+				Name string `json:"name"`
+				Text string `json:"text"`
+			}{}
+			if err := json.Unmarshal(message.Content, &stream); err != nil {
+				return result, err
+			}
+			if stream.Name == "stderr" {
+				result.Stderr += stream.Text
+			} else {
+				result.Stdout += stream.Text
+			}
+			if _, err := io.WriteString(output, stream.Text); err != nil {
+				return result, err
+			}
+		case "execute_result":
+			executeResult := struct {
+				Data map[string]any `json:"data"`
+			}{}
+			if err := json.Unmarshal(message.Content, &executeResult); err != nil {
+				return result, err
+			}
+			result.Result = fmt.Sprint(executeResult.Data["text/plain"])
+		case "error":
+			executionError := struct {
+				Name      string   `json:"ename"`
+				Value     string   `json:"evalue"`
+				Traceback []string `json:"traceback"`
+			}{}
+			if err := json.Unmarshal(message.Content, &executionError); err != nil {
+				return result, err
+			}
+			result.Error = &JupyterExecutionError{Name: executionError.Name, Value: executionError.Value, Traceback: executionError.Traceback}
+		case "status":
+			status := struct {
+				ExecutionState string `json:"execution_state"`
+			}{}
+			if err := json.Unmarshal(message.Content, &status); err != nil {
+				return result, err
+			}
+			if status.ExecutionState == "idle" {
+				return result, nil
+			}
+		}
+	}
+}
+
+func (client *jupyterClient) UploadNotebook(path string, notebook []byte) error {
+	body, err := json.Marshal(map[string]any{
+		"type":    "notebook",
+		"format":  "json",
+		"content": json.RawMessage(notebook),
+	})
+	if err != nil {
+		return err
+	}
+	// The contents API responds with 201 when the notebook is created and 200 when it is replaced
+	_, err = client.request(http.MethodPut, "/api/contents/"+strings.TrimPrefix(path, "/"), body, http.StatusCreated, http.StatusOK)
+	return err
+}
+
+func (client *jupyterClient) NotebookCodeCells(path string) ([]string, error) {
+	respData, err := client.request(http.MethodGet, "/api/contents/"+strings.TrimPrefix(path, "/")+"?type=notebook&content=1", nil, http.StatusOK)
+	if err != nil {
+		return nil, err
+	}
+	contents := struct {
+		Content struct {
+			Cells []struct {
+				CellType string `json:"cell_type"`
+				// The source is either a string or a list of lines
+				Source json.RawMessage `json:"source"`
+			} `json:"cells"`
+		} `json:"content"`
+	}{}
+	if err := json.Unmarshal(respData, &contents); err != nil {
+		return nil, err
+	}
+	var cells []string
+	for _, cell := range contents.Content.Cells {
+		if cell.CellType != "code" {
+			continue
+		}
+		var source string
+		if err := json.Unmarshal(cell.Source, &source); err != nil {
+			var lines []string
+			if err := json.Unmarshal(cell.Source, &lines); err != nil {
+				return nil, fmt.Errorf("error reading source of cell of notebook %s: %w", path, err)
+			}
+			source = strings.Join(lines, "")
+		}
+		cells = append(cells, source)
+	}
+	return cells, nil
+}
+
+func (client *jupyterClient) dialKernel(kernelID string) (*websocket.Conn, error) {
+	location := client.endpoint
+	location.Scheme = strings.Replace(location.Scheme, "http", "ws", 1)
+	location.Path = strings.TrimSuffix(location.Path, "/") + "/api/kernels/" + kernelID + "/channels"
+	location.RawQuery = url.Values{"session_id": {client.session}}.Encode()
+
+	origin := url.URL{Scheme: client.endpoint.Scheme, Host: client.endpoint.Host}
+	config, err := websocket.NewConfig(location.String(), origin.String())
+	if err != nil {
+		return nil, err
+	}
+	config.TlsConfig = &tls.Config{InsecureSkipVerify: true}
+	client.setHeaders(config.Header)
+	for _, cookie := range client.httpClient.Jar.Cookies(&client.endpoint) {
+		config.Header.Add("Cookie", cookie.String())
+	}
+	return websocket.DialConfig(config)
+}
+
+func (client *jupyterClient) request(method, path string, body []byte, expectedStatuses ...int) ([]byte, error) {
+	// Obtain the XSRF cookie first, Jupyter server requires it for requests modifying its state
+	if xsrfCookie(client.httpClient.Jar, client.endpoint) == "" {
+		req, err := http.NewRequest(http.MethodGet, strings.TrimSuffix(client.endpoint.String(), "/")+"/lab", nil)
+		if err != nil {
+			return nil, err
+		}
+		client.setHeaders(req.Header)
+		resp, err := client.httpClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		resp.Body.Close()
+	}
+
+	req, err := http.NewRequest(method, strings.TrimSuffix(client.endpoint.String(), "/")+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client.setHeaders(req.Header)
+
+	resp, err := client.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	respData, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if !slices.Contains(expectedStatuses, resp.StatusCode) {
+		return nil, fmt.Errorf("incorrect response code %d for %s %s, response body: %s", resp.StatusCode, method, path, respData)
+	}
+	return respData, nil
+}
+
+func (client *jupyterClient) setHeaders(header http.Header) {
+	if client.token != "" {
+		header.Set("Authorization", "token "+client.token)
+	}
+	if client.bearerToken != "" {
+		header.Set("Authorization", "Bearer "+client.bearerToken)
+	}
+	if xsrf := xsrfCookie(client.httpClient.Jar, client.endpoint); xsrf != "" {
+		header.Set("X-XSRFToken", xsrf)
+	}
+}
+
+func xsrfCookie(jar http.CookieJar, endpoint url.URL) string {
+	for _, cookie := range jar.Cookies(&endpoint) {
+		if cookie.Name == "_xsrf" {
+			return cookie.Value
+		}
+	}
+	return ""
+}
+
+func newJupyterID() string {
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return hex.EncodeToString(id)
+}
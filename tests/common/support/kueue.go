@@ -0,0 +1,269 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	kueuev1beta1 "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+)
+
+// KueueQueues are the Kueue resources a test queues its workloads in.
+type KueueQueues struct {
+	ResourceFlavor *kueuev1beta1.ResourceFlavor
+	ClusterQueue   *kueuev1beta1.ClusterQueue
+	LocalQueue     *kueuev1beta1.LocalQueue
+}
+
+// CreateKueueQueues creates a ResourceFlavor with the spec, a ClusterQueue with the nominal quota of the flavor,
+// admitting workloads from all the namespaces, and a LocalQueue pointing to it in the namespace.
+// The cluster-scoped ResourceFlavor and ClusterQueue are deleted once the test finishes.
+func CreateKueueQueues(t Test, namespace string, flavorSpec kueuev1beta1.ResourceFlavorSpec, quota corev1.ResourceList) KueueQueues {
+	t.T().Helper()
+
+	resourceFlavor := CreateTrackedKueueResourceFlavor(t, flavorSpec)
+	t.T().Cleanup(func() {
+		_ = t.Client().Kueue().KueueV1beta1().ResourceFlavors().Delete(t.Ctx(), resourceFlavor.Name, metav1.DeleteOptions{})
+	})
+	clusterQueue := CreateTrackedKueueClusterQueue(t, kueuev1beta1.ClusterQueueSpec{
+		NamespaceSelector: &metav1.LabelSelector{},
+		ResourceGroups:    []kueuev1beta1.ResourceGroup{KueueResourceGroup(resourceFlavor.Name, quota)},
+	})
+	// Cleanups run in reverse order, so the ClusterQueue is deleted before its ResourceFlavor
+	t.T().Cleanup(func() {
+		_ = t.Client().Kueue().KueueV1beta1().ClusterQueues().Delete(t.Ctx(), clusterQueue.Name, metav1.DeleteOptions{})
+	})
+	localQueue := CreateKueueLocalQueue(t, namespace, clusterQueue.Name)
+
+	return KueueQueues{
+		ResourceFlavor: resourceFlavor,
+		ClusterQueue:   clusterQueue,
+		LocalQueue:     localQueue,
+	}
+}
+
+// CreateKueueWorkloadPriorityClass creates a WorkloadPriorityClass with the value, the Workloads queued with a higher
+// priority are admitted first and preempt the lower priority ones when the ClusterQueue preemption policy allows it.
+func CreateKueueWorkloadPriorityClass(t Test, value int32) *kueuev1beta1.WorkloadPriorityClass {
+	t.T().Helper()
+	priorityClass := &kueuev1beta1.WorkloadPriorityClass{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: kueuev1beta1.SchemeGroupVersion.String(),
+			Kind:       "WorkloadPriorityClass",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "wpc-",
+		},
+		Value: value,
+	}
+	priorityClass, err := t.Client().Kueue().KueueV1beta1().WorkloadPriorityClasses().Create(t.Ctx(), priorityClass, metav1.CreateOptions{})
+	ExpectNoError(t, err, "creating", Ref("WorkloadPriorityClass", "", "wpc-"))
+	t.T().Logf("Created Kueue WorkloadPriorityClass %s with value %d successfully", priorityClass.Name, value)
+	return priorityClass
+}
+
+// KueueResourceGroup returns the resource group covering the resources of the quota, with their nominal quota
+// in the flavor. The resources are sorted, so the ClusterQueue specs are stable across runs.
+func KueueResourceGroup(flavorName string, quota corev1.ResourceList) kueuev1beta1.ResourceGroup {
+	var coveredResources []corev1.ResourceName
+	for name := range quota {
+		coveredResources = append(coveredResources, name)
+	}
+	sort.Slice(coveredResources, func(i, j int) bool { return coveredResources[i] < coveredResources[j] })
+
+	flavorQuotas := kueuev1beta1.FlavorQuotas{Name: kueuev1beta1.ResourceFlavorReference(flavorName)}
+	for _, name := range coveredResources {
+		flavorQuotas.Resources = append(flavorQuotas.Resources, kueuev1beta1.ResourceQuota{Name: name, NominalQuota: quota[name]})
+	}
+	return kueuev1beta1.ResourceGroup{
+		CoveredResources: coveredResources,
+		Flavors:          []kueuev1beta1.FlavorQuotas{flavorQuotas},
+	}
+}
+
+func KueueClusterQueue(t Test, name string) func(g gomega.Gomega) *kueuev1beta1.ClusterQueue {
+	return func(g gomega.Gomega) *kueuev1beta1.ClusterQueue {
+		clusterQueue, err := t.Client().Kueue().KueueV1beta1().ClusterQueues().Get(t.Ctx(), name, metav1.GetOptions{})
+		g.Expect(WrapError(err, "getting", Ref("ClusterQueue", "", name))).NotTo(gomega.HaveOccurred())
+		return clusterQueue
+	}
+}
+
+func KueueClusterQueueActive(clusterQueue *kueuev1beta1.ClusterQueue) bool {
+	return meta.IsStatusConditionTrue(clusterQueue.Status.Conditions, kueuev1beta1.ClusterQueueActive)
+}
+
+// KueueClusterQueueReservingWorkloads returns the number of workloads holding quota of the ClusterQueue.
+func KueueClusterQueueReservingWorkloads(clusterQueue *kueuev1beta1.ClusterQueue) int32 {
+	return clusterQueue.Status.ReservingWorkloads
+}
+
+func KueueLocalQueue(t Test, namespace, name string) func(g gomega.Gomega) *kueuev1beta1.LocalQueue {
+	return func(g gomega.Gomega) *kueuev1beta1.LocalQueue {
+		localQueue, err := t.Client().Kueue().KueueV1beta1().LocalQueues(namespace).Get(t.Ctx(), name, metav1.GetOptions{})
+		g.Expect(WrapError(err, "getting", Ref("LocalQueue", namespace, name))).NotTo(gomega.HaveOccurred())
+		return localQueue
+	}
+}
+
+func KueueLocalQueueClusterQueue(localQueue *kueuev1beta1.LocalQueue) string {
+	return string(localQueue.Spec.ClusterQueue)
+}
+
+// KueueWorkloadOwnedBy returns the Kueue Workload created for the owner object, i.e. a training job.
+func KueueWorkloadOwnedBy(t Test, namespace string, owner metav1.Object) func(g gomega.Gomega) *kueuev1beta1.Workload {
+	return func(g gomega.Gomega) *kueuev1beta1.Workload {
+		workloads, err := t.Client().Kueue().KueueV1beta1().Workloads(namespace).List(t.Ctx(), metav1.ListOptions{})
+		g.Expect(WrapError(err, "listing", Ref("Workload", namespace, ""))).NotTo(gomega.HaveOccurred())
+
+		var ownedWorkload *kueuev1beta1.Workload
+		for i := range workloads.Items {
+			if metav1.IsControlledBy(&workloads.Items[i], owner) {
+				ownedWorkload = &workloads.Items[i]
+			}
+		}
+		g.Expect(ownedWorkload).NotTo(gomega.BeNil(), "Workload owned by %s/%s not found", namespace, owner.GetName())
+		return ownedWorkload
+	}
+}
+
+func GetKueueWorkloadOwnedBy(t Test, namespace string, owner metav1.Object) *kueuev1beta1.Workload {
+	t.T().Helper()
+	return KueueWorkloadOwnedBy(t, namespace, owner)(t)
+}
+
+// SetKueueWorkloadActive activates or deactivates the Workload, deactivated Workload is evicted and its job suspended.
+func SetKueueWorkloadActive(t Test, namespace, name string, active bool) {
+	t.T().Helper()
+	patch := fmt.Sprintf(`{"spec":{"active":%t}}`, active)
+	_, err := t.Client().Kueue().KueueV1beta1().Workloads(namespace).Patch(t.Ctx(), name, types.MergePatchType, []byte(patch), metav1.PatchOptions{})
+	ExpectNoError(t, err, "patching", Ref("Workload", namespace, name))
+	t.T().Logf("Set Workload %s/%s active to %t successfully", namespace, name, active)
+}
+
+// SetKueueClusterQueueStopPolicy sets the stop policy of the ClusterQueue, the Hold policy keeps the pending Workloads from being admitted.
+func SetKueueClusterQueueStopPolicy(t Test, name string, policy kueuev1beta1.StopPolicy) {
+	t.T().Helper()
+	patch := fmt.Sprintf(`{"spec":{"stopPolicy":%q}}`, policy)
+	_, err := t.Client().Kueue().KueueV1beta1().ClusterQueues().Patch(t.Ctx(), name, types.MergePatchType, []byte(patch), metav1.PatchOptions{})
+	ExpectNoError(t, err, "patching", Ref("ClusterQueue", "", name))
+	t.T().Logf("Set ClusterQueue %s stop policy to %s successfully", name, policy)
+}
+
+// KueueWorkloadAdmissionTime returns the time the Workload got admitted, nil if it isn't admitted.
+func KueueWorkloadAdmissionTime(workload *kueuev1beta1.Workload) *metav1.Time {
+	if condition := kueueWorkloadCondition(workload, kueuev1beta1.WorkloadAdmitted); condition != nil {
+		return &condition.LastTransitionTime
+	}
+	return nil
+}
+
+func KueueWorkloadEvicted(workload *kueuev1beta1.Workload) bool {
+	return kueueWorkloadCondition(workload, kueuev1beta1.WorkloadEvicted) != nil
+}
+
+func KueueWorkloadEvictedByPreemption(workload *kueuev1beta1.Workload) bool {
+	condition := kueueWorkloadCondition(workload, kueuev1beta1.WorkloadEvicted)
+	return condition != nil && condition.Reason == kueuev1beta1.WorkloadEvictedByPreemption
+}
+
+func KueueWorkloadClusterQueue(workload *kueuev1beta1.Workload) string {
+	if workload.Status.Admission == nil {
+		return ""
+	}
+	return string(workload.Status.Admission.ClusterQueue)
+}
+
+func KueueWorkloadEvictedByDeactivation(workload *kueuev1beta1.Workload) bool {
+	condition := kueueWorkloadCondition(workload, kueuev1beta1.WorkloadEvicted)
+	return condition != nil && condition.Reason == kueuev1beta1.WorkloadEvictedByDeactivation
+}
+
+func KueueWorkloadQuotaReserved(workload *kueuev1beta1.Workload) bool {
+	return kueueWorkloadCondition(workload, kueuev1beta1.WorkloadQuotaReserved) != nil
+}
+
+// KueueWorkloadPending returns true when the Workload is queued, waiting for quota to be reserved.
+func KueueWorkloadPending(workload *kueuev1beta1.Workload) bool {
+	for _, condition := range workload.Status.Conditions {
+		if condition.Type == kueuev1beta1.WorkloadQuotaReserved {
+			return condition.Status == metav1.ConditionFalse && condition.Reason == "Pending"
+		}
+	}
+	return false
+}
+
+// KueueWorkloadPendingMessage returns the reason the Workload is pending, i.e. why the ClusterQueue can't admit it.
+func KueueWorkloadPendingMessage(workload *kueuev1beta1.Workload) string {
+	for _, condition := range workload.Status.Conditions {
+		if condition.Type == kueuev1beta1.WorkloadQuotaReserved && condition.Status == metav1.ConditionFalse {
+			return condition.Message
+		}
+	}
+	return ""
+}
+
+// KueueWorkloadPriority returns the priority the Workload is queued with, resolved from its priority class by Kueue.
+func KueueWorkloadPriority(workload *kueuev1beta1.Workload) int32 {
+	if workload.Spec.Priority == nil {
+		return 0
+	}
+	return *workload.Spec.Priority
+}
+
+// KueueWorkloadConditionStatus returns the accessor of the status of the Workload condition, Unknown when the condition
+// isn't set. Unlike the boolean accessors, it tells a condition reset by Kueue, i.e. Evicted once re-admitted, from one never set.
+func KueueWorkloadConditionStatus(conditionType string) func(*kueuev1beta1.Workload) metav1.ConditionStatus {
+	return func(workload *kueuev1beta1.Workload) metav1.ConditionStatus {
+		if condition := meta.FindStatusCondition(workload.Status.Conditions, conditionType); condition != nil {
+			return condition.Status
+		}
+		return metav1.ConditionUnknown
+	}
+}
+
+// KueueWorkloadConditionReason returns the accessor of the reason of the Workload condition, whatever its status,
+// empty when the condition isn't set.
+func KueueWorkloadConditionReason(conditionType string) func(*kueuev1beta1.Workload) string {
+	return func(workload *kueuev1beta1.Workload) string {
+		if condition := meta.FindStatusCondition(workload.Status.Conditions, conditionType); condition != nil {
+			return condition.Reason
+		}
+		return ""
+	}
+}
+
+func KueueWorkloadFinished(workload *kueuev1beta1.Workload) bool {
+	return kueueWorkloadCondition(workload, kueuev1beta1.WorkloadFinished) != nil
+}
+
+func kueueWorkloadCondition(workload *kueuev1beta1.Workload, conditionType string) *metav1.Condition {
+	for _, condition := range workload.Status.Conditions {
+		if condition.Type == conditionType && condition.Status == metav1.ConditionTrue {
+			return &condition
+		}
+	}
+	return nil
+}
@@ -0,0 +1,59 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"flag"
+	"slices"
+	"strings"
+)
+
+// Labels describing the tests, so the suite can be sliced semantically with the -labels flag
+const (
+	// Test requires GPUs
+	LabelGpu = "gpu"
+	// Test takes tens of minutes
+	LabelLong = "long"
+	// Test exercises Kueue
+	LabelKueue = "kueue"
+	// Test exercises workbenches
+	LabelNotebook = "notebook"
+	// Test is part of the tier 1 acceptance suite
+	LabelTier1 = "tier1"
+	// Test requires a large cluster
+	LabelScale = "scale"
+)
+
+var labelsFilter = flag.String("labels", "", "Comma separated list of labels the tests must have to run, labels prefixed with '!' must not be present, i.e. -labels=kueue,!gpu")
+
+// labelsMatch returns true if the test labels satisfy the filter.
+func labelsMatch(filter string, labels []string) bool {
+	for _, label := range strings.Split(filter, ",") {
+		label = strings.TrimSpace(label)
+		if label == "" {
+			continue
+		}
+		if excluded, ok := strings.CutPrefix(label, "!"); ok {
+			if slices.Contains(labels, excluded) {
+				return false
+			}
+		} else if !slices.Contains(labels, label) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,78 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"flag"
+	"testing"
+
+	"github.com/onsi/gomega"
+)
+
+func TestLabelsMatch(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter string
+		labels []string
+		match  bool
+	}{
+		{name: "no filter", filter: "", labels: []string{LabelKueue}, match: true},
+		{name: "no filter without labels", filter: "", match: true},
+		{name: "required label", filter: "kueue", labels: []string{LabelKueue, LabelGpu}, match: true},
+		{name: "missing required label", filter: "kueue", labels: []string{LabelNotebook}, match: false},
+		{name: "required label without labels", filter: "kueue", match: false},
+		{name: "all required labels", filter: "kueue,gpu", labels: []string{LabelGpu, LabelKueue}, match: true},
+		{name: "missing one of the required labels", filter: "kueue,gpu", labels: []string{LabelKueue}, match: false},
+		{name: "excluded label", filter: "!gpu", labels: []string{LabelKueue, LabelGpu}, match: false},
+		{name: "excluded label not present", filter: "!gpu", labels: []string{LabelKueue}, match: true},
+		{name: "excluded label without labels", filter: "!gpu", match: true},
+		{name: "required and excluded labels", filter: "kueue,!gpu", labels: []string{LabelKueue}, match: true},
+		{name: "required and present excluded labels", filter: "kueue,!gpu", labels: []string{LabelKueue, LabelGpu}, match: false},
+		{name: "spaces and empty entries", filter: " kueue , ,!gpu,", labels: []string{LabelKueue}, match: true},
+		{name: "labels are case sensitive", filter: "Kueue", labels: []string{LabelKueue}, match: false},
+		{name: "unknown required label", filter: "kueu", labels: []string{LabelKueue}, match: false},
+		{name: "unknown excluded label", filter: "!kueu", labels: []string{LabelKueue}, match: true},
+		{name: "label not declared as a constant", filter: "custom", labels: []string{"custom"}, match: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+			g.Expect(labelsMatch(tt.filter, tt.labels)).To(gomega.Equal(tt.match))
+		})
+	}
+}
+
+// TestLabelsFlag makes sure the tests are skipped by Track according to the -labels flag.
+func TestLabelsFlag(t *testing.T) {
+	g := gomega.NewWithT(t)
+	filter := *labelsFilter
+	t.Cleanup(func() { *labelsFilter = filter })
+	g.Expect(flag.Set("labels", "kueue,!gpu")).To(gomega.Succeed())
+
+	skipped := map[string]bool{}
+	for name, labels := range map[string][]string{
+		"kueue":     {LabelKueue},
+		"kueue-gpu": {LabelKueue, LabelGpu},
+		"notebook":  {LabelNotebook},
+	} {
+		t.Run(name, func(t *testing.T) {
+			t.Cleanup(func() { skipped[name] = t.Skipped() })
+			Track(t, labels...)
+		})
+	}
+	g.Expect(skipped).To(gomega.Equal(map[string]bool{"kueue": false, "kueue-gpu": true, "notebook": true}))
+}
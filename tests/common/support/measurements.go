@@ -0,0 +1,66 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"strings"
+	"sync"
+
+	. "github.com/project-codeflare/codeflare-common/support"
+)
+
+// measurements records the values measured by tests, they are reported in the test result.
+var measurements = struct {
+	sync.Mutex
+	byTest map[string]map[string]float64
+}{byTest: map[string]map[string]float64{}}
+
+// RecordMeasurement records the value measured by the test, i.e. a bandwidth, so it is part of the test result
+// in the suite report. The unit should be part of the name, i.e. bandwidth_gbps.
+func RecordMeasurement(t Test, name string, value float64) {
+	measurements.Lock()
+	defer measurements.Unlock()
+	if measurements.byTest[t.T().Name()] == nil {
+		measurements.byTest[t.T().Name()] = map[string]float64{}
+	}
+	measurements.byTest[t.T().Name()][name] = value
+}
+
+func takeMeasurements(testName string) map[string]float64 {
+	measurements.Lock()
+	defer measurements.Unlock()
+	// Measurements of subtests are reported with their parent test, prefixed with the subtest name
+	var values map[string]float64
+	for name, recorded := range measurements.byTest {
+		var prefix string
+		switch {
+		case name == testName:
+		case strings.HasPrefix(name, testName+"/"):
+			prefix = strings.TrimPrefix(name, testName+"/") + "/"
+		default:
+			continue
+		}
+		if values == nil {
+			values = map[string]float64{}
+		}
+		for measurement, value := range recorded {
+			values[prefix+measurement] = value
+		}
+		delete(measurements.byTest, name)
+	}
+	return values
+}
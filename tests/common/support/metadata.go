@@ -0,0 +1,64 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"fmt"
+	"reflect"
+
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
+
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// HaveLabel succeeds if the Kubernetes object has the label with the value.
+func HaveLabel(key, value string) types.GomegaMatcher {
+	return gomega.WithTransform(func(object any) (map[string]string, error) {
+		accessor, err := objectMeta(object)
+		if err != nil {
+			return nil, err
+		}
+		return accessor.GetLabels(), nil
+	}, gomega.HaveKeyWithValue(key, value))
+}
+
+// HaveAnnotation succeeds if the Kubernetes object has the annotation with the value.
+func HaveAnnotation(key, value string) types.GomegaMatcher {
+	return gomega.WithTransform(func(object any) (map[string]string, error) {
+		accessor, err := objectMeta(object)
+		if err != nil {
+			return nil, err
+		}
+		return accessor.GetAnnotations(), nil
+	}, gomega.HaveKeyWithValue(key, value))
+}
+
+func objectMeta(object any) (metav1.Object, error) {
+	if accessor, err := meta.Accessor(object); err == nil {
+		return accessor, nil
+	}
+	// Objects passed by value, i.e. listed pods, implement metav1.Object through pointer receivers only
+	value := reflect.ValueOf(object)
+	if !value.IsValid() {
+		return nil, fmt.Errorf("expected a Kubernetes object, got nil")
+	}
+	pointer := reflect.New(value.Type())
+	pointer.Elem().Set(value)
+	return meta.Accessor(pointer.Interface())
+}
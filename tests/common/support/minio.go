@@ -0,0 +1,280 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/rand"
+)
+
+const (
+	minioPort   = 9000
+	minioBucket = "test"
+	// minioReadyFile is created once the bucket is created
+	minioReadyFile = "/tmp/minio-ready"
+)
+
+// minioScript starts MinIO server and creates the bucket, the MinIO image ships with the mc client.
+var minioScript = fmt.Sprintf(`set -e
+minio server /data --address :%[1]d &
+until mc alias set local http://localhost:%[1]d "$MINIO_ROOT_USER" "$MINIO_ROOT_PASSWORD" > /dev/null 2>&1; do sleep 1; done
+mc mb --ignore-existing local/"$MINIO_BUCKET"
+touch %[2]s
+wait
+`, minioPort, minioReadyFile)
+
+// MinIO is an ephemeral MinIO instance deployed in the test namespace, deleted along with it.
+type MinIO struct {
+	S3Bucket
+	// SecretName is the name of the Secret holding the endpoint, credentials and bucket as AWS_* keys
+	SecretName string
+}
+
+// Env returns the environment variables referencing the endpoint, credentials and bucket of the MinIO instance in its
+// Secret, with the names of the variables the tests configure S3 with, i.e. to template notebooks and training pods.
+func (m MinIO) Env() []corev1.EnvVar {
+	var env []corev1.EnvVar
+	for _, name := range []string{s3EndpointEnvVar, s3AccessKeyIDEnvVar, s3SecretAccessKeyEnvVar, s3BucketEnvVar, s3RegionEnvVar} {
+		env = append(env, corev1.EnvVar{
+			Name: name,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: m.SecretName},
+					Key:                  name,
+				},
+			},
+		})
+	}
+	return env
+}
+
+// DeployMinIO deploys MinIO into the namespace with random credentials and a bucket, and waits for it to be ready.
+// The endpoint is only reachable from within the cluster.
+func DeployMinIO(t Test, namespace string) MinIO {
+	t.T().Helper()
+
+	name := "minio"
+	labels := map[string]string{"app.kubernetes.io/name": name}
+	minio := MinIO{
+		S3Bucket: S3Bucket{
+			Endpoint:        fmt.Sprintf("http://%s.%s.svc:%d", name, namespace, minioPort),
+			AccessKeyID:     "minio-" + rand.String(8),
+			SecretAccessKey: rand.String(24),
+			Bucket:          minioBucket,
+			Region:          "us-east-1",
+		},
+		SecretName: name,
+	}
+
+	_, err := t.Client().Core().CoreV1().Secrets(namespace).Create(t.Ctx(), &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      minio.SecretName,
+			Namespace: namespace,
+		},
+		StringData: map[string]string{
+			s3EndpointEnvVar:        minio.Endpoint,
+			s3AccessKeyIDEnvVar:     minio.AccessKeyID,
+			s3SecretAccessKeyEnvVar: minio.SecretAccessKey,
+			s3BucketEnvVar:          minio.Bucket,
+			s3RegionEnvVar:          minio.Region,
+		},
+	}, metav1.CreateOptions{})
+	ExpectNoError(t, err, "creating", Ref("Secret", namespace, minio.SecretName))
+
+	secretEnv := func(name, key string) corev1.EnvVar {
+		return corev1.EnvVar{
+			Name: name,
+			ValueFrom: &corev1.EnvVarSource{
+				SecretKeyRef: &corev1.SecretKeySelector{
+					LocalObjectReference: corev1.LocalObjectReference{Name: minio.SecretName},
+					Key:                  key,
+				},
+			},
+		}
+	}
+	podSpec := corev1.PodSpec{
+		Containers: []corev1.Container{
+			{
+				Name:    name,
+				Image:   GetMinioImage(),
+				Command: []string{"sh", "-c", minioScript},
+				Env: []corev1.EnvVar{
+					{Name: "HOME", Value: "/tmp"},
+					secretEnv("MINIO_ROOT_USER", s3AccessKeyIDEnvVar),
+					secretEnv("MINIO_ROOT_PASSWORD", s3SecretAccessKeyEnvVar),
+					secretEnv("MINIO_BUCKET", s3BucketEnvVar),
+				},
+				Ports: []corev1.ContainerPort{
+					{
+						ContainerPort: minioPort,
+						Name:          "s3",
+					},
+				},
+				ReadinessProbe: &corev1.Probe{
+					ProbeHandler: corev1.ProbeHandler{
+						Exec: &corev1.ExecAction{Command: []string{"test", "-f", minioReadyFile}},
+					},
+					PeriodSeconds: 2,
+				},
+				VolumeMounts: []corev1.VolumeMount{
+					{
+						Name:      "data",
+						MountPath: "/data",
+					},
+				},
+			},
+		},
+		Volumes: []corev1.Volume{
+			{
+				Name: "data",
+				VolumeSource: corev1.VolumeSource{
+					EmptyDir: &corev1.EmptyDirVolumeSource{},
+				},
+			},
+		},
+	}
+	SetArchitectureNodeSelector(&podSpec)
+	_, err = t.Client().Core().AppsV1().Deployments(namespace).Create(t.Ctx(), &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: Ptr(int32(1)),
+			Selector: &metav1.LabelSelector{MatchLabels: labels},
+			// The data is stored in an emptyDir, so the pod mustn't be replaced by a new one while it runs
+			Strategy: appsv1.DeploymentStrategy{Type: appsv1.RecreateDeploymentStrategyType},
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{Labels: labels},
+				Spec:       podSpec,
+			},
+		},
+	}, metav1.CreateOptions{})
+	ExpectNoError(t, err, "creating", Ref("Deployment", namespace, name))
+
+	_, err = t.Client().Core().CoreV1().Services(namespace).Create(t.Ctx(), &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: labels,
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "s3",
+					Port:       minioPort,
+					TargetPort: intstr.FromString("s3"),
+				},
+			},
+		},
+	}, metav1.CreateOptions{})
+	ExpectNoError(t, err, "creating", Ref("Service", namespace, name))
+
+	t.Eventually(func(g gomega.Gomega) int32 {
+		deployment, err := t.Client().Core().AppsV1().Deployments(namespace).Get(t.Ctx(), name, metav1.GetOptions{})
+		g.Expect(WrapError(err, "getting", Ref("Deployment", namespace, name))).NotTo(gomega.HaveOccurred())
+		return deployment.Status.ReadyReplicas
+	}, TestTimeoutMedium).Should(gomega.Equal(int32(1)), "MinIO didn't get ready with bucket %s created", minio.Bucket)
+
+	t.T().Logf("Deployed MinIO %s with bucket %s", minio.Endpoint, minio.Bucket)
+	return minio
+}
+
+// GetOrDeployS3Bucket returns the S3 bucket configured with the AWS_* environment variables, or the bucket of
+// an ephemeral MinIO deployed into the namespace when not configured.
+func GetOrDeployS3Bucket(t Test, namespace string) S3Bucket {
+	t.T().Helper()
+	if bucket, ok := GetS3Bucket(); ok {
+		return bucket
+	}
+	return DeployMinIO(t, namespace).S3Bucket
+}
+
+// ListObjects returns the keys of the objects of the bucket under the prefix, empty or ending with a slash. The bucket is listed from a pod running
+// in the namespace, so the endpoint only needs to be reachable from within the cluster.
+func ListObjects(t Test, namespace string, bucket S3Bucket, prefix string) []string {
+	t.T().Helper()
+	logs := runS3Client(t, namespace, bucket, fmt.Sprintf(`mc --insecure ls --recursive s3/%s/%s`, bucket.Bucket, prefix))
+	var keys []string
+	for _, line := range strings.Split(strings.TrimSpace(logs), "\n") {
+		// Lines end with the key relative to the listed prefix, i.e. [2024-05-02 10:00:00 UTC] 1.2MiB STANDARD final.pt
+		if fields := strings.Fields(line); len(fields) > 0 {
+			keys = append(keys, prefix+fields[len(fields)-1])
+		}
+	}
+	return keys
+}
+
+// ExpectObjectExists asserts the objects with the keys exist in the bucket, i.e. to verify the trained models got
+// uploaded. The objects are looked up from a pod running in the namespace.
+func ExpectObjectExists(t Test, namespace string, bucket S3Bucket, keys ...string) {
+	t.T().Helper()
+	var script strings.Builder
+	for _, key := range keys {
+		fmt.Fprintf(&script, "mc --insecure stat s3/%[1]s/%[2]s > /dev/null 2>&1 && echo 'FOUND %[2]s' || echo 'MISSING %[2]s'\n", bucket.Bucket, key)
+	}
+	logs := runS3Client(t, namespace, bucket, script.String())
+	for _, key := range keys {
+		t.Expect(logs).To(gomega.ContainSubstring("FOUND "+key), "Object %s not found in bucket %s at %s", key, bucket.Bucket, bucket.Endpoint)
+	}
+}
+
+// runS3Client runs the mc client script against the bucket in a pod, and returns its logs once it succeeds.
+func runS3Client(t Test, namespace string, bucket S3Bucket, script string) string {
+	t.T().Helper()
+	endpoint, err := url.Parse(bucket.Endpoint)
+	t.Expect(err).NotTo(gomega.HaveOccurred(), "Error parsing S3 endpoint %s", bucket.Endpoint)
+	endpoint.User = url.UserPassword(bucket.AccessKeyID, bucket.SecretAccessKey)
+
+	pod := CreatePod(t, &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "s3-client-",
+			Namespace:    namespace,
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			Containers: []corev1.Container{
+				{
+					Name:    "mc",
+					Image:   GetMinioImage(),
+					Command: []string{"sh", "-c", "set -e\n" + script},
+					Env: []corev1.EnvVar{
+						{Name: "HOME", Value: "/tmp"},
+						// mc reads the credentials of the s3 alias from the URL of the MC_HOST_s3 variable
+						{Name: "MC_HOST_s3", Value: endpoint.String()},
+					},
+				},
+			},
+		},
+	})
+	EventuallyOf(t, Pod(t, namespace, pod.Name), TestTimeoutMedium).
+		Should(Field(PodPhase).OneOf(corev1.PodSucceeded, corev1.PodFailed))
+	logs := string(GetPodLogs(t, pod, corev1.PodLogOptions{}))
+	ExpectOf(t, GetPod(t, namespace, pod.Name)).To(Field(PodPhase).Equal(corev1.PodSucceeded),
+		"S3 client failed, logs:\n%s", logs)
+	return logs
+}
@@ -0,0 +1,201 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/tools/clientcmd"
+	kueuev1alpha1 "sigs.k8s.io/kueue/apis/kueue/v1alpha1"
+	kueuev1beta1 "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+)
+
+const (
+	// Name of the controller of MultiKueue admission checks
+	MultiKueueControllerName = "kueue.x-k8s.io/multikueue"
+	// Key of the kubeconfig in the secrets referenced by MultiKueueClusters
+	multiKueueKubeconfigKey = "kubeconfig"
+)
+
+// ClusterTest returns the test with its clients pointing to the cluster of the kubeconfig, i.e. a MultiKueue
+// worker cluster, so the support functions can be used against it.
+func ClusterTest(t Test, kubeconfig []byte) Test {
+	t.T().Helper()
+	cfg, err := clientcmd.RESTConfigFromKubeConfig(kubeconfig)
+	t.Expect(err).NotTo(gomega.HaveOccurred(), "Error loading kubeconfig of the cluster")
+	return withRestConfig(t.T(), cfg)
+}
+
+// CreateTestNamespaceMirror creates the namespace with the name of a test namespace of another cluster, i.e. in a
+// MultiKueue worker cluster which requires the namespaces of the dispatched workloads to exist. The namespace is
+// deleted, once its logs stored, when the test finishes.
+func CreateTestNamespaceMirror(t Test, name string) *corev1.Namespace {
+	t.T().Helper()
+	namespace := CreateTestNamespaceWithName(t, name)
+	t.T().Cleanup(func() {
+		DeleteTestNamespace(t, namespace)
+	})
+	return namespace
+}
+
+// CreateKueueLocalQueueWithName creates the LocalQueue with the name, i.e. the name of the LocalQueue of a MultiKueue
+// manager cluster the LocalQueue of a worker cluster must match.
+func CreateKueueLocalQueueWithName(t Test, namespace, name, clusterQueueName string) *kueuev1beta1.LocalQueue {
+	t.T().Helper()
+	localQueue := &kueuev1beta1.LocalQueue{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		Spec: kueuev1beta1.LocalQueueSpec{
+			ClusterQueue: kueuev1beta1.ClusterQueueReference(clusterQueueName),
+		},
+	}
+	localQueue, err := t.Client().Kueue().KueueV1beta1().LocalQueues(namespace).Create(t.Ctx(), localQueue, metav1.CreateOptions{})
+	ExpectNoError(t, err, "creating", Ref("LocalQueue", namespace, name))
+	t.T().Logf("Created Kueue LocalQueue %s/%s successfully", localQueue.Namespace, localQueue.Name)
+	return localQueue
+}
+
+// CreateMultiKueueAdmissionCheck registers the worker clusters of the kubeconfigs with MultiKueue, and returns the
+// admission check dispatching the workloads of the ClusterQueues using it to these clusters, once active.
+// The kubeconfigs are stored in secrets of the Kueue namespace, the MultiKueue controller reads them from.
+// All the resources are deleted once the test finishes.
+func CreateMultiKueueAdmissionCheck(t Test, kubeconfigs ...[]byte) *kueuev1beta1.AdmissionCheck {
+	t.T().Helper()
+
+	kueueNamespace := GetKueueNamespace()
+	var clusters []string
+	for _, kubeconfig := range kubeconfigs {
+		secret, err := t.Client().Core().CoreV1().Secrets(kueueNamespace).Create(t.Ctx(), &corev1.Secret{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: "multikueue-",
+				Namespace:    kueueNamespace,
+			},
+			Data: map[string][]byte{
+				multiKueueKubeconfigKey: kubeconfig,
+			},
+		}, metav1.CreateOptions{})
+		ExpectNoError(t, err, "creating", Ref("Secret", kueueNamespace, "multikueue-"))
+		t.T().Cleanup(func() {
+			_ = t.Client().Core().CoreV1().Secrets(kueueNamespace).Delete(t.Ctx(), secret.Name, metav1.DeleteOptions{})
+		})
+
+		cluster, err := t.Client().Kueue().KueueV1alpha1().MultiKueueClusters().Create(t.Ctx(), &kueuev1alpha1.MultiKueueCluster{
+			ObjectMeta: metav1.ObjectMeta{
+				Name: secret.Name,
+			},
+			Spec: kueuev1alpha1.MultiKueueClusterSpec{
+				KubeConfig: kueuev1alpha1.KubeConfig{
+					Location:     secret.Name,
+					LocationType: kueuev1alpha1.SecretLocationType,
+				},
+			},
+		}, metav1.CreateOptions{})
+		ExpectNoError(t, err, "creating", Ref("MultiKueueCluster", "", secret.Name))
+		TrackClusterScoped(t, kueuev1alpha1.GroupVersion.WithResource("multikueueclusters"), cluster)
+		t.T().Cleanup(func() {
+			_ = t.Client().Kueue().KueueV1alpha1().MultiKueueClusters().Delete(t.Ctx(), cluster.Name, metav1.DeleteOptions{})
+		})
+		clusters = append(clusters, cluster.Name)
+	}
+
+	config, err := t.Client().Kueue().KueueV1alpha1().MultiKueueConfigs().Create(t.Ctx(), &kueuev1alpha1.MultiKueueConfig{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "multikueue-",
+		},
+		Spec: kueuev1alpha1.MultiKueueConfigSpec{
+			Clusters: clusters,
+		},
+	}, metav1.CreateOptions{})
+	ExpectNoError(t, err, "creating", Ref("MultiKueueConfig", "", "multikueue-"))
+	TrackClusterScoped(t, kueuev1alpha1.GroupVersion.WithResource("multikueueconfigs"), config)
+	t.T().Cleanup(func() {
+		_ = t.Client().Kueue().KueueV1alpha1().MultiKueueConfigs().Delete(t.Ctx(), config.Name, metav1.DeleteOptions{})
+	})
+
+	admissionCheck, err := t.Client().Kueue().KueueV1beta1().AdmissionChecks().Create(t.Ctx(), &kueuev1beta1.AdmissionCheck{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "multikueue-",
+		},
+		Spec: kueuev1beta1.AdmissionCheckSpec{
+			ControllerName: MultiKueueControllerName,
+			Parameters: &kueuev1beta1.AdmissionCheckParametersReference{
+				APIGroup: kueuev1alpha1.GroupVersion.Group,
+				Kind:     "MultiKueueConfig",
+				Name:     config.Name,
+			},
+		},
+	}, metav1.CreateOptions{})
+	ExpectNoError(t, err, "creating", Ref("AdmissionCheck", "", "multikueue-"))
+	TrackClusterScoped(t, kueuev1beta1.GroupVersion.WithResource("admissionchecks"), admissionCheck)
+	t.T().Cleanup(func() {
+		_ = t.Client().Kueue().KueueV1beta1().AdmissionChecks().Delete(t.Ctx(), admissionCheck.Name, metav1.DeleteOptions{})
+	})
+	t.T().Logf("Created MultiKueue AdmissionCheck %s dispatching to clusters %v", admissionCheck.Name, clusters)
+
+	// Make sure the worker clusters are reachable and the admission check is active
+	for _, cluster := range clusters {
+		EventuallyOf(t, MultiKueueCluster(t, cluster), TestTimeoutShort).
+			Should(Field(MultiKueueClusterActive).Equal(true))
+	}
+	EventuallyOf(t, KueueAdmissionCheck(t, admissionCheck.Name), TestTimeoutShort).
+		Should(Field(KueueAdmissionCheckActive).Equal(true))
+
+	return admissionCheck
+}
+
+func MultiKueueCluster(t Test, name string) func(g gomega.Gomega) *kueuev1alpha1.MultiKueueCluster {
+	return func(g gomega.Gomega) *kueuev1alpha1.MultiKueueCluster {
+		cluster, err := t.Client().Kueue().KueueV1alpha1().MultiKueueClusters().Get(t.Ctx(), name, metav1.GetOptions{})
+		g.Expect(WrapError(err, "getting", Ref("MultiKueueCluster", "", name))).NotTo(gomega.HaveOccurred())
+		return cluster
+	}
+}
+
+// MultiKueueClusterActive returns whether the manager cluster is connected to the worker cluster.
+func MultiKueueClusterActive(cluster *kueuev1alpha1.MultiKueueCluster) bool {
+	return meta.IsStatusConditionTrue(cluster.Status.Conditions, kueuev1alpha1.MultiKueueClusterActive)
+}
+
+func KueueAdmissionCheck(t Test, name string) func(g gomega.Gomega) *kueuev1beta1.AdmissionCheck {
+	return func(g gomega.Gomega) *kueuev1beta1.AdmissionCheck {
+		admissionCheck, err := t.Client().Kueue().KueueV1beta1().AdmissionChecks().Get(t.Ctx(), name, metav1.GetOptions{})
+		g.Expect(WrapError(err, "getting", Ref("AdmissionCheck", "", name))).NotTo(gomega.HaveOccurred())
+		return admissionCheck
+	}
+}
+
+func KueueAdmissionCheckActive(admissionCheck *kueuev1beta1.AdmissionCheck) bool {
+	return meta.IsStatusConditionTrue(admissionCheck.Status.Conditions, kueuev1beta1.AdmissionCheckActive)
+}
+
+// KueueWorkloadAdmissionCheckState returns the state of the admission check of the Workload, pending if not evaluated yet.
+func KueueWorkloadAdmissionCheckState(name string) func(workload *kueuev1beta1.Workload) kueuev1beta1.CheckState {
+	return func(workload *kueuev1beta1.Workload) kueuev1beta1.CheckState {
+		for _, check := range workload.Status.AdmissionChecks {
+			if check.Name == name {
+				return check.State
+			}
+		}
+		return kueuev1beta1.CheckStatePending
+	}
+}
@@ -0,0 +1,245 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	awv1beta2 "github.com/project-codeflare/appwrapper/api/v1beta2"
+	. "github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+	kueuev1beta1 "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+	"sigs.k8s.io/yaml"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+
+	kftov1 "github.com/kubeflow/training-operator/pkg/apis/kubeflow.org/v1"
+)
+
+// mustGatherResources are the custom resources of the test namespaces gathered when the test fails. Resources of the
+// APIs not installed in the cluster are skipped.
+var mustGatherResources = []schema.GroupVersionResource{
+	awv1beta2.GroupVersion.WithResource("appwrappers"),
+	rayv1.GroupVersion.WithResource("rayclusters"),
+	rayv1.GroupVersion.WithResource("rayjobs"),
+	kftov1.SchemeGroupVersion.WithResource("pytorchjobs"),
+	notebookResource,
+	kueuev1beta1.GroupVersion.WithResource("workloads"),
+}
+
+// MustGather returns the Test gathering the state of its namespaces when it fails, i.e.:
+//
+//	test := MustGather(WithHooks(t))
+//
+// Before each namespace created with NewTestNamespace is deleted, the specs and statuses of its pods, the logs of their
+// init containers and of the previous runs of their restarted containers, and the YAML of its AppWrappers, RayClusters,
+// RayJobs, PyTorchJobs, Notebooks and Kueue Workloads are stored in a directory per namespace, along with the
+// conditions of the nodes. The logs of the containers and the events are already stored into the output directory of
+// the test by NewTestNamespace, whether the test fails or not.
+// The directory of the test is created in TEST_MUST_GATHER_DIR, or in the output directory of the test when not set.
+// Whether the test fails or not, the digests of the images run by the pods are recorded into the suite summary.
+func MustGather(t Test) Test {
+	return &mustGatherTest{Test: t}
+}
+
+type mustGatherTest struct {
+	Test
+	nodes sync.Once
+}
+
+func (t *mustGatherTest) unwrap() Test {
+	return t.Test
+}
+
+func (t *mustGatherTest) NewTestNamespace(options ...Option[*corev1.Namespace]) *corev1.Namespace {
+	t.T().Helper()
+	namespace := t.Test.NewTestNamespace(options...)
+	// Cleanups run in reverse order, so the namespace is gathered before it is deleted
+	t.T().Cleanup(func() {
+		recordImageDigests(t, namespace.Name)
+		if !t.T().Failed() {
+			return
+		}
+		dir := t.mustGatherDir()
+		t.nodes.Do(func() { gatherNodes(t, dir) })
+		gatherNamespace(t, filepath.Join(dir, namespace.Name), namespace.Name)
+		t.T().Logf("Gathered namespace %s into %s", namespace.Name, dir)
+	})
+	return namespace
+}
+
+func (t *mustGatherTest) mustGatherDir() string {
+	dir := filepath.Join(t.OutputDir(), "must-gather")
+	if parent, ok := GetMustGatherDir(); ok {
+		dir = filepath.Join(parent, strings.ReplaceAll(t.T().Name(), "/", "_"))
+	}
+	return dir
+}
+
+// recordImageDigests records the digests of the images run by the pods of the namespace into the suite summary.
+func recordImageDigests(t Test, namespace string) {
+	t.T().Helper()
+	pods, err := t.Client().Core().CoreV1().Pods(namespace).List(t.Ctx(), metav1.ListOptions{})
+	if err != nil {
+		t.T().Logf("Error listing pods of namespace %s to record their image digests: %v", namespace, err)
+		return
+	}
+	suite.Lock()
+	defer suite.Unlock()
+	for _, pod := range pods.Items {
+		statuses := append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...)
+		for _, status := range statuses {
+			if digest := imageDigest(status.ImageID); digest != "" {
+				suite.images[status.Image] = digest
+			}
+		}
+	}
+}
+
+// imageDigest returns the digest of the image ID reported by the container runtime, i.e. sha256:... of
+// quay.io/modh/training@sha256:..., or the image ID as is when it isn't qualified with a repository.
+func imageDigest(imageID string) string {
+	if i := strings.LastIndex(imageID, "@"); i >= 0 {
+		return imageID[i+1:]
+	}
+	return strings.TrimPrefix(imageID, "docker-pullable://")
+}
+
+func gatherNamespace(t Test, dir, namespace string) {
+	t.T().Helper()
+
+	pods, err := t.Client().Core().CoreV1().Pods(namespace).List(t.Ctx(), metav1.ListOptions{})
+	if err != nil {
+		t.T().Logf("Error listing pods of namespace %s to gather them: %v", namespace, err)
+	} else {
+		for i := range pods.Items {
+			pods.Items[i].ManagedFields = nil
+		}
+		writeMustGatherYAML(t, filepath.Join(dir, "pods.yaml"), pods)
+		for _, pod := range pods.Items {
+			// The logs of the containers are stored by NewTestNamespace, the ones of the init containers aren't
+			for _, container := range pod.Spec.InitContainers {
+				gatherContainerLogs(t, filepath.Join(dir, "logs", pod.Name, container.Name+".log"), pod, container.Name, false)
+			}
+			containers := append(append([]corev1.Container{}, pod.Spec.InitContainers...), pod.Spec.Containers...)
+			for _, container := range containers {
+				if containerRestarted(pod, container.Name) {
+					gatherContainerLogs(t, filepath.Join(dir, "logs", pod.Name, container.Name+".previous.log"), pod, container.Name, true)
+				}
+			}
+		}
+	}
+
+	for _, resource := range mustGatherResources {
+		objects, err := t.Client().Dynamic().Resource(resource).Namespace(namespace).List(t.Ctx(), metav1.ListOptions{})
+		if err != nil {
+			// The API isn't installed in the cluster, or the test can't list it
+			continue
+		}
+		if len(objects.Items) == 0 {
+			continue
+		}
+		for i := range objects.Items {
+			objects.Items[i].SetManagedFields(nil)
+		}
+		writeMustGatherYAML(t, filepath.Join(dir, resource.Resource+".yaml"), objects)
+	}
+}
+
+// gatherNodes stores the conditions, taints and allocatable resources of the nodes.
+func gatherNodes(t Test, dir string) {
+	t.T().Helper()
+
+	nodes, err := t.Client().Core().CoreV1().Nodes().List(t.Ctx(), metav1.ListOptions{})
+	if err != nil {
+		t.T().Logf("Error listing nodes to gather them: %v", err)
+		return
+	}
+	var b strings.Builder
+	for _, node := range nodes.Items {
+		fmt.Fprintf(&b, "%s\n", node.Name)
+		for _, condition := range node.Status.Conditions {
+			fmt.Fprintf(&b, "  %s=%s\t%s\t%s\n", condition.Type, condition.Status, condition.Reason, condition.Message)
+		}
+		for _, taint := range node.Spec.Taints {
+			fmt.Fprintf(&b, "  taint %s=%s:%s\n", taint.Key, taint.Value, taint.Effect)
+		}
+		var allocatable []string
+		for name, quantity := range node.Status.Allocatable {
+			allocatable = append(allocatable, fmt.Sprintf("%s=%s", name, quantity.String()))
+		}
+		sort.Strings(allocatable)
+		fmt.Fprintf(&b, "  allocatable %s\n", strings.Join(allocatable, " "))
+	}
+	writeMustGatherFile(t, filepath.Join(dir, "nodes.txt"), []byte(b.String()))
+}
+
+func gatherContainerLogs(t Test, file string, pod corev1.Pod, container string, previous bool) {
+	t.T().Helper()
+
+	stream, err := t.Client().Core().CoreV1().Pods(pod.Namespace).
+		GetLogs(pod.Name, &corev1.PodLogOptions{Container: container, Previous: previous}).Stream(t.Ctx())
+	if err != nil {
+		// The container hasn't started yet
+		return
+	}
+	defer stream.Close()
+	logs, err := io.ReadAll(stream)
+	if err != nil {
+		t.T().Logf("Error reading logs of container %s of pod %s/%s: %v", container, pod.Namespace, pod.Name, err)
+	}
+	writeMustGatherFile(t, file, logs)
+}
+
+func containerRestarted(pod corev1.Pod, container string) bool {
+	for _, status := range append(append([]corev1.ContainerStatus{}, pod.Status.InitContainerStatuses...), pod.Status.ContainerStatuses...) {
+		if status.Name == container {
+			return status.RestartCount > 0
+		}
+	}
+	return false
+}
+
+func writeMustGatherYAML(t Test, file string, object any) {
+	t.T().Helper()
+	content, err := yaml.Marshal(object)
+	if err != nil {
+		t.T().Logf("Error marshalling %s: %v", filepath.Base(file), err)
+		return
+	}
+	writeMustGatherFile(t, file, content)
+}
+
+// writeMustGatherFile stores the file, errors are only logged as the test has already failed.
+func writeMustGatherFile(t Test, file string, content []byte) {
+	t.T().Helper()
+	if err := os.MkdirAll(filepath.Dir(file), 0o755); err != nil {
+		t.T().Logf("Error creating must-gather directory %s: %v", filepath.Dir(file), err)
+		return
+	}
+	if err := os.WriteFile(file, content, 0o644); err != nil {
+		t.T().Logf("Error writing must-gather file %s: %v", file, err)
+	}
+}
@@ -0,0 +1,137 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// Default port of iperf3 server
+	iperfPort = 5201
+	// Duration and number of parallel streams of the iperf3 measurement
+	iperfDuration = 10 * time.Second
+	iperfStreams  = 4
+)
+
+// NetworkProbeResult holds the bandwidth and latency measured between pods on two nodes.
+type NetworkProbeResult struct {
+	// BitsPerSecond is the bandwidth received by the server
+	BitsPerSecond float64
+	// MeanRTT is the mean TCP round-trip time of the streams, as measured by the sender
+	MeanRTT time.Duration
+}
+
+func (r NetworkProbeResult) Gbps() float64 {
+	return r.BitsPerSecond / 1e9
+}
+
+type iperfReport struct {
+	Error string `json:"error"`
+	End   struct {
+		SumReceived struct {
+			BitsPerSecond float64 `json:"bits_per_second"`
+		} `json:"sum_received"`
+		Streams []struct {
+			Sender struct {
+				// Mean round-trip time in microseconds
+				MeanRTT float64 `json:"mean_rtt"`
+			} `json:"sender"`
+		} `json:"streams"`
+	} `json:"end"`
+}
+
+// MeasureNetworkBandwidth runs iperf3 between a server pod on the server node and a client pod on the client node,
+// over the pod network, and returns the measured bandwidth and round-trip time.
+func MeasureNetworkBandwidth(t Test, namespace string, server, client corev1.Node) NetworkProbeResult {
+	t.T().Helper()
+
+	serverPod := CreatePod(t, newIperfPod(namespace, server.Name, "iperf-server-", "iperf3 -s"))
+	defer deletePod(t, namespace, serverPod.Name)
+	EventuallyOf(t, Pod(t, namespace, serverPod.Name), TestTimeoutMedium).
+		Should(Field(PodPhase).Equal(corev1.PodRunning))
+	serverIP := GetPod(t, namespace, serverPod.Name).Status.PodIP
+
+	// Wait for the server to accept connections before running the measurement
+	command := fmt.Sprintf("until iperf3 -c %[1]s -p %[2]d -t 1 > /dev/null; do sleep 2; done; iperf3 -c %[1]s -p %[2]d -t %[3]d -P %[4]d -J",
+		serverIP, iperfPort, int(iperfDuration.Seconds()), iperfStreams)
+	clientPod := CreatePod(t, newIperfPod(namespace, client.Name, "iperf-client-", command))
+	defer deletePod(t, namespace, clientPod.Name)
+	EventuallyOf(t, Pod(t, namespace, clientPod.Name), TestTimeoutMedium).
+		Should(Field(PodPhase).OneOf(corev1.PodSucceeded, corev1.PodFailed))
+
+	logs := GetPodLogs(t, GetPod(t, namespace, clientPod.Name), corev1.PodLogOptions{})
+	ExpectOf(t, GetPod(t, namespace, clientPod.Name)).To(Field(PodPhase).Equal(corev1.PodSucceeded),
+		"iperf3 client on node %s failed, logs:\n%s", client.Name, logs)
+
+	report := iperfReport{}
+	ExpectNoError(t, json.Unmarshal(logs, &report), "parsing iperf3 report of", Ref("Pod", namespace, clientPod.Name))
+	t.Expect(report.Error).To(gomega.BeEmpty(), "iperf3 measurement from node %s to node %s failed", client.Name, server.Name)
+
+	result := NetworkProbeResult{BitsPerSecond: report.End.SumReceived.BitsPerSecond}
+	if len(report.End.Streams) > 0 {
+		var rtt float64
+		for _, stream := range report.End.Streams {
+			rtt += stream.Sender.MeanRTT
+		}
+		result.MeanRTT = time.Duration(rtt/float64(len(report.End.Streams))) * time.Microsecond
+	}
+	return result
+}
+
+func newIperfPod(namespace, nodeName, generateName, command string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: generateName,
+			Namespace:    namespace,
+		},
+		Spec: corev1.PodSpec{
+			NodeName:      nodeName,
+			RestartPolicy: corev1.RestartPolicyNever,
+			Tolerations: []corev1.Toleration{
+				{
+					Operator: corev1.TolerationOpExists,
+				},
+			},
+			Containers: []corev1.Container{
+				{
+					Name:    "iperf",
+					Image:   GetIperfImage(),
+					Command: []string{"sh", "-c", command},
+					Ports: []corev1.ContainerPort{
+						{
+							ContainerPort: iperfPort,
+							Name:          "iperf",
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func deletePod(t Test, namespace, name string) {
+	_ = t.Client().Core().CoreV1().Pods(namespace).Delete(t.Ctx(), name, metav1.DeleteOptions{GracePeriodSeconds: Ptr(int64(0))})
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	. "github.com/project-codeflare/codeflare-common/support"
+	prometheusv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/model"
+)
+
+const noisyNeighborsSamplingInterval = 15 * time.Second
+
+// contaminations records the reasons the measurements of the tests aren't representative, they are reported in the test result.
+var contaminations = struct {
+	sync.Mutex
+	byTest map[string][]string
+}{byTest: map[string][]string{}}
+
+func recordContamination(testName, reason string) {
+	contaminations.Lock()
+	defer contaminations.Unlock()
+	contaminations.byTest[testName] = append(contaminations.byTest[testName], reason)
+}
+
+func takeContaminations(testName string) []string {
+	contaminations.Lock()
+	defer contaminations.Unlock()
+	// Contaminations of subtests are reported with their parent test
+	var reasons []string
+	for name, recorded := range contaminations.byTest {
+		if name == testName || strings.HasPrefix(name, testName+"/") {
+			reasons = append(reasons, recorded...)
+			delete(contaminations.byTest, name)
+		}
+	}
+	return reasons
+}
+
+// noisyNeighborsQuery is a utilization of the cluster by the other namespaces sampled by the monitor.
+type noisyNeighborsQuery struct {
+	name  string
+	query string
+	max   float64
+	peak  float64
+}
+
+// MonitorNoisyNeighbors samples the utilization of the GPUs and CPUs of the cluster by the other namespaces than the
+// namespace of the performance test while it runs. Once the test finishes, the peak utilizations are recorded as its
+// measurements, and its result is marked as contaminated when they exceed NOISY_NEIGHBORS_MAX_GPU_UTILIZATION or
+// NOISY_NEIGHBORS_MAX_CPU_UTILIZATION, so the measurements of a loaded shared cluster aren't taken for a regression.
+// The test isn't monitored when Prometheus isn't available.
+func MonitorNoisyNeighbors(t Test, namespace string) {
+	t.T().Helper()
+
+	if !PrometheusAvailable(t) {
+		t.T().Logf("Not monitoring noisy neighbors, %s isn't set", prometheusUrlEnvVar)
+		return
+	}
+	api := NewPrometheusClient(t, namespace)
+
+	queries := []*noisyNeighborsQuery{
+		{
+			name: "cpu_utilization_percent",
+			query: fmt.Sprintf(`sum(rate(container_cpu_usage_seconds_total{container!="",namespace!=%q}[2m])) / sum(kube_node_status_allocatable{resource="cpu"}) * 100`,
+				namespace),
+			max: GetNoisyNeighborsMaxCpuUtilization(t),
+		},
+	}
+	if metrics, ok := lookupAcceleratorMetrics(t); ok {
+		queries = append(queries, &noisyNeighborsQuery{
+			name:  "gpu_utilization_percent",
+			query: metrics.OtherNamespacesUtilizationQuery(namespace),
+			max:   GetNoisyNeighborsMaxGpuUtilization(t),
+		})
+	}
+
+	ctx, cancel := context.WithCancel(t.Ctx())
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		ticker := time.NewTicker(noisyNeighborsSamplingInterval)
+		defer ticker.Stop()
+		for {
+			for _, query := range queries {
+				value, err := queryNoisyNeighbors(ctx, api, query.query)
+				if err != nil {
+					if ctx.Err() == nil {
+						t.T().Logf("Error sampling %s of noisy neighbors: %v", query.name, err)
+					}
+					continue
+				}
+				if math.IsNaN(value) {
+					continue
+				}
+				query.peak = max(query.peak, value)
+			}
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+
+	t.T().Cleanup(func() {
+		cancel()
+		<-done
+		for _, query := range queries {
+			RecordMeasurement(t, "noisy_neighbors/peak_"+query.name, query.peak)
+			if query.peak > query.max {
+				reason := fmt.Sprintf("peak %s of other namespaces %.1f above %.1f", query.name, query.peak, query.max)
+				recordContamination(t.T().Name(), reason)
+				t.T().Logf("Measurements contaminated by noisy neighbors, %s", reason)
+			}
+		}
+	})
+}
+
+// queryNoisyNeighbors returns the value of the instant query, no sample meaning no utilization.
+func queryNoisyNeighbors(ctx context.Context, api prometheusv1.API, query string) (float64, error) {
+	result, _, err := api.Query(ctx, query, time.Now())
+	if err != nil {
+		return 0, err
+	}
+	switch value := result.(type) {
+	case *model.Scalar:
+		return float64(value.Value), nil
+	case model.Vector:
+		if len(value) == 0 {
+			return 0, nil
+		}
+		return float64(value[0].Value), nil
+	default:
+		return 0, fmt.Errorf("unexpected result type %s of Prometheus query %s", result.Type(), query)
+	}
+}
@@ -0,0 +1,275 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/onsi/gomega"
+	routev1 "github.com/openshift/api/route/v1"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var notebookResource = schema.GroupVersionResource{Group: "kubeflow.org", Version: "v1", Resource: "notebooks"}
+
+const (
+	// Label set by the notebook controller on the Notebook pods
+	NotebookNameLabel = "notebook-name"
+	// Directory where the workspace PVC is mounted in workbench images
+	NotebookWorkspaceMountPath = "/opt/app-root/src"
+	// Annotation making the notebook controller serve the Jupyter server behind the OAuth proxy, exposed by a Route
+	NotebookInjectOAuthAnnotation = "notebooks.opendatahub.io/inject-oauth"
+)
+
+// NotebookHyperparameters are the training hyperparameters injected into the Notebooks, so the same notebook content
+// serves smoke runs training on a tiny subset of the dataset and nightly runs with a realistic training.
+type NotebookHyperparameters struct {
+	Epochs    int
+	BatchSize int
+	// Fraction of the dataset trained on, from 0 excluded to 1
+	SubsetFraction float64
+}
+
+const (
+	NotebookTrainingProfileSmoke   = "smoke"
+	NotebookTrainingProfileNightly = "nightly"
+)
+
+// notebookTrainingProfiles are the hyperparameters of the notebook training profiles, selected with NOTEBOOK_TRAINING_PROFILE.
+var notebookTrainingProfiles = map[string]NotebookHyperparameters{
+	NotebookTrainingProfileSmoke:   {Epochs: 1, BatchSize: 8, SubsetFraction: 0.01},
+	NotebookTrainingProfileNightly: {Epochs: 3, BatchSize: 32, SubsetFraction: 1},
+}
+
+// Env returns the environment variables the notebook content reads the hyperparameters from.
+func (h NotebookHyperparameters) Env() []corev1.EnvVar {
+	return []corev1.EnvVar{
+		{Name: "EPOCHS", Value: strconv.Itoa(h.Epochs)},
+		{Name: "BATCH_SIZE", Value: strconv.Itoa(h.BatchSize)},
+		{Name: "SUBSET_FRACTION", Value: strconv.FormatFloat(h.SubsetFraction, 'f', -1, 64)},
+	}
+}
+
+func (h NotebookHyperparameters) String() string {
+	return fmt.Sprintf("%d epochs, batch size %d, %g of the dataset", h.Epochs, h.BatchSize, h.SubsetFraction)
+}
+
+// CreateNotebook creates a Notebook running the container, with the workspace PVC mounted into it.
+// The hyperparameters of the notebook training profile are injected into the container, unless already set.
+func CreateNotebook(t Test, namespace, name string, container corev1.Container, workspacePvcName string) *unstructured.Unstructured {
+	t.T().Helper()
+	return createNotebook(t, namespace, name, container, workspacePvcName, nil)
+}
+
+// CreateNotebookWithOAuth creates a Notebook like CreateNotebook, serving the Jupyter server behind the OAuth proxy,
+// as the dashboard does, so it is only accessible through its Route by users allowed to get the Notebook.
+func CreateNotebookWithOAuth(t Test, namespace, name string, container corev1.Container, workspacePvcName string) *unstructured.Unstructured {
+	t.T().Helper()
+	return createNotebook(t, namespace, name, container, workspacePvcName, map[string]any{NotebookInjectOAuthAnnotation: "true"})
+}
+
+func createNotebook(t Test, namespace, name string, container corev1.Container, workspacePvcName string, annotations map[string]any) *unstructured.Unstructured {
+	t.T().Helper()
+
+	container.Name = name
+	hyperparameters := GetNotebookHyperparameters(t)
+	for _, env := range hyperparameters.Env() {
+		if !hasEnvVar(container.Env, env.Name) {
+			container.Env = append(container.Env, env)
+		}
+	}
+	container.VolumeMounts = append(container.VolumeMounts, corev1.VolumeMount{
+		Name:      "workspace",
+		MountPath: NotebookWorkspaceMountPath,
+	})
+	podSpec := corev1.PodSpec{
+		Containers: []corev1.Container{container},
+		Volumes: []corev1.Volume{
+			{
+				Name: "workspace",
+				VolumeSource: corev1.VolumeSource{
+					PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+						ClaimName: workspacePvcName,
+					},
+				},
+			},
+		},
+	}
+	podSpecContent, err := runtime.DefaultUnstructuredConverter.ToUnstructured(&podSpec)
+	ExpectNoError(t, err, "converting pod spec of", Ref("Notebook", namespace, name))
+
+	notebook := &unstructured.Unstructured{
+		Object: map[string]any{
+			"apiVersion": notebookResource.GroupVersion().String(),
+			"kind":       "Notebook",
+			"metadata": map[string]any{
+				"name":      name,
+				"namespace": namespace,
+			},
+			"spec": map[string]any{
+				"template": map[string]any{
+					"spec": podSpecContent,
+				},
+			},
+		},
+	}
+	if annotations != nil {
+		notebook.Object["metadata"].(map[string]any)["annotations"] = annotations
+	}
+
+	notebook, err = t.Client().Dynamic().Resource(notebookResource).Namespace(namespace).Create(t.Ctx(), notebook, metav1.CreateOptions{})
+	ExpectNoError(t, err, "creating", Ref("Notebook", namespace, name))
+	t.T().Logf("Created Notebook %s/%s successfully, training with %s", notebook.GetNamespace(), notebook.GetName(), hyperparameters)
+
+	return notebook
+}
+
+func hasEnvVar(env []corev1.EnvVar, name string) bool {
+	for _, envVar := range env {
+		if envVar.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func Notebook(t Test, namespace, name string) func(g gomega.Gomega) *unstructured.Unstructured {
+	return func(g gomega.Gomega) *unstructured.Unstructured {
+		notebook, err := t.Client().Dynamic().Resource(notebookResource).Namespace(namespace).Get(t.Ctx(), name, metav1.GetOptions{})
+		g.Expect(WrapError(err, "getting", Ref("Notebook", namespace, name))).NotTo(gomega.HaveOccurred())
+		return notebook
+	}
+}
+
+func GetNotebook(t Test, namespace, name string) *unstructured.Unstructured {
+	t.T().Helper()
+	return Notebook(t, namespace, name)(t)
+}
+
+// PatchNotebook applies JSON patch to the Notebook.
+func PatchNotebook(t Test, namespace, name string, patch []byte) *unstructured.Unstructured {
+	t.T().Helper()
+	notebook, err := t.Client().Dynamic().Resource(notebookResource).Namespace(namespace).Patch(t.Ctx(), name, types.JSONPatchType, patch, metav1.PatchOptions{})
+	ExpectNoError(t, err, "patching", Ref("Notebook", namespace, name))
+	t.T().Logf("Patched Notebook %s/%s successfully", namespace, name)
+	return notebook
+}
+
+func DeleteNotebook(t Test, namespace, name string) {
+	t.T().Helper()
+	err := t.Client().Dynamic().Resource(notebookResource).Namespace(namespace).Delete(t.Ctx(), name, metav1.DeleteOptions{})
+	ExpectNoError(t, err, "deleting", Ref("Notebook", namespace, name))
+	t.T().Logf("Deleted Notebook %s/%s successfully", namespace, name)
+}
+
+// NotebookPods returns pods created by the notebook controller for the Notebook.
+func NotebookPods(t Test, namespace, name string) func(g gomega.Gomega) []corev1.Pod {
+	return func(g gomega.Gomega) []corev1.Pod {
+		pods, err := t.Client().Core().CoreV1().Pods(namespace).List(t.Ctx(), metav1.ListOptions{LabelSelector: NotebookNameLabel + "=" + name})
+		g.Expect(WrapError(err, "listing pods of", Ref("Notebook", namespace, name))).NotTo(gomega.HaveOccurred())
+		return pods.Items
+	}
+}
+
+// NotebookOAuthJupyterClient returns a client of the Jupyter server of the Notebook created with CreateNotebookWithOAuth,
+// authenticating through the OAuth proxy of its Route with the token of a ServiceAccount allowed to get the Notebook.
+func NotebookOAuthJupyterClient(t Test, namespace, name string) JupyterClient {
+	t.T().Helper()
+
+	role := CreateRole(t, namespace, []rbacv1.PolicyRule{
+		{
+			Verbs:         []string{"get"},
+			APIGroups:     []string{notebookResource.Group},
+			Resources:     []string{notebookResource.Resource},
+			ResourceNames: []string{name},
+		},
+	})
+	serviceAccount := CreateServiceAccount(t, namespace)
+	CreateRoleBinding(t, namespace, serviceAccount, role)
+	token := CreateTrackedToken(t, namespace, serviceAccount)
+
+	var route *routev1.Route
+	t.Eventually(func(g gomega.Gomega) string {
+		route = Route(t, namespace, name)(g)
+		return route.Spec.Host
+	}, TestTimeoutShort).ShouldNot(gomega.BeEmpty(), "Route of Notebook %s/%s not created by the notebook controller", namespace, name)
+	endpoint := url.URL{Scheme: "https", Host: route.Spec.Host, Path: fmt.Sprintf("/notebook/%s/%s", namespace, name)}
+	t.T().Logf("Connecting to Jupyter server of Notebook %s/%s at %s through the OAuth proxy", namespace, name, endpoint.String())
+
+	return NewJupyterClientWithBearerToken(endpoint, token)
+}
+
+// RunJupyterNotebook executes the code cells of the notebook at the path with the kernel, in order, logging their outputs
+// as they come. The test fails on the first cell raising an error, reporting the cell and the traceback.
+func RunJupyterNotebook(t Test, client JupyterClient, kernelID, path string, timeout time.Duration) []*JupyterExecutionResult {
+	t.T().Helper()
+
+	cells, err := client.NotebookCodeCells(path)
+	t.Expect(err).NotTo(gomega.HaveOccurred(), "Error reading notebook %s", path)
+	t.T().Logf("Running notebook %s with %d code cells", path, len(cells))
+
+	deadline := time.Now().Add(timeout)
+	var results []*JupyterExecutionResult
+	for i, cell := range cells {
+		output := &notebookCellOutput{t: t, prefix: fmt.Sprintf("[%s cell %d] ", path, i+1)}
+		result, err := client.ExecuteStreaming(kernelID, cell, time.Until(deadline), output)
+		output.flush()
+		t.Expect(err).NotTo(gomega.HaveOccurred(), "Error executing cell %d of notebook %s:\n%s", i+1, path, cell)
+		if result.Error != nil {
+			t.T().Fatalf("Cell %d of notebook %s raised %v:\n%s\n%s", i+1, path, result.Error, cell, strings.Join(result.Error.Traceback, "\n"))
+		}
+		results = append(results, result)
+	}
+	return results
+}
+
+// notebookCellOutput logs the outputs of a notebook cell line by line.
+type notebookCellOutput struct {
+	t      Test
+	prefix string
+	line   strings.Builder
+}
+
+func (o *notebookCellOutput) Write(p []byte) (int, error) {
+	for _, b := range p {
+		if b == '\n' {
+			o.flush()
+			continue
+		}
+		o.line.WriteByte(b)
+	}
+	return len(p), nil
+}
+
+func (o *notebookCellOutput) flush() {
+	if o.line.Len() > 0 {
+		o.t.T().Log(o.prefix + o.line.String())
+		o.line.Reset()
+	}
+}
@@ -0,0 +1,102 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+)
+
+const (
+	// The environment variable for webhook URL the suite summary is posted to, i.e. Slack incoming webhook
+	summaryWebhookURLEnvVar = "TEST_SUMMARY_WEBHOOK_URL"
+	// The environment variable for URL where test artifacts are published, i.e. CI job artifacts
+	artifactsURLEnvVar = "TEST_ARTIFACTS_URL"
+)
+
+// notifySuiteSummary posts the suite summary to the configured webhook, it does nothing if no webhook is configured.
+func notifySuiteSummary(summary SuiteSummary) {
+	webhookURL, ok := os.LookupEnv(summaryWebhookURLEnvVar)
+	if !ok || webhookURL == "" {
+		return
+	}
+
+	payload, err := json.Marshal(map[string]string{"text": formatSuiteSummary(summary)})
+	if err != nil {
+		fmt.Printf("Error marshalling suite summary: %v\n", err)
+		return
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Post(webhookURL, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		fmt.Printf("Error posting suite summary to webhook: %v\n", err)
+		return
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		fmt.Printf("Incorrect response code %d when posting suite summary to webhook\n", resp.StatusCode)
+	}
+}
+
+func formatSuiteSummary(summary SuiteSummary) string {
+	var b strings.Builder
+	status := "PASSED"
+	if summary.Count(TestFailed) > 0 {
+		status = "FAILED"
+	}
+	fmt.Fprintf(&b, "*%s* suite %s in %s: %d passed, %d failed, %d skipped\n", summary.Suite, status, summary.Duration.Round(time.Second),
+		summary.Count(TestPassed), summary.Count(TestFailed), summary.Count(TestSkipped))
+
+	for _, cluster := range summary.Clusters {
+		clusterSummary := summary.ForCluster(cluster)
+		fmt.Fprintf(&b, "Cluster %s: %d passed, %d failed, %d skipped\n", cluster,
+			clusterSummary.Count(TestPassed), clusterSummary.Count(TestFailed), clusterSummary.Count(TestSkipped))
+	}
+	if failed := summary.Failed(); len(failed) > 0 {
+		fmt.Fprintf(&b, "Failed: %s\n", strings.Join(failed, ", "))
+	}
+	if xfailed := summary.Count(TestXFailed); xfailed > 0 {
+		fmt.Fprintf(&b, "Expected failures of known bugs: %d\n", xfailed)
+	}
+	if xpassed := summary.XPassed(); len(xpassed) > 0 {
+		fmt.Fprintf(&b, "Unexpectedly passed, check the bugs are fixed and remove XFail: %s\n", strings.Join(xpassed, ", "))
+	}
+	if contaminated := summary.Contaminated(); len(contaminated) > 0 {
+		fmt.Fprintf(&b, "Measurements contaminated by noisy neighbors: %s\n", strings.Join(contaminated, ", "))
+	}
+	if flaky := summary.Flaky(); len(flaky) > 0 {
+		fmt.Fprintf(&b, "Flaky (failed then passed on retry): %s\n", strings.Join(flaky, ", "))
+	}
+	if slowest := summary.Slowest(3); len(slowest) > 0 {
+		b.WriteString("Slowest:")
+		for _, result := range slowest {
+			fmt.Fprintf(&b, " %s (%s)", result.DisplayName(), result.Duration.Round(time.Second))
+		}
+		b.WriteString("\n")
+	}
+	if artifactsURL, ok := os.LookupEnv(artifactsURLEnvVar); ok {
+		fmt.Fprintf(&b, "Artifacts: %s\n", artifactsURL)
+	}
+
+	return b.String()
+}
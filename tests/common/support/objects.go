@@ -0,0 +1,83 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// NamespaceCredentialObjects returns the Secrets, ServiceAccounts, Services, Ingresses and, on OpenShift, Routes
+// in the namespace. These are the objects controllers create to expose and authenticate workloads,
+// so they must be garbage collected together with the workload.
+func NamespaceCredentialObjects(t Test, namespace string) func(g gomega.Gomega) []ObjectRef {
+	openShift := IsOpenShift(t)
+	return func(g gomega.Gomega) []ObjectRef {
+		var refs []ObjectRef
+
+		secrets, err := t.Client().Core().CoreV1().Secrets(namespace).List(t.Ctx(), metav1.ListOptions{})
+		g.Expect(WrapError(err, "listing", Ref("Secret", namespace, ""))).NotTo(gomega.HaveOccurred())
+		for _, secret := range secrets.Items {
+			refs = append(refs, Ref("Secret", namespace, secret.Name))
+		}
+
+		serviceAccounts, err := t.Client().Core().CoreV1().ServiceAccounts(namespace).List(t.Ctx(), metav1.ListOptions{})
+		g.Expect(WrapError(err, "listing", Ref("ServiceAccount", namespace, ""))).NotTo(gomega.HaveOccurred())
+		for _, serviceAccount := range serviceAccounts.Items {
+			refs = append(refs, Ref("ServiceAccount", namespace, serviceAccount.Name))
+		}
+
+		services, err := t.Client().Core().CoreV1().Services(namespace).List(t.Ctx(), metav1.ListOptions{})
+		g.Expect(WrapError(err, "listing", Ref("Service", namespace, ""))).NotTo(gomega.HaveOccurred())
+		for _, service := range services.Items {
+			refs = append(refs, Ref("Service", namespace, service.Name))
+		}
+
+		ingresses, err := t.Client().Core().NetworkingV1().Ingresses(namespace).List(t.Ctx(), metav1.ListOptions{})
+		g.Expect(WrapError(err, "listing", Ref("Ingress", namespace, ""))).NotTo(gomega.HaveOccurred())
+		for _, ingress := range ingresses.Items {
+			refs = append(refs, Ref("Ingress", namespace, ingress.Name))
+		}
+
+		if openShift {
+			routes, err := t.Client().Route().RouteV1().Routes(namespace).List(t.Ctx(), metav1.ListOptions{})
+			g.Expect(WrapError(err, "listing", Ref("Route", namespace, ""))).NotTo(gomega.HaveOccurred())
+			for _, route := range routes.Items {
+				refs = append(refs, Ref("Route", namespace, route.Name))
+			}
+		}
+
+		return refs
+	}
+}
+
+// ObjectsAdded returns the objects which are in after but not in before.
+func ObjectsAdded(before, after []ObjectRef) []ObjectRef {
+	existing := map[ObjectRef]bool{}
+	for _, ref := range before {
+		existing[ref] = true
+	}
+	var added []ObjectRef
+	for _, ref := range after {
+		if !existing[ref] {
+			added = append(added, ref)
+		}
+	}
+	return added
+}
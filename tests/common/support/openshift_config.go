@@ -0,0 +1,56 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	configv1 "github.com/openshift/api/config/v1"
+	configv1client "github.com/openshift/client-go/config/clientset/versioned/typed/config/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// clusterConfigName is the name of the singleton config.openshift.io resources of the cluster
+const clusterConfigName = "cluster"
+
+// ConfigClient returns the typed client of the OpenShift config.openshift.io API, created from the rest config of the
+// test, so it talks to the same cluster as the other clients of the test.
+func ConfigClient(t Test) configv1client.ConfigV1Interface {
+	t.T().Helper()
+	if configured, ok := configured(t); ok {
+		client, err := configured.ConfigClient()
+		ExpectNoError(t, err, "creating client for", Ref("API group", "", configv1.GroupName))
+		return client
+	}
+	cfg, err := restConfig(t)
+	ExpectNoError(t, err, "loading client configuration for", Ref("API group", "", configv1.GroupName))
+	client, err := configv1client.NewForConfig(cfg)
+	ExpectNoError(t, err, "creating client for", Ref("API group", "", configv1.GroupName))
+	return client
+}
+
+// GetClusterApiUrl returns the URL of the API server of the cluster, read from its Infrastructure config.
+func GetClusterApiUrl(t Test) string {
+	t.T().Helper()
+	infrastructure, err := ConfigClient(t).Infrastructures().Get(t.Ctx(), clusterConfigName, metav1.GetOptions{})
+	ExpectNoError(t, err, "getting", Ref("Infrastructure", "", clusterConfigName))
+	t.Expect(infrastructure.Status.APIServerURL).NotTo(gomega.BeEmpty(), "Infrastructure %s has no API server URL", clusterConfigName)
+	t.T().Logf("OpenShift API URL: %s", infrastructure.Status.APIServerURL)
+	return infrastructure.Status.APIServerURL
+}
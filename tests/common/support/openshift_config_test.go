@@ -0,0 +1,48 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/onsi/gomega"
+
+	"k8s.io/client-go/rest"
+)
+
+// TestClusterConfig makes sure the API server URL is read from the cluster of the test, with the client cached by the
+// configured Test decorated by the hooks.
+func TestClusterConfig(t *testing.T) {
+	g := gomega.NewWithT(t)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		switch r.URL.Path {
+		case "/apis/config.openshift.io/v1/infrastructures/cluster":
+			w.Write([]byte(`{"apiVersion": "config.openshift.io/v1", "kind": "Infrastructure", "metadata": {"name": "cluster"},
+				"status": {"apiServerURL": "https://api.example.com:6443"}}`))
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	t.Cleanup(server.Close)
+
+	test := MustGather(recordAssertionFailures(DryRunFirst(withRestConfig(t, &rest.Config{Host: server.URL}))))
+	g.Expect(ConfigClient(test)).To(gomega.BeIdenticalTo(ConfigClient(test)))
+	g.Expect(GetClusterApiUrl(test)).To(gomega.Equal("https://api.example.com:6443"))
+}
@@ -0,0 +1,85 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"encoding/json"
+
+	"github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// WithPatched applies JSON merge patch to the cluster-scoped object, runs fn and restores the object afterwards.
+// The object is restored even if fn fails the test or panics, so the shared cluster isn't left modified. Only the
+// fields of the patch are restored, with a reverse merge patch, so the changes made by controllers and GitOps
+// meanwhile are kept, and the field managers of the other fields aren't taken over.
+func WithPatched(t Test, resource schema.GroupVersionResource, name string, patch []byte, fn func()) {
+	t.T().Helper()
+
+	client := t.Client().Dynamic().Resource(resource)
+	original, err := client.Get(t.Ctx(), name, metav1.GetOptions{})
+	ExpectNoError(t, err, "getting", Ref(resource.Resource, "", name))
+
+	var patchFields map[string]any
+	t.Expect(json.Unmarshal(patch, &patchFields)).To(gomega.Succeed(), "Patch of %s %s isn't a JSON object", resource.Resource, name)
+	reverse, err := json.Marshal(reverseMergePatch(original.Object, patchFields))
+	t.Expect(err).NotTo(gomega.HaveOccurred())
+
+	defer restorePatched(t, resource, name, reverse)
+
+	_, err = client.Patch(t.Ctx(), name, types.MergePatchType, patch, metav1.PatchOptions{})
+	ExpectNoError(t, err, "patching", Ref(resource.Resource, "", name))
+	t.T().Logf("Patched %s %s successfully", resource.Resource, name)
+
+	fn()
+}
+
+func restorePatched(t Test, resource schema.GroupVersionResource, name string, reverse []byte) {
+	t.T().Helper()
+
+	_, err := t.Client().Dynamic().Resource(resource).Patch(t.Ctx(), name, types.MergePatchType, reverse, metav1.PatchOptions{})
+	t.Expect(err).NotTo(gomega.HaveOccurred(), "Failed to restore %s %s, the cluster may be left modified", resource.Resource, name)
+	t.T().Logf("Restored %s %s successfully", resource.Resource, name)
+}
+
+// reverseMergePatch returns the JSON merge patch restoring the keys of the patch to their values in the original
+// object, the keys added by the patch being removed with nulls. Lists are replaced as a whole by merge patches,
+// so they are restored as a whole.
+func reverseMergePatch(original, patch map[string]any) map[string]any {
+	reverse := map[string]any{}
+	for key, value := range patch {
+		originalValue, ok := original[key]
+		if !ok {
+			if value != nil {
+				reverse[key] = nil
+			}
+			continue
+		}
+		patchMap, patchIsMap := value.(map[string]any)
+		originalMap, originalIsMap := originalValue.(map[string]any)
+		if patchIsMap && originalIsMap {
+			reverse[key] = reverseMergePatch(originalMap, patchMap)
+			continue
+		}
+		reverse[key] = originalValue
+	}
+	return reverse
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/onsi/gomega"
+)
+
+func TestReverseMergePatch(t *testing.T) {
+	original := `{
+		"metadata": {"name": "cq", "labels": {"team": "a"}},
+		"spec": {
+			"stopPolicy": "None",
+			"namespaceSelector": {},
+			"resourceGroups": [{"coveredResources": ["cpu"]}]
+		}
+	}`
+
+	tests := []struct {
+		name    string
+		patch   string
+		reverse string
+	}{
+		{
+			name:    "changed field restored",
+			patch:   `{"spec": {"stopPolicy": "Hold"}}`,
+			reverse: `{"spec": {"stopPolicy": "None"}}`,
+		},
+		{
+			name:    "added fields removed",
+			patch:   `{"spec": {"cohort": "shared"}, "metadata": {"annotations": {"note": "test"}}}`,
+			reverse: `{"spec": {"cohort": null}, "metadata": {"annotations": null}}`,
+		},
+		{
+			name:    "added key of existing map removed",
+			patch:   `{"metadata": {"labels": {"owner": "test", "team": "b"}}}`,
+			reverse: `{"metadata": {"labels": {"owner": null, "team": "a"}}}`,
+		},
+		{
+			name:    "removed field restored",
+			patch:   `{"metadata": {"labels": {"team": null}}}`,
+			reverse: `{"metadata": {"labels": {"team": "a"}}}`,
+		},
+		{
+			name:    "removal of missing field is a no-op",
+			patch:   `{"spec": {"cohort": null}}`,
+			reverse: `{"spec": {}}`,
+		},
+		{
+			name:    "list restored as a whole",
+			patch:   `{"spec": {"resourceGroups": [{"coveredResources": ["cpu", "memory"]}]}}`,
+			reverse: `{"spec": {"resourceGroups": [{"coveredResources": ["cpu"]}]}}`,
+		},
+		{
+			name:    "map replacing scalar restored",
+			patch:   `{"spec": {"stopPolicy": {"invalid": true}}}`,
+			reverse: `{"spec": {"stopPolicy": "None"}}`,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+			var originalObject, patch map[string]any
+			g.Expect(json.Unmarshal([]byte(original), &originalObject)).To(gomega.Succeed())
+			g.Expect(json.Unmarshal([]byte(tt.patch), &patch)).To(gomega.Succeed())
+
+			reverse, err := json.Marshal(reverseMergePatch(originalObject, patch))
+			g.Expect(err).NotTo(gomega.HaveOccurred())
+			g.Expect(reverse).To(gomega.MatchJSON(tt.reverse))
+		})
+	}
+}
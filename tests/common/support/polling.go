@@ -0,0 +1,137 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"math"
+	"math/rand"
+	"time"
+
+	"github.com/onsi/gomega"
+	"github.com/onsi/gomega/types"
+	. "github.com/project-codeflare/codeflare-common/support"
+)
+
+// pollingTick is the Gomega polling interval used when polling with a strategy,
+// the effective polling cadence is driven by the strategy itself.
+const pollingTick = 10 * time.Millisecond
+
+// PollingStrategy computes the interval to wait before the next poll, attempt starts at 0.
+type PollingStrategy interface {
+	NextInterval(attempt int) time.Duration
+}
+
+type fixedPolling struct {
+	interval time.Duration
+}
+
+func (p fixedPolling) NextInterval(int) time.Duration {
+	return p.interval
+}
+
+type exponentialPolling struct {
+	initial time.Duration
+	max     time.Duration
+	factor  float64
+}
+
+func (p exponentialPolling) NextInterval(attempt int) time.Duration {
+	interval := float64(p.initial) * math.Pow(p.factor, float64(attempt))
+	if interval > float64(p.max) {
+		return p.max
+	}
+	return time.Duration(interval)
+}
+
+type jitterPolling struct {
+	strategy PollingStrategy
+	fraction float64
+}
+
+func (p jitterPolling) NextInterval(attempt int) time.Duration {
+	interval := p.strategy.NextInterval(attempt)
+	jitter := (rand.Float64()*2 - 1) * p.fraction * float64(interval)
+	return interval + time.Duration(jitter)
+}
+
+func FixedPolling(interval time.Duration) PollingStrategy {
+	return fixedPolling{interval: interval}
+}
+
+// ExponentialPolling multiplies the interval by factor after each poll, up to the max interval.
+func ExponentialPolling(initial, max time.Duration, factor float64) PollingStrategy {
+	return exponentialPolling{initial: initial, max: max, factor: factor}
+}
+
+// WithJitter randomizes the intervals of the strategy by +/- fraction, so concurrent waits don't poll in lockstep.
+func WithJitter(strategy PollingStrategy, fraction float64) PollingStrategy {
+	return jitterPolling{strategy: strategy, fraction: fraction}
+}
+
+// Polling strategies per resource kind, tuned to reduce the API server load for long-running waits.
+var resourcePollingStrategies = map[string]PollingStrategy{
+	"Pod":          WithJitter(ExponentialPolling(1*time.Second, 10*time.Second, 1.5), 0.2),
+	"Job":          WithJitter(ExponentialPolling(1*time.Second, 15*time.Second, 1.5), 0.2),
+	"PyTorchJob":   WithJitter(ExponentialPolling(1*time.Second, 15*time.Second, 1.5), 0.2),
+	"RayCluster":   WithJitter(ExponentialPolling(1*time.Second, 15*time.Second, 1.5), 0.2),
+	"RayJob":       WithJitter(ExponentialPolling(1*time.Second, 15*time.Second, 1.5), 0.2),
+	"Workload":     WithJitter(ExponentialPolling(1*time.Second, 10*time.Second, 1.5), 0.2),
+	"AppWrapper":   WithJitter(ExponentialPolling(1*time.Second, 10*time.Second, 1.5), 0.2),
+	"ClusterQueue": WithJitter(ExponentialPolling(1*time.Second, 10*time.Second, 1.5), 0.2),
+}
+
+// PollingStrategyFor returns the default polling strategy for the resource kind, fixed polling at the configured
+// polling interval otherwise.
+func PollingStrategyFor(kind string) PollingStrategy {
+	if strategy, ok := resourcePollingStrategies[kind]; ok {
+		return strategy
+	}
+	return FixedPolling(pollingInterval)
+}
+
+// EventuallyWithPolling is the equivalent of Test.Eventually, polling the actual function according to the strategy.
+func EventuallyWithPolling[T any](t Test, actual func(g gomega.Gomega) T, timeout time.Duration, strategy PollingStrategy) types.AsyncAssertion {
+	deadline := time.Now().Add(timeout)
+	return t.Eventually(throttle(t, actual, strategy, deadline), timeout).WithPolling(pollingTick)
+}
+
+// EventuallyOfWithPolling is the equivalent of EventuallyOf, polling the actual function according to the strategy.
+func EventuallyOfWithPolling[T any](t Test, actual func(g gomega.Gomega) T, timeout time.Duration, strategy PollingStrategy) TypedAsyncAssertion[T] {
+	return TypedAsyncAssertion[T]{assertion: EventuallyWithPolling(t, actual, timeout, strategy)}
+}
+
+func throttle[T any](t Test, actual func(g gomega.Gomega) T, strategy PollingStrategy, deadline time.Time) func(g gomega.Gomega) T {
+	attempt := 0
+	var next time.Time
+	return func(g gomega.Gomega) T {
+		if attempt > 0 {
+			wait := time.Until(next)
+			if untilDeadline := time.Until(deadline); wait > untilDeadline {
+				wait = untilDeadline
+			}
+			if wait > 0 {
+				select {
+				case <-time.After(wait):
+				case <-t.Ctx().Done():
+				}
+			}
+		}
+		next = time.Now().Add(strategy.NextInterval(attempt))
+		attempt++
+		return actual(g)
+	}
+}
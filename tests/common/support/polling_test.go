@@ -0,0 +1,125 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+)
+
+func TestPollingStrategies(t *testing.T) {
+	tests := []struct {
+		name      string
+		strategy  PollingStrategy
+		intervals []time.Duration
+	}{
+		{
+			name:      "fixed",
+			strategy:  FixedPolling(2 * time.Second),
+			intervals: []time.Duration{2 * time.Second, 2 * time.Second, 2 * time.Second},
+		},
+		{
+			name:     "exponential backoff",
+			strategy: ExponentialPolling(time.Second, time.Minute, 2),
+			intervals: []time.Duration{
+				time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 16 * time.Second, 32 * time.Second,
+			},
+		},
+		{
+			name:     "exponential backoff capped at the max interval",
+			strategy: ExponentialPolling(time.Second, 10*time.Second, 1.5),
+			intervals: []time.Duration{
+				time.Second, 1500 * time.Millisecond, 2250 * time.Millisecond, 3375 * time.Millisecond,
+				5062500 * time.Microsecond, 7593750 * time.Microsecond, 10 * time.Second, 10 * time.Second,
+			},
+		},
+		{
+			name:      "exponential without backoff",
+			strategy:  ExponentialPolling(time.Second, 10*time.Second, 1),
+			intervals: []time.Duration{time.Second, time.Second, time.Second},
+		},
+		{
+			name:      "no jitter",
+			strategy:  WithJitter(FixedPolling(time.Second), 0),
+			intervals: []time.Duration{time.Second, time.Second},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+			for attempt, interval := range tt.intervals {
+				g.Expect(tt.strategy.NextInterval(attempt)).To(gomega.Equal(interval), "attempt %d", attempt)
+			}
+		})
+	}
+}
+
+func TestWithJitterBounds(t *testing.T) {
+	tests := []struct {
+		name     string
+		strategy PollingStrategy
+		fraction float64
+	}{
+		{name: "fixed", strategy: FixedPolling(time.Second), fraction: 0.2},
+		{name: "exponential", strategy: ExponentialPolling(time.Second, 10*time.Second, 1.5), fraction: 0.2},
+		{name: "full jitter", strategy: FixedPolling(time.Second), fraction: 1},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+			jittered := WithJitter(tt.strategy, tt.fraction)
+			for attempt := 0; attempt < 10; attempt++ {
+				interval := tt.strategy.NextInterval(attempt)
+				spread := time.Duration(tt.fraction * float64(interval))
+				intervals := map[time.Duration]bool{}
+				for i := 0; i < 100; i++ {
+					next := jittered.NextInterval(attempt)
+					g.Expect(next).To(gomega.BeNumerically("~", interval, spread), "attempt %d", attempt)
+					intervals[next] = true
+				}
+				// The intervals are randomized, so concurrent waits don't poll in lockstep
+				g.Expect(len(intervals)).To(gomega.BeNumerically(">", 1), "attempt %d", attempt)
+			}
+		})
+	}
+}
+
+func TestPollingStrategyFor(t *testing.T) {
+	tests := []struct {
+		kind    string
+		initial time.Duration
+		max     time.Duration
+		jitter  float64
+	}{
+		{kind: "Pod", initial: time.Second, max: 10 * time.Second, jitter: 0.2},
+		{kind: "Job", initial: time.Second, max: 15 * time.Second, jitter: 0.2},
+		{kind: "RayCluster", initial: time.Second, max: 15 * time.Second, jitter: 0.2},
+		{kind: "Workload", initial: time.Second, max: 10 * time.Second, jitter: 0.2},
+		// Kinds without a tuned strategy are polled at the configured polling interval
+		{kind: "ConfigMap", initial: pollingInterval, max: pollingInterval},
+	}
+	for _, tt := range tests {
+		t.Run(tt.kind, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+			strategy := PollingStrategyFor(tt.kind)
+			g.Expect(strategy.NextInterval(0)).To(gomega.BeNumerically("~", tt.initial, time.Duration(tt.jitter*float64(tt.initial))))
+			g.Expect(strategy.NextInterval(100)).To(gomega.BeNumerically("~", tt.max, time.Duration(tt.jitter*float64(tt.max))))
+		})
+	}
+}
@@ -0,0 +1,229 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+const (
+	// The environment variable for address the progress dashboard listens on, i.e. ":8080", the dashboard is disabled if not set
+	progressAddressEnvVar = "TEST_PROGRESS_ADDRESS"
+	// Number of output lines served by the progress dashboard
+	progressLogTailLines = 100
+)
+
+type TestPhase struct {
+	Name     string        `json:"name"`
+	Start    time.Time     `json:"start"`
+	Duration time.Duration `json:"duration"`
+}
+
+type TestProgress struct {
+	Name   string      `json:"name"`
+	Start  time.Time   `json:"start"`
+	Phases []TestPhase `json:"phases"`
+}
+
+type SuiteProgress struct {
+	Suite   string         `json:"suite"`
+	Elapsed time.Duration  `json:"elapsed"`
+	Running []TestProgress `json:"running"`
+	Results []TestResult   `json:"results"`
+	Log     []string       `json:"log"`
+}
+
+// progress holds the state of the running tests, it is only populated when the progress dashboard is enabled.
+var progress = struct {
+	sync.Mutex
+	enabled bool
+	start   time.Time
+	running map[string]*TestProgress
+	log     []string
+}{}
+
+// Phase marks the beginning of a new phase of the test, i.e. "Create RayCluster", ending the previous one.
+// Phases and their timings are served by the progress dashboard.
+func Phase(t *testing.T, name string) {
+	progress.Lock()
+	defer progress.Unlock()
+	test, ok := progress.running[t.Name()]
+	if !ok {
+		return
+	}
+	now := time.Now()
+	if n := len(test.Phases); n > 0 {
+		test.Phases[n-1].Duration = now.Sub(test.Phases[n-1].Start)
+	}
+	test.Phases = append(test.Phases, TestPhase{Name: name, Start: now})
+}
+
+func progressTestStarted(t *testing.T) {
+	progress.Lock()
+	defer progress.Unlock()
+	if !progress.enabled {
+		return
+	}
+	progress.running[t.Name()] = &TestProgress{Name: t.Name(), Start: time.Now()}
+}
+
+func progressTestFinished(t *testing.T) {
+	progress.Lock()
+	defer progress.Unlock()
+	delete(progress.running, t.Name())
+}
+
+// startProgressDashboard serves the progress of the suite over HTTP and captures the suite output.
+// It returns the function stopping the dashboard, it does nothing if the dashboard isn't enabled.
+func startProgressDashboard() func() {
+	address, ok := os.LookupEnv(progressAddressEnvVar)
+	if !ok || address == "" {
+		return func() {}
+	}
+
+	progress.Lock()
+	progress.enabled = true
+	progress.start = time.Now()
+	progress.running = map[string]*TestProgress{}
+	progress.Unlock()
+
+	restoreOutput := captureProgressLog()
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; charset=utf-8")
+		fmt.Fprint(w, formatSuiteProgress(currentSuiteProgress()))
+	})
+	mux.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if err := json.NewEncoder(w).Encode(currentSuiteProgress()); err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+	})
+	server := &http.Server{Addr: address, Handler: mux, ReadHeaderTimeout: 10 * time.Second}
+	go func() {
+		if err := server.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			fmt.Fprintf(os.Stderr, "Error serving progress dashboard: %v\n", err)
+		}
+	}()
+	fmt.Fprintf(os.Stderr, "Serving test progress dashboard on %s\n", address)
+
+	return func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := server.Shutdown(ctx); err != nil {
+			fmt.Fprintf(os.Stderr, "Error stopping progress dashboard: %v\n", err)
+		}
+		restoreOutput()
+	}
+}
+
+// captureProgressLog tees the standard output, where the testing package writes the test logs, into the progress log.
+func captureProgressLog() func() {
+	stdout := os.Stdout
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error capturing test output for progress dashboard: %v\n", err)
+		return func() {}
+	}
+	os.Stdout = writer
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		scanner := bufio.NewScanner(io.TeeReader(reader, stdout))
+		for scanner.Scan() {
+			progress.Lock()
+			progress.log = append(progress.log, scanner.Text())
+			if len(progress.log) > progressLogTailLines {
+				progress.log = progress.log[len(progress.log)-progressLogTailLines:]
+			}
+			progress.Unlock()
+		}
+	}()
+
+	return func() {
+		os.Stdout = stdout
+		writer.Close()
+		<-done
+		reader.Close()
+	}
+}
+
+func currentSuiteProgress() SuiteProgress {
+	// The leaks are only read, they are taken for the suite summary once the suite finishes
+	summary := newSuiteSummary(0, nil, clusterScopedLeaks())
+
+	progress.Lock()
+	defer progress.Unlock()
+	now := time.Now()
+	suiteProgress := SuiteProgress{
+		Suite:   summary.Suite,
+		Elapsed: now.Sub(progress.start),
+		Results: summary.Results,
+		Log:     append([]string(nil), progress.log...),
+	}
+	for _, test := range progress.running {
+		running := *test
+		running.Phases = append([]TestPhase(nil), test.Phases...)
+		if n := len(running.Phases); n > 0 {
+			running.Phases[n-1].Duration = now.Sub(running.Phases[n-1].Start)
+		}
+		suiteProgress.Running = append(suiteProgress.Running, running)
+	}
+	sort.Slice(suiteProgress.Running, func(i, j int) bool {
+		return suiteProgress.Running[i].Start.Before(suiteProgress.Running[j].Start)
+	})
+	return suiteProgress
+}
+
+func formatSuiteProgress(suiteProgress SuiteProgress) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Suite %s running for %s\n\n", suiteProgress.Suite, suiteProgress.Elapsed.Round(time.Second))
+
+	b.WriteString("Running:\n")
+	for _, test := range suiteProgress.Running {
+		fmt.Fprintf(&b, "  %s (%s)\n", test.Name, time.Since(test.Start).Round(time.Second))
+		for _, phase := range test.Phases {
+			fmt.Fprintf(&b, "    %s (%s)\n", phase.Name, phase.Duration.Round(time.Second))
+		}
+	}
+
+	b.WriteString("\nFinished:\n")
+	for _, result := range suiteProgress.Results {
+		fmt.Fprintf(&b, "  %s %s (%s)\n", strings.ToUpper(string(result.Status)), result.DisplayName(), result.Duration.Round(time.Second))
+	}
+
+	b.WriteString("\nLog:\n")
+	for _, line := range suiteProgress.Log {
+		fmt.Fprintf(&b, "  %s\n", line)
+	}
+
+	return b.String()
+}
@@ -0,0 +1,40 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"testing"
+
+	"github.com/onsi/gomega"
+)
+
+// TestSuiteProgressKeepsLeaks makes sure reading the progress of the suite, i.e. from the dashboard, doesn't drop
+// the cluster-scoped objects left over from the suite summary.
+func TestSuiteProgressKeepsLeaks(t *testing.T) {
+	g := gomega.NewWithT(t)
+	t.Cleanup(func() { takeClusterScopedLeaks() })
+	clusterScopedObjects.Lock()
+	clusterScopedObjects.leaked = []string{"ClusterQueue cq, not deleted, delete it manually"}
+	clusterScopedObjects.Unlock()
+
+	currentSuiteProgress()
+	currentSuiteProgress()
+
+	summary := newSuiteSummary(0, nil, takeClusterScopedLeaks())
+	g.Expect(summary.Leaked).To(gomega.Equal([]string{"ClusterQueue cq, not deleted, delete it manually"}))
+	g.Expect(clusterScopedLeaks()).To(gomega.BeEmpty())
+}
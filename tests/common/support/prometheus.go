@@ -0,0 +1,145 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"crypto/tls"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+	prometheusapi "github.com/prometheus/client_golang/api"
+	prometheusv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	"github.com/prometheus/common/config"
+	"github.com/prometheus/common/model"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// Namespace and Routes of the Thanos querier aggregating the platform and user workload monitoring,
+	// and of the Alertmanager, on OpenShift
+	openShiftMonitoringNamespace = "openshift-monitoring"
+	thanosQuerierRoute           = "thanos-querier"
+	alertmanagerRoute            = "alertmanager-main"
+	// ClusterRole granting read access to the cluster monitoring metrics
+	clusterMonitoringViewClusterRole = "cluster-monitoring-view"
+)
+
+// NewPrometheusClient returns a client of the Prometheus API configured with PROMETHEUS_URL,
+// defaulting to the Thanos querier on OpenShift. The queries are authorized with a token of a service account
+// created in the namespace and bound to the cluster-monitoring-view ClusterRole.
+func NewPrometheusClient(t Test, namespace string) prometheusv1.API {
+	t.T().Helper()
+
+	address, ok := GetPrometheusUrl()
+	address = monitoringEndpoint(t, address, ok, prometheusUrlEnvVar, thanosQuerierRoute)
+	token := createMonitoringViewerToken(t, namespace)
+
+	client, err := prometheusapi.NewClient(prometheusapi.Config{
+		Address: address,
+		RoundTripper: config.NewAuthorizationCredentialsRoundTripper("Bearer", config.Secret(token),
+			&http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}),
+	})
+	ExpectNoError(t, err, "creating client for", Ref("Prometheus", "", address))
+	t.T().Logf("Querying Prometheus API at %s", address)
+
+	return prometheusv1.NewAPI(client)
+}
+
+// monitoringEndpoint returns the configured endpoint of a monitoring component, or the URL of its Route
+// in openshift-monitoring namespace when not configured. The test is skipped if neither is available.
+func monitoringEndpoint(t Test, address string, configured bool, envVar, routeName string) string {
+	t.T().Helper()
+	if configured {
+		return address
+	}
+	if !IsOpenShift(t) {
+		t.T().Skipf("%s isn't set and the %s Route is only looked up on OpenShift", envVar, routeName)
+	}
+	route := GetRoute(t, openShiftMonitoringNamespace, routeName)
+	return "https://" + route.Spec.Host
+}
+
+// createMonitoringViewerToken creates a service account in the namespace bound to the cluster-monitoring-view
+// ClusterRole and returns its token.
+func createMonitoringViewerToken(t Test, namespace string) string {
+	t.T().Helper()
+	serviceAccount := CreateServiceAccount(t, namespace)
+	CreateClusterRoleBinding(t, serviceAccount, &rbacv1.ClusterRole{ObjectMeta: metav1.ObjectMeta{Name: clusterMonitoringViewClusterRole}})
+	return CreateTrackedToken(t, namespace, serviceAccount)
+}
+
+// PrometheusAlertingRule returns the alerting rule with the name, ok is false if no such rule is loaded.
+func PrometheusAlertingRule(t Test, api prometheusv1.API, name string) (prometheusv1.AlertingRule, bool) {
+	t.T().Helper()
+	rules, err := api.Rules(t.Ctx())
+	ExpectNoError(t, err, "listing rules of", Ref("Prometheus", "", ""))
+	for _, group := range rules.Groups {
+		for _, rule := range group.Rules {
+			if alertingRule, ok := rule.(prometheusv1.AlertingRule); ok && alertingRule.Name == name {
+				return alertingRule, true
+			}
+		}
+	}
+	return prometheusv1.AlertingRule{}, false
+}
+
+// PrometheusQueryValue returns the value of the instant query, the query is expected to return a single sample.
+func PrometheusQueryValue(t Test, api prometheusv1.API, query string) func(g gomega.Gomega) float64 {
+	return func(g gomega.Gomega) float64 {
+		return prometheusQueryValue(t, g, api, query, time.Now())
+	}
+}
+
+// PrometheusQueryValueAt returns the value of the instant query evaluated at the time, the query is expected to return
+// a single sample. Unlike PrometheusQueryValue, polling it picks the samples scraped late for the time.
+func PrometheusQueryValueAt(t Test, api prometheusv1.API, query string, ts time.Time) func(g gomega.Gomega) float64 {
+	return func(g gomega.Gomega) float64 {
+		return prometheusQueryValue(t, g, api, query, ts)
+	}
+}
+
+// PrometheusAvailable returns whether the Prometheus API is configured with PROMETHEUS_URL or can be looked up
+// on OpenShift, so tests asserting metrics along with other checks don't get skipped by NewPrometheusClient.
+func PrometheusAvailable(t Test) bool {
+	t.T().Helper()
+	_, ok := GetPrometheusUrl()
+	return ok || IsOpenShift(t)
+}
+
+func prometheusQueryValue(t Test, g gomega.Gomega, api prometheusv1.API, query string, ts time.Time) float64 {
+	result, warnings, err := api.Query(t.Ctx(), query, ts)
+	g.Expect(err).NotTo(gomega.HaveOccurred(), "Error running Prometheus query %s", query)
+	for _, warning := range warnings {
+		t.T().Logf("Prometheus query %s warning: %s", query, warning)
+	}
+
+	switch value := result.(type) {
+	case *model.Scalar:
+		return float64(value.Value)
+	case model.Vector:
+		g.Expect(value).To(gomega.HaveLen(1), "Prometheus query %s is expected to return a single sample", query)
+		return float64(value[0].Value)
+	default:
+		g.Expect(fmt.Errorf("unexpected result type %s of Prometheus query %s", result.Type(), query)).NotTo(gomega.HaveOccurred())
+		return 0
+	}
+}
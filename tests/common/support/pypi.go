@@ -0,0 +1,181 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+const (
+	devpiPort = 3141
+	// Index the wheels are uploaded to, inheriting the mirror of the upstream package index for the other packages
+	devpiIndex = "root/test"
+	// devpiReadyFile is created once the wheels are uploaded
+	devpiReadyFile = "/tmp/devpi-ready"
+)
+
+// devpiScript starts devpi server and uploads the wheels mounted in /wheels into the test index. The packages uploaded into
+// the test index shadow the same packages in the mirror, so pip can only install the uploaded versions.
+var devpiScript = fmt.Sprintf(`set -e
+command -v devpi-server > /dev/null || pip install --quiet devpi-server devpi-client
+devpi-init --serverdir /tmp/devpi --no-root-pypi
+devpi-server --serverdir /tmp/devpi --host %[4]s --port %[1]d &
+until devpi use http://localhost:%[1]d > /dev/null 2>&1; do sleep 1; done
+devpi login root --password ''
+devpi index -c root/pypi type=mirror mirror_url="$PIP_INDEX_URL"
+devpi index -c %[2]s bases=root/pypi volatile=False
+devpi use %[2]s
+devpi upload /wheels/*.whl
+touch %[3]s
+wait
+`, devpiPort, devpiIndex, devpiReadyFile, WildcardAddress())
+
+// LocalPyPI is a package index deployed in the test namespace.
+type LocalPyPI struct {
+	// IndexURL is the URL of the simple index pip installs the packages from, i.e. with PIP_INDEX_URL
+	IndexURL string
+	// TrustedHost is the host the index is served from over plain HTTP, i.e. set with PIP_TRUSTED_HOST
+	TrustedHost string
+}
+
+// Env returns the environment variables pointing pip at the index, none for the zero value.
+func (p LocalPyPI) Env() []corev1.EnvVar {
+	if p.IndexURL == "" {
+		return nil
+	}
+	return []corev1.EnvVar{
+		{Name: "PIP_INDEX_URL", Value: p.IndexURL},
+		{Name: "PIP_TRUSTED_HOST", Value: p.TrustedHost},
+	}
+}
+
+// DeployCodeFlareSdkIndex deploys a local package index seeded with the CodeFlare SDK wheels under test, set with
+// CODEFLARE_SDK_WHEELS, so the SDK version installed by the tests doesn't depend on PyPI availability and release timing.
+// It returns false when no wheels are set, the tests install the SDK from PIP_INDEX_URL then.
+func DeployCodeFlareSdkIndex(t Test, namespace string) (LocalPyPI, bool) {
+	t.T().Helper()
+	wheels := GetCodeFlareSdkWheels()
+	if len(wheels) == 0 {
+		return LocalPyPI{}, false
+	}
+	return DeployLocalPyPI(t, namespace, wheels...), true
+}
+
+// DeployLocalPyPI deploys devpi into the namespace, seeded with the local wheel files. The other packages are served
+// from the mirror of PIP_INDEX_URL.
+func DeployLocalPyPI(t Test, namespace string, wheels ...string) LocalPyPI {
+	t.T().Helper()
+
+	content := map[string][]byte{}
+	for _, wheel := range wheels {
+		data, err := os.ReadFile(wheel)
+		t.Expect(err).NotTo(gomega.HaveOccurred(), "Error reading wheel %s", wheel)
+		content[filepath.Base(wheel)] = data
+	}
+	wheelsConfigMap := CreateConfigMap(t, namespace, content)
+
+	labels := map[string]string{"app.kubernetes.io/name": "devpi"}
+	pod := CreatePod(t, &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "devpi-",
+			Namespace:    namespace,
+			Labels:       labels,
+		},
+		Spec: corev1.PodSpec{
+			Containers: []corev1.Container{
+				{
+					Name:    "devpi",
+					Image:   GetDevpiImage(),
+					Command: []string{"sh", "-c", devpiScript},
+					Env: []corev1.EnvVar{
+						{Name: "HOME", Value: "/tmp"},
+						{Name: "PIP_INDEX_URL", Value: GetPipIndexURL()},
+					},
+					Ports: []corev1.ContainerPort{
+						{
+							ContainerPort: devpiPort,
+							Name:          "devpi",
+						},
+					},
+					ReadinessProbe: &corev1.Probe{
+						ProbeHandler: corev1.ProbeHandler{
+							Exec: &corev1.ExecAction{Command: []string{"test", "-f", devpiReadyFile}},
+						},
+						PeriodSeconds: 2,
+					},
+					VolumeMounts: []corev1.VolumeMount{
+						{
+							Name:      "wheels",
+							MountPath: "/wheels",
+						},
+					},
+				},
+			},
+			Volumes: []corev1.Volume{
+				{
+					Name: "wheels",
+					VolumeSource: corev1.VolumeSource{
+						ConfigMap: &corev1.ConfigMapVolumeSource{
+							LocalObjectReference: corev1.LocalObjectReference{
+								Name: wheelsConfigMap.Name,
+							},
+						},
+					},
+				},
+			},
+		},
+	})
+
+	service, err := t.Client().Core().CoreV1().Services(namespace).Create(t.Ctx(), &corev1.Service{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "devpi",
+			Namespace: namespace,
+		},
+		Spec: corev1.ServiceSpec{
+			Selector: labels,
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "devpi",
+					Port:       devpiPort,
+					TargetPort: intstr.FromString("devpi"),
+				},
+			},
+		},
+	}, metav1.CreateOptions{})
+	ExpectNoError(t, err, "creating", Ref("Service", namespace, "devpi"))
+
+	EventuallyOf(t, Pod(t, namespace, pod.Name), TestTimeoutMedium).
+		Should(Field(func(pod *corev1.Pod) bool { return PodRunningAndReady(*pod) }).Equal(true),
+			"devpi didn't get ready with the wheels %v uploaded", wheels)
+
+	host := fmt.Sprintf("%s.%s.svc", service.Name, namespace)
+	pypi := LocalPyPI{
+		IndexURL:    fmt.Sprintf("http://%s:%d/%s/+simple/", host, devpiPort, devpiIndex),
+		TrustedHost: host,
+	}
+	t.T().Logf("Deployed local package index %s with wheels %v", pypi.IndexURL, wheels)
+	return pypi
+}
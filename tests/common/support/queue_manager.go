@@ -0,0 +1,391 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+	kueuev1beta1 "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+	utilrand "k8s.io/apimachinery/pkg/util/rand"
+
+	kftov1 "github.com/kubeflow/training-operator/pkg/apis/kubeflow.org/v1"
+)
+
+const (
+	QueueManagerKueue = "kueue"
+	QueueManagerMCAD  = "mcad"
+)
+
+// QueueManager dispatches the workloads submitted by the tests, so workload scenarios are written once and run against
+// the dispatcher the cluster uses, Kueue or MCAD.
+type QueueManager interface {
+	Name() string
+	// Submit queues the workload, a PyTorchJob, RayJob or batch Job, and returns it once submitted
+	Submit(t Test, workload metav1.Object) *QueuedWorkload
+	// WaitAdmitted waits until the queue manager admits the workload, so its pods can be created
+	WaitAdmitted(t Test, workload *QueuedWorkload, timeout time.Duration)
+	// WaitCompleted waits until the workload finishes and asserts it succeeded
+	WaitCompleted(t Test, workload *QueuedWorkload, timeout time.Duration)
+	// Admitted returns whether the queue manager admitted the workload, without waiting for it
+	Admitted(t Test, workload *QueuedWorkload) bool
+	// ExpectQueued asserts the workload stays queued without being admitted
+	ExpectQueued(t Test, workload *QueuedWorkload)
+	// Suspend suspends the admitted workload, so its pods are deleted, and waits until the queue manager releases its quota
+	Suspend(t Test, workload *QueuedWorkload)
+	// Resume resumes the suspended workload, queueing it to be admitted again
+	Resume(t Test, workload *QueuedWorkload)
+}
+
+// QueuedWorkload is a workload submitted to a QueueManager.
+type QueuedWorkload struct {
+	Kind      string
+	Namespace string
+	Name      string
+	// Object is the submitted workload, or the MCAD AppWrapper wrapping it
+	Object  metav1.Object
+	handler workloadHandler
+}
+
+// NewQueueManager returns the queue manager set with QUEUE_MANAGER, Kueue by default, dispatching the workloads of the
+// namespace within the quota. The quota only applies to Kueue, which gets a dedicated ClusterQueue and LocalQueue,
+// MCAD dispatches the workloads within the capacity of the cluster.
+func NewQueueManager(t Test, namespace string, quota corev1.ResourceList) QueueManager {
+	t.T().Helper()
+	switch manager := GetQueueManager(); manager {
+	case QueueManagerKueue:
+		return newKueueQueueManager(t, namespace, quota)
+	case QueueManagerMCAD:
+		return &mcadQueueManager{}
+	default:
+		t.T().Fatalf("Unsupported queue manager %q set with %s, supported are %s and %s", manager, queueManagerEnvVar, QueueManagerKueue, QueueManagerMCAD)
+		return nil
+	}
+}
+
+// NewSharedQueueManagers returns the queue managers of the namespaces sharing the cluster, set with QUEUE_MANAGER,
+// keyed by namespace. With Kueue, each namespace gets a ClusterQueue with its nominal quota, in a cohort of a single
+// ResourceFlavor, so the quota unused by a namespace is borrowed by the others. MCAD dispatches the workloads of all
+// the namespaces within the capacity of the cluster.
+func NewSharedQueueManagers(t Test, quotas map[string]corev1.ResourceList) map[string]QueueManager {
+	t.T().Helper()
+	managers := map[string]QueueManager{}
+	switch manager := GetQueueManager(); manager {
+	case QueueManagerKueue:
+		resourceFlavor := CreateTrackedKueueResourceFlavor(t, kueuev1beta1.ResourceFlavorSpec{})
+		t.T().Cleanup(func() {
+			_ = t.Client().Kueue().KueueV1beta1().ResourceFlavors().Delete(t.Ctx(), resourceFlavor.Name, metav1.DeleteOptions{})
+		})
+		cohort := "cohort-" + resourceFlavor.Name
+		for namespace, quota := range quotas {
+			clusterQueue := CreateTrackedKueueClusterQueue(t, kueuev1beta1.ClusterQueueSpec{
+				Cohort:            cohort,
+				NamespaceSelector: &metav1.LabelSelector{},
+				ResourceGroups:    []kueuev1beta1.ResourceGroup{KueueResourceGroup(resourceFlavor.Name, quota)},
+			})
+			t.T().Cleanup(func() {
+				_ = t.Client().Kueue().KueueV1beta1().ClusterQueues().Delete(t.Ctx(), clusterQueue.Name, metav1.DeleteOptions{})
+			})
+			localQueue := CreateKueueLocalQueue(t, namespace, clusterQueue.Name)
+			managers[namespace] = &kueueQueueManager{localQueue: localQueue.Name}
+		}
+	case QueueManagerMCAD:
+		for namespace := range quotas {
+			managers[namespace] = &mcadQueueManager{}
+		}
+	default:
+		t.T().Fatalf("Unsupported queue manager %q set with %s, supported are %s and %s", manager, queueManagerEnvVar, QueueManagerKueue, QueueManagerMCAD)
+	}
+	return managers
+}
+
+type kueueQueueManager struct {
+	localQueue string
+}
+
+var _ QueueManager = (*kueueQueueManager)(nil)
+
+func newKueueQueueManager(t Test, namespace string, quota corev1.ResourceList) *kueueQueueManager {
+	t.T().Helper()
+
+	queues := CreateKueueQueues(t, namespace, kueuev1beta1.ResourceFlavorSpec{}, quota)
+	return &kueueQueueManager{localQueue: queues.LocalQueue.Name}
+}
+
+func (m *kueueQueueManager) Name() string {
+	return QueueManagerKueue
+}
+
+func (m *kueueQueueManager) Submit(t Test, workload metav1.Object) *QueuedWorkload {
+	t.T().Helper()
+	labels := workload.GetLabels()
+	if labels == nil {
+		labels = map[string]string{}
+	}
+	labels[kueueQueueNameLabel] = m.localQueue
+	workload.SetLabels(labels)
+
+	handler := newWorkloadHandler(t, workload)
+	created := handler.create(t)
+	return &QueuedWorkload{Kind: handler.kind, Namespace: created.GetNamespace(), Name: created.GetName(), Object: created, handler: handler}
+}
+
+func (m *kueueQueueManager) WaitAdmitted(t Test, workload *QueuedWorkload, timeout time.Duration) {
+	t.T().Helper()
+	EventuallyOf(t, KueueWorkloadOwnedBy(t, workload.Namespace, workload.Object), timeout).
+		Should(Field(KueueWorkloadAdmitted).Equal(true), "%s %s/%s wasn't admitted by Kueue", workload.Kind, workload.Namespace, workload.Name)
+}
+
+func (m *kueueQueueManager) WaitCompleted(t Test, workload *QueuedWorkload, timeout time.Duration) {
+	t.T().Helper()
+	waitWorkloadSucceeded(t, workload, timeout)
+}
+
+// Admitted returns false until Kueue creates the Workload of the workload.
+func (m *kueueQueueManager) Admitted(t Test, workload *QueuedWorkload) bool {
+	t.T().Helper()
+	workloads, err := t.Client().Kueue().KueueV1beta1().Workloads(workload.Namespace).List(t.Ctx(), metav1.ListOptions{})
+	ExpectNoError(t, err, "listing", Ref("Workload", workload.Namespace, ""))
+	for i := range workloads.Items {
+		if metav1.IsControlledBy(&workloads.Items[i], workload.Object) {
+			return KueueWorkloadAdmitted(&workloads.Items[i])
+		}
+	}
+	return false
+}
+
+func (m *kueueQueueManager) ExpectQueued(t Test, workload *QueuedWorkload) {
+	t.T().Helper()
+	EventuallyOf(t, KueueWorkloadOwnedBy(t, workload.Namespace, workload.Object), TestTimeoutShort).
+		Should(Field(KueueWorkloadQuotaReserved).Equal(false))
+	ConsistentlyOf(t, KueueWorkloadOwnedBy(t, workload.Namespace, workload.Object)).
+		Should(Field(KueueWorkloadQuotaReserved).Equal(false), "%s %s/%s was admitted by Kueue", workload.Kind, workload.Namespace, workload.Name)
+}
+
+// Suspend deactivates the Workload of the workload, so Kueue evicts it and suspends the workload, rather than
+// suspending the workload directly, which Kueue would revert while the Workload is admitted.
+func (m *kueueQueueManager) Suspend(t Test, workload *QueuedWorkload) {
+	t.T().Helper()
+	SetKueueWorkloadActive(t, workload.Namespace, GetKueueWorkloadOwnedBy(t, workload.Namespace, workload.Object).Name, false)
+	EventuallyOf(t, KueueWorkloadOwnedBy(t, workload.Namespace, workload.Object), TestTimeoutMedium).
+		Should(Field(KueueWorkloadQuotaReserved).Equal(false), "%s %s/%s still holds quota once suspended", workload.Kind, workload.Namespace, workload.Name)
+}
+
+func (m *kueueQueueManager) Resume(t Test, workload *QueuedWorkload) {
+	t.T().Helper()
+	SetKueueWorkloadActive(t, workload.Namespace, GetKueueWorkloadOwnedBy(t, workload.Namespace, workload.Object).Name, true)
+}
+
+// MCAD dispatches the legacy AppWrappers, wrapping the workloads as generic items
+var mcadAppWrapperResource = schema.GroupVersionResource{Group: "workload.codeflare.dev", Version: "v1beta1", Resource: "appwrappers"}
+
+type mcadQueueManager struct{}
+
+var _ QueueManager = (*mcadQueueManager)(nil)
+
+func (m *mcadQueueManager) Name() string {
+	return QueueManagerMCAD
+}
+
+func (m *mcadQueueManager) Submit(t Test, workload metav1.Object) *QueuedWorkload {
+	t.T().Helper()
+
+	// MCAD creates the wrapped workload with the name of its template
+	if workload.GetName() == "" {
+		workload.SetName(workload.GetGenerateName() + utilrand.String(5))
+		workload.SetGenerateName("")
+	}
+	handler := newWorkloadHandler(t, workload)
+
+	template, err := runtime.DefaultUnstructuredConverter.ToUnstructured(workload)
+	ExpectNoError(t, err, "converting", Ref(handler.kind, workload.GetNamespace(), workload.GetName()))
+	var podResources []any
+	for _, pods := range workloadPodTemplates(workload) {
+		requests, limits := corev1.ResourceList{}, corev1.ResourceList{}
+		for _, container := range pods.template.Spec.Containers {
+			addResources(requests, container.Resources.Requests, 1)
+			addResources(limits, container.Resources.Limits, 1)
+		}
+		podResources = append(podResources, map[string]any{
+			"replicas": int64(pods.replicas),
+			"requests": resourceListToUnstructured(requests),
+			"limits":   resourceListToUnstructured(limits),
+		})
+	}
+	appWrapper := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": mcadAppWrapperResource.GroupVersion().String(),
+		"kind":       "AppWrapper",
+		"metadata": map[string]any{
+			"name":      workload.GetName(),
+			"namespace": workload.GetNamespace(),
+		},
+		"spec": map[string]any{
+			"resources": map[string]any{
+				"GenericItems": []any{
+					map[string]any{
+						"replicas":           int64(1),
+						"custompodresources": podResources,
+						"generictemplate":    template,
+					},
+				},
+			},
+		},
+	}}
+	created, err := t.Client().Dynamic().Resource(mcadAppWrapperResource).Namespace(workload.GetNamespace()).Create(t.Ctx(), appWrapper, metav1.CreateOptions{})
+	ExpectNoError(t, err, "creating", Ref("AppWrapper", workload.GetNamespace(), workload.GetName()))
+	t.T().Logf("Created MCAD AppWrapper %s/%s wrapping %s successfully", created.GetNamespace(), created.GetName(), handler.kind)
+
+	return &QueuedWorkload{Kind: handler.kind, Namespace: created.GetNamespace(), Name: created.GetName(), Object: created, handler: handler}
+}
+
+func (m *mcadQueueManager) WaitAdmitted(t Test, workload *QueuedWorkload, timeout time.Duration) {
+	t.T().Helper()
+	t.Eventually(mcadAppWrapperState(t, workload), timeout).
+		Should(gomega.BeElementOf("Running", "Completed"), "%s %s/%s wasn't dispatched by MCAD", workload.Kind, workload.Namespace, workload.Name)
+}
+
+func (m *mcadQueueManager) WaitCompleted(t Test, workload *QueuedWorkload, timeout time.Duration) {
+	t.T().Helper()
+	waitWorkloadSucceeded(t, workload, timeout)
+}
+
+func (m *mcadQueueManager) Admitted(t Test, workload *QueuedWorkload) bool {
+	t.T().Helper()
+	return slices.Contains([]string{"Running", "Completed"}, mcadAppWrapperState(t, workload)(t))
+}
+
+func (m *mcadQueueManager) ExpectQueued(t Test, workload *QueuedWorkload) {
+	t.T().Helper()
+	t.Consistently(mcadAppWrapperState(t, workload)).
+		Should(gomega.Not(gomega.BeElementOf("Running", "Completed")), "%s %s/%s was dispatched by MCAD", workload.Kind, workload.Namespace, workload.Name)
+}
+
+// Suspend suspends the wrapped workload directly, MCAD has no notion of suspension and keeps the AppWrapper dispatched,
+// its quota being the capacity of the cluster, released once the pods are deleted.
+func (m *mcadQueueManager) Suspend(t Test, workload *QueuedWorkload) {
+	t.T().Helper()
+	setWorkloadSuspended(t, workload, true)
+}
+
+func (m *mcadQueueManager) Resume(t Test, workload *QueuedWorkload) {
+	t.T().Helper()
+	setWorkloadSuspended(t, workload, false)
+}
+
+// setWorkloadSuspended patches the suspend field of the workload, which the controller of the workload acts upon.
+func setWorkloadSuspended(t Test, workload *QueuedWorkload, suspend bool) {
+	t.T().Helper()
+	var err error
+	switch workload.Kind {
+	case "RayJob":
+		patch := fmt.Sprintf(`{"spec":{"suspend":%t}}`, suspend)
+		_, err = t.Client().Ray().RayV1().RayJobs(workload.Namespace).Patch(t.Ctx(), workload.Name, types.MergePatchType, []byte(patch), metav1.PatchOptions{})
+	case "PyTorchJob":
+		patch := fmt.Sprintf(`{"spec":{"runPolicy":{"suspend":%t}}}`, suspend)
+		_, err = t.Client().Kubeflow().KubeflowV1().PyTorchJobs(workload.Namespace).Patch(t.Ctx(), workload.Name, types.MergePatchType, []byte(patch), metav1.PatchOptions{})
+	case "Job":
+		patch := fmt.Sprintf(`{"spec":{"suspend":%t}}`, suspend)
+		_, err = t.Client().Core().BatchV1().Jobs(workload.Namespace).Patch(t.Ctx(), workload.Name, types.MergePatchType, []byte(patch), metav1.PatchOptions{})
+	default:
+		t.T().Fatalf("Suspending %s isn't supported", workload.Kind)
+	}
+	ExpectNoError(t, err, "patching", Ref(workload.Kind, workload.Namespace, workload.Name))
+	t.T().Logf("Set %s %s/%s suspend to %t successfully", workload.Kind, workload.Namespace, workload.Name, suspend)
+}
+
+func mcadAppWrapperState(t Test, workload *QueuedWorkload) func(g gomega.Gomega) string {
+	return func(g gomega.Gomega) string {
+		appWrapper, err := t.Client().Dynamic().Resource(mcadAppWrapperResource).Namespace(workload.Namespace).Get(t.Ctx(), workload.Name, metav1.GetOptions{})
+		g.Expect(WrapError(err, "getting", Ref("AppWrapper", workload.Namespace, workload.Name))).NotTo(gomega.HaveOccurred())
+		state, _, _ := unstructured.NestedString(appWrapper.Object, "status", "state")
+		return state
+	}
+}
+
+// waitWorkloadSucceeded waits until the workload finishes and asserts it succeeded, whichever queue manager dispatched it.
+func waitWorkloadSucceeded(t Test, workload *QueuedWorkload, timeout time.Duration) {
+	t.T().Helper()
+	var state workloadState
+	EventuallyWithPolling(t, func(g gomega.Gomega) bool {
+		state = workload.handler.state(t, g, workload.Namespace, workload.Name)
+		return state.finished
+	}, timeout, PollingStrategyFor(workload.Kind)).Should(gomega.BeTrue(),
+		"%s %s/%s didn't finish within %s", workload.Kind, workload.Namespace, workload.Name, timeout)
+	t.Expect(state.succeeded).To(gomega.BeTrue(), "%s %s/%s finished with status %s: %s", workload.Kind, workload.Namespace, workload.Name, state.status, state.message)
+}
+
+type podTemplateReplicas struct {
+	replicas int32
+	template corev1.PodTemplateSpec
+}
+
+// workloadPodTemplates returns the pod templates of the workload with the number of pods created from each.
+func workloadPodTemplates(workload metav1.Object) []podTemplateReplicas {
+	switch workload := workload.(type) {
+	case *batchv1.Job:
+		replicas := int32(1)
+		if workload.Spec.Parallelism != nil {
+			replicas = *workload.Spec.Parallelism
+		}
+		return []podTemplateReplicas{{replicas: replicas, template: workload.Spec.Template}}
+	case *kftov1.PyTorchJob:
+		var templates []podTemplateReplicas
+		for _, replicaSpec := range workload.Spec.PyTorchReplicaSpecs {
+			replicas := int32(1)
+			if replicaSpec.Replicas != nil {
+				replicas = *replicaSpec.Replicas
+			}
+			templates = append(templates, podTemplateReplicas{replicas: replicas, template: replicaSpec.Template})
+		}
+		return templates
+	case *rayv1.RayJob:
+		if workload.Spec.RayClusterSpec == nil {
+			return nil
+		}
+		templates := []podTemplateReplicas{{replicas: 1, template: workload.Spec.RayClusterSpec.HeadGroupSpec.Template}}
+		for _, group := range workload.Spec.RayClusterSpec.WorkerGroupSpecs {
+			replicas := int32(1)
+			if group.Replicas != nil {
+				replicas = *group.Replicas
+			}
+			templates = append(templates, podTemplateReplicas{replicas: replicas, template: group.Template})
+		}
+		return templates
+	}
+	return nil
+}
+
+func resourceListToUnstructured(resources corev1.ResourceList) map[string]any {
+	content := map[string]any{}
+	for name, quantity := range resources {
+		content[strings.ToLower(string(name))] = quantity.String()
+	}
+	return content
+}
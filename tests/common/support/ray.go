@@ -0,0 +1,109 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+
+	"github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+)
+
+type RayPlacementGroup struct {
+	PlacementGroupID string                    `json:"placement_group_id"`
+	Name             string                    `json:"name"`
+	State            string                    `json:"state"`
+	Bundles          []RayPlacementGroupBundle `json:"bundles"`
+}
+
+type RayPlacementGroupBundle struct {
+	BundleID      string             `json:"bundle_id"`
+	NodeID        string             `json:"node_id"`
+	UnitResources map[string]float64 `json:"unit_resources"`
+}
+
+type RayNode struct {
+	NodeID     string `json:"node_id"`
+	NodeIP     string `json:"node_ip"`
+	IsHeadNode bool   `json:"is_head_node"`
+	State      string `json:"state"`
+}
+
+type rayStateAPIResponse[T any] struct {
+	Result bool   `json:"result"`
+	Msg    string `json:"msg"`
+	Data   struct {
+		Result struct {
+			Total  int `json:"total"`
+			Result []T `json:"result"`
+		} `json:"result"`
+	} `json:"data"`
+}
+
+// RayJobDeploymentStatus returns the status of the deployment of the RayJob, i.e. Suspended once its RayCluster is torn down.
+func RayJobDeploymentStatus(job *rayv1.RayJob) rayv1.JobDeploymentStatus {
+	return job.Status.JobDeploymentStatus
+}
+
+// RayJobSucceeded returns whether the Ray job of the RayJob succeeded, once the RayJob deployment is complete.
+func RayJobSucceeded(job *rayv1.RayJob) bool {
+	return job.Status.JobDeploymentStatus == rayv1.JobDeploymentStatusComplete && job.Status.JobStatus == rayv1.JobStatusSucceeded
+}
+
+// GetRayPlacementGroups lists placement groups of the Ray cluster through the Ray dashboard state API.
+func GetRayPlacementGroups(t Test, dashboardEndpoint url.URL) []RayPlacementGroup {
+	t.T().Helper()
+	return getRayStateAPIResources[RayPlacementGroup](t, dashboardEndpoint, "placement_groups")
+}
+
+// GetRayNodes lists nodes registered with the GCS of the Ray cluster through the Ray dashboard state API.
+func GetRayNodes(t Test, dashboardEndpoint url.URL) []RayNode {
+	t.T().Helper()
+	return getRayStateAPIResources[RayNode](t, dashboardEndpoint, "nodes")
+}
+
+// RayPlacementGroupBundleNodes returns the IDs of the nodes each bundle of the placement group is placed on.
+func RayPlacementGroupBundleNodes(placementGroup RayPlacementGroup) []string {
+	var nodes []string
+	for _, bundle := range placementGroup.Bundles {
+		nodes = append(nodes, bundle.NodeID)
+	}
+	return nodes
+}
+
+func getRayStateAPIResources[T any](t Test, dashboardEndpoint url.URL, resource string) []T {
+	t.T().Helper()
+
+	resp, err := http.Get(fmt.Sprintf("%s/api/v0/%s?detail=1&limit=1000", dashboardEndpoint.String(), resource))
+	ExpectNoError(t, err, "getting Ray "+resource+" from", Ref("dashboard", "", dashboardEndpoint.Host))
+	defer resp.Body.Close()
+
+	respData, err := io.ReadAll(resp.Body)
+	ExpectNoError(t, err, "reading Ray "+resource+" from", Ref("dashboard", "", dashboardEndpoint.Host))
+	t.Expect(resp.StatusCode).To(gomega.Equal(http.StatusOK), "Incorrect response code for listing Ray %s, response body: %s", resource, respData)
+
+	response := rayStateAPIResponse[T]{}
+	t.Expect(json.Unmarshal(respData, &response)).To(gomega.Succeed())
+	t.Expect(response.Result).To(gomega.BeTrue(), "Listing Ray %s failed: %s", resource, response.Msg)
+
+	return response.Data.Result.Result
+}
@@ -0,0 +1,104 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"github.com/opendatahub-io/distributed-workloads/pkg/examples"
+	. "github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+)
+
+// RayClusterBuilder builds RayClusters for the tests, with a head exposing the dashboard and a single worker group,
+// i.e. NewRayClusterBuilder().WithName(namespace, "raycluster").WithWorkers(2).WithGPU(1).Build().
+type RayClusterBuilder struct {
+	options examples.RayClusterOptions
+}
+
+// NewRayClusterBuilder returns a builder of a RayCluster with a single worker advertising one CPU, running
+// the configured Ray version and image on nodes of the test architecture, with the dashboard listening on
+// the wildcard address of the cluster IP family.
+func NewRayClusterBuilder() *RayClusterBuilder {
+	return &RayClusterBuilder{
+		options: examples.RayClusterOptions{
+			RayVersion:    GetRayVersion(),
+			Image:         GetRayImageForArchitecture(),
+			Workers:       1,
+			WorkerCPUs:    "1",
+			NodeSelector:  ArchitectureNodeSelector(),
+			DashboardHost: WildcardAddress(),
+		},
+	}
+}
+
+func (b *RayClusterBuilder) WithName(namespace, name string) *RayClusterBuilder {
+	b.options.Namespace = namespace
+	b.options.Name = name
+	return b
+}
+
+func (b *RayClusterBuilder) WithImage(image string) *RayClusterBuilder {
+	b.options.Image = image
+	return b
+}
+
+// WithWorkers sets the fixed number of workers of the worker group.
+func (b *RayClusterBuilder) WithWorkers(workers int32) *RayClusterBuilder {
+	b.options.Workers = workers
+	return b
+}
+
+// WithWorkerCPUs sets the number of CPUs each worker advertises to Ray.
+func (b *RayClusterBuilder) WithWorkerCPUs(cpus string) *RayClusterBuilder {
+	b.options.WorkerCPUs = cpus
+	return b
+}
+
+// WithGPU sets the number of NVIDIA GPUs requested by each worker, the image is expected to support CUDA.
+func (b *RayClusterBuilder) WithGPU(gpus int32) *RayClusterBuilder {
+	b.options.WorkerGPUs = gpus
+	return b
+}
+
+// WithAccelerator sets the number of GPUs of the accelerator requested by each worker, and lets the workers tolerate
+// the taint of the GPU nodes, the image is expected to support the runtime of the accelerator.
+func (b *RayClusterBuilder) WithAccelerator(accelerator Accelerator, gpus int32) *RayClusterBuilder {
+	b.options.WorkerGPUs = gpus
+	b.options.WorkerGPUResource = accelerator.Resource
+	b.options.WorkerTolerations = accelerator.Tolerations()
+	return b
+}
+
+// WithLocalQueue queues the RayCluster in the Kueue LocalQueue when not empty.
+func (b *RayClusterBuilder) WithLocalQueue(localQueue string) *RayClusterBuilder {
+	b.options.LocalQueue = localQueue
+	return b
+}
+
+// WithScriptsConfigMap mounts the ConfigMap into the head at examples.RayClusterScriptsMountPath when not empty.
+func (b *RayClusterBuilder) WithScriptsConfigMap(configMap string) *RayClusterBuilder {
+	b.options.ScriptsConfigMap = configMap
+	return b
+}
+
+func (b *RayClusterBuilder) Build() *rayv1.RayCluster {
+	return examples.RayCluster(b.options)
+}
+
+// BuildSpec returns the specification of the RayCluster, i.e. for a RayJob creating its RayCluster.
+func (b *RayClusterBuilder) BuildSpec() *rayv1.RayClusterSpec {
+	return examples.RayClusterSpec(b.options)
+}
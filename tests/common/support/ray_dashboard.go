@@ -0,0 +1,107 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"bytes"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	. "github.com/project-codeflare/codeflare-common/support"
+)
+
+// rayDashboardClient calls the Ray job REST API through the Ray dashboard route, authenticated with a bearer token
+// accepted by the OAuth proxy in front of the dashboard, the same way CodeFlare SDK does.
+type rayDashboardClient struct {
+	endpoint   url.URL
+	token      string
+	httpClient *http.Client
+}
+
+var _ RayClusterClient = (*rayDashboardClient)(nil)
+
+// NewRayDashboardClient creates a Ray cluster client for the dashboard exposed through an OAuth proxy.
+func NewRayDashboardClient(endpoint url.URL, token string) RayClusterClient {
+	return &rayDashboardClient{
+		endpoint: endpoint,
+		token:    token,
+		httpClient: &http.Client{
+			Timeout:   30 * time.Second,
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
+		},
+	}
+}
+
+func (client *rayDashboardClient) CreateJob(job *RayJobSetup) (*RayJobResponse, error) {
+	body, err := json.Marshal(job)
+	if err != nil {
+		return nil, err
+	}
+	respData, err := client.request(http.MethodPost, "/api/jobs/", body)
+	if err != nil {
+		return nil, err
+	}
+	response := &RayJobResponse{}
+	return response, json.Unmarshal(respData, response)
+}
+
+func (client *rayDashboardClient) GetJobDetails(jobID string) (*RayJobDetailsResponse, error) {
+	respData, err := client.request(http.MethodGet, "/api/jobs/"+jobID, nil)
+	if err != nil {
+		return nil, err
+	}
+	response := &RayJobDetailsResponse{}
+	return response, json.Unmarshal(respData, response)
+}
+
+func (client *rayDashboardClient) GetJobLogs(jobID string) (string, error) {
+	respData, err := client.request(http.MethodGet, "/api/jobs/"+jobID+"/logs", nil)
+	if err != nil {
+		return "", err
+	}
+	jobLogs := RayJobLogsResponse{}
+	return jobLogs.Logs, json.Unmarshal(respData, &jobLogs)
+}
+
+func (client *rayDashboardClient) request(method, path string, body []byte) ([]byte, error) {
+	req, err := http.NewRequest(method, strings.TrimSuffix(client.endpoint.String(), "/")+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+client.token)
+
+	resp, err := client.httpClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	respData, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("incorrect response code %d for %s %s, response body: %s", resp.StatusCode, method, path, respData)
+	}
+	return respData, nil
+}
@@ -0,0 +1,172 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/url"
+	"strings"
+
+	"github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// Label of the namespaces kept for reuse by the test they are labeled with in warm standby mode
+	warmStandbyLabel = "distributed-workloads.opendatahub.io/warm-standby"
+	// Annotation with the hash of the specification of resources reused in warm standby mode
+	warmStandbySpecHashAnnotation = "distributed-workloads.opendatahub.io/spec-hash"
+)
+
+// NewWarmStandbyNamespace returns a new test namespace, or in warm standby mode enabled with TEST_WARM_STANDBY,
+// the namespace kept by the previous run of the test. Warm standby namespaces aren't deleted once the test finishes,
+// so the resources created in them can be reused by the next run while developing the test.
+func NewWarmStandbyNamespace(t Test) *corev1.Namespace {
+	t.T().Helper()
+	if !IsWarmStandby() {
+		return t.NewTestNamespace()
+	}
+
+	key := warmStandbyKey(t)
+	namespaces, err := t.Client().Core().CoreV1().Namespaces().List(t.Ctx(), metav1.ListOptions{LabelSelector: warmStandbyLabel + "=" + key})
+	ExpectNoError(t, err, "listing warm standby", Ref("Namespace", "", key))
+	for i := range namespaces.Items {
+		if namespaces.Items[i].Status.Phase == corev1.NamespaceActive {
+			t.T().Logf("Reusing warm standby namespace %s", namespaces.Items[i].Name)
+			return &namespaces.Items[i]
+		}
+	}
+
+	namespace := &corev1.Namespace{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "test-standby-",
+			Labels:       map[string]string{warmStandbyLabel: key},
+		},
+	}
+	namespace, err = t.Client().Core().CoreV1().Namespaces().Create(t.Ctx(), namespace, metav1.CreateOptions{})
+	ExpectNoError(t, err, "creating warm standby", Ref("Namespace", "", key))
+	t.T().Logf("Created warm standby namespace %s, delete it once done with developing the test", namespace.Name)
+	return namespace
+}
+
+// CreateWarmStandbyConfigMap creates a ConfigMap with the content. In warm standby mode, the ConfigMap is named after
+// a hash of its content, so an existing ConfigMap with the same content is reused, and resources mounting it
+// are only recreated when the content changes.
+func CreateWarmStandbyConfigMap(t Test, namespace string, content map[string][]byte) *corev1.ConfigMap {
+	t.T().Helper()
+	if !IsWarmStandby() {
+		return CreateConfigMap(t, namespace, content)
+	}
+
+	name := "config-" + specHash(t, content)[:10]
+	configMap, err := t.Client().Core().CoreV1().ConfigMaps(namespace).Get(t.Ctx(), name, metav1.GetOptions{})
+	if err == nil {
+		t.T().Logf("Reusing warm standby ConfigMap %s/%s", namespace, name)
+		return configMap
+	}
+	t.Expect(errors.IsNotFound(err)).To(gomega.BeTrue(), "Error getting warm standby ConfigMap %s/%s: %v", namespace, name, err)
+
+	configMap = &corev1.ConfigMap{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      name,
+			Namespace: namespace,
+		},
+		BinaryData: content,
+		Immutable:  Ptr(true),
+	}
+	configMap, err = t.Client().Core().CoreV1().ConfigMaps(namespace).Create(t.Ctx(), configMap, metav1.CreateOptions{})
+	ExpectNoError(t, err, "creating", Ref("ConfigMap", namespace, name))
+	t.T().Logf("Created ConfigMap %s/%s successfully", configMap.Namespace, configMap.Name)
+	return configMap
+}
+
+// CreateWarmStandbyRayCluster creates the RayCluster. In warm standby mode, an existing RayCluster with the same
+// name and specification is reused, while a RayCluster with a different specification is recreated.
+func CreateWarmStandbyRayCluster(t Test, rayCluster *rayv1.RayCluster) *rayv1.RayCluster {
+	t.T().Helper()
+	client := t.Client().Ray().RayV1().RayClusters(rayCluster.Namespace)
+
+	if IsWarmStandby() {
+		hash := specHash(t, rayCluster.Spec)
+		existing, err := client.Get(t.Ctx(), rayCluster.Name, metav1.GetOptions{})
+		switch {
+		case err == nil && existing.Annotations[warmStandbySpecHashAnnotation] == hash:
+			t.T().Logf("Reusing warm standby RayCluster %s/%s", existing.Namespace, existing.Name)
+			return existing
+		case err == nil:
+			t.T().Logf("Recreating warm standby RayCluster %s/%s as its specification changed", existing.Namespace, existing.Name)
+			err = client.Delete(t.Ctx(), existing.Name, metav1.DeleteOptions{PropagationPolicy: Ptr(metav1.DeletePropagationForeground)})
+			ExpectNoError(t, err, "deleting", Ref("RayCluster", existing.Namespace, existing.Name))
+			t.Eventually(func() bool {
+				_, err := client.Get(t.Ctx(), existing.Name, metav1.GetOptions{})
+				return errors.IsNotFound(err)
+			}, TestTimeoutMedium).Should(gomega.BeTrue())
+		case !errors.IsNotFound(err):
+			ExpectNoError(t, err, "getting", Ref("RayCluster", rayCluster.Namespace, rayCluster.Name))
+		}
+		if rayCluster.Annotations == nil {
+			rayCluster.Annotations = map[string]string{}
+		}
+		rayCluster.Annotations[warmStandbySpecHashAnnotation] = hash
+	}
+
+	rayCluster, err := client.Create(t.Ctx(), rayCluster, metav1.CreateOptions{})
+	ExpectNoError(t, err, "creating", Ref("RayCluster", rayCluster.Namespace, rayCluster.Name))
+	t.T().Logf("Created RayCluster %s/%s successfully", rayCluster.Namespace, rayCluster.Name)
+	return rayCluster
+}
+
+// ExposeWarmStandbyService exposes the service, replacing the Route or Ingress left by the previous run in warm standby mode.
+func ExposeWarmStandbyService(t Test, name, namespace, serviceName, servicePort string) url.URL {
+	t.T().Helper()
+	if IsWarmStandby() {
+		var err error
+		if IsOpenShift(t) {
+			err = t.Client().Route().RouteV1().Routes(namespace).Delete(t.Ctx(), name, metav1.DeleteOptions{})
+		} else {
+			err = t.Client().Core().NetworkingV1().Ingresses(namespace).Delete(t.Ctx(), name, metav1.DeleteOptions{})
+		}
+		if !errors.IsNotFound(err) {
+			ExpectNoError(t, err, "deleting exposure of", Ref("Service", namespace, serviceName))
+		}
+	}
+	return ExposeService(t, name, namespace, serviceName, servicePort)
+}
+
+// warmStandbyKey returns the label value identifying the warm standby namespace of the test.
+func warmStandbyKey(t Test) string {
+	key := strings.ToLower(strings.NewReplacer("/", ".", "_", "-").Replace(t.T().Name()))
+	if len(key) > 63 {
+		key = key[:54] + "." + specHash(t, t.T().Name())[:8]
+	}
+	return key
+}
+
+func specHash(t Test, spec any) string {
+	t.T().Helper()
+	data, err := json.Marshal(spec)
+	ExpectNoError(t, err, "hashing", Ref("specification", "", ""))
+	hash := sha256.Sum256(data)
+	return hex.EncodeToString(hash[:])
+}
@@ -0,0 +1,72 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// CreatePersistentVolumeClaimWithStorageClass creates a PVC provisioned by the storage class, empty storage class stands for the cluster default one.
+func CreatePersistentVolumeClaimWithStorageClass(t Test, namespace, storageSize, storageClass string, accessMode ...corev1.PersistentVolumeAccessMode) *corev1.PersistentVolumeClaim {
+	t.T().Helper()
+
+	pvc := &corev1.PersistentVolumeClaim{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "PersistentVolumeClaim",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "pvc-",
+			Namespace:    namespace,
+		},
+		Spec: corev1.PersistentVolumeClaimSpec{
+			AccessModes: accessMode,
+			Resources: corev1.VolumeResourceRequirements{
+				Requests: corev1.ResourceList{
+					corev1.ResourceStorage: resource.MustParse(storageSize),
+				},
+			},
+		},
+	}
+	if storageClass != "" {
+		pvc.Spec.StorageClassName = &storageClass
+	}
+
+	ref := Ref("PersistentVolumeClaim", namespace, pvc.GenerateName)
+	pvc, err := t.Client().Core().CoreV1().PersistentVolumeClaims(namespace).Create(t.Ctx(), pvc, metav1.CreateOptions{})
+	ExpectNoError(t, err, "creating", ref)
+	t.T().Logf("Created PersistentVolumeClaim %s/%s with storage class %q successfully", pvc.Namespace, pvc.Name, storageClass)
+
+	return pvc
+}
+
+func PersistentVolumeClaim(t Test, namespace, name string) func(g gomega.Gomega) *corev1.PersistentVolumeClaim {
+	return func(g gomega.Gomega) *corev1.PersistentVolumeClaim {
+		pvc, err := t.Client().Core().CoreV1().PersistentVolumeClaims(namespace).Get(t.Ctx(), name, metav1.GetOptions{})
+		g.Expect(WrapError(err, "getting", Ref("PersistentVolumeClaim", namespace, name))).NotTo(gomega.HaveOccurred())
+		return pvc
+	}
+}
+
+func PersistentVolumeClaimPhase(pvc *corev1.PersistentVolumeClaim) corev1.PersistentVolumeClaimPhase {
+	return pvc.Status.Phase
+}
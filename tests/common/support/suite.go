@@ -0,0 +1,410 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	configv1client "github.com/openshift/client-go/config/clientset/versioned/typed/config/v1"
+
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+type TestStatus string
+
+const (
+	TestPassed  TestStatus = "passed"
+	TestFailed  TestStatus = "failed"
+	TestSkipped TestStatus = "skipped"
+	// TestXFailed and TestXPassed are the statuses of tests marked with XFail which failed as expected, or passed
+	TestXFailed TestStatus = "xfailed"
+	TestXPassed TestStatus = "xpassed"
+)
+
+type TestResult struct {
+	Name     string        `json:"name"`
+	Status   TestStatus    `json:"status"`
+	Duration time.Duration `json:"duration"`
+	Labels   []string      `json:"labels,omitempty"`
+	Failures []string      `json:"failures,omitempty"`
+	// Issue is the issue tracking the bug the test is expected to fail with, when marked with XFail
+	Issue string `json:"issue,omitempty"`
+	// Measurements are the values recorded by the test with RecordMeasurement
+	Measurements map[string]float64 `json:"measurements,omitempty"`
+	// Contaminated lists the reasons the measurements of the test aren't representative, i.e. noisy neighbors
+	Contaminated []string `json:"contaminated,omitempty"`
+	// Timeout is the time the test had until the test binary deadline, set with go test -timeout, when it started
+	Timeout time.Duration `json:"timeout,omitempty"`
+	// Cluster is the kubeconfig context the test ran against when the suite is run against TEST_CLUSTERS
+	Cluster string `json:"cluster,omitempty"`
+}
+
+// DisplayName returns the test name qualified with the cluster it ran against, if any.
+func (r TestResult) DisplayName() string {
+	if r.Cluster == "" {
+		return r.Name
+	}
+	return fmt.Sprintf("%s [%s]", r.Name, r.Cluster)
+}
+
+type SuiteSummary struct {
+	Suite    string        `json:"suite"`
+	Duration time.Duration `json:"duration"`
+	Clusters []string      `json:"clusters,omitempty"`
+	// Images maps the images run by the pods of the tests to the image digests they resolved to
+	Images  map[string]string `json:"images,omitempty"`
+	Results []TestResult      `json:"results"`
+	// Leaked are the cluster-scoped objects created by the tests found left over once they ran
+	Leaked []string `json:"leaked,omitempty"`
+}
+
+// testHooks decorate the Test of each test created with WithHooks, in order of registration.
+var testHooks []func(Test) Test
+
+func registerTestHook(hook func(Test) Test) {
+	testHooks = append(testHooks, hook)
+}
+
+// WithHooks returns the Test of the test like With, decorated with the hooks registered by RunSuite,
+// i.e. recording the failed assertions into the failure summary, it is meant to be called right after Track:
+//
+//	Track(t, LabelKueue)
+//	test := MustGather(WithHooks(t))
+//
+// Its clients are created from the current kubeconfig context, i.e. the one of TEST_CLUSTERS the suite is run against.
+func WithHooks(t *testing.T) Test {
+	t.Helper()
+	var test Test
+	cfg, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		clientcmd.NewDefaultClientConfigLoadingRules(),
+		&clientcmd.ConfigOverrides{},
+	).ClientConfig()
+	if err == nil {
+		test = withRestConfig(t, cfg)
+	} else {
+		// The error is reported by Client, like With does
+		test = With(t)
+	}
+	return applyTestHooks(test)
+}
+
+// applyTestHooks decorates the test with the hooks registered by RunSuite.
+func applyTestHooks(test Test) Test {
+	for _, hook := range testHooks {
+		test = hook(test)
+	}
+	return test
+}
+
+// configuredTest is the Test with the rest config its clients are created from, so the hooks decorating its
+// clients, i.e. DryRunFirst, create them from the same config.
+type configuredTest struct {
+	Test
+	cfg *rest.Config
+
+	configOnce   sync.Once
+	configClient configv1client.ConfigV1Interface
+	configErr    error
+}
+
+func (t *configuredTest) restConfig() *rest.Config {
+	return rest.CopyConfig(t.cfg)
+}
+
+// ConfigClient returns the client of the config.openshift.io API, created once like the other clients of the test.
+func (t *configuredTest) ConfigClient() (configv1client.ConfigV1Interface, error) {
+	t.configOnce.Do(func() {
+		t.configClient, t.configErr = configv1client.NewForConfig(t.restConfig())
+	})
+	return t.configClient, t.configErr
+}
+
+// withRestConfig returns the Test of the test like WithConfig, with the config exposed to the hooks.
+func withRestConfig(t *testing.T, cfg *rest.Config) Test {
+	t.Helper()
+	return &configuredTest{Test: WithConfig(t, cfg), cfg: cfg}
+}
+
+// configured returns the configuredTest decorated by the hooks of the test, if any.
+func configured(t Test) (*configuredTest, bool) {
+	for {
+		switch test := t.(type) {
+		case *configuredTest:
+			return test, true
+		case interface{ unwrap() Test }:
+			t = test.unwrap()
+		default:
+			return nil, false
+		}
+	}
+}
+
+// restConfig returns a copy of the rest config the clients of the test are created from, or the config of the
+// current kubeconfig context if the test doesn't expose it.
+func restConfig(t Test) (*rest.Config, error) {
+	if configured, ok := configured(t); ok {
+		return configured.restConfig(), nil
+	}
+	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		clientcmd.NewDefaultClientConfigLoadingRules(),
+		&clientcmd.ConfigOverrides{},
+	).ClientConfig()
+}
+
+// suite records the results of the tracked tests of the running test binary.
+var suite = struct {
+	sync.Mutex
+	results []TestResult
+	images  map[string]string
+}{images: map[string]string{}}
+
+// RunSuite runs the tests and reports the suite summary once they are done, it is meant to be called from TestMain:
+//
+//	func TestMain(m *testing.M) {
+//		os.Exit(RunSuite(m))
+//	}
+//
+// When TEST_CLUSTERS is set, the tests are run against each of the kubeconfig contexts one after another,
+// and the suite summary combines the results of all the runs. When TEST_CHANGED_COMPONENTS is set, the suite
+// is only run if it depends on any of the changed components.
+func RunSuite(m *testing.M) int {
+	if changed := splitEnvList(lookupEnvOrDefault(testChangedComponentsEnvVar, "")); len(changed) > 0 {
+		affected, reason := suiteAffected(suiteName(), changed)
+		if !affected {
+			fmt.Printf("Skipping %s suite, %s\n", suiteName(), reason)
+			return 0
+		}
+		fmt.Printf("Running %s suite, %s\n", suiteName(), reason)
+	}
+
+	configureTimeouts()
+	if IsDryRunFirst() {
+		// Registered first, so it decorates the clients of the configured Test
+		registerTestHook(DryRunFirst)
+	}
+	registerTestHook(recordAssertionFailures)
+	start := time.Now()
+	stopProgressDashboard := startProgressDashboard()
+	clusters := suiteClusters(suiteName())
+	code := 0
+	if len(clusters) == 0 {
+		code = m.Run()
+		if len(sweepClusterScoped()) > 0 {
+			code = 1
+		}
+	}
+	for _, cluster := range clusters {
+		fmt.Printf("Running %s suite against cluster %s\n", suiteName(), cluster)
+		restore, err := useCluster(cluster)
+		if err != nil {
+			fmt.Printf("Error switching to cluster %s: %v\n", cluster, err)
+			code = 1
+			continue
+		}
+		if clusterCode := m.Run(); clusterCode != 0 {
+			code = clusterCode
+		}
+		if len(sweepClusterScoped()) > 0 {
+			code = 1
+		}
+		restore()
+	}
+	stopProgressDashboard()
+
+	// The leaks are taken once the cluster-scoped objects of the last run are swept
+	summary := newSuiteSummary(time.Since(start), clusters, takeClusterScopedLeaks())
+	fmt.Print(formatFailureSummary(summary))
+	notifySuiteSummary(summary)
+	appendSuiteReport(summary)
+	recordDurationHistory(summary)
+
+	return code
+}
+
+// Track registers the test with its labels into the suite, so its result is part of the suite summary.
+// The test is skipped if its labels don't match the -labels filter. It should be called at the very beginning of the test.
+func Track(t *testing.T, labels ...string) {
+	t.Helper()
+	start := time.Now()
+	cluster := currentCluster
+	var timeout time.Duration
+	if deadline, ok := t.Deadline(); ok {
+		timeout = deadline.Sub(start)
+	}
+	progressTestStarted(t)
+	t.Cleanup(func() {
+		progressTestFinished(t)
+		// The artifacts are complete once the other cleanups, storing them, are done
+		for _, leak := range takeTokenLeaks(t.Name()) {
+			recordFailure(t.Name(), leak)
+			t.Errorf("Credential leak: %s", leak)
+		}
+		result := TestResult{
+			Name:     t.Name(),
+			Status:   TestPassed,
+			Duration: time.Since(start),
+			Labels:   labels,
+			Timeout:  timeout,
+			Cluster:  cluster,
+		}
+		result.Measurements = takeMeasurements(t.Name())
+		result.Contaminated = takeContaminations(t.Name())
+		if t.Failed() {
+			result.Status = TestFailed
+			result.Failures = takeFailures(t.Name())
+		} else if t.Skipped() {
+			result.Status = TestSkipped
+		}
+		if xfail, ok := takeXFail(t.Name()); ok && !t.Failed() {
+			result.Issue = xfail.issue
+			if xfail.failure != "" {
+				result.Status = TestXFailed
+				if result.Failures = takeFailures(t.Name()); len(result.Failures) == 0 {
+					result.Failures = []string{xfail.failure}
+				}
+			} else if !t.Skipped() {
+				result.Status = TestXPassed
+			}
+		}
+
+		suite.Lock()
+		defer suite.Unlock()
+		suite.results = append(suite.results, result)
+	})
+
+	if !labelsMatch(*labelsFilter, labels) {
+		t.Skipf("Test labels %v don't match the labels filter %q", labels, *labelsFilter)
+	}
+}
+
+func newSuiteSummary(duration time.Duration, clusters, leaked []string) SuiteSummary {
+	suite.Lock()
+	defer suite.Unlock()
+	return SuiteSummary{
+		Suite:    suiteName(),
+		Duration: duration,
+		Clusters: clusters,
+		Images:   maps.Clone(suite.images),
+		Results:  append([]TestResult(nil), suite.results...),
+		Leaked:   leaked,
+	}
+}
+
+// suiteName returns the name of the suite, derived from the name of the test binary.
+func suiteName() string {
+	return strings.TrimSuffix(filepath.Base(os.Args[0]), ".test")
+}
+
+// ForCluster returns the summary of the results of the tests run against the cluster.
+func (s SuiteSummary) ForCluster(cluster string) SuiteSummary {
+	summary := SuiteSummary{Suite: s.Suite, Duration: s.Duration, Clusters: []string{cluster}}
+	for _, result := range s.Results {
+		if result.Cluster == cluster {
+			summary.Results = append(summary.Results, result)
+		}
+	}
+	return summary
+}
+
+func (s SuiteSummary) Count(status TestStatus) int {
+	count := 0
+	for _, result := range s.Results {
+		if result.Status == status {
+			count++
+		}
+	}
+	return count
+}
+
+// Failed returns display names of the tests which failed in all their runs.
+func (s SuiteSummary) Failed() []string {
+	var failed []string
+	flaky := s.Flaky()
+	for _, result := range s.Results {
+		name := result.DisplayName()
+		if result.Status == TestFailed && !slices.Contains(flaky, name) && !slices.Contains(failed, name) {
+			failed = append(failed, name)
+		}
+	}
+	return failed
+}
+
+// Flaky returns display names of the tests which both failed and passed, i.e. when the suite is run with -count or retried.
+func (s SuiteSummary) Flaky() []string {
+	statuses := map[string]map[TestStatus]bool{}
+	for _, result := range s.Results {
+		name := result.DisplayName()
+		if statuses[name] == nil {
+			statuses[name] = map[TestStatus]bool{}
+		}
+		statuses[name][result.Status] = true
+	}
+	var flaky []string
+	for name, status := range statuses {
+		if status[TestPassed] && status[TestFailed] {
+			flaky = append(flaky, name)
+		}
+	}
+	sort.Strings(flaky)
+	return flaky
+}
+
+// XPassed returns display names of the tests marked with XFail which passed, with the issues they are marked with.
+func (s SuiteSummary) XPassed() []string {
+	var xpassed []string
+	for _, result := range s.Results {
+		if result.Status == TestXPassed {
+			xpassed = append(xpassed, fmt.Sprintf("%s (%s)", result.DisplayName(), result.Issue))
+		}
+	}
+	return xpassed
+}
+
+// Contaminated returns display names of the tests with measurements contaminated by the cluster, with the reasons.
+func (s SuiteSummary) Contaminated() []string {
+	var contaminated []string
+	for _, result := range s.Results {
+		if len(result.Contaminated) > 0 {
+			contaminated = append(contaminated, fmt.Sprintf("%s (%s)", result.DisplayName(), strings.Join(result.Contaminated, "; ")))
+		}
+	}
+	return contaminated
+}
+
+// Slowest returns up to n slowest test results.
+func (s SuiteSummary) Slowest(n int) []TestResult {
+	results := append([]TestResult(nil), s.Results...)
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Duration > results[j].Duration
+	})
+	if len(results) > n {
+		results = results[:n]
+	}
+	return results
+}
@@ -0,0 +1,106 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"testing"
+
+	"github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+)
+
+type hookedTest struct {
+	Test
+	hooks []string
+}
+
+func TestWithHooks(t *testing.T) {
+	g := gomega.NewWithT(t)
+	registered := testHooks
+	t.Cleanup(func() { testHooks = registered })
+
+	testHooks = nil
+	for _, name := range []string{"first", "second"} {
+		name := name
+		registerTestHook(func(test Test) Test {
+			hooked, ok := test.(*hookedTest)
+			if !ok {
+				hooked = &hookedTest{Test: test}
+			}
+			hooked.hooks = append(hooked.hooks, name)
+			return hooked
+		})
+	}
+
+	test := WithHooks(t)
+	g.Expect(test).To(gomega.BeAssignableToTypeOf(&hookedTest{}))
+	g.Expect(test.(*hookedTest).hooks).To(gomega.Equal([]string{"first", "second"}))
+	g.Expect(test.T()).To(gomega.BeIdenticalTo(t))
+}
+
+func TestSuiteSummaryFlaky(t *testing.T) {
+	tests := []struct {
+		name    string
+		results []TestResult
+		flaky   []string
+		failed  []string
+	}{
+		{
+			name:    "failed in all runs",
+			results: []TestResult{{Name: "TestA", Status: TestFailed}, {Name: "TestA", Status: TestFailed}},
+			failed:  []string{"TestA"},
+		},
+		{
+			name:    "failed then passed",
+			results: []TestResult{{Name: "TestA", Status: TestFailed}, {Name: "TestA", Status: TestPassed}},
+			flaky:   []string{"TestA"},
+		},
+		{
+			name:    "skipped then failed",
+			results: []TestResult{{Name: "TestA", Status: TestSkipped}, {Name: "TestA", Status: TestFailed}},
+			failed:  []string{"TestA"},
+		},
+		{
+			name: "failed against one cluster and passed against another",
+			results: []TestResult{
+				{Name: "TestA", Status: TestFailed, Cluster: "cpu"},
+				{Name: "TestA", Status: TestPassed, Cluster: "gpu"},
+			},
+			failed: []string{"TestA [cpu]"},
+		},
+		{
+			name: "flaky and failed tests",
+			results: []TestResult{
+				{Name: "TestB", Status: TestFailed},
+				{Name: "TestA", Status: TestPassed},
+				{Name: "TestC", Status: TestFailed},
+				{Name: "TestB", Status: TestPassed},
+				{Name: "TestA", Status: TestFailed},
+			},
+			flaky:  []string{"TestA", "TestB"},
+			failed: []string{"TestC"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := gomega.NewWithT(t)
+			summary := SuiteSummary{Suite: "kueue", Results: tt.results}
+			g.Expect(summary.Flaky()).To(gomega.Equal(tt.flaky))
+			g.Expect(summary.Failed()).To(gomega.Equal(tt.failed))
+		})
+	}
+}
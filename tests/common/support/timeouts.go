@@ -0,0 +1,81 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"flag"
+	"fmt"
+	"time"
+
+	"github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+)
+
+// The timeouts are read from the CODEFLARE_TEST_TIMEOUT_SHORT, CODEFLARE_TEST_TIMEOUT_MEDIUM, CODEFLARE_TEST_TIMEOUT_LONG
+// and CODEFLARE_TEST_TIMEOUT_GPU_PROVISIONING environment variables, the flags take precedence over them.
+var (
+	timeoutShortFlag           = flag.Duration("timeout-short", 0, "Timeout of short tasks, overrides CODEFLARE_TEST_TIMEOUT_SHORT")
+	timeoutMediumFlag          = flag.Duration("timeout-medium", 0, "Timeout of medium tasks, overrides CODEFLARE_TEST_TIMEOUT_MEDIUM")
+	timeoutLongFlag            = flag.Duration("timeout-long", 0, "Timeout of long tasks, overrides CODEFLARE_TEST_TIMEOUT_LONG")
+	timeoutGpuProvisioningFlag = flag.Duration("timeout-gpu-provisioning", 0, "Timeout of GPU nodes provisioning, overrides CODEFLARE_TEST_TIMEOUT_GPU_PROVISIONING")
+	pollingIntervalFlag        = flag.Duration("polling-interval", 0, "Polling interval of Eventually and Consistently assertions, overrides "+pollingIntervalEnvVar)
+)
+
+// pollingInterval is the polling interval of the assertions not polling with a strategy tuned for the resource kind.
+var pollingInterval = time.Second
+
+// configureTimeouts applies the timeouts and polling interval set with the flags and environment variables, and
+// prints them, so the values a suite ran with are part of its output.
+func configureTimeouts() {
+	if !flag.Parsed() {
+		flag.Parse()
+	}
+	override := func(timeout *time.Duration, value time.Duration) {
+		if value > 0 {
+			*timeout = value
+		}
+	}
+	override(&TestTimeoutShort, *timeoutShortFlag)
+	override(&TestTimeoutMedium, *timeoutMediumFlag)
+	override(&TestTimeoutLong, *timeoutLongFlag)
+	override(&TestTimeoutGpuProvisioning, *timeoutGpuProvisioningFlag)
+
+	if value, ok := lookupPollingInterval(); ok {
+		pollingInterval = value
+	}
+	override(&pollingInterval, *pollingIntervalFlag)
+
+	gomega.SetDefaultEventuallyTimeout(TestTimeoutShort)
+	gomega.SetDefaultEventuallyPollingInterval(pollingInterval)
+	gomega.SetDefaultConsistentlyPollingInterval(pollingInterval)
+
+	fmt.Printf("Timeouts: short %s, medium %s, long %s, GPU provisioning %s, polling interval %s\n",
+		TestTimeoutShort, TestTimeoutMedium, TestTimeoutLong, TestTimeoutGpuProvisioning, pollingInterval)
+}
+
+func lookupPollingInterval() (time.Duration, bool) {
+	value := lookupEnvOrDefault(pollingIntervalEnvVar, "")
+	if value == "" {
+		return 0, false
+	}
+	interval, err := time.ParseDuration(value)
+	if err != nil || interval <= 0 {
+		fmt.Printf("Error parsing %s %q, using the default polling interval of %s\n", pollingIntervalEnvVar, value, pollingInterval)
+		return 0, false
+	}
+	return interval, true
+}
@@ -0,0 +1,89 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"bytes"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+type trackedToken struct {
+	token     string
+	owner     ObjectRef
+	outputDir string
+}
+
+// tokens records the tokens created by tests, their artifacts are scanned for them once the tests finish.
+var tokens = struct {
+	sync.Mutex
+	byTest map[string][]trackedToken
+}{byTest: map[string][]trackedToken{}}
+
+// CreateTrackedToken creates a token for the service account, like CreateToken, and tracks it so the test fails
+// if the token leaks into its artifacts, i.e. pod logs or resources stored in the test output directory.
+// The artifacts are only kept, and scanned, when CODEFLARE_TEST_OUTPUT_DIR is set.
+func CreateTrackedToken(t Test, namespace string, serviceAccount *corev1.ServiceAccount) string {
+	t.T().Helper()
+	token := CreateToken(t, namespace, serviceAccount)
+
+	tokens.Lock()
+	defer tokens.Unlock()
+	tokens.byTest[t.T().Name()] = append(tokens.byTest[t.T().Name()], trackedToken{
+		token:     token,
+		owner:     Ref("ServiceAccount", namespace, serviceAccount.Name),
+		outputDir: t.OutputDir(),
+	})
+	return token
+}
+
+// takeTokenLeaks scans the artifacts of the test and its subtests for the tokens they created, and returns the leaks found.
+func takeTokenLeaks(testName string) []string {
+	tokens.Lock()
+	var tracked []trackedToken
+	for name, created := range tokens.byTest {
+		if name == testName || strings.HasPrefix(name, testName+"/") {
+			tracked = append(tracked, created...)
+			delete(tokens.byTest, name)
+		}
+	}
+	tokens.Unlock()
+
+	var leaks []string
+	for _, token := range tracked {
+		// The ephemeral output directory is already removed when CODEFLARE_TEST_OUTPUT_DIR isn't set
+		_ = filepath.WalkDir(token.outputDir, func(path string, entry fs.DirEntry, err error) error {
+			if err != nil || entry.IsDir() {
+				return nil
+			}
+			content, err := os.ReadFile(path)
+			if err == nil && bytes.Contains(content, []byte(token.token)) {
+				leaks = append(leaks, fmt.Sprintf("token of %s leaked into %s", token.owner, path))
+			}
+			return nil
+		})
+	}
+	return leaks
+}
@@ -0,0 +1,244 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"encoding/json"
+	"strconv"
+
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// Topology manager policy admitting pods only when all their devices and CPUs are aligned to a single NUMA node
+	SingleNumaNodeTopologyPolicy = "single-numa-node"
+
+	// Directory of the kubelet pod resources API socket on the nodes
+	podResourcesDir = "/var/lib/kubelet/pod-resources"
+
+	// Subset of the kubelet pod resources API v1 needed to list the devices assigned to the pods with their NUMA nodes,
+	// the kubelet doesn't serve gRPC reflection so grpcurl needs the service definition
+	podResourcesProto = `syntax = "proto3";
+
+package v1;
+
+service PodResourcesLister {
+    rpc List(ListPodResourcesRequest) returns (ListPodResourcesResponse) {}
+}
+
+message ListPodResourcesRequest {}
+
+message ListPodResourcesResponse {
+    repeated PodResources pod_resources = 1;
+}
+
+message PodResources {
+    string name = 1;
+    string namespace = 2;
+    repeated ContainerResources containers = 3;
+}
+
+message ContainerResources {
+    string name = 1;
+    repeated ContainerDevices devices = 2;
+}
+
+message ContainerDevices {
+    string resource_name = 1;
+    repeated string device_ids = 2;
+    TopologyInfo topology = 3;
+}
+
+message TopologyInfo {
+    repeated NUMANode nodes = 1;
+}
+
+message NUMANode {
+    int64 ID = 1;
+}
+`
+)
+
+// ContainerDevices are the devices of a resource assigned to a container, as reported by the kubelet pod resources API.
+type ContainerDevices struct {
+	ResourceName string
+	DeviceIDs    []string
+	// NumaNodes are the NUMA nodes the devices are attached to
+	NumaNodes []int64
+}
+
+type podResourcesList struct {
+	PodResources []struct {
+		Name       string `json:"name"`
+		Namespace  string `json:"namespace"`
+		Containers []struct {
+			Name    string `json:"name"`
+			Devices []struct {
+				ResourceName string   `json:"resourceName"`
+				DeviceIDs    []string `json:"deviceIds"`
+				Topology     struct {
+					Nodes []struct {
+						// int64 fields are encoded as strings in protobuf JSON
+						ID string `json:"ID"`
+					} `json:"nodes"`
+				} `json:"topology"`
+			} `json:"devices"`
+		} `json:"containers"`
+	} `json:"podResources"`
+}
+
+// GetTopologyManagerPolicy returns the topology manager policy of the kubelet running on the node, read from its configz endpoint.
+func GetTopologyManagerPolicy(t Test, node corev1.Node) string {
+	t.T().Helper()
+	data, err := t.Client().Core().CoreV1().RESTClient().Get().
+		AbsPath("/api/v1/nodes", node.Name, "proxy", "configz").
+		DoRaw(t.Ctx())
+	ExpectNoError(t, err, "getting kubelet configuration of", Ref("Node", "", node.Name))
+
+	configz := struct {
+		KubeletConfig struct {
+			TopologyManagerPolicy string `json:"topologyManagerPolicy"`
+		} `json:"kubeletconfig"`
+	}{}
+	ExpectNoError(t, json.Unmarshal(data, &configz), "parsing kubelet configuration of", Ref("Node", "", node.Name))
+	return configz.KubeletConfig.TopologyManagerPolicy
+}
+
+// GetContainerDevices returns the devices assigned to the container of the running pod, read from the kubelet pod resources API
+// by a privileged probe pod running on the node of the pod.
+func GetContainerDevices(t Test, pod *corev1.Pod, container string) []ContainerDevices {
+	t.T().Helper()
+
+	list := podResourcesList{}
+	ExpectNoError(t, json.Unmarshal(listPodResources(t, pod.Namespace, pod.Spec.NodeName), &list),
+		"parsing pod resources of", Ref("Node", "", pod.Spec.NodeName))
+
+	var devices []ContainerDevices
+	for _, podResources := range list.PodResources {
+		if podResources.Namespace != pod.Namespace || podResources.Name != pod.Name {
+			continue
+		}
+		for _, containerResources := range podResources.Containers {
+			if containerResources.Name != container {
+				continue
+			}
+			for _, device := range containerResources.Devices {
+				containerDevices := ContainerDevices{ResourceName: device.ResourceName, DeviceIDs: device.DeviceIDs}
+				for _, numaNode := range device.Topology.Nodes {
+					id, err := strconv.ParseInt(numaNode.ID, 10, 64)
+					ExpectNoError(t, err, "parsing NUMA node of the devices of", Ref("Pod", pod.Namespace, pod.Name))
+					containerDevices.NumaNodes = append(containerDevices.NumaNodes, id)
+				}
+				devices = append(devices, containerDevices)
+			}
+		}
+	}
+	return devices
+}
+
+// listPodResources calls the List method of the kubelet pod resources API on the node with grpcurl and returns its JSON output.
+func listPodResources(t Test, namespace, nodeName string) []byte {
+	t.T().Helper()
+
+	proto := CreateConfigMap(t, namespace, map[string][]byte{
+		"podresources.proto": []byte(podResourcesProto),
+	})
+
+	// The probe mounts the kubelet socket, which requires a privileged pod
+	serviceAccount := CreateServiceAccount(t, namespace)
+	if IsOpenShift(t) {
+		role := CreateRole(t, namespace, []rbacv1.PolicyRule{
+			{
+				Verbs:         []string{"use"},
+				APIGroups:     []string{"security.openshift.io"},
+				Resources:     []string{"securitycontextconstraints"},
+				ResourceNames: []string{"privileged"},
+			},
+		})
+		CreateRoleBinding(t, namespace, serviceAccount, role)
+	}
+
+	pod := CreatePod(t, &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "pod-resources-probe-",
+			Namespace:    namespace,
+		},
+		Spec: corev1.PodSpec{
+			NodeName:           nodeName,
+			ServiceAccountName: serviceAccount.Name,
+			RestartPolicy:      corev1.RestartPolicyNever,
+			Tolerations: []corev1.Toleration{
+				{
+					Operator: corev1.TolerationOpExists,
+				},
+			},
+			Containers: []corev1.Container{
+				{
+					Name:  "grpcurl",
+					Image: GetGrpcurlImage(),
+					Args: []string{"-plaintext", "-import-path", "/proto", "-proto", "podresources.proto",
+						"-unix", podResourcesDir + "/kubelet.sock", "v1.PodResourcesLister/List"},
+					SecurityContext: &corev1.SecurityContext{
+						Privileged: Ptr(true),
+					},
+					VolumeMounts: []corev1.VolumeMount{
+						{
+							Name:      "pod-resources",
+							MountPath: podResourcesDir,
+						},
+						{
+							Name:      "proto",
+							MountPath: "/proto",
+						},
+					},
+				},
+			},
+			Volumes: []corev1.Volume{
+				{
+					Name: "pod-resources",
+					VolumeSource: corev1.VolumeSource{
+						HostPath: &corev1.HostPathVolumeSource{
+							Path: podResourcesDir,
+						},
+					},
+				},
+				{
+					Name: "proto",
+					VolumeSource: corev1.VolumeSource{
+						ConfigMap: &corev1.ConfigMapVolumeSource{
+							LocalObjectReference: corev1.LocalObjectReference{
+								Name: proto.Name,
+							},
+						},
+					},
+				},
+			},
+		},
+	})
+	defer deletePod(t, namespace, pod.Name)
+
+	EventuallyOf(t, Pod(t, namespace, pod.Name), TestTimeoutMedium).
+		Should(Field(PodPhase).OneOf(corev1.PodSucceeded, corev1.PodFailed))
+	logs := GetPodLogs(t, GetPod(t, namespace, pod.Name), corev1.PodLogOptions{})
+	ExpectOf(t, GetPod(t, namespace, pod.Name)).To(Field(PodPhase).Equal(corev1.PodSucceeded),
+		"Listing pod resources on node %s failed, logs:\n%s", nodeName, logs)
+	return logs
+}
@@ -0,0 +1,163 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/onsi/gomega"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+// DeletedState is recorded once the watched resource is deleted.
+const DeletedState = "Deleted"
+
+// StateFunc returns the state of the resource, empty states aren't recorded.
+type StateFunc func(object *unstructured.Unstructured) string
+
+// StatusFieldState returns the state stored in the nested string field of the resource, i.e. status.phase.
+func StatusFieldState(fields ...string) StateFunc {
+	return func(object *unstructured.Unstructured) string {
+		state, _, _ := unstructured.NestedString(object.Object, fields...)
+		return state
+	}
+}
+
+// StateRecorder records the states of a resource observed by a watch, so short-lived states
+// that polling would miss are kept for assertions.
+type StateRecorder struct {
+	ref    ObjectRef
+	mutex  sync.Mutex
+	states []string
+}
+
+// RecordStates starts watching the resource and records its states until the test finishes.
+// The recording can start before the resource is created, otherwise the states before the first
+// observed one are missed.
+func RecordStates(t Test, resource schema.GroupVersionResource, kind, namespace, name string, state StateFunc) *StateRecorder {
+	t.T().Helper()
+
+	recorder := &StateRecorder{ref: Ref(kind, namespace, name)}
+	ctx, cancel := context.WithCancel(t.Ctx())
+	t.T().Cleanup(cancel)
+
+	client := t.Client().Dynamic().Resource(resource).Namespace(namespace)
+	options := metav1.ListOptions{FieldSelector: fields.OneTermEqualSelector("metadata.name", name).String()}
+	w, err := client.Watch(ctx, options)
+	ExpectNoError(t, err, "watching", recorder.ref)
+
+	go func() {
+		for {
+			for event := range w.ResultChan() {
+				switch event.Type {
+				case watch.Added, watch.Modified:
+					object := event.Object.(*unstructured.Unstructured)
+					recorder.record(state(object))
+					options.ResourceVersion = object.GetResourceVersion()
+				case watch.Deleted:
+					recorder.record(DeletedState)
+					options.ResourceVersion = event.Object.(*unstructured.Unstructured).GetResourceVersion()
+				case watch.Error:
+					// Resume from the current state when the resource version is too old
+					if errors.IsResourceExpired(errors.FromObject(event.Object)) || errors.IsGone(errors.FromObject(event.Object)) {
+						options.ResourceVersion = ""
+					}
+				}
+			}
+			// The watch is closed by the API server periodically, resume it until the test finishes
+			for {
+				if ctx.Err() != nil {
+					return
+				}
+				if w, err = client.Watch(ctx, options); err == nil {
+					break
+				}
+				select {
+				case <-ctx.Done():
+				case <-time.After(time.Second):
+				}
+			}
+		}
+	}()
+
+	return recorder
+}
+
+func (r *StateRecorder) record(state string) {
+	if state == "" {
+		return
+	}
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	if len(r.states) > 0 && r.states[len(r.states)-1] == state {
+		return
+	}
+	r.states = append(r.states, state)
+}
+
+// States returns the recorded states in the order they were observed, without repeated consecutive states.
+func (r *StateRecorder) States() []string {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+	return append([]string(nil), r.states...)
+}
+
+// ExpectTransitions waits for the recorded resource to reach the last of the states, and asserts it passed
+// through the states in order, reporting any unexpected intermediate state.
+func ExpectTransitions(t Test, recorder *StateRecorder, states []string, timeout time.Duration) {
+	t.T().Helper()
+	t.Expect(states).NotTo(gomega.BeEmpty())
+
+	last := states[len(states)-1]
+	t.Eventually(recorder.States, timeout).Should(gomega.ContainElement(last),
+		"%s didn't reach state %s", recorder.ref, last)
+
+	observed := recorder.States()
+	for i, state := range observed {
+		if state == last {
+			observed = observed[:i+1]
+			break
+		}
+	}
+	t.Expect(observed).To(gomega.Equal(states), "%s went through %s, unexpected states: %v",
+		recorder.ref, strings.Join(observed, " -> "), unexpectedStates(observed, states))
+}
+
+// unexpectedStates returns the observed states missing from the expected states.
+func unexpectedStates(observed, expected []string) []string {
+	var unexpected []string
+	for _, state := range observed {
+		found := false
+		for _, expectedState := range expected {
+			found = found || state == expectedState
+		}
+		if !found {
+			unexpected = append(unexpected, state)
+		}
+	}
+	return unexpected
+}
@@ -0,0 +1,188 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"fmt"
+
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const (
+	// Label identifying the purpose of the cluster-scoped RBAC resources created by the tests
+	rbacPurposeLabel = "distributed-workloads.opendatahub.io/purpose"
+	// Label referencing the test namespace owning the cluster-scoped RBAC resources
+	rbacTestNamespaceLabel = "distributed-workloads.opendatahub.io/test-namespace"
+	// Annotation recording the name of the test creating the cluster-scoped RBAC resources
+	rbacTestAnnotation = "distributed-workloads.opendatahub.io/test"
+
+	crossNamespaceViewerPurpose = "cross-namespace-viewer"
+)
+
+// viewerVerbs are the only verbs granted to cross-namespace viewers.
+var viewerVerbs = []string{"get", "list", "watch"}
+
+// CrossNamespaceViewer is a service account with read access to resources across all the namespaces,
+// granted by a ClusterRole scoped to these resources.
+type CrossNamespaceViewer struct {
+	ServiceAccount     string
+	Namespace          string
+	ClusterRole        string
+	ClusterRoleBinding string
+	Token              string
+}
+
+// ViewerRule returns the rule granting read access to the resources of the API group, i.e.
+// ViewerRule("kueue.x-k8s.io", "workloads", "localqueues").
+func ViewerRule(apiGroup string, resources ...string) rbacv1.PolicyRule {
+	return rbacv1.PolicyRule{
+		APIGroups: []string{apiGroup},
+		Resources: resources,
+		Verbs:     viewerVerbs,
+	}
+}
+
+// CreateCrossNamespaceViewer creates a service account in the namespace, bound to a ClusterRole granting read access
+// to the resources of the rules across all the namespaces, and returns it with its token. Rules granting other verbs
+// than get, list and watch, or granting wildcards, fail the test, so the tests needing cross-namespace access don't
+// end up using cluster-admin. The ClusterRole and its binding are labelled with the test namespace and annotated with
+// the test name, so leftovers can be traced back to their test, and are deleted once the test finishes.
+func CreateCrossNamespaceViewer(t Test, namespace string, rules ...rbacv1.PolicyRule) CrossNamespaceViewer {
+	t.T().Helper()
+
+	if len(rules) == 0 {
+		t.T().Fatalf("Cross-namespace viewer requires at least one rule")
+	}
+	for _, rule := range rules {
+		if err := validateViewerRule(rule); err != nil {
+			t.T().Fatalf("Invalid cross-namespace viewer rule %v: %v", rule, err)
+		}
+	}
+
+	objectMeta := metav1.ObjectMeta{
+		GenerateName: "test-viewer-",
+		Labels: map[string]string{
+			rbacPurposeLabel:       crossNamespaceViewerPurpose,
+			rbacTestNamespaceLabel: namespace,
+		},
+		Annotations: map[string]string{
+			rbacTestAnnotation: t.T().Name(),
+		},
+	}
+
+	serviceAccount := CreateServiceAccount(t, namespace)
+
+	clusterRole := &rbacv1.ClusterRole{
+		ObjectMeta: objectMeta,
+		Rules:      rules,
+	}
+	clusterRole, err := t.Client().Core().RbacV1().ClusterRoles().Create(t.Ctx(), clusterRole, metav1.CreateOptions{})
+	ExpectNoError(t, err, "creating", Ref("ClusterRole", "", objectMeta.GenerateName))
+	TrackClusterScoped(t, rbacv1.SchemeGroupVersion.WithResource("clusterroles"), clusterRole)
+	t.T().Cleanup(func() {
+		deleteClusterScoped(t, Ref("ClusterRole", "", clusterRole.Name),
+			t.Client().Core().RbacV1().ClusterRoles().Delete(t.Ctx(), clusterRole.Name, metav1.DeleteOptions{}))
+	})
+
+	clusterRoleBinding := &rbacv1.ClusterRoleBinding{
+		ObjectMeta: objectMeta,
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.SchemeGroupVersion.Group,
+			Kind:     "ClusterRole",
+			Name:     clusterRole.Name,
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      rbacv1.ServiceAccountKind,
+				Name:      serviceAccount.Name,
+				Namespace: namespace,
+			},
+		},
+	}
+	clusterRoleBinding, err = t.Client().Core().RbacV1().ClusterRoleBindings().Create(t.Ctx(), clusterRoleBinding, metav1.CreateOptions{})
+	ExpectNoError(t, err, "creating", Ref("ClusterRoleBinding", "", objectMeta.GenerateName))
+	TrackClusterScoped(t, rbacv1.SchemeGroupVersion.WithResource("clusterrolebindings"), clusterRoleBinding)
+	// Cleanups run in reverse order, so the binding is deleted before the role it references
+	t.T().Cleanup(func() {
+		deleteClusterScoped(t, Ref("ClusterRoleBinding", "", clusterRoleBinding.Name),
+			t.Client().Core().RbacV1().ClusterRoleBindings().Delete(t.Ctx(), clusterRoleBinding.Name, metav1.DeleteOptions{}))
+	})
+	t.T().Logf("Created cross-namespace viewer %s/%s bound to ClusterRole %s", namespace, serviceAccount.Name, clusterRole.Name)
+
+	return CrossNamespaceViewer{
+		ServiceAccount:     serviceAccount.Name,
+		Namespace:          namespace,
+		ClusterRole:        clusterRole.Name,
+		ClusterRoleBinding: clusterRoleBinding.Name,
+		Token:              CreateTrackedToken(t, namespace, serviceAccount),
+	}
+}
+
+// Client returns a client of the cluster authenticated as the viewer.
+func (v CrossNamespaceViewer) Client(t Test) kubernetes.Interface {
+	t.T().Helper()
+	cfg, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+		clientcmd.NewDefaultClientConfigLoadingRules(),
+		&clientcmd.ConfigOverrides{},
+	).ClientConfig()
+	ExpectNoError(t, err, "loading client configuration for", Ref("ServiceAccount", v.Namespace, v.ServiceAccount))
+
+	// Drop the credentials of the test user, so the requests are only authorized by the ClusterRole of the viewer
+	cfg = rest.AnonymousClientConfig(cfg)
+	cfg.BearerToken = v.Token
+	client, err := kubernetes.NewForConfig(cfg)
+	ExpectNoError(t, err, "creating client for", Ref("ServiceAccount", v.Namespace, v.ServiceAccount))
+	return client
+}
+
+func validateViewerRule(rule rbacv1.PolicyRule) error {
+	if len(rule.NonResourceURLs) > 0 {
+		return fmt.Errorf("non-resource URLs aren't allowed")
+	}
+	if len(rule.Resources) == 0 {
+		return fmt.Errorf("no resources")
+	}
+	for _, verb := range rule.Verbs {
+		if verb != "get" && verb != "list" && verb != "watch" {
+			return fmt.Errorf("verb %q isn't read-only", verb)
+		}
+	}
+	for _, values := range [][]string{rule.APIGroups, rule.Resources, rule.Verbs} {
+		for _, value := range values {
+			if value == rbacv1.ResourceAll {
+				return fmt.Errorf("wildcards aren't allowed")
+			}
+		}
+	}
+	return nil
+}
+
+// deleteClusterScoped reports the failed deletion of a cluster-scoped resource created by the test,
+// as it isn't garbage collected along with the test namespace.
+func deleteClusterScoped(t Test, ref ObjectRef, err error) {
+	t.T().Helper()
+	if err != nil && !errors.IsNotFound(err) {
+		t.T().Errorf("Failed to delete %s, delete it manually: %v", ref, err)
+	}
+}
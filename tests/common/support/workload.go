@@ -0,0 +1,304 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package support
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/onsi/gomega"
+	awv1beta2 "github.com/project-codeflare/appwrapper/api/v1beta2"
+	. "github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+
+	kftov1 "github.com/kubeflow/training-operator/pkg/apis/kubeflow.org/v1"
+)
+
+// SubmitOptions configure how SubmitAndWait waits for the workload.
+type SubmitOptions struct {
+	// Timeout for the workload to finish, defaults to TestTimeoutLong
+	Timeout time.Duration
+}
+
+// WorkloadResult summarizes the run of a workload submitted by SubmitAndWait.
+type WorkloadResult struct {
+	Kind      string `json:"kind"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+	// Status is the final status of the workload, i.e. Succeeded or Failed for a PyTorchJob
+	Status    string `json:"status"`
+	Succeeded bool   `json:"succeeded"`
+	Message   string `json:"message,omitempty"`
+	// QueuedDuration is the time from the submission until the workload started running, zero if it never ran
+	QueuedDuration time.Duration `json:"queuedDuration"`
+	// Duration is the time from the submission until the workload finished
+	Duration time.Duration `json:"duration"`
+	Pods     []PodSummary  `json:"pods"`
+}
+
+// PodSummary summarizes a pod of a workload once the workload finished.
+type PodSummary struct {
+	Name     string          `json:"name"`
+	Node     string          `json:"node"`
+	Phase    corev1.PodPhase `json:"phase"`
+	Restarts int32           `json:"restarts"`
+	// Reason is the reason the last container terminated, i.e. OOMKilled or Error
+	Reason string `json:"reason,omitempty"`
+}
+
+// workloadState is the state of a workload observed while waiting for it.
+type workloadState struct {
+	status    string
+	message   string
+	running   bool
+	finished  bool
+	succeeded bool
+}
+
+// workloadHandler creates and observes a workload of a supported type.
+type workloadHandler struct {
+	kind   string
+	create func(t Test) metav1.Object
+	state  func(t Test, g gomega.Gomega, namespace, name string) workloadState
+	// podSelectors select the pods of the workload, the RayJob pods span its RayCluster and its submitter
+	podSelectors func(t Test, namespace, name string) []string
+}
+
+// SubmitAndWait submits the workload, waits until it finishes, collects the logs of its pods with the test output,
+// and returns the result of the run. Supported workloads are PyTorchJob, RayJob, AppWrapper and batch Job.
+// The workload failing doesn't fail the test, assert on the result instead, i.e. result.Succeeded.
+func SubmitAndWait(t Test, workload any, opts SubmitOptions) *WorkloadResult {
+	t.T().Helper()
+
+	handler := newWorkloadHandler(t, workload)
+	timeout := opts.Timeout
+	if timeout == 0 {
+		timeout = TestTimeoutLong
+	}
+
+	stopwatch := StartStopwatch()
+	created := handler.create(t)
+	result := &WorkloadResult{Kind: handler.kind, Namespace: created.GetNamespace(), Name: created.GetName()}
+
+	var state workloadState
+	EventuallyWithPolling(t, func(g gomega.Gomega) bool {
+		state = handler.state(t, g, result.Namespace, result.Name)
+		if state.running && result.QueuedDuration == 0 {
+			result.QueuedDuration = stopwatch.Elapsed()
+			t.T().Logf("%s %s/%s started running after %s", result.Kind, result.Namespace, result.Name, result.QueuedDuration.Round(time.Second))
+		}
+		return state.finished
+	}, timeout, PollingStrategyFor(handler.kind)).Should(gomega.BeTrue(),
+		"%s %s/%s didn't finish within %s", result.Kind, result.Namespace, result.Name, timeout)
+
+	result.Duration = stopwatch.Elapsed()
+	result.Status, result.Message, result.Succeeded = state.status, state.message, state.succeeded
+	if result.Succeeded && result.QueuedDuration == 0 {
+		// The workload ran between two polls
+		result.QueuedDuration = result.Duration
+	}
+	t.T().Logf("%s %s/%s finished with status %s after %s", result.Kind, result.Namespace, result.Name, result.Status, result.Duration.Round(time.Second))
+
+	for _, selector := range handler.podSelectors(t, result.Namespace, result.Name) {
+		pods, err := t.Client().Core().CoreV1().Pods(result.Namespace).List(t.Ctx(), metav1.ListOptions{LabelSelector: selector})
+		ExpectNoError(t, err, "listing pods of", Ref(result.Kind, result.Namespace, result.Name))
+		for i := range pods.Items {
+			result.Pods = append(result.Pods, summarizePod(&pods.Items[i]))
+			storePodLogs(t, &pods.Items[i])
+		}
+	}
+
+	content, err := json.MarshalIndent(result, "", "  ")
+	t.Expect(err).NotTo(gomega.HaveOccurred())
+	WriteToOutputDir(t, "workload-result-"+result.Name, Log, content)
+
+	return result
+}
+
+func newWorkloadHandler(t Test, workload any) workloadHandler {
+	t.T().Helper()
+
+	switch workload := workload.(type) {
+	case *kftov1.PyTorchJob:
+		return workloadHandler{
+			kind: "PyTorchJob",
+			create: func(t Test) metav1.Object {
+				created, err := t.Client().Kubeflow().KubeflowV1().PyTorchJobs(workload.Namespace).Create(t.Ctx(), workload, metav1.CreateOptions{})
+				ExpectNoError(t, err, "creating", Ref("PyTorchJob", workload.Namespace, workload.Name+workload.GenerateName))
+				return created
+			},
+			state: func(t Test, g gomega.Gomega, namespace, name string) workloadState {
+				job, err := t.Client().Kubeflow().KubeflowV1().PyTorchJobs(namespace).Get(t.Ctx(), name, metav1.GetOptions{})
+				g.Expect(WrapError(err, "getting", Ref("PyTorchJob", namespace, name))).NotTo(gomega.HaveOccurred())
+				state := workloadState{}
+				for _, condition := range job.Status.Conditions {
+					if condition.Status != corev1.ConditionTrue {
+						continue
+					}
+					state.status, state.message = string(condition.Type), condition.Message
+					state.running = state.running || condition.Type == kftov1.JobRunning
+					state.finished = condition.Type == kftov1.JobSucceeded || condition.Type == kftov1.JobFailed
+					state.succeeded = condition.Type == kftov1.JobSucceeded
+				}
+				return state
+			},
+			podSelectors: func(t Test, namespace, name string) []string {
+				return []string{"training.kubeflow.org/job-name=" + name}
+			},
+		}
+
+	case *rayv1.RayJob:
+		return workloadHandler{
+			kind: "RayJob",
+			create: func(t Test) metav1.Object {
+				created, err := t.Client().Ray().RayV1().RayJobs(workload.Namespace).Create(t.Ctx(), workload, metav1.CreateOptions{})
+				ExpectNoError(t, err, "creating", Ref("RayJob", workload.Namespace, workload.Name+workload.GenerateName))
+				return created
+			},
+			state: func(t Test, g gomega.Gomega, namespace, name string) workloadState {
+				job := RayJob(t, namespace, name)(g)
+				return workloadState{
+					status:    string(job.Status.JobStatus),
+					message:   job.Status.Message,
+					running:   job.Status.JobStatus == rayv1.JobStatusRunning,
+					finished:  job.Status.JobDeploymentStatus == rayv1.JobDeploymentStatusComplete,
+					succeeded: job.Status.JobStatus == rayv1.JobStatusSucceeded,
+				}
+			},
+			podSelectors: func(t Test, namespace, name string) []string {
+				selectors := []string{"job-name=" + name}
+				if rayCluster := GetRayJob(t, namespace, name).Status.RayClusterName; rayCluster != "" {
+					selectors = append(selectors, "ray.io/cluster="+rayCluster)
+				}
+				return selectors
+			},
+		}
+
+	case *awv1beta2.AppWrapper:
+		return workloadHandler{
+			kind: "AppWrapper",
+			create: func(t Test) metav1.Object {
+				return CreateAppWrapper(t, workload)
+			},
+			state: func(t Test, g gomega.Gomega, namespace, name string) workloadState {
+				object, err := t.Client().Dynamic().Resource(appWrapperResource).Namespace(namespace).Get(t.Ctx(), name, metav1.GetOptions{})
+				g.Expect(WrapError(err, "getting", Ref("AppWrapper", namespace, name))).NotTo(gomega.HaveOccurred())
+				appWrapper := &awv1beta2.AppWrapper{}
+				g.Expect(runtime.DefaultUnstructuredConverter.FromUnstructured(object.UnstructuredContent(), appWrapper)).To(gomega.Succeed())
+				state := workloadState{
+					status:    string(appWrapper.Status.Phase),
+					running:   appWrapper.Status.Phase == awv1beta2.AppWrapperRunning,
+					finished:  appWrapper.Status.Phase == awv1beta2.AppWrapperSucceeded || appWrapper.Status.Phase == awv1beta2.AppWrapperFailed,
+					succeeded: appWrapper.Status.Phase == awv1beta2.AppWrapperSucceeded,
+				}
+				for _, condition := range appWrapper.Status.Conditions {
+					if condition.Status == metav1.ConditionFalse && condition.Message != "" {
+						state.message = condition.Message
+					}
+				}
+				return state
+			},
+			podSelectors: func(t Test, namespace, name string) []string {
+				return []string{AppWrapperNameLabel + "=" + name}
+			},
+		}
+
+	case *batchv1.Job:
+		return workloadHandler{
+			kind: "Job",
+			create: func(t Test) metav1.Object {
+				created, err := t.Client().Core().BatchV1().Jobs(workload.Namespace).Create(t.Ctx(), workload, metav1.CreateOptions{})
+				ExpectNoError(t, err, "creating", Ref("Job", workload.Namespace, workload.Name+workload.GenerateName))
+				return created
+			},
+			state: func(t Test, g gomega.Gomega, namespace, name string) workloadState {
+				job := Job(t, namespace, name)(g)
+				state := workloadState{status: "Pending", running: job.Status.Active > 0}
+				if state.running {
+					state.status = "Running"
+				}
+				for _, condition := range job.Status.Conditions {
+					if condition.Status == corev1.ConditionTrue && (condition.Type == batchv1.JobComplete || condition.Type == batchv1.JobFailed) {
+						state.status, state.message = string(condition.Type), condition.Message
+						state.finished, state.succeeded = true, condition.Type == batchv1.JobComplete
+					}
+				}
+				return state
+			},
+			podSelectors: func(t Test, namespace, name string) []string {
+				return []string{"job-name=" + name}
+			},
+		}
+	}
+
+	t.T().Fatalf("Unsupported workload type %T, supported are PyTorchJob, RayJob, AppWrapper and batch Job", workload)
+	return workloadHandler{}
+}
+
+func summarizePod(pod *corev1.Pod) PodSummary {
+	summary := PodSummary{Name: pod.Name, Node: pod.Spec.NodeName, Phase: pod.Status.Phase}
+	for _, status := range pod.Status.ContainerStatuses {
+		summary.Restarts += status.RestartCount
+		if terminated := status.State.Terminated; terminated != nil && terminated.ExitCode != 0 {
+			summary.Reason = terminated.Reason
+		} else if terminated := status.LastTerminationState.Terminated; terminated != nil && summary.Reason == "" {
+			summary.Reason = terminated.Reason
+		}
+	}
+	return summary
+}
+
+// storePodLogs stores the logs of the pod containers with the test output, with the crashes reported by the crash
+// capture wrapper, pods being deleted are skipped.
+func storePodLogs(t Test, pod *corev1.Pod) {
+	t.T().Helper()
+	for _, container := range pod.Spec.Containers {
+		logs, err := t.Client().Core().CoreV1().Pods(pod.Namespace).GetLogs(pod.Name, &corev1.PodLogOptions{Container: container.Name}).DoRaw(t.Ctx())
+		if err != nil {
+			t.T().Logf("Error getting logs of container %s of pod %s/%s: %v", container.Name, pod.Namespace, pod.Name, err)
+			continue
+		}
+		WriteToOutputDir(t, fmt.Sprintf("pod-%s-%s", pod.Name, container.Name), Log, logs)
+		storeCrashArtifacts(t, pod, container.Name, logs)
+	}
+}
+
+// String formats the result for test logs, i.e. "PyTorchJob ns/job Succeeded in 3m2s (queued 10s), 3 pods".
+func (r *WorkloadResult) String() string {
+	var failedPods []string
+	for _, pod := range r.Pods {
+		if pod.Phase == corev1.PodFailed || pod.Reason != "" {
+			failedPods = append(failedPods, fmt.Sprintf("%s (%s)", pod.Name, valueOrNone(pod.Reason)))
+		}
+	}
+	description := fmt.Sprintf("%s %s/%s %s in %s (queued %s), %d pods", r.Kind, r.Namespace, r.Name, r.Status,
+		r.Duration.Round(time.Second), r.QueuedDuration.Round(time.Second), len(r.Pods))
+	if r.Message != "" {
+		description += ": " + r.Message
+	}
+	if len(failedPods) > 0 {
+		description += ", failed pods: " + strings.Join(failedPods, ", ")
+	}
+	return description
+}
@@ -0,0 +1,58 @@
+import os
+
+import torch
+import torch.distributed as dist
+from torch.nn.parallel import DistributedDataParallel
+from torch.utils.data import DataLoader, Dataset
+from torch.utils.data.distributed import DistributedSampler
+
+dataset_dir = os.environ.get("DATASET_DIR", "/mnt/dataset")
+epochs = int(os.environ.get("EPOCHS", "2"))
+features = 16
+
+
+class FileDataset(Dataset):
+    """Reads each sample from its own file, like image classification datasets stored on shared storage."""
+
+    def __init__(self, root):
+        self.files = sorted(os.path.join(root, name) for name in os.listdir(root))
+
+    def __len__(self):
+        return len(self.files)
+
+    def __getitem__(self, index):
+        with open(self.files[index], "rb") as file:
+            data = file.read()
+        sample = torch.tensor(list(data[:features]), dtype=torch.float32) / 255
+        label = torch.tensor([sum(data) % 2], dtype=torch.float32)
+        return sample, label
+
+
+dist.init_process_group("gloo")
+rank = dist.get_rank()
+world_size = dist.get_world_size()
+print(f"torch {torch.__version__}, rank {rank} of {world_size}")
+
+dataset = FileDataset(dataset_dir)
+sampler = DistributedSampler(dataset, shuffle=True, seed=42)
+loader = DataLoader(dataset, batch_size=16, sampler=sampler)
+model = DistributedDataParallel(torch.nn.Linear(features, 1))
+optimizer = torch.optim.SGD(model.parameters(), lr=0.1)
+loss_fn = torch.nn.BCEWithLogitsLoss()
+
+read = torch.tensor(0)
+for epoch in range(epochs):
+    sampler.set_epoch(epoch)
+    for samples, labels in loader:
+        optimizer.zero_grad()
+        loss = loss_fn(model(samples), labels)
+        loss.backward()
+        optimizer.step()
+        read += len(samples)
+    print(f"rank {rank} epoch {epoch}: loss {loss.item():.4f}", flush=True)
+
+dist.all_reduce(read)
+if rank == 0:
+    print(f"READ {read.item()} samples of {len(dataset)} in {epochs} epochs", flush=True)
+
+dist.destroy_process_group()
@@ -0,0 +1,36 @@
+import json
+import os
+
+import torch
+import torch.distributed as dist
+from torch.utils.data import DataLoader, Dataset
+from torch.utils.data.distributed import DistributedSampler
+
+dataset_size = int(os.environ.get("DATASET_SIZE", "300"))
+epochs = int(os.environ.get("EPOCHS", "2"))
+
+
+class IndexDataset(Dataset):
+    def __len__(self):
+        return dataset_size
+
+    def __getitem__(self, index):
+        return index
+
+
+dist.init_process_group("gloo")
+rank = dist.get_rank()
+world_size = dist.get_world_size()
+print(f"torch {torch.__version__}, rank {rank} of {world_size}")
+
+sampler = DistributedSampler(IndexDataset(), shuffle=True, seed=42)
+loader = DataLoader(IndexDataset(), batch_size=16, sampler=sampler)
+
+for epoch in range(epochs):
+    sampler.set_epoch(epoch)
+    indices = [int(index) for batch in loader for index in batch]
+    print(f"rank {rank} epoch {epoch}: {len(indices)} samples, {len(set(indices))} unique")
+    print("SAMPLES " + json.dumps({"rank": rank, "worldSize": world_size, "epoch": epoch, "indices": indices}), flush=True)
+    dist.barrier()
+
+dist.destroy_process_group()
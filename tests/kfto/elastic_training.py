@@ -0,0 +1,52 @@
+import os
+import time
+
+import torch
+import torch.distributed as dist
+from torch.nn.parallel import DistributedDataParallel
+
+steps = int(os.environ.get("STEPS", "60"))
+step_duration = float(os.environ.get("STEP_DURATION", "2"))
+# The checkpoint is local to the pod, it survives the restarts of the workers by the elastic agent
+checkpoint_path = "/tmp/elastic-checkpoint.pt"
+
+dist.init_process_group("gloo")
+rank = dist.get_rank()
+world_size = dist.get_world_size()
+restart_count = int(os.environ.get("TORCHELASTIC_RESTART_COUNT", "0"))
+print(f"RENDEZVOUS world_size={world_size} rank={rank} restart_count={restart_count}", flush=True)
+
+torch.manual_seed(42)
+model = torch.nn.Linear(16, 1)
+step = 0
+if os.path.exists(checkpoint_path):
+    checkpoint = torch.load(checkpoint_path)
+    model.load_state_dict(checkpoint["model"])
+    step = checkpoint["step"]
+
+# Resume from the checkpoint of rank 0, DDP broadcasts its parameters to the other ranks
+start = torch.tensor([step])
+dist.broadcast(start, src=0)
+step = int(start.item())
+if step > 0:
+    print(f"RESUMED step={step} world_size={world_size}", flush=True)
+
+model = DistributedDataParallel(model)
+optimizer = torch.optim.SGD(model.parameters(), lr=0.01)
+
+while step < steps:
+    inputs = torch.randn(32, 16)
+    loss = (model(inputs) - inputs.sum(dim=1, keepdim=True)).pow(2).mean()
+    optimizer.zero_grad()
+    loss.backward()
+    optimizer.step()
+    step += 1
+    torch.save({"model": model.module.state_dict(), "step": step}, checkpoint_path)
+    if rank == 0:
+        print(f"STEP {step} world_size={world_size} loss={loss.item():.4f}", flush=True)
+    time.sleep(step_duration)
+
+dist.barrier()
+if rank == 0:
+    print(f"TRAINING COMPLETED steps={step} world_size={world_size}", flush=True)
+dist.destroy_process_group()
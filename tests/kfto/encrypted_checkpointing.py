@@ -0,0 +1,78 @@
+import base64
+import io
+import os
+import sys
+
+import boto3
+import torch
+
+# The crypto libraries must be shipped by the runtime image, only boto3 is installed by the test
+import cryptography
+from cryptography.exceptions import InvalidTag
+from cryptography.hazmat.primitives.ciphers.aead import AESGCM
+
+if cryptography.__file__.startswith("/tmp/lib"):
+    raise SystemExit(f"cryptography {cryptography.__version__} isn't shipped by the runtime image")
+print(f"Using cryptography {cryptography.__version__} of the runtime image", flush=True)
+
+total_steps = int(os.environ.get("TOTAL_STEPS", "30"))
+bucket = os.environ["AWS_STORAGE_BUCKET"]
+key = os.environ["S3_CHECKPOINT_KEY"]
+# AES-256-GCM key provided by the user in a Secret, base64 encoded
+aesgcm = AESGCM(base64.b64decode(os.environ["CHECKPOINT_ENCRYPTION_KEY"]))
+# The nonce is stored in front of the ciphertext, the S3 key authenticates the checkpoint against being swapped
+nonce_size = 12
+associated_data = key.encode()
+
+s3 = boto3.client(
+    "s3",
+    endpoint_url=os.environ["AWS_DEFAULT_ENDPOINT"],
+    aws_access_key_id=os.environ["AWS_ACCESS_KEY_ID"],
+    aws_secret_access_key=os.environ["AWS_SECRET_ACCESS_KEY"],
+    verify=False,
+)
+
+
+def new_model():
+    torch.manual_seed(0)
+    return torch.nn.Linear(16, 1)
+
+
+if os.environ.get("MODE", "train") == "decrypt":
+    stored = s3.get_object(Bucket=bucket, Key=key)["Body"].read()
+    try:
+        torch.load(io.BytesIO(stored))
+    except Exception:
+        print("Stored checkpoint isn't loadable without decryption", flush=True)
+    else:
+        raise SystemExit("Stored checkpoint is loadable without decryption, it isn't encrypted at rest")
+    try:
+        plaintext = aesgcm.decrypt(stored[:nonce_size], stored[nonce_size:], associated_data)
+    except InvalidTag:
+        print("Decryption of the checkpoint failed, the key doesn't match", flush=True)
+        sys.exit(1)
+    checkpoint = torch.load(io.BytesIO(plaintext))
+    model = new_model()
+    model.load_state_dict(checkpoint["model"])
+    loss = torch.nn.functional.mse_loss(model(checkpoint["inputs"]), checkpoint["targets"])
+    print(f"Decrypted and loaded checkpoint of step {checkpoint['step']} with loss {loss.item():.4f}", flush=True)
+    sys.exit(0)
+
+model = new_model()
+optimizer = torch.optim.SGD(model.parameters(), lr=0.01)
+inputs = torch.randn(64, 16)
+targets = inputs.sum(dim=1, keepdim=True)
+
+for step in range(1, total_steps + 1):
+    optimizer.zero_grad()
+    loss = torch.nn.functional.mse_loss(model(inputs), targets)
+    loss.backward()
+    optimizer.step()
+    print(f"Step {step} loss {loss.item():.4f}", flush=True)
+
+# The checkpoint is serialized and encrypted in memory, so it's never written to disk in clear
+buffer = io.BytesIO()
+torch.save({"step": total_steps, "model": model.state_dict(), "inputs": inputs, "targets": targets}, buffer)
+nonce = os.urandom(nonce_size)
+s3.put_object(Bucket=bucket, Key=key, Body=nonce + aesgcm.encrypt(nonce, buffer.getvalue(), associated_data))
+print(f"Uploaded encrypted checkpoint to s3://{bucket}/{key}", flush=True)
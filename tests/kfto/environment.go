@@ -18,15 +18,64 @@ package kfto
 
 import (
 	"os"
+	"strconv"
+	"strings"
+
+	"github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+
+	"github.com/opendatahub-io/distributed-workloads/pkg/examples"
 )
 
 const (
 	// The environment variable for FMS HF Tuning image to be tested
 	fmsHfTuningImageEnvVar = "FMS_HF_TUNING_IMAGE"
+	// The environment variable for maximum perplexity accepted when evaluating the fine-tuned model
+	fmsHfTuningMaxPerplexityEnvVar = "FMS_HF_TUNING_MAX_PERPLEXITY"
+	// The environment variable for number of AMD GPUs the RCCL all-reduce benchmark runs on
+	rcclGpusEnvVar = "RCCL_GPUS"
+	// The environment variable for comma separated list of minimum RCCL all-reduce bus bandwidths in GB/s per GPU model
+	rcclBusBandwidthBaselinesEnvVar = "RCCL_BUS_BANDWIDTH_BASELINES"
 )
 
 func GetFmsHfTuningImage() string {
-	return lookupEnvOrDefault(fmsHfTuningImageEnvVar, "quay.io/modh/fms-hf-tuning:b71215c3ae202eab9da1d347f52b89feb3d0378c")
+	return lookupEnvOrDefault(fmsHfTuningImageEnvVar, examples.FmsHfTuningImage)
+}
+
+func GetFmsHfTuningMaxPerplexity(t support.Test) float64 {
+	t.T().Helper()
+	maxPerplexity, err := strconv.ParseFloat(lookupEnvOrDefault(fmsHfTuningMaxPerplexityEnvVar, "100"), 64)
+	t.Expect(err).NotTo(gomega.HaveOccurred(), "Error parsing %s", fmsHfTuningMaxPerplexityEnvVar)
+	return maxPerplexity
+}
+
+func GetRcclGpus(t support.Test) int64 {
+	t.T().Helper()
+	gpus, err := strconv.ParseInt(lookupEnvOrDefault(rcclGpusEnvVar, "2"), 10, 64)
+	t.Expect(err).NotTo(gomega.HaveOccurred(), "Error parsing %s", rcclGpusEnvVar)
+	t.Expect(gpus).To(gomega.BeNumerically(">=", 2), "%s must be at least 2", rcclGpusEnvVar)
+	return gpus
+}
+
+// GetRcclBusBandwidthBaseline returns the minimum bus bandwidth in GB/s for the GPU product name overriding the
+// performance baselines, the models are matched as substrings of the product name, i.e. MI300 matches AMD_Instinct_MI300X_OAM.
+func GetRcclBusBandwidthBaseline(t support.Test, productName string) (float64, bool) {
+	t.T().Helper()
+	baselines, ok := os.LookupEnv(rcclBusBandwidthBaselinesEnvVar)
+	if !ok {
+		return 0, false
+	}
+	for _, baseline := range strings.Split(baselines, ",") {
+		model, value, ok := strings.Cut(strings.TrimSpace(baseline), "=")
+		t.Expect(ok).To(gomega.BeTrue(), "Error parsing %s, expected MODEL=GBPS entries", rcclBusBandwidthBaselinesEnvVar)
+		if !strings.Contains(productName, model) {
+			continue
+		}
+		bandwidth, err := strconv.ParseFloat(value, 64)
+		t.Expect(err).NotTo(gomega.HaveOccurred(), "Error parsing %s", rcclBusBandwidthBaselinesEnvVar)
+		return bandwidth, true
+	}
+	return 0, false
 }
 
 func lookupEnvOrDefault(key, value string) string {
@@ -0,0 +1,42 @@
+import glob
+import json
+import math
+import os
+import sys
+
+import torch
+from peft import PeftModel
+from transformers import AutoModelForCausalLM, AutoTokenizer
+
+config_path = os.environ.get("SFT_TRAINER_CONFIG_JSON_PATH", "/etc/config/config.json")
+with open(config_path) as f:
+    config = json.load(f)
+
+checkpoints = glob.glob(os.path.join(config["output_dir"], "checkpoint-*"))
+if not checkpoints:
+    print(f"No checkpoint found in {config['output_dir']}")
+    sys.exit(1)
+checkpoint = max(checkpoints, key=lambda path: int(path.rsplit("-", 1)[1]))
+print(f"Evaluating checkpoint {checkpoint}")
+
+tokenizer = AutoTokenizer.from_pretrained(config["tokenizer_name_or_path"])
+model = AutoModelForCausalLM.from_pretrained(config["model_name_or_path"], torch_dtype=torch.float32)
+model = PeftModel.from_pretrained(model, checkpoint)
+model.eval()
+
+losses = []
+with open(config["training_data_path"]) as f:
+    for line in f:
+        text = json.loads(line)[config["dataset_text_field"]]
+        inputs = tokenizer(text, return_tensors="pt")
+        with torch.no_grad():
+            outputs = model(**inputs, labels=inputs["input_ids"])
+        losses.append(outputs.loss.item())
+
+mean_loss = sum(losses) / len(losses)
+try:
+    perplexity = math.exp(mean_loss)
+except OverflowError:
+    perplexity = float("inf")
+
+print(f"perplexity: {perplexity}")
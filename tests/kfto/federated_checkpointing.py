@@ -0,0 +1,58 @@
+import os
+import sys
+import time
+
+import boto3
+import torch
+
+# Intermediate checkpoints are written to the local PVC, the final checkpoint is uploaded to S3
+local_dir = os.environ.get("CHECKPOINT_DIR", "/mnt/checkpoints")
+total_steps = int(os.environ.get("TOTAL_STEPS", "30"))
+checkpoint_interval = int(os.environ.get("CHECKPOINT_INTERVAL", "10"))
+bucket = os.environ["AWS_STORAGE_BUCKET"]
+key = os.environ["S3_CHECKPOINT_KEY"]
+
+s3 = boto3.client(
+    "s3",
+    endpoint_url=os.environ["AWS_DEFAULT_ENDPOINT"],
+    aws_access_key_id=os.environ["AWS_ACCESS_KEY_ID"],
+    aws_secret_access_key=os.environ["AWS_SECRET_ACCESS_KEY"],
+    verify=False,
+)
+
+
+def new_model():
+    torch.manual_seed(0)
+    return torch.nn.Linear(16, 1)
+
+
+if os.environ.get("MODE", "train") == "verify":
+    s3.download_file(bucket, key, "/tmp/final.pt")
+    checkpoint = torch.load("/tmp/final.pt")
+    model = new_model()
+    model.load_state_dict(checkpoint["model"])
+    loss = torch.nn.functional.mse_loss(model(checkpoint["inputs"]), checkpoint["targets"])
+    print(f"Loaded final checkpoint of step {checkpoint['step']} with loss {loss.item():.4f}", flush=True)
+    sys.exit(0)
+
+model = new_model()
+optimizer = torch.optim.SGD(model.parameters(), lr=0.01)
+inputs = torch.randn(64, 16)
+targets = inputs.sum(dim=1, keepdim=True)
+
+for step in range(1, total_steps + 1):
+    optimizer.zero_grad()
+    loss = torch.nn.functional.mse_loss(model(inputs), targets)
+    loss.backward()
+    optimizer.step()
+    print(f"Step {step} loss {loss.item():.4f}", flush=True)
+    if step % checkpoint_interval == 0 and step < total_steps:
+        torch.save({"step": step, "model": model.state_dict(), "optimizer": optimizer.state_dict()},
+                   os.path.join(local_dir, f"checkpoint-{step}.pt"))
+        print(f"Saved intermediate checkpoint of step {step}", flush=True)
+    time.sleep(0.5)
+
+final_path = os.path.join("/tmp", "final.pt")
+torch.save({"step": total_steps, "model": model.state_dict(), "inputs": inputs, "targets": targets}, final_path)
+s3.upload_file(final_path, bucket, key)
+print(f"Uploaded final checkpoint to s3://{bucket}/{key}", flush=True)
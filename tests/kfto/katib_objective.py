@@ -0,0 +1,35 @@
+import argparse
+
+import torch
+import torch.distributed as dist
+
+parser = argparse.ArgumentParser()
+parser.add_argument("--lr", type=float, required=True)
+args = parser.parse_args()
+
+dist.init_process_group("gloo")
+rank = dist.get_rank()
+world_size = dist.get_world_size()
+
+# Each rank fits its own shard of y = 3x with gradient descent, the loss is averaged across ranks
+torch.manual_seed(rank)
+x = torch.rand(256, 1)
+y = 3 * x
+weight = torch.zeros(1, requires_grad=True)
+for step in range(20):
+    loss = ((x * weight - y) ** 2).mean()
+    loss.backward()
+    dist.all_reduce(weight.grad)
+    with torch.no_grad():
+        weight -= args.lr * weight.grad / world_size
+        weight.grad.zero_()
+
+loss = ((x * weight - y) ** 2).mean().detach()
+dist.all_reduce(loss)
+loss /= world_size
+
+# The loss is collected from the master logs by Katib StdOut metrics collector
+if rank == 0:
+    print(f"loss={loss.item():.6f}")
+
+dist.destroy_process_group()
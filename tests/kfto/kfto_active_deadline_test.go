@@ -0,0 +1,113 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kfto
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/opendatahub-io/distributed-workloads/pkg/examples"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	. "github.com/project-codeflare/codeflare-common/support"
+	kueuev1beta1 "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kftov1 "github.com/kubeflow/training-operator/pkg/apis/kubeflow.org/v1"
+)
+
+const activeDeadlineSeconds = 60
+
+// TestPytorchjobActiveDeadline makes sure a PyTorchJob running longer than its activeDeadlineSeconds is terminated,
+// reported as failed because of the deadline, and its Kueue quota is released, as admins rely on to reclaim stuck GPUs.
+func TestPytorchjobActiveDeadline(t *testing.T) {
+	Track(t, LabelKueue)
+	test := MustGather(WithHooks(t))
+
+	// Create a namespace
+	namespace := test.NewTestNamespace()
+
+	// Create Kueue resources
+	resourceFlavor := CreateTrackedKueueResourceFlavor(test, kueuev1beta1.ResourceFlavorSpec{})
+	defer test.Client().Kueue().KueueV1beta1().ResourceFlavors().Delete(test.Ctx(), resourceFlavor.Name, metav1.DeleteOptions{})
+	cqSpec := kueuev1beta1.ClusterQueueSpec{
+		NamespaceSelector: &metav1.LabelSelector{},
+		ResourceGroups: []kueuev1beta1.ResourceGroup{
+			{
+				CoveredResources: []corev1.ResourceName{corev1.ResourceName("cpu"), corev1.ResourceName("memory")},
+				Flavors: []kueuev1beta1.FlavorQuotas{
+					{
+						Name: kueuev1beta1.ResourceFlavorReference(resourceFlavor.Name),
+						Resources: []kueuev1beta1.ResourceQuota{
+							{
+								Name:         corev1.ResourceCPU,
+								NominalQuota: resource.MustParse("2"),
+							},
+							{
+								Name:         corev1.ResourceMemory,
+								NominalQuota: resource.MustParse("4Gi"),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	clusterQueue := CreateTrackedKueueClusterQueue(test, cqSpec)
+	defer test.Client().Kueue().KueueV1beta1().ClusterQueues().Delete(test.Ctx(), clusterQueue.Name, metav1.DeleteOptions{})
+	localQueue := CreateKueueLocalQueue(test, namespace.Name, clusterQueue.Name)
+
+	// Create PyTorch job training forever, stuck as far as the deadline is concerned
+	job := examples.PyTorchJob(examples.PyTorchJobOptions{
+		GenerateName: "kfto-deadline-",
+		Namespace:    namespace.Name,
+		Image:        GetFmsHfTuningImage(),
+		Command:      []string{"python", "-c", "import time; time.sleep(3600)"},
+		Workers:      1,
+		CPU:          "250m",
+		Memory:       "512Mi",
+		LocalQueue:   localQueue.Name,
+	})
+	job.Spec.RunPolicy.ActiveDeadlineSeconds = Ptr(int64(activeDeadlineSeconds))
+	created, err := test.Client().Kubeflow().KubeflowV1().PyTorchJobs(namespace.Name).Create(test.Ctx(), job, metav1.CreateOptions{})
+	ExpectNoError(test, err, "creating", Ref("PyTorchJob", namespace.Name, job.Name+job.GenerateName))
+	job = created
+	test.T().Logf("Created PytorchJob %s/%s successfully", job.Namespace, job.Name)
+
+	EventuallyOf(test, PytorchJob(test, namespace.Name, job.Name), TestTimeoutMedium).
+		Should(Field(PytorchJobConditionRunning).Equal(corev1.ConditionTrue))
+	EventuallyOf(test, KueueClusterQueue(test, clusterQueue.Name), TestTimeoutShort).
+		Should(Field(KueueClusterQueueReservingWorkloads).Equal(int32(1)))
+
+	// Make sure the PyTorch job fails once the deadline passes, with the deadline reported as the reason
+	EventuallyOf(test, PytorchJob(test, namespace.Name, job.Name), activeDeadlineSeconds*time.Second+TestTimeoutShort).
+		Should(Field(PytorchJobConditionFailed).Equal(corev1.ConditionTrue))
+	job = PytorchJob(test, namespace.Name, job.Name)(test)
+	test.Expect(pytorchJobConditionMessage(job, kftov1.JobFailed)).To(ContainSubstring("active longer than specified deadline"))
+	test.Expect(job.Status.CompletionTime).NotTo(BeNil())
+	test.T().Logf("PyTorch job %s/%s terminated %s after it started", job.Namespace, job.Name, job.Status.CompletionTime.Sub(job.Status.StartTime.Time))
+
+	// Make sure the pods are terminated and the quota is released promptly, so other workloads can use it
+	WaitForDeletionOf(test, corev1.SchemeGroupVersion.WithResource("pods"), namespace.Name, metav1.ListOptions{LabelSelector: "training.kubeflow.org/job-name=" + job.Name}, TestTimeoutShort)
+	EventuallyOf(test, KueueWorkloadOwnedBy(test, namespace.Name, job), TestTimeoutShort).
+		Should(Field(KueueWorkloadFinished).Equal(true))
+	EventuallyOf(test, KueueClusterQueue(test, clusterQueue.Name), TestTimeoutShort).
+		Should(Field(KueueClusterQueueReservingWorkloads).Equal(int32(0)))
+}
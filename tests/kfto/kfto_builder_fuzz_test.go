@@ -0,0 +1,67 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kfto
+
+import (
+	"testing"
+
+	"github.com/opendatahub-io/distributed-workloads/pkg/examples"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	kftov1 "github.com/kubeflow/training-operator/pkg/apis/kubeflow.org/v1"
+)
+
+// FuzzPyTorchJobBuilder renders PyTorchJobs with randomized-but-valid names, worker counts, resources, elastic
+// policies, local queues and scripts ConfigMaps, and asserts the API server accepts them with server-side dry-run.
+func FuzzPyTorchJobBuilder(f *testing.F) {
+	f.Add("pytorchjob", uint8(0), uint16(1000), uint16(2048), uint8(0), "", "")
+	f.Add("PyTorchJob.With_Upper-Case", uint8(3), uint16(0), uint16(0), uint8(2), "local-queue", "scripts")
+	f.Add("a-very-long-name-exceeding-the-length-of-the-service-names-the-operator-derives", uint8(255), uint16(65535), uint16(65535), uint8(255), "-", "-")
+	f.Fuzz(func(t *testing.T, name string, workers uint8, milliCPUs, memory uint16, elasticReplicas uint8, localQueue, scriptsConfigMap string) {
+		Track(t)
+		test := WithHooks(t)
+
+		options := examples.PyTorchJobOptions{
+			Name:      FuzzName(name, 40),
+			Namespace: FuzzNamespace,
+			Image:     GetFmsHfTuningImage(),
+			Command:   []string{"python", "-c", "print('fuzz')"},
+			Workers:   FuzzCount(workers, 0, 8),
+			CPU:       FuzzMilliCPUs(milliCPUs),
+			Memory:    FuzzMemory(memory),
+		}
+		if localQueue != "" {
+			options.LocalQueue = FuzzName(localQueue, 63)
+		}
+		if scriptsConfigMap != "" {
+			options.ScriptsConfigMap = FuzzName(scriptsConfigMap, 63)
+		}
+		// Elastic jobs have workers only, the replicas bound the number of workers
+		if replicas := FuzzCount(elasticReplicas, 0, 8); replicas > 0 {
+			options.Workers = max(options.Workers, 1)
+			options.ElasticPolicy = &kftov1.ElasticPolicy{
+				MinReplicas: Ptr(int32(1)),
+				MaxReplicas: Ptr(max(replicas, options.Workers)),
+			}
+		}
+		job := examples.PyTorchJob(options)
+
+		_, err := test.Client().Kubeflow().KubeflowV1().PyTorchJobs(FuzzNamespace).Create(test.Ctx(), job, DryRunCreate)
+		ExpectDryRunValid(test, job, err)
+	})
+}
@@ -0,0 +1,230 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kfto
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kftov1 "github.com/kubeflow/training-operator/pkg/apis/kubeflow.org/v1"
+)
+
+// checkpointEncryptionKeyName is the key of the encryption key in the Secret provided by the user
+const checkpointEncryptionKeyName = "CHECKPOINT_ENCRYPTION_KEY"
+
+// TestPytorchjobCheckpointEncryption trains with the checkpoint encrypted client-side with a key provided by the user
+// in a Secret before it's uploaded to S3, as required to keep checkpoints encrypted at rest with keys the storage
+// provider doesn't hold, and makes sure a follow-up Job decrypts and loads it with the key, but not with another key.
+// The crypto libraries are used from the training runtime image.
+func TestPytorchjobCheckpointEncryption(t *testing.T) {
+	Track(t)
+	test := MustGather(WithHooks(t))
+
+	// Create a namespace
+	namespace := test.NewTestNamespace()
+
+	// Use the configured S3 bucket, or deploy MinIO
+	bucket := GetOrDeployS3Bucket(test, namespace.Name)
+
+	// Create a ConfigMap with the training script, a Secret with S3 credentials and the Secrets with the user key and another key
+	config := CreateConfigMap(test, namespace.Name, map[string][]byte{
+		"encrypted_checkpointing.py": ReadFile(test, "encrypted_checkpointing.py"),
+	})
+	s3Secret := createS3Secret(test, namespace.Name, bucket)
+	keySecret := createCheckpointEncryptionKeySecret(test, namespace.Name)
+	otherKeySecret := createCheckpointEncryptionKeySecret(test, namespace.Name)
+	checkpointKey := fmt.Sprintf("checkpoints/%s/encrypted.pt", namespace.Name)
+
+	// Train the model
+	job := &kftov1.PyTorchJob{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "PyTorchJob",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "kfto-encrypted-checkpoint-",
+		},
+		Spec: kftov1.PyTorchJobSpec{
+			PyTorchReplicaSpecs: map[kftov1.ReplicaType]*kftov1.ReplicaSpec{
+				"Master": {
+					Replicas:      Ptr(int32(1)),
+					RestartPolicy: "Never",
+					Template:      encryptedCheckpointingPodTemplate(config.Name, s3Secret.Name, keySecret.Name, checkpointKey, "train"),
+				},
+			},
+		},
+	}
+	job, err := test.Client().Kubeflow().KubeflowV1().PyTorchJobs(namespace.Name).Create(test.Ctx(), job, metav1.CreateOptions{})
+	ExpectNoError(test, err, "creating", Ref("PyTorchJob", namespace.Name, job.GenerateName))
+	test.T().Logf("Created PytorchJob %s/%s successfully", job.Namespace, job.Name)
+
+	EventuallyOfWithPolling(test, PytorchJob(test, namespace.Name, job.Name), TestTimeoutLong, PollingStrategyFor("PyTorchJob")).
+		Should(Field(PytorchJobConditionSucceeded).Equal(corev1.ConditionTrue))
+	test.Expect(masterPodLogs(test, namespace.Name, job.Name)(test)).
+		To(ContainSubstring("Uploaded encrypted checkpoint to s3://%s/%s", bucket.Bucket, checkpointKey))
+	ExpectObjectExists(test, namespace.Name, bucket, checkpointKey)
+
+	// Make sure the checkpoint is decrypted and loaded with the user key, and isn't loadable as stored
+	succeeded, logs := runCheckpointDecryption(test, namespace.Name, encryptedCheckpointingPodTemplate(config.Name, s3Secret.Name, keySecret.Name, checkpointKey, "decrypt"))
+	test.Expect(succeeded).To(BeTrue(), "Checkpoint decryption failed, logs:\n%s", logs)
+	test.Expect(logs).To(And(
+		ContainSubstring("Stored checkpoint isn't loadable without decryption"),
+		ContainSubstring("Decrypted and loaded checkpoint of step 30"),
+	))
+
+	// Make sure the checkpoint isn't decrypted with another key
+	succeeded, logs = runCheckpointDecryption(test, namespace.Name, encryptedCheckpointingPodTemplate(config.Name, s3Secret.Name, otherKeySecret.Name, checkpointKey, "decrypt"))
+	test.Expect(succeeded).To(BeFalse(), "Checkpoint decrypted with another key, logs:\n%s", logs)
+	test.Expect(logs).To(ContainSubstring("Decryption of the checkpoint failed, the key doesn't match"))
+}
+
+// createCheckpointEncryptionKeySecret creates a Secret with a random AES-256 key, as provided by the user.
+func createCheckpointEncryptionKeySecret(test Test, namespace string) *corev1.Secret {
+	test.T().Helper()
+
+	key := make([]byte, 32)
+	_, err := rand.Read(key)
+	ExpectNoError(test, err, "generating", Ref("encryption key", "", ""))
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "checkpoint-key-",
+		},
+		StringData: map[string]string{
+			checkpointEncryptionKeyName: base64.StdEncoding.EncodeToString(key),
+		},
+	}
+	secret, err = test.Client().Core().CoreV1().Secrets(namespace).Create(test.Ctx(), secret, metav1.CreateOptions{})
+	ExpectNoError(test, err, "creating", Ref("Secret", namespace, "checkpoint-key-"))
+	test.T().Logf("Created Secret %s/%s successfully", secret.Namespace, secret.Name)
+
+	return secret
+}
+
+// runCheckpointDecryption runs the decryption Job to completion, and returns whether it succeeded along with its logs.
+func runCheckpointDecryption(test Test, namespace string, template corev1.PodTemplateSpec) (bool, string) {
+	test.T().Helper()
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "checkpoint-decryption-",
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: Ptr(int32(0)),
+			Template:     template,
+		},
+	}
+	job, err := test.Client().Core().BatchV1().Jobs(namespace).Create(test.Ctx(), job, metav1.CreateOptions{})
+	ExpectNoError(test, err, "creating", Ref("Job", namespace, job.GenerateName))
+	test.T().Logf("Created decryption Job %s/%s successfully", job.Namespace, job.Name)
+
+	EventuallyOfWithPolling(test, Job(test, namespace, job.Name), TestTimeoutMedium, PollingStrategyFor("Job")).
+		Should(Field(JobConditionStatus(batchv1.JobComplete)).Equal(corev1.ConditionTrue).
+			Or(Field(JobConditionStatus(batchv1.JobFailed)).Equal(corev1.ConditionTrue)))
+
+	pods := GetPods(test, namespace, metav1.ListOptions{LabelSelector: "job-name=" + job.Name})
+	test.Expect(pods).To(HaveLen(1))
+	logs := string(GetPodLogs(test, &pods[0], corev1.PodLogOptions{}))
+	return ConditionStatus(batchv1.JobComplete)(GetJob(test, namespace, job.Name)) == corev1.ConditionTrue, logs
+}
+
+func encryptedCheckpointingPodTemplate(configMapName, s3SecretName, keySecretName, checkpointKey, mode string) corev1.PodTemplateSpec {
+	return corev1.PodTemplateSpec{
+		Spec: corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			Containers: []corev1.Container{
+				{
+					Name:            "pytorch",
+					Image:           GetFmsHfTuningImage(),
+					ImagePullPolicy: corev1.PullIfNotPresent,
+					// Only boto3 is installed, the crypto libraries are expected in the image
+					Command: []string{"sh", "-c", "pip install --quiet --target /tmp/lib boto3 && " +
+						"PYTHONPATH=/tmp/lib python /etc/config/encrypted_checkpointing.py"},
+					Env: []corev1.EnvVar{
+						{
+							Name:  "MODE",
+							Value: mode,
+						},
+						{
+							Name:  "S3_CHECKPOINT_KEY",
+							Value: checkpointKey,
+						},
+						{
+							Name:  "PIP_INDEX_URL",
+							Value: GetPipIndexURL(),
+						},
+						{
+							Name: checkpointEncryptionKeyName,
+							ValueFrom: &corev1.EnvVarSource{
+								SecretKeyRef: &corev1.SecretKeySelector{
+									LocalObjectReference: corev1.LocalObjectReference{
+										Name: keySecretName,
+									},
+									Key: checkpointEncryptionKeyName,
+								},
+							},
+						},
+					},
+					EnvFrom: []corev1.EnvFromSource{
+						{
+							SecretRef: &corev1.SecretEnvSource{
+								LocalObjectReference: corev1.LocalObjectReference{
+									Name: s3SecretName,
+								},
+							},
+						},
+					},
+					VolumeMounts: []corev1.VolumeMount{
+						{
+							Name:      "config-volume",
+							MountPath: "/etc/config",
+						},
+					},
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("500m"),
+							corev1.ResourceMemory: resource.MustParse("1Gi"),
+						},
+					},
+				},
+			},
+			Volumes: []corev1.Volume{
+				{
+					Name: "config-volume",
+					VolumeSource: corev1.VolumeSource{
+						ConfigMap: &corev1.ConfigMapVolumeSource{
+							LocalObjectReference: corev1.LocalObjectReference{
+								Name: configMapName,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
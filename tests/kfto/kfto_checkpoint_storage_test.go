@@ -0,0 +1,236 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kfto
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kftov1 "github.com/kubeflow/training-operator/pkg/apis/kubeflow.org/v1"
+)
+
+// TestPytorchjobFederatedCheckpointStorage trains with intermediate checkpoints written to a PVC and the final
+// checkpoint uploaded to S3, as recommended for long running training jobs.
+func TestPytorchjobFederatedCheckpointStorage(t *testing.T) {
+	Track(t)
+	test := MustGather(WithHooks(t))
+
+	// Create a namespace
+	namespace := test.NewTestNamespace()
+
+	// Use the configured S3 bucket, or deploy MinIO
+	bucket := GetOrDeployS3Bucket(test, namespace.Name)
+
+	// Create a ConfigMap with the training script, a Secret with S3 credentials and a PVC for intermediate checkpoints
+	config := CreateConfigMap(test, namespace.Name, map[string][]byte{
+		"federated_checkpointing.py": ReadFile(test, "federated_checkpointing.py"),
+	})
+	secret := createS3Secret(test, namespace.Name, bucket)
+	checkpointPvc := CreatePersistentVolumeClaimWithStorageClass(test, namespace.Name, "1Gi", GetStorageClass(), corev1.ReadWriteOnce)
+	finalCheckpointKey := fmt.Sprintf("checkpoints/%s/final.pt", namespace.Name)
+
+	// Train the model
+	job := &kftov1.PyTorchJob{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "PyTorchJob",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "kfto-checkpoint-",
+		},
+		Spec: kftov1.PyTorchJobSpec{
+			PyTorchReplicaSpecs: map[kftov1.ReplicaType]*kftov1.ReplicaSpec{
+				"Master": {
+					Replicas:      Ptr(int32(1)),
+					RestartPolicy: "Never",
+					Template:      federatedCheckpointingPodTemplate(config.Name, secret.Name, checkpointPvc.Name, finalCheckpointKey, "train"),
+				},
+			},
+		},
+	}
+	created, err := test.Client().Kubeflow().KubeflowV1().PyTorchJobs(namespace.Name).Create(test.Ctx(), job, metav1.CreateOptions{})
+	ExpectNoError(test, err, "creating", Ref("PyTorchJob", namespace.Name, job.Name+job.GenerateName))
+	job = created
+	test.T().Logf("Created PytorchJob %s/%s successfully", job.Namespace, job.Name)
+
+	EventuallyOfWithPolling(test, PytorchJob(test, namespace.Name, job.Name), TestTimeoutLong, PollingStrategyFor("PyTorchJob")).
+		Should(Field(PytorchJobConditionSucceeded).Equal(corev1.ConditionTrue))
+	test.Expect(masterPodLogs(test, namespace.Name, job.Name)(test)).
+		To(ContainSubstring("Uploaded final checkpoint to s3://%s/%s", bucket.Bucket, finalCheckpointKey))
+	ExpectObjectExists(test, namespace.Name, bucket, finalCheckpointKey)
+
+	// Make sure the intermediate checkpoints are stored in the PVC
+	lister := CreatePod(test, &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "checkpoint-lister-",
+			Namespace:    namespace.Name,
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			Containers: []corev1.Container{
+				{
+					Name:    "lister",
+					Image:   GetToolsImage(),
+					Command: []string{"ls", "/mnt/checkpoints"},
+					VolumeMounts: []corev1.VolumeMount{
+						{
+							Name:      "checkpoints",
+							MountPath: "/mnt/checkpoints",
+						},
+					},
+				},
+			},
+			Volumes: []corev1.Volume{
+				{
+					Name: "checkpoints",
+					VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+							ClaimName: checkpointPvc.Name,
+						},
+					},
+				},
+			},
+		},
+	})
+	EventuallyOf(test, Pod(test, namespace.Name, lister.Name), TestTimeoutMedium).
+		Should(Field(PodPhase).Equal(corev1.PodSucceeded))
+	checkpoints := strings.Fields(string(GetPodLogs(test, lister, corev1.PodLogOptions{})))
+	test.Expect(checkpoints).To(ConsistOf("checkpoint-10.pt", "checkpoint-20.pt"))
+
+	// Make sure the final checkpoint stored in S3 is loadable
+	verifier := CreatePod(test, &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "checkpoint-verifier-",
+			Namespace:    namespace.Name,
+		},
+		Spec: federatedCheckpointingPodTemplate(config.Name, secret.Name, checkpointPvc.Name, finalCheckpointKey, "verify").Spec,
+	})
+	EventuallyOf(test, Pod(test, namespace.Name, verifier.Name), TestTimeoutMedium).
+		Should(Field(PodPhase).OneOf(corev1.PodSucceeded, corev1.PodFailed))
+	logs := string(GetPodLogs(test, verifier, corev1.PodLogOptions{}))
+	ExpectOf(test, GetPod(test, namespace.Name, verifier.Name)).
+		To(Field(PodPhase).Equal(corev1.PodSucceeded), "Final checkpoint isn't loadable, logs:\n%s", logs)
+	test.Expect(logs).To(ContainSubstring("Loaded final checkpoint of step 30"))
+}
+
+func createS3Secret(test Test, namespace string, bucket S3Bucket) *corev1.Secret {
+	test.T().Helper()
+
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "s3-",
+		},
+		StringData: map[string]string{
+			"AWS_DEFAULT_ENDPOINT":  bucket.Endpoint,
+			"AWS_ACCESS_KEY_ID":     bucket.AccessKeyID,
+			"AWS_SECRET_ACCESS_KEY": bucket.SecretAccessKey,
+			"AWS_STORAGE_BUCKET":    bucket.Bucket,
+		},
+	}
+
+	created, err := test.Client().Core().CoreV1().Secrets(namespace).Create(test.Ctx(), secret, metav1.CreateOptions{})
+	ExpectNoError(test, err, "creating", Ref("Secret", namespace, secret.Name+secret.GenerateName))
+	secret = created
+	test.T().Logf("Created Secret %s/%s successfully", secret.Namespace, secret.Name)
+
+	return secret
+}
+
+func federatedCheckpointingPodTemplate(configMapName, secretName, pvcName, finalCheckpointKey, mode string) corev1.PodTemplateSpec {
+	return corev1.PodTemplateSpec{
+		Spec: corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			Containers: []corev1.Container{
+				{
+					Name:            "pytorch",
+					Image:           GetFmsHfTuningImage(),
+					ImagePullPolicy: corev1.PullIfNotPresent,
+					Command: []string{"sh", "-c", "pip install --quiet --target /tmp/lib boto3 && " +
+						"PYTHONPATH=/tmp/lib python /etc/config/federated_checkpointing.py"},
+					Env: []corev1.EnvVar{
+						{
+							Name:  "MODE",
+							Value: mode,
+						},
+						{
+							Name:  "S3_CHECKPOINT_KEY",
+							Value: finalCheckpointKey,
+						},
+						{
+							Name:  "PIP_INDEX_URL",
+							Value: GetPipIndexURL(),
+						},
+					},
+					EnvFrom: []corev1.EnvFromSource{
+						{
+							SecretRef: &corev1.SecretEnvSource{
+								LocalObjectReference: corev1.LocalObjectReference{
+									Name: secretName,
+								},
+							},
+						},
+					},
+					VolumeMounts: []corev1.VolumeMount{
+						{
+							Name:      "config-volume",
+							MountPath: "/etc/config",
+						},
+						{
+							Name:      "checkpoints",
+							MountPath: "/mnt/checkpoints",
+						},
+					},
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("500m"),
+							corev1.ResourceMemory: resource.MustParse("1Gi"),
+						},
+					},
+				},
+			},
+			Volumes: []corev1.Volume{
+				{
+					Name: "config-volume",
+					VolumeSource: corev1.VolumeSource{
+						ConfigMap: &corev1.ConfigMapVolumeSource{
+							LocalObjectReference: corev1.LocalObjectReference{
+								Name: configMapName,
+							},
+						},
+					},
+				},
+				{
+					Name: "checkpoints",
+					VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+							ClaimName: pvcName,
+						},
+					},
+				},
+			},
+		},
+	}
+}
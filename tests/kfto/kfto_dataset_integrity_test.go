@@ -0,0 +1,180 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kfto
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/opendatahub-io/distributed-workloads/pkg/examples"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	integrityDatasetSamples    = 200
+	integrityDatasetSampleSize = 4096
+	integrityEpochs            = 2
+	integrityWorkers           = 2
+	integrityDatasetMountPath  = "/mnt/dataset"
+)
+
+// TestPytorchjobSharedDatasetIntegrity checksums a dataset on shared RWX storage before and after a multi-worker
+// training run reads it, to make sure no worker mutates the shared inputs, as some RWX filesystems did.
+func TestPytorchjobSharedDatasetIntegrity(t *testing.T) {
+	Track(t)
+	test := MustGather(WithHooks(t))
+
+	storageClasses := GetRwxStorageClasses()
+	if len(storageClasses) == 0 {
+		test.T().Skip("No RWX storage classes configured")
+	}
+
+	// Create a namespace
+	namespace := test.NewTestNamespace()
+
+	// Create a ConfigMap with the training script and write the dataset into a RWX PVC
+	config := CreateConfigMap(test, namespace.Name, map[string][]byte{
+		"dataset_reader.py": ReadFile(test, "dataset_reader.py"),
+	})
+	pvc := CreatePersistentVolumeClaimWithStorageClass(test, namespace.Name, "1Gi", storageClasses[0], corev1.ReadWriteMany)
+	runDatasetPod(test, namespace.Name, pvc.Name, "dataset-writer-", false, fmt.Sprintf(
+		"for i in $(seq -w 0 %d); do head -c %d /dev/urandom > %s/sample-$i.bin || exit 1; done",
+		integrityDatasetSamples-1, integrityDatasetSampleSize, integrityDatasetMountPath))
+
+	// Checksum the dataset before training
+	before := datasetChecksums(test, namespace.Name, pvc.Name)
+	test.Expect(before).To(HaveLen(integrityDatasetSamples))
+
+	// Train on the dataset with master and workers reading it concurrently from the shared PVC
+	job := examples.PyTorchJob(examples.PyTorchJobOptions{
+		GenerateName: "kfto-dataset-integrity-",
+		Namespace:    namespace.Name,
+		Image:        GetFmsHfTuningImage(),
+		Command:      []string{"python", examples.PyTorchJobScriptsMountPath + "/dataset_reader.py"},
+		Env: []corev1.EnvVar{
+			{
+				Name:  "DATASET_DIR",
+				Value: integrityDatasetMountPath,
+			},
+			{
+				Name:  "EPOCHS",
+				Value: fmt.Sprint(integrityEpochs),
+			},
+		},
+		Workers:          integrityWorkers,
+		CPU:              "500m",
+		Memory:           "1Gi",
+		ScriptsConfigMap: config.Name,
+		CrashCapture:     IsCrashCapture(),
+	})
+	// The dataset is mounted read-write into the training pods, as it usually is, so mutations aren't prevented
+	for _, replica := range job.Spec.PyTorchReplicaSpecs {
+		podSpec := &replica.Template.Spec
+		podSpec.Containers[0].VolumeMounts = append(podSpec.Containers[0].VolumeMounts, corev1.VolumeMount{
+			Name:      "dataset",
+			MountPath: integrityDatasetMountPath,
+		})
+		podSpec.Volumes = append(podSpec.Volumes, datasetVolume(pvc.Name, false))
+	}
+	result := SubmitAndWait(test, job, SubmitOptions{})
+	test.Expect(result.Succeeded).To(BeTrue(), result.String())
+	test.Expect(masterPodLogs(test, namespace.Name, result.Name)(test)).
+		To(ContainSubstring("READ %d samples of %d", integrityDatasetSamples*integrityEpochs, integrityDatasetSamples))
+
+	// Make sure the dataset files are unchanged, and none were added or removed
+	after := datasetChecksums(test, namespace.Name, pvc.Name)
+	var changed []string
+	for file, checksum := range before {
+		if after[file] != checksum {
+			changed = append(changed, file)
+		}
+	}
+	for file := range after {
+		if _, ok := before[file]; !ok {
+			changed = append(changed, file)
+		}
+	}
+	test.Expect(changed).To(BeEmpty(), "Dataset files on storage class %q mutated by the training", storageClasses[0])
+}
+
+// datasetChecksums returns the SHA-256 checksums of the dataset files in the PVC, by their path relative to the dataset directory.
+func datasetChecksums(test Test, namespace, pvcName string) map[string]string {
+	test.T().Helper()
+
+	logs := runDatasetPod(test, namespace, pvcName, "dataset-checksum-", true,
+		fmt.Sprintf("cd %s && find . -type f | sort | xargs sha256sum", integrityDatasetMountPath))
+	checksums := map[string]string{}
+	for _, line := range strings.Split(strings.TrimSpace(logs), "\n") {
+		fields := strings.Fields(line)
+		test.Expect(fields).To(HaveLen(2), "Unexpected sha256sum output: %s", line)
+		checksums[fields[1]] = fields[0]
+	}
+	return checksums
+}
+
+// runDatasetPod runs the shell command in a pod with the dataset PVC mounted, and returns its logs once it succeeds.
+func runDatasetPod(test Test, namespace, pvcName, generateName string, readOnly bool, command string) string {
+	test.T().Helper()
+
+	pod := CreatePod(test, &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: generateName,
+			Namespace:    namespace,
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			Containers: []corev1.Container{
+				{
+					Name:    "dataset",
+					Image:   GetToolsImage(),
+					Command: []string{"sh", "-c", command},
+					VolumeMounts: []corev1.VolumeMount{
+						{
+							Name:      "dataset",
+							MountPath: integrityDatasetMountPath,
+						},
+					},
+				},
+			},
+			Volumes: []corev1.Volume{datasetVolume(pvcName, readOnly)},
+		},
+	})
+	EventuallyOf(test, Pod(test, namespace, pod.Name), TestTimeoutMedium).
+		Should(Field(PodPhase).OneOf(corev1.PodSucceeded, corev1.PodFailed))
+	logs := string(GetPodLogs(test, pod, corev1.PodLogOptions{}))
+	ExpectOf(test, GetPod(test, namespace, pod.Name)).To(Field(PodPhase).Equal(corev1.PodSucceeded),
+		"Pod %s/%s failed, logs:\n%s", namespace, pod.Name, logs)
+	return logs
+}
+
+func datasetVolume(pvcName string, readOnly bool) corev1.Volume {
+	return corev1.Volume{
+		Name: "dataset",
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: pvcName,
+				ReadOnly:  readOnly,
+			},
+		},
+	}
+}
@@ -0,0 +1,69 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kfto
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/opendatahub-io/distributed-workloads/pkg/examples"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestPytorchjobDistributedEnv checks the rendezvous environment the training operator injects into the master and
+// worker pods of a PyTorchJob, and that the NCCL and Gloo tuning of the job is passed through unchanged.
+func TestPytorchjobDistributedEnv(t *testing.T) {
+	Track(t)
+	test := MustGather(WithHooks(t))
+
+	// Create a namespace
+	namespace := test.NewTestNamespace()
+
+	// Create the PyTorchJob with NCCL and Gloo tuning, the pods only wait to be inspected
+	job := examples.PyTorchJob(examples.PyTorchJobOptions{
+		GenerateName: "kfto-distributed-env-",
+		Namespace:    namespace.Name,
+		Image:        GetFmsHfTuningImage(),
+		Command:      []string{"sleep", "600"},
+		Env: []corev1.EnvVar{
+			{Name: "NCCL_DEBUG", Value: "INFO"},
+			{Name: "NCCL_SOCKET_IFNAME", Value: "eth0"},
+			{Name: "GLOO_SOCKET_IFNAME", Value: "eth0"},
+		},
+		Workers: 2,
+		CPU:     "250m",
+		Memory:  "256Mi",
+	})
+	job, err := test.Client().Kubeflow().KubeflowV1().PyTorchJobs(namespace.Name).Create(test.Ctx(), job, metav1.CreateOptions{})
+	ExpectNoError(test, err, "creating", Ref("PyTorchJob", namespace.Name, job.GenerateName))
+	test.T().Logf("Created PyTorchJob %s/%s successfully", job.Namespace, job.Name)
+
+	// Make sure each pod gets exactly the expected environment, the operator defaults included
+	test.Eventually(pytorchJobReplicaPods(test, namespace.Name, job.Name, "master"), TestTimeoutMedium).Should(HaveLen(1))
+	test.Eventually(pytorchJobReplicaPods(test, namespace.Name, job.Name, "worker"), TestTimeoutMedium).Should(HaveLen(2))
+	job = PytorchJob(test, namespace.Name, job.Name)(test)
+	for _, replicaType := range []string{"master", "worker"} {
+		for _, pod := range pytorchJobReplicaPods(test, namespace.Name, job.Name, replicaType)(test) {
+			test.T().Logf("Pod %s distributed environment: %v", pod.Name, PodDistributedEnv(pod))
+			ExpectOf(test, pod).To(HaveDistributedEnv(PyTorchJobDistributedEnv(job, pod)))
+		}
+	}
+}
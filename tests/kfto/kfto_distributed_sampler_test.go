@@ -0,0 +1,133 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kfto
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/opendatahub-io/distributed-workloads/pkg/examples"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	corev1 "k8s.io/api/core/v1"
+
+	kftov1 "github.com/kubeflow/training-operator/pkg/apis/kubeflow.org/v1"
+)
+
+const (
+	samplerDatasetSize = 300
+	samplerEpochs      = 2
+	samplerWorkers     = 2
+)
+
+// samplerRecord is a line logged by distributed_sampler.py with the samples seen by a rank in an epoch.
+type samplerRecord struct {
+	Rank      int   `json:"rank"`
+	WorldSize int   `json:"worldSize"`
+	Epoch     int   `json:"epoch"`
+	Indices   []int `json:"indices"`
+}
+
+func TestPytorchjobDistributedSamplerSharding(t *testing.T) {
+	Track(t)
+	test := MustGather(WithHooks(t))
+
+	// Create a namespace
+	namespace := test.NewTestNamespace()
+
+	// Create a ConfigMap with the sampling script
+	config := CreateConfigMap(test, namespace.Name, map[string][]byte{
+		"distributed_sampler.py": ReadFile(test, "distributed_sampler.py"),
+	})
+
+	// Run PyTorch job sampling the dataset on master and workers
+	result := SubmitAndWait(test, newDistributedSamplerJob(namespace.Name, *config), SubmitOptions{})
+	test.Expect(result.Succeeded).To(BeTrue(), result.String())
+
+	// Collect the samples seen by each rank from the pod logs
+	test.Expect(result.Pods).To(HaveLen(samplerWorkers + 1))
+
+	var records []samplerRecord
+	for _, pod := range result.Pods {
+		logs := string(GetPodLogs(test, GetPod(test, namespace.Name, pod.Name), corev1.PodLogOptions{}))
+		for _, line := range strings.Split(logs, "\n") {
+			payload, ok := strings.CutPrefix(line, "SAMPLES ")
+			if !ok {
+				continue
+			}
+			record := samplerRecord{}
+			test.Expect(json.Unmarshal([]byte(payload), &record)).To(Succeed())
+			test.T().Logf("Rank %d saw %d samples in epoch %d", record.Rank, len(record.Indices), record.Epoch)
+			records = append(records, record)
+		}
+	}
+	test.Expect(records).To(HaveLen((samplerWorkers + 1) * samplerEpochs))
+
+	// Make sure the ranks see disjoint shards, covering the whole dataset in each epoch
+	worldSize := samplerWorkers + 1
+	for epoch := 0; epoch < samplerEpochs; epoch++ {
+		seenBy := map[int]int{}
+		for _, record := range records {
+			if record.Epoch != epoch {
+				continue
+			}
+			test.Expect(record.WorldSize).To(Equal(worldSize))
+			test.Expect(record.Indices).To(HaveLen(samplerDatasetSize/worldSize),
+				"Rank %d got an unbalanced shard in epoch %d", record.Rank, epoch)
+			for _, index := range record.Indices {
+				previous, duplicated := seenBy[index]
+				test.Expect(duplicated).To(BeFalse(), "Sample %d seen by both rank %d and rank %d in epoch %d", index, previous, record.Rank, epoch)
+				seenBy[index] = record.Rank
+			}
+		}
+		var omitted []int
+		for index := 0; index < samplerDatasetSize; index++ {
+			if _, ok := seenBy[index]; !ok {
+				omitted = append(omitted, index)
+			}
+		}
+		test.Expect(omitted).To(BeEmpty(), "Samples omitted in epoch %d", epoch)
+	}
+}
+
+func newDistributedSamplerJob(namespace string, config corev1.ConfigMap) *kftov1.PyTorchJob {
+	return examples.PyTorchJob(examples.PyTorchJobOptions{
+		GenerateName: "kfto-sampler-",
+		Namespace:    namespace,
+		Image:        GetFmsHfTuningImage(),
+		Command:      []string{"python", examples.PyTorchJobScriptsMountPath + "/distributed_sampler.py"},
+		Env: []corev1.EnvVar{
+			{
+				Name:  "DATASET_SIZE",
+				Value: fmt.Sprint(samplerDatasetSize),
+			},
+			{
+				Name:  "EPOCHS",
+				Value: fmt.Sprint(samplerEpochs),
+			},
+		},
+		Workers:          samplerWorkers,
+		CPU:              "500m",
+		Memory:           "1Gi",
+		ScriptsConfigMap: config.Name,
+		CrashCapture:     IsCrashCapture(),
+	})
+}
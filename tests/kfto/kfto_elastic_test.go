@@ -0,0 +1,150 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kfto
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/opendatahub-io/distributed-workloads/pkg/examples"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+
+	kftov1 "github.com/kubeflow/training-operator/pkg/apis/kubeflow.org/v1"
+)
+
+const (
+	elasticMinWorkers = 2
+	elasticMaxWorkers = 3
+	elasticSteps      = 60
+)
+
+var (
+	elasticRendezvousRegexp = regexp.MustCompile(`RENDEZVOUS world_size=(\d+) rank=\d+ restart_count=(\d+)`)
+	elasticResumedRegexp    = regexp.MustCompile(`RESUMED step=(\d+) world_size=(\d+)`)
+)
+
+// TestPytorchjobElasticWorkerRemoval removes a worker from a running elastic PyTorchJob, as when its node is lost,
+// and makes sure the rendezvous re-forms with the remaining workers and the training completes with the reduced world size.
+func TestPytorchjobElasticWorkerRemoval(t *testing.T) {
+	Track(t)
+	test := MustGather(WithHooks(t))
+
+	// Create a namespace
+	namespace := test.NewTestNamespace()
+
+	// Create a ConfigMap with the training script
+	config := CreateConfigMap(test, namespace.Name, map[string][]byte{
+		"elastic_training.py": ReadFile(test, "elastic_training.py"),
+	})
+
+	// Create the elastic PyTorchJob with the maximum number of workers
+	job := newElasticTrainingJob(namespace.Name, *config)
+	created, err := test.Client().Kubeflow().KubeflowV1().PyTorchJobs(namespace.Name).Create(test.Ctx(), job, metav1.CreateOptions{})
+	ExpectNoError(test, err, "creating", Ref("PyTorchJob", namespace.Name, job.Name+job.GenerateName))
+	job = created
+	test.T().Logf("Created PytorchJob %s/%s successfully", job.Namespace, job.Name)
+
+	// Wait for the training to start with all the workers
+	test.Eventually(func(g Gomega) string {
+		return elasticWorkerLogs(test, g, namespace.Name, job.Name)
+	}, TestTimeoutLong).Should(MatchRegexp(fmt.Sprintf(`STEP \d+ world_size=%d`, elasticMaxWorkers)))
+
+	// Remove the last worker, scaling the job down so the worker isn't recreated, as the autoscaler does when a node is lost
+	patch := fmt.Sprintf(`{"spec":{"pytorchReplicaSpecs":{"Worker":{"replicas":%d}}}}`, elasticMinWorkers)
+	_, err = test.Client().Kubeflow().KubeflowV1().PyTorchJobs(namespace.Name).Patch(test.Ctx(), job.Name, types.MergePatchType, []byte(patch), metav1.PatchOptions{})
+	ExpectNoError(test, err, "scaling down", Ref("PyTorchJob", namespace.Name, job.Name))
+	removed := fmt.Sprintf("%s-worker-%d", job.Name, elasticMaxWorkers-1)
+	err = test.Client().Core().CoreV1().Pods(namespace.Name).Delete(test.Ctx(), removed, metav1.DeleteOptions{GracePeriodSeconds: Ptr(int64(0))})
+	ExpectNoError(test, err, "deleting", Ref("Pod", namespace.Name, removed))
+	test.T().Logf("Removed worker %s/%s", namespace.Name, removed)
+
+	// Make sure the job completes with the remaining workers
+	EventuallyOf(test, PytorchJob(test, namespace.Name, job.Name), TestTimeoutLong).
+		Should(Field(PytorchJobConditionSucceeded).Equal(corev1.ConditionTrue).Or(
+			Field(PytorchJobConditionFailed).Equal(corev1.ConditionTrue),
+		))
+	logs := elasticWorkerLogs(test, test, namespace.Name, job.Name)
+	WriteToOutputDir(test, "elastic-training", Log, []byte(logs))
+	ExpectOf(test, PytorchJob(test, namespace.Name, job.Name)(test)).
+		To(Field(PytorchJobConditionSucceeded).Equal(corev1.ConditionTrue), "Elastic training failed after removing a worker")
+	test.Expect(pytorchJobReplicaPods(test, namespace.Name, job.Name, "worker")(test)).
+		To(HaveLen(elasticMinWorkers), "Removed worker was recreated")
+
+	// Make sure the rendezvous re-formed with the reduced world size after the workers were restarted
+	rendezvousReformed := false
+	for _, match := range elasticRendezvousRegexp.FindAllStringSubmatch(logs, -1) {
+		if match[1] == fmt.Sprint(elasticMinWorkers) && match[2] != "0" {
+			rendezvousReformed = true
+		}
+	}
+	test.Expect(rendezvousReformed).To(BeTrue(), "Rendezvous didn't re-form with %d workers", elasticMinWorkers)
+
+	// Make sure the training resumed from its progress rather than starting over, and completed with the reduced world size
+	resumed := elasticResumedRegexp.FindStringSubmatch(logs)
+	test.Expect(resumed).NotTo(BeNil(), "Training didn't resume after the rendezvous re-formed")
+	test.Expect(resumed[1]).NotTo(Equal("0"))
+	test.Expect(resumed[2]).To(Equal(fmt.Sprint(elasticMinWorkers)))
+	test.Expect(logs).To(ContainSubstring(fmt.Sprintf("TRAINING COMPLETED steps=%d world_size=%d", elasticSteps, elasticMinWorkers)))
+}
+
+func newElasticTrainingJob(namespace string, config corev1.ConfigMap) *kftov1.PyTorchJob {
+	return examples.PyTorchJob(examples.PyTorchJobOptions{
+		GenerateName: "kfto-elastic-",
+		Namespace:    namespace,
+		Image:        GetFmsHfTuningImage(),
+		Command:      []string{"torchrun", examples.PyTorchJobScriptsMountPath + "/elastic_training.py"},
+		Env: []corev1.EnvVar{
+			{
+				Name:  "STEPS",
+				Value: fmt.Sprint(elasticSteps),
+			},
+		},
+		Workers:          elasticMaxWorkers,
+		CPU:              "500m",
+		Memory:           "1Gi",
+		ScriptsConfigMap: config.Name,
+		ElasticPolicy: &kftov1.ElasticPolicy{
+			MinReplicas:  Ptr(int32(elasticMinWorkers)),
+			MaxReplicas:  Ptr(int32(elasticMaxWorkers)),
+			RDZVBackend:  Ptr(kftov1.BackendC10D),
+			RDZVPort:     Ptr(int32(29400)),
+			NProcPerNode: Ptr(int32(1)),
+			MaxRestarts:  Ptr(int32(3)),
+		},
+	})
+}
+
+// elasticWorkerLogs returns the logs of the workers of the elastic PyTorchJob, including the restarts of the training
+// processes by the elastic agent, which run in the same containers.
+func elasticWorkerLogs(test Test, g Gomega, namespace, jobName string) string {
+	var logs strings.Builder
+	for _, pod := range pytorchJobReplicaPods(test, namespace, jobName, "worker")(g) {
+		if pod.Status.Phase == corev1.PodPending {
+			continue
+		}
+		fmt.Fprintf(&logs, "==> %s <==\n%s\n", pod.Name, GetPodLogs(test, &pod, corev1.PodLogOptions{}))
+	}
+	return logs.String()
+}
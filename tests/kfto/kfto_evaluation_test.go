@@ -0,0 +1,138 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kfto
+
+import (
+	"regexp"
+	"strconv"
+	"time"
+
+	. "github.com/onsi/gomega"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var perplexityRegexp = regexp.MustCompile(`perplexity: (\S+)`)
+
+// evaluateTrainedModel runs a follow-up Job computing the perplexity of the model stored in the output PVC
+// against the training dataset within the timeout, and returns the computed perplexity. The base model and tokenizer
+// are read from the Hugging Face cache, unless it's the zero value.
+func evaluateTrainedModel(test Test, namespace, localQueueName string, config corev1.ConfigMap, outputPvcName string, cache HuggingFaceCache, timeout time.Duration) float64 {
+	test.T().Helper()
+
+	job := &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: batchv1.SchemeGroupVersion.String(),
+			Kind:       "Job",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "kfto-eval-",
+			Labels: map[string]string{
+				"kueue.x-k8s.io/queue-name": localQueueName,
+			},
+		},
+		Spec: batchv1.JobSpec{
+			Suspend:      Ptr(true),
+			BackoffLimit: Ptr(int32(0)),
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers: []corev1.Container{
+						{
+							Name:            "evaluation",
+							Image:           GetFmsHfTuningImage(),
+							ImagePullPolicy: corev1.PullIfNotPresent,
+							Command:         []string{"python", "/etc/config/evaluate_perplexity.py"},
+							Env: []corev1.EnvVar{
+								{
+									Name:  "SFT_TRAINER_CONFIG_JSON_PATH",
+									Value: "/etc/config/config.json",
+								},
+							},
+							VolumeMounts: []corev1.VolumeMount{
+								{
+									Name:      "config-volume",
+									MountPath: "/etc/config",
+								},
+								{
+									Name:      "output-volume",
+									MountPath: "/tmp/out",
+								},
+							},
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									corev1.ResourceCPU:    resource.MustParse("2"),
+									corev1.ResourceMemory: resource.MustParse("5Gi"),
+								},
+							},
+						},
+					},
+					Volumes: []corev1.Volume{
+						{
+							Name: "config-volume",
+							VolumeSource: corev1.VolumeSource{
+								ConfigMap: &corev1.ConfigMapVolumeSource{
+									LocalObjectReference: corev1.LocalObjectReference{
+										Name: config.Name,
+									},
+								},
+							},
+						},
+						{
+							Name: "output-volume",
+							VolumeSource: corev1.VolumeSource{
+								PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+									ClaimName: outputPvcName,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	cache.Apply(&job.Spec.Template.Spec)
+
+	created, err := test.Client().Core().BatchV1().Jobs(namespace).Create(test.Ctx(), job, metav1.CreateOptions{})
+	ExpectNoError(test, err, "creating", Ref("Job", namespace, job.Name+job.GenerateName))
+	job = created
+	test.T().Logf("Created evaluation Job %s/%s successfully", job.Namespace, job.Name)
+
+	EventuallyOfWithPolling(test, Job(test, namespace, job.Name), timeout, PollingStrategyFor("Job")).
+		Should(Field(JobConditionStatus(batchv1.JobComplete)).Equal(corev1.ConditionTrue).
+			Or(Field(JobConditionStatus(batchv1.JobFailed)).Equal(corev1.ConditionTrue)))
+	ExpectOf(test, GetJob(test, namespace, job.Name)).
+		To(Field(JobConditionStatus(batchv1.JobComplete)).Equal(corev1.ConditionTrue), "Evaluation Job failed")
+
+	pods := GetPods(test, namespace, metav1.ListOptions{LabelSelector: "job-name=" + job.Name})
+	test.Expect(pods).To(HaveLen(1))
+	logs := GetPodLogs(test, &pods[0], corev1.PodLogOptions{})
+
+	match := perplexityRegexp.FindStringSubmatch(string(logs))
+	test.Expect(match).To(HaveLen(2), "Perplexity not found in evaluation Job logs")
+	perplexity, err := strconv.ParseFloat(match[1], 64)
+	ExpectNoError(test, err, "parsing perplexity from", Ref("Job", namespace, job.Name))
+	test.T().Logf("Trained model perplexity: %f", perplexity)
+
+	return perplexity
+}
@@ -0,0 +1,221 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kfto
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/opendatahub-io/distributed-workloads/pkg/examples"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	. "github.com/project-codeflare/codeflare-common/support"
+	kueuev1beta1 "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	katibMaxTrials      = 3
+	katibParallelTrials = 2
+	// Label enabling the injection of Katib metrics collector into the trial pods
+	katibMetricsCollectorInjectionLabel = "katib.kubeflow.org/metrics-collector-injection"
+	// Label of the Katib Experiment set on its Trials
+	katibExperimentLabel = "katib.kubeflow.org/experiment"
+)
+
+var (
+	katibExperimentResource = schema.GroupVersionResource{Group: "kubeflow.org", Version: "v1beta1", Resource: "experiments"}
+	katibTrialResource      = schema.GroupVersionResource{Group: "kubeflow.org", Version: "v1beta1", Resource: "trials"}
+)
+
+// TestKatibExperimentWithPytorchjobTrials runs a Katib Experiment tuning the learning rate of a distributed
+// PyTorchJob, each trial is queued in Kueue, and checks the best trial is recorded once the experiment completes.
+func TestKatibExperimentWithPytorchjobTrials(t *testing.T) {
+	Track(t, LabelKueue)
+	test := MustGather(WithHooks(t))
+
+	// Katib isn't part of the distributed workloads components, it is installed separately
+	_, err := test.Client().Dynamic().Resource(katibExperimentResource).List(test.Ctx(), metav1.ListOptions{Limit: 1})
+	if meta.IsNoMatchError(err) || errors.IsNotFound(err) {
+		test.T().Skip("Katib isn't installed in the cluster")
+	}
+
+	// Create a namespace with Katib metrics collector injection enabled
+	namespace := test.NewTestNamespace()
+	patch := fmt.Sprintf(`{"metadata":{"labels":{%q:"enabled"}}}`, katibMetricsCollectorInjectionLabel)
+	_, err = test.Client().Core().CoreV1().Namespaces().Patch(test.Ctx(), namespace.Name, types.MergePatchType, []byte(patch), metav1.PatchOptions{})
+	ExpectNoError(test, err, "labeling", Ref("Namespace", "", namespace.Name))
+
+	// Create a ConfigMap with the objective script
+	config := CreateConfigMap(test, namespace.Name, map[string][]byte{
+		"katib_objective.py": ReadFile(test, "katib_objective.py"),
+	})
+
+	// Create Kueue resources fitting the parallel trials
+	resourceFlavor := CreateTrackedKueueResourceFlavor(test, kueuev1beta1.ResourceFlavorSpec{})
+	defer test.Client().Kueue().KueueV1beta1().ResourceFlavors().Delete(test.Ctx(), resourceFlavor.Name, metav1.DeleteOptions{})
+	cqSpec := kueuev1beta1.ClusterQueueSpec{
+		NamespaceSelector: &metav1.LabelSelector{},
+		ResourceGroups: []kueuev1beta1.ResourceGroup{
+			{
+				CoveredResources: []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory},
+				Flavors: []kueuev1beta1.FlavorQuotas{
+					{
+						Name: kueuev1beta1.ResourceFlavorReference(resourceFlavor.Name),
+						Resources: []kueuev1beta1.ResourceQuota{
+							{
+								Name:         corev1.ResourceCPU,
+								NominalQuota: resource.MustParse("4"),
+							},
+							{
+								Name:         corev1.ResourceMemory,
+								NominalQuota: resource.MustParse("8Gi"),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	clusterQueue := CreateTrackedKueueClusterQueue(test, cqSpec)
+	defer test.Client().Kueue().KueueV1beta1().ClusterQueues().Delete(test.Ctx(), clusterQueue.Name, metav1.DeleteOptions{})
+	localQueue := CreateKueueLocalQueue(test, namespace.Name, clusterQueue.Name)
+
+	// Run the Experiment and wait for it to complete
+	experiment := createKatibExperiment(test, namespace.Name, localQueue.Name, config.Name)
+	EventuallyOf(test, katibExperiment(test, namespace.Name, experiment.GetName()), TestTimeoutLong).
+		Should(Field(katibConditionStatus("Succeeded")).Equal("True"))
+
+	// Make sure each trial ran as a PyTorchJob admitted by Kueue
+	trials, err := test.Client().Dynamic().Resource(katibTrialResource).Namespace(namespace.Name).
+		List(test.Ctx(), metav1.ListOptions{LabelSelector: katibExperimentLabel + "=" + experiment.GetName()})
+	ExpectNoError(test, err, "listing trials of", Ref("Experiment", namespace.Name, experiment.GetName()))
+	test.Expect(trials.Items).To(HaveLen(katibMaxTrials))
+	for i := range trials.Items {
+		trial := &trials.Items[i]
+		ExpectOf(test, trial).To(Field(katibConditionStatus("Succeeded")).Equal("True"), "Trial %s didn't succeed", trial.GetName())
+		job := PytorchJob(test, namespace.Name, trial.GetName())(test)
+		workload := GetKueueWorkloadOwnedBy(test, namespace.Name, job)
+		test.Expect(workload.Status.Admission).NotTo(BeNil(), "PyTorchJob %s of trial %s wasn't admitted by Kueue", job.Name, trial.GetName())
+		test.Expect(workload.Status.Admission.ClusterQueue).To(Equal(kueuev1beta1.ClusterQueueReference(clusterQueue.Name)))
+	}
+
+	// Make sure the best trial is recorded with its parameters and observed loss
+	experiment = katibExperiment(test, namespace.Name, experiment.GetName())(test)
+	bestTrialName, _, _ := unstructured.NestedString(experiment.Object, "status", "currentOptimalTrial", "bestTrialName")
+	test.Expect(bestTrialName).NotTo(BeEmpty(), "Best trial isn't recorded in Experiment %s", experiment.GetName())
+	parameters, _, _ := unstructured.NestedSlice(experiment.Object, "status", "currentOptimalTrial", "parameterAssignments")
+	test.Expect(parameters).To(ContainElement(HaveKeyWithValue("name", "lr")))
+	metrics, _, _ := unstructured.NestedSlice(experiment.Object, "status", "currentOptimalTrial", "observation", "metrics")
+	test.Expect(metrics).To(ContainElement(And(HaveKeyWithValue("name", "loss"), HaveKey("latest"))))
+	test.T().Logf("Best trial %s with parameters %v and metrics %v", bestTrialName, parameters, metrics)
+}
+
+func createKatibExperiment(test Test, namespace, localQueueName, configMapName string) *unstructured.Unstructured {
+	test.T().Helper()
+
+	// Each trial is a PyTorchJob with a master and a worker, queued in the local queue
+	trialJob := examples.PyTorchJob(examples.PyTorchJobOptions{
+		Image:            GetFmsHfTuningImage(),
+		Command:          []string{"python", examples.PyTorchJobScriptsMountPath + "/katib_objective.py", "--lr", "${trialParameters.learningRate}"},
+		Workers:          1,
+		CPU:              "500m",
+		Memory:           "1Gi",
+		LocalQueue:       localQueueName,
+		ScriptsConfigMap: configMapName,
+	})
+	trialSpec, err := runtime.DefaultUnstructuredConverter.ToUnstructured(trialJob)
+	ExpectNoError(test, err, "converting trial", Ref("PyTorchJob", namespace, ""))
+
+	experiment := &unstructured.Unstructured{Object: map[string]any{
+		"apiVersion": katibExperimentResource.GroupVersion().String(),
+		"kind":       "Experiment",
+		"metadata": map[string]any{
+			"generateName": "katib-",
+			"namespace":    namespace,
+		},
+		"spec": map[string]any{
+			"objective": map[string]any{
+				"type":                "minimize",
+				"objectiveMetricName": "loss",
+			},
+			"algorithm": map[string]any{
+				"algorithmName": "random",
+			},
+			"maxTrialCount":       int64(katibMaxTrials),
+			"parallelTrialCount":  int64(katibParallelTrials),
+			"maxFailedTrialCount": int64(1),
+			"metricsCollectorSpec": map[string]any{
+				"collector": map[string]any{"kind": "StdOut"},
+			},
+			"parameters": []any{
+				map[string]any{
+					"name":          "lr",
+					"parameterType": "double",
+					"feasibleSpace": map[string]any{"min": "0.01", "max": "0.5"},
+				},
+			},
+			"trialTemplate": map[string]any{
+				"primaryContainerName": "pytorch",
+				// The metrics are collected from the master, the trial succeeds with the PyTorchJob
+				"primaryPodLabels": map[string]any{"training.kubeflow.org/job-role": "master"},
+				"successCondition": `status.conditions.#(type=="Succeeded")#|#(status=="True")#`,
+				"failureCondition": `status.conditions.#(type=="Failed")#|#(status=="True")#`,
+				"trialParameters": []any{
+					map[string]any{"name": "learningRate", "reference": "lr"},
+				},
+				"trialSpec": trialSpec,
+			},
+		},
+	}}
+
+	experiment, err = test.Client().Dynamic().Resource(katibExperimentResource).Namespace(namespace).Create(test.Ctx(), experiment, metav1.CreateOptions{})
+	ExpectNoError(test, err, "creating", Ref("Experiment", namespace, "katib-"))
+	test.T().Logf("Created Experiment %s/%s successfully", experiment.GetNamespace(), experiment.GetName())
+
+	return experiment
+}
+
+func katibExperiment(test Test, namespace, name string) func(g Gomega) *unstructured.Unstructured {
+	return func(g Gomega) *unstructured.Unstructured {
+		experiment, err := test.Client().Dynamic().Resource(katibExperimentResource).Namespace(namespace).Get(test.Ctx(), name, metav1.GetOptions{})
+		g.Expect(WrapError(err, "getting", Ref("Experiment", namespace, name))).NotTo(HaveOccurred())
+		return experiment
+	}
+}
+
+// katibConditionStatus returns the status of the condition of a Katib Experiment or Trial, empty if not set.
+func katibConditionStatus(conditionType string) func(object *unstructured.Unstructured) string {
+	return func(object *unstructured.Unstructured) string {
+		conditions, _, _ := unstructured.NestedSlice(object.Object, "status", "conditions")
+		for _, condition := range conditions {
+			if condition, ok := condition.(map[string]any); ok && condition["type"] == conditionType {
+				return fmt.Sprint(condition["status"])
+			}
+		}
+		return ""
+	}
+}
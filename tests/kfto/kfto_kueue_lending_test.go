@@ -0,0 +1,179 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kfto
+
+import (
+	"testing"
+
+	kftov1 "github.com/kubeflow/training-operator/pkg/apis/kubeflow.org/v1"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	. "github.com/project-codeflare/codeflare-common/support"
+	kueuev1beta1 "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestPytorchjobReclaimLentQuotaWithinCohort(t *testing.T) {
+	Track(t, LabelKueue, LabelLong)
+	test := MustGather(WithHooks(t))
+
+	// Create a namespace
+	namespace := test.NewTestNamespace()
+
+	// Create a ConfigMap with training dataset and configuration
+	configData := map[string][]byte{
+		"config.json":                   ReadFile(test, "config.json"),
+		"twitter_complaints_small.json": ReadFile(test, "twitter_complaints_small.json"),
+	}
+	config := CreateConfigMap(test, namespace.Name, configData)
+
+	// Provision the Hugging Face cache with the model and tokenizer, so they aren't downloaded by each workload
+	cache := provisionHuggingFaceCache(test, namespace.Name)
+
+	// Create the low and high WorkloadPriorityClasses of the borrower PyTorch jobs
+	lowPriority := CreateTrackedKueueWorkloadPriorityClass(test, 100)
+	defer test.Client().Kueue().KueueV1beta1().WorkloadPriorityClasses().Delete(test.Ctx(), lowPriority.Name, metav1.DeleteOptions{})
+	highPriority := CreateTrackedKueueWorkloadPriorityClass(test, 1000)
+	defer test.Client().Kueue().KueueV1beta1().WorkloadPriorityClasses().Delete(test.Ctx(), highPriority.Name, metav1.DeleteOptions{})
+
+	// Create Kueue resources, the lender lends the quota of two PyTorch jobs to the cohort and can reclaim it back
+	resourceFlavor := CreateTrackedKueueResourceFlavor(test, kueuev1beta1.ResourceFlavorSpec{})
+	defer test.Client().Kueue().KueueV1beta1().ResourceFlavors().Delete(test.Ctx(), resourceFlavor.Name, metav1.DeleteOptions{})
+	cohort := "cohort-" + namespace.Name
+
+	lenderCqSpec := kueuev1beta1.ClusterQueueSpec{
+		Cohort:            cohort,
+		NamespaceSelector: &metav1.LabelSelector{},
+		ResourceGroups: []kueuev1beta1.ResourceGroup{
+			{
+				CoveredResources: []corev1.ResourceName{corev1.ResourceName("cpu"), corev1.ResourceName("memory")},
+				Flavors: []kueuev1beta1.FlavorQuotas{
+					{
+						Name: kueuev1beta1.ResourceFlavorReference(resourceFlavor.Name),
+						Resources: []kueuev1beta1.ResourceQuota{
+							{
+								Name:         corev1.ResourceCPU,
+								NominalQuota: resource.MustParse("6"),
+								LendingLimit: Ptr(resource.MustParse("4")),
+							},
+							{
+								Name:         corev1.ResourceMemory,
+								NominalQuota: resource.MustParse("15Gi"),
+								LendingLimit: Ptr(resource.MustParse("10Gi")),
+							},
+						},
+					},
+				},
+			},
+		},
+		Preemption: &kueuev1beta1.ClusterQueuePreemption{
+			ReclaimWithinCohort: kueuev1beta1.PreemptionPolicyAny,
+		},
+	}
+	lenderClusterQueue := CreateTrackedKueueClusterQueue(test, lenderCqSpec)
+	defer test.Client().Kueue().KueueV1beta1().ClusterQueues().Delete(test.Ctx(), lenderClusterQueue.Name, metav1.DeleteOptions{})
+	if lenderClusterQueue.Spec.ResourceGroups[0].Flavors[0].Resources[0].LendingLimit == nil {
+		test.T().Skip("LendingLimit was dropped from the ClusterQueue, Kueue LendingLimit feature gate is most likely disabled")
+	}
+
+	borrowerCqSpec := kueuev1beta1.ClusterQueueSpec{
+		Cohort:            cohort,
+		NamespaceSelector: &metav1.LabelSelector{},
+		ResourceGroups: []kueuev1beta1.ResourceGroup{
+			{
+				CoveredResources: []corev1.ResourceName{corev1.ResourceName("cpu"), corev1.ResourceName("memory")},
+				Flavors: []kueuev1beta1.FlavorQuotas{
+					{
+						Name: kueuev1beta1.ResourceFlavorReference(resourceFlavor.Name),
+						Resources: []kueuev1beta1.ResourceQuota{
+							{
+								Name:         corev1.ResourceCPU,
+								NominalQuota: resource.MustParse("2"),
+							},
+							{
+								Name:         corev1.ResourceMemory,
+								NominalQuota: resource.MustParse("5Gi"),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	borrowerClusterQueue := CreateTrackedKueueClusterQueue(test, borrowerCqSpec)
+	defer test.Client().Kueue().KueueV1beta1().ClusterQueues().Delete(test.Ctx(), borrowerClusterQueue.Name, metav1.DeleteOptions{})
+
+	lenderLocalQueue := CreateKueueLocalQueue(test, namespace.Name, lenderClusterQueue.Name)
+	borrowerLocalQueue := CreateKueueLocalQueue(test, namespace.Name, borrowerClusterQueue.Name)
+
+	// Create three borrower PyTorch jobs, the first one fits into the borrower quota, the other two borrow the lent quota.
+	// The low priority one is admitted before the high priority one.
+	borrowerJob := createPriorityPyTorchJob(test, namespace.Name, borrowerLocalQueue.Name, highPriority.Name, *config, cache)
+	EventuallyOf(test, PytorchJob(test, namespace.Name, borrowerJob.Name), TestTimeoutShort).
+		Should(Field(PytorchJobConditionRunning).Equal(corev1.ConditionTrue))
+	lowBorrowingJob := createPriorityPyTorchJob(test, namespace.Name, borrowerLocalQueue.Name, lowPriority.Name, *config, cache)
+	EventuallyOf(test, PytorchJob(test, namespace.Name, lowBorrowingJob.Name), TestTimeoutShort).
+		Should(Field(PytorchJobConditionRunning).Equal(corev1.ConditionTrue))
+	highBorrowingJob := createPriorityPyTorchJob(test, namespace.Name, borrowerLocalQueue.Name, highPriority.Name, *config, cache)
+	EventuallyOf(test, PytorchJob(test, namespace.Name, highBorrowingJob.Name), TestTimeoutShort).
+		Should(Field(PytorchJobConditionRunning).Equal(corev1.ConditionTrue))
+
+	// Create first lender PyTorch job, it fits into the quota which isn't lent, so no eviction is expected
+	lenderJob := createPyTorchJob(test, namespace.Name, lenderLocalQueue.Name, *config, "", cache)
+	EventuallyOf(test, PytorchJob(test, namespace.Name, lenderJob.Name), TestTimeoutShort).
+		Should(Field(PytorchJobConditionRunning).Equal(corev1.ConditionTrue))
+	for _, job := range []*kftov1.PyTorchJob{borrowerJob, lowBorrowingJob, highBorrowingJob} {
+		ExpectOf(test, GetKueueWorkloadOwnedBy(test, namespace.Name, job)).
+			To(Field(KueueWorkloadEvicted).Equal(false))
+	}
+
+	// Create second lender PyTorch job, it needs the quota of one of the borrowing jobs back
+	secondLenderJob := createPyTorchJob(test, namespace.Name, lenderLocalQueue.Name, *config, "", cache)
+
+	// Make sure the low priority borrowing workload is evicted first, although admitted before the high priority one,
+	// and its PyTorch job suspended
+	EventuallyOf(test, KueueWorkloadOwnedBy(test, namespace.Name, lowBorrowingJob), TestTimeoutShort).
+		Should(Field(KueueWorkloadEvictedByPreemption).Equal(true))
+	EventuallyOf(test, PytorchJob(test, namespace.Name, lowBorrowingJob.Name), TestTimeoutShort).
+		Should(Field(PytorchJobConditionSuspended).Equal(corev1.ConditionTrue))
+
+	// Make sure the second lender PyTorch job runs on the reclaimed quota
+	EventuallyOf(test, PytorchJob(test, namespace.Name, secondLenderJob.Name), TestTimeoutShort).
+		Should(Field(PytorchJobConditionRunning).Equal(corev1.ConditionTrue))
+
+	// Make sure the high priority borrowing workload, and the borrower workload running within its own quota, are left
+	// untouched, as the quota of the evicted workload is enough
+	for _, job := range []*kftov1.PyTorchJob{borrowerJob, highBorrowingJob} {
+		ExpectOf(test, GetKueueWorkloadOwnedBy(test, namespace.Name, job)).
+			To(Field(KueueWorkloadEvicted).Equal(false))
+	}
+
+	// Make sure the second lender PyTorch job succeeds
+	EventuallyOfWithPolling(test, PytorchJob(test, namespace.Name, secondLenderJob.Name), TestTimeoutLong, PollingStrategyFor("PyTorchJob")).
+		Should(Field(PytorchJobConditionSucceeded).Equal(corev1.ConditionTrue))
+	test.T().Logf("PytorchJob %s/%s ran successfully on reclaimed quota", secondLenderJob.Namespace, secondLenderJob.Name)
+}
+
+// createPriorityPyTorchJob creates a training job queued with the Kueue WorkloadPriorityClass.
+func createPriorityPyTorchJob(test Test, namespace, localQueueName, priorityClass string, config corev1.ConfigMap, cache HuggingFaceCache) *kftov1.PyTorchJob {
+	test.T().Helper()
+	job := newPyTorchJob(namespace, localQueueName, config, "", cache)
+	job.Labels["kueue.x-k8s.io/priority-class"] = priorityClass
+	return submitPyTorchJob(test, job)
+}
@@ -17,9 +17,12 @@ limitations under the License.
 package kfto
 
 import (
+	"math"
 	"testing"
+	"time"
 
 	. "github.com/onsi/gomega"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
 	. "github.com/project-codeflare/codeflare-common/support"
 	kueuev1beta1 "sigs.k8s.io/kueue/apis/kueue/v1beta1"
 
@@ -33,7 +36,7 @@ import (
 func PytorchJob(t Test, namespace, name string) func(g Gomega) *kftov1.PyTorchJob {
 	return func(g Gomega) *kftov1.PyTorchJob {
 		job, err := t.Client().Kubeflow().KubeflowV1().PyTorchJobs(namespace).Get(t.Ctx(), name, metav1.GetOptions{})
-		g.Expect(err).NotTo(HaveOccurred())
+		g.Expect(WrapError(err, "getting", Ref("PyTorchJob", namespace, name))).NotTo(HaveOccurred())
 		return job
 	}
 }
@@ -46,6 +49,10 @@ func PytorchJobConditionSucceeded(job *kftov1.PyTorchJob) corev1.ConditionStatus
 	return PytorchJobCondition(job, kftov1.JobSucceeded)
 }
 
+func PytorchJobConditionFailed(job *kftov1.PyTorchJob) corev1.ConditionStatus {
+	return PytorchJobCondition(job, kftov1.JobFailed)
+}
+
 func PytorchJobConditionSuspended(job *kftov1.PyTorchJob) corev1.ConditionStatus {
 	return PytorchJobCondition(job, kftov1.JobSuspended)
 }
@@ -59,25 +66,65 @@ func PytorchJobCondition(job *kftov1.PyTorchJob, conditionType kftov1.JobConditi
 	return corev1.ConditionUnknown
 }
 
+// PytorchJobReplicaStatus returns the status of the replicas of the type, with no replica counted when the
+// training operator hasn't reported them yet.
+func PytorchJobReplicaStatus(replicaType kftov1.ReplicaType) func(job *kftov1.PyTorchJob) kftov1.ReplicaStatus {
+	return func(job *kftov1.PyTorchJob) kftov1.ReplicaStatus {
+		if status, ok := job.Status.ReplicaStatuses[replicaType]; ok && status != nil {
+			return *status
+		}
+		return kftov1.ReplicaStatus{}
+	}
+}
+
+// PytorchJobReplicasActive returns the number of running replicas of the type.
+func PytorchJobReplicasActive(replicaType kftov1.ReplicaType) func(job *kftov1.PyTorchJob) int32 {
+	return func(job *kftov1.PyTorchJob) int32 {
+		return PytorchJobReplicaStatus(replicaType)(job).Active
+	}
+}
+
+// PytorchJobReplicasSucceeded returns the number of succeeded replicas of the type.
+func PytorchJobReplicasSucceeded(replicaType kftov1.ReplicaType) func(job *kftov1.PyTorchJob) int32 {
+	return func(job *kftov1.PyTorchJob) int32 {
+		return PytorchJobReplicaStatus(replicaType)(job).Succeeded
+	}
+}
+
 func OwnerReferenceName(meta metav1.Object) string {
 	return meta.GetOwnerReferences()[0].Name
 }
 
 func TestPytorchjobWithSFTtrainer(t *testing.T) {
-	test := With(t)
+	Track(t, LabelKueue, LabelLong, LabelTier1)
+	test := MustGather(WithHooks(t))
+
+	// Budget the scenario, so it aborts early once the training can't be evaluated in time
+	budget := NewScenarioBudget(test, 2*TestTimeoutLong+2*TestTimeoutShort,
+		BudgetPhase{Name: "Admission", MaxDuration: TestTimeoutLong},
+		BudgetPhase{Name: "Training", MinDuration: time.Minute, MaxDuration: TestTimeoutShort},
+		BudgetPhase{Name: "Evaluation", MinDuration: time.Minute, MaxDuration: TestTimeoutLong},
+	)
 
 	// Create a namespace
 	namespace := test.NewTestNamespace()
 
-	// Create a ConfigMap with training dataset and configuration
+	// Create a ConfigMap with training dataset, configuration and evaluation script
 	configData := map[string][]byte{
 		"config.json":                   ReadFile(test, "config.json"),
 		"twitter_complaints_small.json": ReadFile(test, "twitter_complaints_small.json"),
+		"evaluate_perplexity.py":        ReadFile(test, "evaluate_perplexity.py"),
 	}
 	config := CreateConfigMap(test, namespace.Name, configData)
 
+	// Provision the Hugging Face cache with the model and tokenizer, so they aren't downloaded by each workload
+	cache := provisionHuggingFaceCache(test, namespace.Name)
+
+	// Create a PVC to store the trained model, so it can be evaluated afterwards
+	outputPvc := CreatePersistentVolumeClaimWithStorageClass(test, namespace.Name, "10Gi", GetStorageClass(), corev1.ReadWriteOnce)
+
 	// Create Kueue resources
-	resourceFlavor := CreateKueueResourceFlavor(test, kueuev1beta1.ResourceFlavorSpec{})
+	resourceFlavor := CreateTrackedKueueResourceFlavor(test, kueuev1beta1.ResourceFlavorSpec{})
 	defer test.Client().Kueue().KueueV1beta1().ResourceFlavors().Delete(test.Ctx(), resourceFlavor.Name, metav1.DeleteOptions{})
 	cqSpec := kueuev1beta1.ClusterQueueSpec{
 		NamespaceSelector: &metav1.LabelSelector{},
@@ -102,33 +149,37 @@ func TestPytorchjobWithSFTtrainer(t *testing.T) {
 			},
 		},
 	}
-	clusterQueue := CreateKueueClusterQueue(test, cqSpec)
+	clusterQueue := CreateTrackedKueueClusterQueue(test, cqSpec)
 	defer test.Client().Kueue().KueueV1beta1().ClusterQueues().Delete(test.Ctx(), clusterQueue.Name, metav1.DeleteOptions{})
 	localQueue := CreateKueueLocalQueue(test, namespace.Name, clusterQueue.Name)
 
 	// Create training PyTorch job
-	tuningJob := createPyTorchJob(test, namespace.Name, localQueue.Name, *config)
+	admissionTimeout := budget.Phase("Admission")
+	tuningJob := createPyTorchJob(test, namespace.Name, localQueue.Name, *config, outputPvc.Name, cache)
 
 	// Make sure the Kueue Workload is admitted
-	test.Eventually(KueueWorkloads(test, namespace.Name), TestTimeoutLong).
-		Should(
-			And(
-				HaveLen(1),
-				ContainElement(WithTransform(KueueWorkloadAdmitted, BeTrueBecause("Workload failed to be admitted"))),
-			),
-		)
+	EventuallyOfWithPolling(test, KueueWorkloads(test, namespace.Name), admissionTimeout, PollingStrategyFor("Workload")).
+		Should(HaveLenOf[*kueuev1beta1.Workload](1).And(ContainElementOf(Field(KueueWorkloadAdmitted).Equal(true))), "Workload failed to be admitted")
 
 	// Make sure the PyTorch job is running
-	test.Eventually(PytorchJob(test, namespace.Name, tuningJob.Name), TestTimeoutShort).
-		Should(WithTransform(PytorchJobConditionRunning, Equal(corev1.ConditionTrue)))
+	trainingTimeout := budget.Phase("Training")
+	EventuallyOf(test, PytorchJob(test, namespace.Name, tuningJob.Name), TestTimeoutShort).
+		Should(Field(PytorchJobConditionRunning).Equal(corev1.ConditionTrue))
 
 	// Make sure the PyTorch job succeed
-	test.Eventually(PytorchJob(test, namespace.Name, tuningJob.Name), TestTimeoutShort).Should(WithTransform(PytorchJobConditionSucceeded, Equal(corev1.ConditionTrue)))
+	EventuallyOf(test, PytorchJob(test, namespace.Name, tuningJob.Name), trainingTimeout).Should(Field(PytorchJobConditionSucceeded).Equal(corev1.ConditionTrue))
 	test.T().Logf("PytorchJob %s/%s ran successfully", tuningJob.Namespace, tuningJob.Name)
+
+	// Evaluate the trained model, a job which completes with broken model (e.g. because of fp16 overflow) must not pass
+	evaluationTimeout := budget.Phase("Evaluation")
+	perplexity := evaluateTrainedModel(test, namespace.Name, localQueue.Name, *config, outputPvc.Name, cache, evaluationTimeout)
+	test.Expect(math.IsNaN(perplexity)).To(BeFalse(), "Perplexity of the trained model is NaN")
+	test.Expect(perplexity).To(BeNumerically("<=", GetFmsHfTuningMaxPerplexity(test)), "Perplexity of the trained model is above the threshold")
 }
 
 func TestPytorchjobUsingKueueQuota(t *testing.T) {
-	test := With(t)
+	Track(t, LabelKueue, LabelLong, LabelTier1)
+	test := MustGather(WithHooks(t))
 
 	// Create a namespace
 	namespace := test.NewTestNamespace()
@@ -140,8 +191,11 @@ func TestPytorchjobUsingKueueQuota(t *testing.T) {
 	}
 	config := CreateConfigMap(test, namespace.Name, configData)
 
+	// Provision the Hugging Face cache with the model and tokenizer, so they aren't downloaded by each workload
+	cache := provisionHuggingFaceCache(test, namespace.Name)
+
 	// Create limited Kueue resources to run just one Pytorchjob at a time
-	resourceFlavor := CreateKueueResourceFlavor(test, kueuev1beta1.ResourceFlavorSpec{})
+	resourceFlavor := CreateTrackedKueueResourceFlavor(test, kueuev1beta1.ResourceFlavorSpec{})
 	defer test.Client().Kueue().KueueV1beta1().ResourceFlavors().Delete(test.Ctx(), resourceFlavor.Name, metav1.DeleteOptions{})
 	cqSpec := kueuev1beta1.ClusterQueueSpec{
 		NamespaceSelector: &metav1.LabelSelector{},
@@ -166,38 +220,55 @@ func TestPytorchjobUsingKueueQuota(t *testing.T) {
 			},
 		},
 	}
-	clusterQueue := CreateKueueClusterQueue(test, cqSpec)
+	clusterQueue := CreateTrackedKueueClusterQueue(test, cqSpec)
 	defer test.Client().Kueue().KueueV1beta1().ClusterQueues().Delete(test.Ctx(), clusterQueue.Name, metav1.DeleteOptions{})
 	localQueue := CreateKueueLocalQueue(test, namespace.Name, clusterQueue.Name)
 
 	// Create first training PyTorch job
-	tuningJob := createPyTorchJob(test, namespace.Name, localQueue.Name, *config)
+	tuningJob := createPyTorchJob(test, namespace.Name, localQueue.Name, *config, "", cache)
 
 	// Make sure the PyTorch job is running
-	test.Eventually(PytorchJob(test, namespace.Name, tuningJob.Name), TestTimeoutShort).
-		Should(WithTransform(PytorchJobConditionRunning, Equal(corev1.ConditionTrue)))
+	EventuallyOf(test, PytorchJob(test, namespace.Name, tuningJob.Name), TestTimeoutShort).
+		Should(Field(PytorchJobConditionRunning).Equal(corev1.ConditionTrue))
 
 	// Create second training PyTorch job
-	secondTuningJob := createPyTorchJob(test, namespace.Name, localQueue.Name, *config)
+	secondTuningJob := createPyTorchJob(test, namespace.Name, localQueue.Name, *config, "", cache)
 
 	// Make sure the second PyTorch job is suspended, waiting for first job to finish
-	test.Eventually(PytorchJob(test, namespace.Name, secondTuningJob.Name), TestTimeoutShort).
-		Should(WithTransform(PytorchJobConditionSuspended, Equal(corev1.ConditionTrue)))
+	EventuallyOf(test, PytorchJob(test, namespace.Name, secondTuningJob.Name), TestTimeoutShort).
+		Should(Field(PytorchJobConditionSuspended).Equal(corev1.ConditionTrue))
 
 	// Make sure the first PyTorch job succeed
-	test.Eventually(PytorchJob(test, namespace.Name, tuningJob.Name), TestTimeoutLong).Should(WithTransform(PytorchJobConditionSucceeded, Equal(corev1.ConditionTrue)))
+	EventuallyOfWithPolling(test, PytorchJob(test, namespace.Name, tuningJob.Name), TestTimeoutLong, PollingStrategyFor("PyTorchJob")).Should(Field(PytorchJobConditionSucceeded).Equal(corev1.ConditionTrue))
 	test.T().Logf("PytorchJob %s/%s ran successfully", tuningJob.Namespace, tuningJob.Name)
 
 	// Second PyTorch job should be started now
-	test.Eventually(PytorchJob(test, namespace.Name, secondTuningJob.Name), TestTimeoutShort).
-		Should(WithTransform(PytorchJobConditionRunning, Equal(corev1.ConditionTrue)))
+	EventuallyOf(test, PytorchJob(test, namespace.Name, secondTuningJob.Name), TestTimeoutShort).
+		Should(Field(PytorchJobConditionRunning).Equal(corev1.ConditionTrue))
 
 	// Make sure the second PyTorch job succeed
-	test.Eventually(PytorchJob(test, namespace.Name, secondTuningJob.Name), TestTimeoutLong).Should(WithTransform(PytorchJobConditionSucceeded, Equal(corev1.ConditionTrue)))
+	EventuallyOfWithPolling(test, PytorchJob(test, namespace.Name, secondTuningJob.Name), TestTimeoutLong, PollingStrategyFor("PyTorchJob")).Should(Field(PytorchJobConditionSucceeded).Equal(corev1.ConditionTrue))
 	test.T().Logf("PytorchJob %s/%s ran successfully", secondTuningJob.Namespace, secondTuningJob.Name)
 }
 
-func createPyTorchJob(test Test, namespace, localQueueName string, config corev1.ConfigMap) *kftov1.PyTorchJob {
+// provisionHuggingFaceCache provisions the Hugging Face cache with the model and tokenizer of the training configuration.
+func provisionHuggingFaceCache(test Test, namespace string) HuggingFaceCache {
+	test.T().Helper()
+	return ProvisionHuggingFaceCache(test, namespace,
+		HuggingFaceModel("bigscience/bloom-560m", "*.json", "*.safetensors", "*.txt"),
+		HuggingFaceModel("bigscience/bloom", "tokenizer*", "special_tokens_map.json", "config.json"),
+	)
+}
+
+// createPyTorchJob creates a training job, the trained model is stored into the PVC if outputPvcName is set.
+// The model and tokenizer are read from the Hugging Face cache, unless it's the zero value.
+func createPyTorchJob(test Test, namespace, localQueueName string, config corev1.ConfigMap, outputPvcName string, cache HuggingFaceCache) *kftov1.PyTorchJob {
+	test.T().Helper()
+	return submitPyTorchJob(test, newPyTorchJob(namespace, localQueueName, config, outputPvcName, cache))
+}
+
+// newPyTorchJob returns the training job created by createPyTorchJob, so it can be customized before being submitted.
+func newPyTorchJob(namespace, localQueueName string, config corev1.ConfigMap, outputPvcName string, cache HuggingFaceCache) *kftov1.PyTorchJob {
 	tuningJob := &kftov1.PyTorchJob{
 		TypeMeta: metav1.TypeMeta{
 			APIVersion: corev1.SchemeGroupVersion.String(),
@@ -205,6 +276,7 @@ func createPyTorchJob(test Test, namespace, localQueueName string, config corev1
 		},
 		ObjectMeta: metav1.ObjectMeta{
 			GenerateName: "kfto-sft-",
+			Namespace:    namespace,
 			Labels: map[string]string{
 				"kueue.x-k8s.io/queue-name": localQueueName,
 			},
@@ -271,8 +343,33 @@ func createPyTorchJob(test Test, namespace, localQueueName string, config corev1
 		},
 	}
 
-	tuningJob, err := test.Client().Kubeflow().KubeflowV1().PyTorchJobs(namespace).Create(test.Ctx(), tuningJob, metav1.CreateOptions{})
-	test.Expect(err).NotTo(HaveOccurred())
+	if outputPvcName != "" {
+		podSpec := &tuningJob.Spec.PyTorchReplicaSpecs["Master"].Template.Spec
+		podSpec.Containers[0].VolumeMounts = append(podSpec.Containers[0].VolumeMounts, corev1.VolumeMount{
+			Name:      "output-volume",
+			MountPath: "/tmp/out",
+		})
+		podSpec.Volumes = append(podSpec.Volumes, corev1.Volume{
+			Name: "output-volume",
+			VolumeSource: corev1.VolumeSource{
+				PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+					ClaimName: outputPvcName,
+				},
+			},
+		})
+	}
+
+	cache.Apply(&tuningJob.Spec.PyTorchReplicaSpecs["Master"].Template.Spec)
+
+	return tuningJob
+}
+
+// submitPyTorchJob creates the training job in its namespace.
+func submitPyTorchJob(test Test, tuningJob *kftov1.PyTorchJob) *kftov1.PyTorchJob {
+	test.T().Helper()
+	created, err := test.Client().Kubeflow().KubeflowV1().PyTorchJobs(tuningJob.Namespace).Create(test.Ctx(), tuningJob, metav1.CreateOptions{})
+	ExpectNoError(test, err, "creating", Ref("PyTorchJob", tuningJob.Namespace, tuningJob.Name+tuningJob.GenerateName))
+	tuningJob = created
 	test.T().Logf("Created PytorchJob %s/%s successfully", tuningJob.Namespace, tuningJob.Name)
 
 	return tuningJob
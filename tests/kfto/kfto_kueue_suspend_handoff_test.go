@@ -0,0 +1,130 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kfto
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/opendatahub-io/distributed-workloads/pkg/examples"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	. "github.com/project-codeflare/codeflare-common/support"
+	kueuev1beta1 "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/apis/meta/v1/unstructured"
+
+	kftov1 "github.com/kubeflow/training-operator/pkg/apis/kubeflow.org/v1"
+)
+
+// TestPytorchjobSuspendHandoffToKueue makes sure a PyTorchJob created suspended in a Kueue queue is only unsuspended
+// by Kueue once admitted, and the training operator never starts its pods before, as regressed across operator versions.
+func TestPytorchjobSuspendHandoffToKueue(t *testing.T) {
+	Track(t, LabelKueue)
+	test := MustGather(WithHooks(t))
+
+	// Create a namespace
+	namespace := test.NewTestNamespace()
+
+	// Create Kueue resources, holding the admission of the queued workloads
+	resourceFlavor := CreateTrackedKueueResourceFlavor(test, kueuev1beta1.ResourceFlavorSpec{})
+	defer test.Client().Kueue().KueueV1beta1().ResourceFlavors().Delete(test.Ctx(), resourceFlavor.Name, metav1.DeleteOptions{})
+	cqSpec := kueuev1beta1.ClusterQueueSpec{
+		NamespaceSelector: &metav1.LabelSelector{},
+		StopPolicy:        Ptr(kueuev1beta1.Hold),
+		ResourceGroups: []kueuev1beta1.ResourceGroup{
+			{
+				CoveredResources: []corev1.ResourceName{corev1.ResourceName("cpu"), corev1.ResourceName("memory")},
+				Flavors: []kueuev1beta1.FlavorQuotas{
+					{
+						Name: kueuev1beta1.ResourceFlavorReference(resourceFlavor.Name),
+						Resources: []kueuev1beta1.ResourceQuota{
+							{
+								Name:         corev1.ResourceCPU,
+								NominalQuota: resource.MustParse("2"),
+							},
+							{
+								Name:         corev1.ResourceMemory,
+								NominalQuota: resource.MustParse("4Gi"),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	clusterQueue := CreateTrackedKueueClusterQueue(test, cqSpec)
+	defer test.Client().Kueue().KueueV1beta1().ClusterQueues().Delete(test.Ctx(), clusterQueue.Name, metav1.DeleteOptions{})
+	localQueue := CreateKueueLocalQueue(test, namespace.Name, clusterQueue.Name)
+
+	// Create PyTorch job suspended, recording its suspension as soon as it's created
+	job := examples.PyTorchJob(examples.PyTorchJobOptions{
+		Name:       "kfto-suspend-handoff",
+		Namespace:  namespace.Name,
+		Image:      GetFmsHfTuningImage(),
+		Command:    []string{"python", "-c", "import torch; print(f'torch {torch.__version__}')"},
+		Workers:    1,
+		CPU:        "250m",
+		Memory:     "512Mi",
+		LocalQueue: localQueue.Name,
+	})
+	job.Spec.RunPolicy.Suspend = Ptr(true)
+	events := RecordEvents(test, namespace.Name)
+	suspension := RecordStates(test, kftov1.SchemeGroupVersion.WithResource("pytorchjobs"), "PyTorchJob", namespace.Name, job.Name,
+		func(object *unstructured.Unstructured) string {
+			suspended, _, _ := unstructured.NestedBool(object.Object, "spec", "runPolicy", "suspend")
+			if suspended {
+				return "Suspended"
+			}
+			return "Unsuspended"
+		})
+	created, err := test.Client().Kubeflow().KubeflowV1().PyTorchJobs(namespace.Name).Create(test.Ctx(), job, metav1.CreateOptions{})
+	ExpectNoError(test, err, "creating", Ref("PyTorchJob", namespace.Name, job.Name+job.GenerateName))
+	job = created
+	test.T().Logf("Created PytorchJob %s/%s successfully", job.Namespace, job.Name)
+
+	// Make sure the PyTorch job stays suspended without any pod while its Workload is pending
+	EventuallyOf(test, KueueWorkloadOwnedBy(test, namespace.Name, job), TestTimeoutShort).
+		Should(Field(KueueWorkloadPending).Equal(true))
+	test.Consistently(func(g Gomega) {
+		g.Expect(PytorchJob(test, namespace.Name, job.Name)(g).Spec.RunPolicy.Suspend).To(Equal(Ptr(true)))
+		g.Expect(pytorchJobPods(test, namespace.Name, job.Name)(g)).To(BeEmpty())
+		g.Expect(KueueWorkloadOwnedBy(test, namespace.Name, job)(g)).To(Field(KueueWorkloadQuotaReserved).Equal(false))
+	}, TestTimeoutShort).Should(Succeed())
+
+	// Release the admission and make sure Kueue unsuspends the PyTorch job once admitted
+	SetKueueClusterQueueStopPolicy(test, clusterQueue.Name, kueuev1beta1.None)
+	EventuallyOf(test, KueueWorkloadOwnedBy(test, namespace.Name, job), TestTimeoutShort).
+		Should(Field(KueueWorkloadAdmitted).Equal(true))
+	ExpectTransitions(test, suspension, []string{"Suspended", "Unsuspended"}, TestTimeoutShort)
+	test.Eventually(events.EventsOf("PyTorchJob", job.Name), TestTimeoutShort).
+		Should(And(HaveEvent("Admitted"), HaveEvent("Started", clusterQueue.Name)))
+	EventuallyOfWithPolling(test, PytorchJob(test, namespace.Name, job.Name), TestTimeoutLong, PollingStrategyFor("PyTorchJob")).
+		Should(Field(PytorchJobConditionSucceeded).Equal(corev1.ConditionTrue))
+
+	// Make sure none of the pods was created before the admission, the timestamps have a second precision
+	admitted := KueueWorkloadAdmissionTime(GetKueueWorkloadOwnedBy(test, namespace.Name, job))
+	test.Expect(admitted).NotTo(BeNil())
+	pods := pytorchJobPods(test, namespace.Name, job.Name)(test)
+	test.Expect(pods).To(HaveLen(2))
+	for _, pod := range pods {
+		test.Expect(pod.CreationTimestamp.Before(admitted)).To(BeFalse(),
+			"Pod %s created at %s before the PyTorch job got admitted at %s", pod.Name, pod.CreationTimestamp, admitted)
+	}
+}
@@ -0,0 +1,236 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kfto
+
+import (
+	"regexp"
+	"strconv"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	. "github.com/project-codeflare/codeflare-common/support"
+	kueuev1beta1 "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kftov1 "github.com/kubeflow/training-operator/pkg/apis/kubeflow.org/v1"
+)
+
+var (
+	trainingStepRegexp    = regexp.MustCompile(`Step (\d+) loss`)
+	trainingResumedRegexp = regexp.MustCompile(`Resumed from step (\d+)`)
+)
+
+func TestPytorchjobSuspendResumeWithKueue(t *testing.T) {
+	Track(t, LabelKueue)
+	test := MustGather(WithHooks(t))
+
+	// Create a namespace
+	namespace := test.NewTestNamespace()
+
+	// Create a ConfigMap with the training script and a PVC storing checkpoints
+	config := CreateConfigMap(test, namespace.Name, map[string][]byte{
+		"resumable_training.py": ReadFile(test, "resumable_training.py"),
+	})
+	checkpointPvc := CreatePersistentVolumeClaimWithStorageClass(test, namespace.Name, "1Gi", GetStorageClass(), corev1.ReadWriteOnce)
+
+	// Create Kueue resources
+	resourceFlavor := CreateTrackedKueueResourceFlavor(test, kueuev1beta1.ResourceFlavorSpec{})
+	defer test.Client().Kueue().KueueV1beta1().ResourceFlavors().Delete(test.Ctx(), resourceFlavor.Name, metav1.DeleteOptions{})
+	cqSpec := kueuev1beta1.ClusterQueueSpec{
+		NamespaceSelector: &metav1.LabelSelector{},
+		ResourceGroups: []kueuev1beta1.ResourceGroup{
+			{
+				CoveredResources: []corev1.ResourceName{corev1.ResourceName("cpu"), corev1.ResourceName("memory")},
+				Flavors: []kueuev1beta1.FlavorQuotas{
+					{
+						Name: kueuev1beta1.ResourceFlavorReference(resourceFlavor.Name),
+						Resources: []kueuev1beta1.ResourceQuota{
+							{
+								Name:         corev1.ResourceCPU,
+								NominalQuota: resource.MustParse("2"),
+							},
+							{
+								Name:         corev1.ResourceMemory,
+								NominalQuota: resource.MustParse("4Gi"),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	clusterQueue := CreateTrackedKueueClusterQueue(test, cqSpec)
+	defer test.Client().Kueue().KueueV1beta1().ClusterQueues().Delete(test.Ctx(), clusterQueue.Name, metav1.DeleteOptions{})
+	localQueue := CreateKueueLocalQueue(test, namespace.Name, clusterQueue.Name)
+
+	// Create training PyTorch job and wait until it writes a few checkpoints
+	job := createResumableTrainingJob(test, namespace.Name, localQueue.Name, *config, checkpointPvc.Name)
+	EventuallyOf(test, PytorchJob(test, namespace.Name, job.Name), TestTimeoutMedium).
+		Should(Field(PytorchJobConditionRunning).Equal(corev1.ConditionTrue))
+	test.Eventually(masterPodLogs(test, namespace.Name, job.Name), TestTimeoutMedium).
+		Should(MatchRegexp(`Step 5 loss`))
+
+	// Suspend the PyTorch job by deactivating its Kueue Workload
+	workload := GetKueueWorkloadOwnedBy(test, namespace.Name, job)
+	SetKueueWorkloadActive(test, namespace.Name, workload.Name, false)
+
+	EventuallyOf(test, KueueWorkloadOwnedBy(test, namespace.Name, job), TestTimeoutShort).
+		Should(Field(KueueWorkloadEvictedByDeactivation).Equal(true).And(
+			Field(KueueWorkloadQuotaReserved).Equal(false),
+		))
+	EventuallyOf(test, PytorchJob(test, namespace.Name, job.Name), TestTimeoutShort).
+		Should(Field(PytorchJobConditionSuspended).Equal(corev1.ConditionTrue))
+
+	// Make sure the pods are terminated and the quota is released
+	WaitForDeletionOf(test, corev1.SchemeGroupVersion.WithResource("pods"), namespace.Name, metav1.ListOptions{LabelSelector: "training.kubeflow.org/job-name=" + job.Name}, TestTimeoutMedium)
+	EventuallyOf(test, KueueClusterQueue(test, clusterQueue.Name), TestTimeoutShort).
+		Should(Field(KueueClusterQueueReservingWorkloads).Equal(0))
+
+	// Resume the PyTorch job
+	SetKueueWorkloadActive(test, namespace.Name, workload.Name, true)
+	EventuallyOf(test, KueueWorkloadOwnedBy(test, namespace.Name, job), TestTimeoutShort).
+		Should(Field(KueueWorkloadAdmitted).Equal(true))
+	EventuallyOf(test, PytorchJob(test, namespace.Name, job.Name), TestTimeoutMedium).
+		Should(Field(PytorchJobConditionRunning).Equal(corev1.ConditionTrue))
+
+	// Make sure the training resumed from the checkpoint and completed
+	test.Eventually(masterPodLogs(test, namespace.Name, job.Name), TestTimeoutMedium).
+		Should(MatchRegexp(`Resumed from step \d+`))
+	EventuallyOfWithPolling(test, PytorchJob(test, namespace.Name, job.Name), TestTimeoutLong, PollingStrategyFor("PyTorchJob")).
+		Should(Field(PytorchJobConditionSucceeded).Equal(corev1.ConditionTrue))
+
+	logs := masterPodLogs(test, namespace.Name, job.Name)(test)
+	resumedStep, err := strconv.Atoi(trainingResumedRegexp.FindStringSubmatch(logs)[1])
+	ExpectNoError(test, err, "parsing training logs of", Ref("PyTorchJob", namespace.Name, job.Name))
+	test.Expect(resumedStep).To(BeNumerically(">=", 5), "Training didn't resume from the checkpoint written before the suspension")
+	firstStep, err := strconv.Atoi(trainingStepRegexp.FindStringSubmatch(logs)[1])
+	ExpectNoError(test, err, "parsing training logs of", Ref("PyTorchJob", namespace.Name, job.Name))
+	test.Expect(firstStep).To(Equal(resumedStep+1), "Training repeated steps already done before the suspension")
+	test.Expect(logs).To(ContainSubstring("Training completed"))
+}
+
+func createResumableTrainingJob(test Test, namespace, localQueueName string, config corev1.ConfigMap, checkpointPvcName string) *kftov1.PyTorchJob {
+	test.T().Helper()
+
+	job := &kftov1.PyTorchJob{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "PyTorchJob",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "kfto-resumable-",
+			Labels: map[string]string{
+				"kueue.x-k8s.io/queue-name": localQueueName,
+			},
+		},
+		Spec: kftov1.PyTorchJobSpec{
+			PyTorchReplicaSpecs: map[kftov1.ReplicaType]*kftov1.ReplicaSpec{
+				"Master": {
+					Replicas:      Ptr(int32(1)),
+					RestartPolicy: "Never",
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name:            "pytorch",
+									Image:           GetFmsHfTuningImage(),
+									ImagePullPolicy: corev1.PullIfNotPresent,
+									Command:         []string{"python", "/etc/config/resumable_training.py"},
+									Env: []corev1.EnvVar{
+										{
+											Name:  "CHECKPOINT_DIR",
+											Value: "/tmp/out",
+										},
+									},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "config-volume",
+											MountPath: "/etc/config",
+										},
+										{
+											Name:      "output-volume",
+											MountPath: "/tmp/out",
+										},
+									},
+									Resources: corev1.ResourceRequirements{
+										Requests: corev1.ResourceList{
+											corev1.ResourceCPU:    resource.MustParse("1"),
+											corev1.ResourceMemory: resource.MustParse("2Gi"),
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "config-volume",
+									VolumeSource: corev1.VolumeSource{
+										ConfigMap: &corev1.ConfigMapVolumeSource{
+											LocalObjectReference: corev1.LocalObjectReference{
+												Name: config.Name,
+											},
+										},
+									},
+								},
+								{
+									Name: "output-volume",
+									VolumeSource: corev1.VolumeSource{
+										PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+											ClaimName: checkpointPvcName,
+										},
+									},
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	created, err := test.Client().Kubeflow().KubeflowV1().PyTorchJobs(namespace).Create(test.Ctx(), job, metav1.CreateOptions{})
+	ExpectNoError(test, err, "creating", Ref("PyTorchJob", namespace, job.Name+job.GenerateName))
+	job = created
+	test.T().Logf("Created PytorchJob %s/%s successfully", job.Namespace, job.Name)
+
+	return job
+}
+
+func pytorchJobPods(test Test, namespace, jobName string) func(g Gomega) []corev1.Pod {
+	return func(g Gomega) []corev1.Pod {
+		pods, err := test.Client().Core().CoreV1().Pods(namespace).List(test.Ctx(), metav1.ListOptions{LabelSelector: "training.kubeflow.org/job-name=" + jobName})
+		g.Expect(WrapError(err, "listing pods of", Ref("PyTorchJob", namespace, jobName))).NotTo(HaveOccurred())
+		return pods.Items
+	}
+}
+
+// masterPodLogs returns logs of the current master pod of the PyTorch job.
+func masterPodLogs(test Test, namespace, jobName string) func(g Gomega) string {
+	return func(g Gomega) string {
+		pods, err := test.Client().Core().CoreV1().Pods(namespace).List(test.Ctx(), metav1.ListOptions{
+			LabelSelector: "training.kubeflow.org/job-name=" + jobName + ",training.kubeflow.org/replica-type=master",
+		})
+		g.Expect(WrapError(err, "listing pods of", Ref("PyTorchJob", namespace, jobName))).NotTo(HaveOccurred())
+		g.Expect(pods.Items).To(HaveLen(1))
+		logs, err := test.Client().Core().CoreV1().Pods(namespace).GetLogs(pods.Items[0].Name, &corev1.PodLogOptions{}).DoRaw(test.Ctx())
+		g.Expect(WrapError(err, "getting logs of", Ref("Pod", namespace, pods.Items[0].Name))).NotTo(HaveOccurred())
+		return string(logs)
+	}
+}
@@ -0,0 +1,92 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kfto
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/opendatahub-io/distributed-workloads/pkg/examples"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kftov1 "github.com/kubeflow/training-operator/pkg/apis/kubeflow.org/v1"
+)
+
+const mnistWorkers = 2
+
+// TestPytorchjobMnistMultiWorker trains MNIST with a PyTorchJob of a master and two workers, using distributed
+// data parallel over gloo, and checks the replicas are reported by the training operator and all of them succeed.
+func TestPytorchjobMnistMultiWorker(t *testing.T) {
+	Track(t)
+	test := MustGather(WithHooks(t))
+
+	// Create a namespace
+	namespace := test.NewTestNamespace()
+
+	// Create a ConfigMap with the training script
+	config := CreateConfigMap(test, namespace.Name, map[string][]byte{
+		"mnist.py": ReadFile(test, "mnist.py"),
+	})
+
+	// Create the PyTorchJob
+	job := examples.PyTorchJob(examples.PyTorchJobOptions{
+		GenerateName:     "kfto-mnist-",
+		Namespace:        namespace.Name,
+		Image:            GetFmsHfTuningImage(),
+		Command:          []string{"python", examples.PyTorchJobScriptsMountPath + "/mnist.py"},
+		Workers:          mnistWorkers,
+		CPU:              "1",
+		Memory:           "2Gi",
+		ScriptsConfigMap: config.Name,
+		CrashCapture:     IsCrashCapture(),
+	})
+	job, err := test.Client().Kubeflow().KubeflowV1().PyTorchJobs(namespace.Name).Create(test.Ctx(), job, metav1.CreateOptions{})
+	ExpectNoError(test, err, "creating", Ref("PyTorchJob", namespace.Name, job.GenerateName))
+	test.T().Logf("Created PyTorchJob %s/%s successfully", job.Namespace, job.Name)
+
+	// Make sure all the replicas run
+	EventuallyOf(test, PytorchJob(test, namespace.Name, job.Name), TestTimeoutLong).
+		Should(Field(PytorchJobConditionRunning).Equal(corev1.ConditionTrue))
+	EventuallyOf(test, PytorchJob(test, namespace.Name, job.Name), TestTimeoutMedium).
+		Should(Field(PytorchJobReplicasActive(kftov1.PyTorchJobReplicaTypeMaster)).Equal(int32(1)).
+			And(Field(PytorchJobReplicasActive(kftov1.PyTorchJobReplicaTypeWorker)).Equal(int32(mnistWorkers))))
+
+	// Make sure the training succeeds on the master and all the workers
+	EventuallyOf(test, PytorchJob(test, namespace.Name, job.Name), TestTimeoutLong).
+		Should(Field(PytorchJobConditionSucceeded).Equal(corev1.ConditionTrue).
+			Or(Field(PytorchJobConditionFailed).Equal(corev1.ConditionTrue)))
+	for _, replicaType := range []string{"master", "worker"} {
+		for _, pod := range pytorchJobReplicaPods(test, namespace.Name, job.Name, replicaType)(test) {
+			test.T().Logf("Pod %s logs:\n%s", pod.Name, GetPodLogs(test, &pod, corev1.PodLogOptions{}))
+		}
+	}
+	ExpectOf(test, PytorchJob(test, namespace.Name, job.Name)(test)).
+		To(Field(PytorchJobConditionSucceeded).Equal(corev1.ConditionTrue).
+			And(Field(PytorchJobReplicasSucceeded(kftov1.PyTorchJobReplicaTypeMaster)).Equal(int32(1))))
+	EventuallyOf(test, pytorchJobReplicaPods(test, namespace.Name, job.Name, "worker"), TestTimeoutShort).Should(
+		HaveLenOf[corev1.Pod](mnistWorkers).And(HaveEachOf(Field(func(pod corev1.Pod) corev1.PodPhase { return pod.Status.Phase }).Equal(corev1.PodSucceeded))),
+	)
+	for _, pod := range pytorchJobReplicaPods(test, namespace.Name, job.Name, "worker")(test) {
+		test.Expect(string(GetPodLogs(test, &pod, corev1.PodLogOptions{}))).
+			To(ContainSubstring(fmt.Sprintf("world_size=%d", mnistWorkers+1)))
+	}
+}
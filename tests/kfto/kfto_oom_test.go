@@ -0,0 +1,147 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kfto
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kftov1 "github.com/kubeflow/training-operator/pkg/apis/kubeflow.org/v1"
+)
+
+const oomBackoffLimit = 2
+
+func TestPytorchjobWorkerOOMKilled(t *testing.T) {
+	Track(t)
+	test := MustGather(WithHooks(t))
+
+	// Create a namespace
+	namespace := test.NewTestNamespace()
+
+	// Create PyTorch job with worker allocating more memory than its limit
+	events := RecordEvents(test, namespace.Name)
+	job := createOOMPyTorchJob(test, namespace.Name)
+
+	// Make sure the worker is OOM killed and restarted in place
+	test.Eventually(pytorchJobReplicaPods(test, namespace.Name, job.Name, "worker"), TestTimeoutMedium).
+		Should(ContainElement(Field(lastTerminationReason).Equal("OOMKilled")))
+
+	// Make sure the PyTorch job fails once the restarts reach the backoff limit
+	EventuallyOf(test, PytorchJob(test, namespace.Name, job.Name), TestTimeoutMedium).
+		Should(Field(PytorchJobConditionFailed).Equal(corev1.ConditionTrue))
+	job = PytorchJob(test, namespace.Name, job.Name)(test)
+	test.Expect(pytorchJobConditionMessage(job, kftov1.JobFailed)).To(ContainSubstring("backoff limit"))
+
+	workers := pytorchJobReplicaPods(test, namespace.Name, job.Name, "worker")(test)
+	test.Expect(workers).To(HaveLen(1))
+	test.Expect(workers[0].Status.ContainerStatuses[0].RestartCount).To(BeNumerically(">=", oomBackoffLimit))
+	test.Expect(lastTerminationReason(workers[0])).To(Equal("OOMKilled"))
+
+	// Make sure the failure is recorded in the PyTorch job events, which are collected with the test artifacts
+	test.Eventually(events.EventsOf("PyTorchJob", job.Name), TestTimeoutShort).Should(HaveEvent("PyTorchJobFailed"))
+}
+
+func createOOMPyTorchJob(test Test, namespace string) *kftov1.PyTorchJob {
+	test.T().Helper()
+
+	newReplica := func(command string, memoryLimit string) *kftov1.ReplicaSpec {
+		return &kftov1.ReplicaSpec{
+			Replicas:      Ptr(int32(1)),
+			RestartPolicy: kftov1.RestartPolicyOnFailure,
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{
+						{
+							Name:            "pytorch",
+							Image:           GetFmsHfTuningImage(),
+							ImagePullPolicy: corev1.PullIfNotPresent,
+							Command:         []string{"python", "-c", command},
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									corev1.ResourceCPU:    resource.MustParse("250m"),
+									corev1.ResourceMemory: resource.MustParse(memoryLimit),
+								},
+								Limits: corev1.ResourceList{
+									corev1.ResourceMemory: resource.MustParse(memoryLimit),
+								},
+							},
+						},
+					},
+				},
+			},
+		}
+	}
+
+	job := &kftov1.PyTorchJob{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "PyTorchJob",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "kfto-oom-",
+		},
+		Spec: kftov1.PyTorchJobSpec{
+			RunPolicy: kftov1.RunPolicy{
+				BackoffLimit: Ptr(int32(oomBackoffLimit)),
+			},
+			PyTorchReplicaSpecs: map[kftov1.ReplicaType]*kftov1.ReplicaSpec{
+				"Master": newReplica("import time; time.sleep(3600)", "512Mi"),
+				"Worker": newReplica("import time; data = bytearray(1024 * 1024 * 1024); time.sleep(3600)", "256Mi"),
+			},
+		},
+	}
+
+	created, err := test.Client().Kubeflow().KubeflowV1().PyTorchJobs(namespace).Create(test.Ctx(), job, metav1.CreateOptions{})
+	ExpectNoError(test, err, "creating", Ref("PyTorchJob", namespace, job.Name+job.GenerateName))
+	job = created
+	test.T().Logf("Created PytorchJob %s/%s successfully", job.Namespace, job.Name)
+
+	return job
+}
+
+func pytorchJobReplicaPods(test Test, namespace, jobName, replicaType string) func(g Gomega) []corev1.Pod {
+	return func(g Gomega) []corev1.Pod {
+		pods, err := test.Client().Core().CoreV1().Pods(namespace).List(test.Ctx(), metav1.ListOptions{
+			LabelSelector: "training.kubeflow.org/job-name=" + jobName + ",training.kubeflow.org/replica-type=" + replicaType,
+		})
+		g.Expect(WrapError(err, "listing pods of", Ref("PyTorchJob", namespace, jobName))).NotTo(HaveOccurred())
+		return pods.Items
+	}
+}
+
+func pytorchJobConditionMessage(job *kftov1.PyTorchJob, conditionType kftov1.JobConditionType) string {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == conditionType {
+			return condition.Message
+		}
+	}
+	return ""
+}
+
+func lastTerminationReason(pod corev1.Pod) string {
+	if len(pod.Status.ContainerStatuses) == 0 || pod.Status.ContainerStatuses[0].LastTerminationState.Terminated == nil {
+		return ""
+	}
+	return pod.Status.ContainerStatuses[0].LastTerminationState.Terminated.Reason
+}
@@ -0,0 +1,184 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kfto
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	kftov1 "github.com/kubeflow/training-operator/pkg/apis/kubeflow.org/v1"
+
+	"github.com/opendatahub-io/distributed-workloads/pkg/baselines"
+)
+
+var busBandwidthRegexp = regexp.MustCompile(`BUS_BANDWIDTH ([0-9.]+)`)
+
+func TestPytorchjobRcclAllReduce(t *testing.T) {
+	Track(t, LabelGpu)
+	test := MustGather(WithHooks(t))
+
+	gpus := GetRcclGpus(test)
+	if len(GetAmdGpuNodes(test, gpus)) == 0 {
+		test.T().Skipf("No node with %d AMD GPUs available in the cluster", gpus)
+	}
+	AcquireGpuLease(test)
+
+	// Create a namespace
+	namespace := test.NewTestNamespace()
+
+	// Monitor the load of the other namespaces, contaminating the measurements
+	MonitorNoisyNeighbors(test, namespace.Name)
+
+	// Create a ConfigMap with the benchmark script
+	config := CreateConfigMap(test, namespace.Name, map[string][]byte{
+		"rccl_all_reduce.py": ReadFile(test, "rccl_all_reduce.py"),
+	})
+
+	// Run the all-reduce benchmark across the GPUs of a single node
+	job := createRcclAllReduceJob(test, namespace.Name, *config, gpus)
+	EventuallyOf(test, PytorchJob(test, namespace.Name, job.Name), TestTimeoutLong).
+		Should(Field(PytorchJobConditionSucceeded).Equal(corev1.ConditionTrue).Or(
+			Field(PytorchJobConditionFailed).Equal(corev1.ConditionTrue),
+		))
+
+	pods := pytorchJobReplicaPods(test, namespace.Name, job.Name, "master")(test)
+	test.Expect(pods).To(HaveLen(1))
+	logs := string(GetPodLogs(test, &pods[0], corev1.PodLogOptions{}))
+	WriteToOutputDir(test, "rccl-all-reduce", Log, []byte(logs))
+	ExpectOf(test, PytorchJob(test, namespace.Name, job.Name)(test)).
+		To(Field(PytorchJobConditionSucceeded).Equal(corev1.ConditionTrue), "RCCL all-reduce benchmark failed, logs:\n%s", logs)
+
+	// Compare the bus bandwidth against the baseline of the GPU model
+	match := busBandwidthRegexp.FindStringSubmatch(logs)
+	test.Expect(match).NotTo(BeNil(), "Bus bandwidth not reported by the benchmark")
+	busBandwidth, err := strconv.ParseFloat(match[1], 64)
+	ExpectNoError(test, err, "parsing bus bandwidth from", Ref("PyTorchJob", namespace.Name, job.Name))
+
+	node, err := test.Client().Core().CoreV1().Nodes().Get(test.Ctx(), pods[0].Spec.NodeName, metav1.GetOptions{})
+	ExpectNoError(test, err, "getting", Ref("Node", "", pods[0].Spec.NodeName))
+	productName := node.Labels[AmdGpuProductNameLabel]
+	test.T().Logf("RCCL all-reduce bus bandwidth across %d %s GPUs is %.2f GB/s", gpus, productName, busBandwidth)
+
+	baseline, ok := GetRcclBusBandwidthBaseline(test, productName)
+	if !ok {
+		baseline, ok = GetPerformanceBaseline(test, baselines.MetricRcclBusBandwidth, productName)
+	}
+	if !ok {
+		test.T().Skipf("No bus bandwidth baseline for GPU model %q", productName)
+	}
+	test.Expect(busBandwidth).To(BeNumerically(">=", baseline),
+		"RCCL all-reduce bus bandwidth across %d %s GPUs is below the baseline of %.2f GB/s", gpus, productName, baseline)
+}
+
+func createRcclAllReduceJob(test Test, namespace string, config corev1.ConfigMap, gpus int64) *kftov1.PyTorchJob {
+	test.T().Helper()
+
+	job := &kftov1.PyTorchJob{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "PyTorchJob",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "kfto-rccl-",
+		},
+		Spec: kftov1.PyTorchJobSpec{
+			NprocPerNode: Ptr(fmt.Sprint(gpus)),
+			PyTorchReplicaSpecs: map[kftov1.ReplicaType]*kftov1.ReplicaSpec{
+				"Master": {
+					Replicas:      Ptr(int32(1)),
+					RestartPolicy: "Never",
+					Template: corev1.PodTemplateSpec{
+						Spec: corev1.PodSpec{
+							Containers: []corev1.Container{
+								{
+									Name:            "pytorch",
+									Image:           GetRocmPyTorchImage(),
+									ImagePullPolicy: corev1.PullIfNotPresent,
+									Command:         []string{"torchrun", "/etc/config/rccl_all_reduce.py"},
+									VolumeMounts: []corev1.VolumeMount{
+										{
+											Name:      "config-volume",
+											MountPath: "/etc/config",
+										},
+										{
+											// RCCL uses shared memory for intra-node transport
+											Name:      "shm",
+											MountPath: "/dev/shm",
+										},
+									},
+									Resources: corev1.ResourceRequirements{
+										Requests: corev1.ResourceList{
+											corev1.ResourceCPU:    resource.MustParse("4"),
+											corev1.ResourceMemory: resource.MustParse("16Gi"),
+										},
+										Limits: corev1.ResourceList{
+											AmdGpuResource: *resource.NewQuantity(gpus, resource.DecimalSI),
+										},
+									},
+								},
+							},
+							Volumes: []corev1.Volume{
+								{
+									Name: "config-volume",
+									VolumeSource: corev1.VolumeSource{
+										ConfigMap: &corev1.ConfigMapVolumeSource{
+											LocalObjectReference: corev1.LocalObjectReference{
+												Name: config.Name,
+											},
+										},
+									},
+								},
+								{
+									Name: "shm",
+									VolumeSource: corev1.VolumeSource{
+										EmptyDir: &corev1.EmptyDirVolumeSource{
+											Medium:    corev1.StorageMediumMemory,
+											SizeLimit: Ptr(resource.MustParse("8Gi")),
+										},
+									},
+								},
+							},
+							Tolerations: []corev1.Toleration{
+								{
+									Key:      string(AmdGpuResource),
+									Operator: corev1.TolerationOpExists,
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	created, err := test.Client().Kubeflow().KubeflowV1().PyTorchJobs(namespace).Create(test.Ctx(), job, metav1.CreateOptions{})
+	ExpectNoError(test, err, "creating", Ref("PyTorchJob", namespace, job.Name+job.GenerateName))
+	job = created
+	test.T().Logf("Created PytorchJob %s/%s successfully", job.Namespace, job.Name)
+
+	return job
+}
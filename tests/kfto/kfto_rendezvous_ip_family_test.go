@@ -0,0 +1,106 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kfto
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/opendatahub-io/distributed-workloads/pkg/examples"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+const rendezvousWorkers = 1
+
+// rendezvousRecord is the line logged by rendezvous_ip_family.py with the addresses of the master the rank resolved.
+type rendezvousRecord struct {
+	Rank            int      `json:"rank"`
+	MasterAddr      string   `json:"masterAddr"`
+	MasterAddresses []string `json:"masterAddresses"`
+}
+
+// TestPytorchjobRendezvousIPFamily checks the PyTorch distributed rendezvous and collectives work on IPv6-only and
+// dual-stack clusters, as configured with TEST_IP_FAMILY.
+func TestPytorchjobRendezvousIPFamily(t *testing.T) {
+	Track(t)
+	test := MustGather(WithHooks(t))
+
+	mode := GetIPFamilyMode()
+	if mode == IPFamilyModeIPv4 {
+		test.T().Skip("IPv4-only cluster, set TEST_IP_FAMILY to IPv6 or DualStack to run the IP family tests")
+	}
+
+	// Create a namespace
+	namespace := test.NewTestNamespace()
+
+	// Create a ConfigMap with the rendezvous script
+	config := CreateConfigMap(test, namespace.Name, map[string][]byte{
+		"rendezvous_ip_family.py": ReadFile(test, "rendezvous_ip_family.py"),
+	})
+
+	// Run PyTorch job rendezvousing at the master service and all-reducing across the ranks
+	job := examples.PyTorchJob(examples.PyTorchJobOptions{
+		GenerateName:     "kfto-rendezvous-",
+		Namespace:        namespace.Name,
+		Image:            GetFmsHfTuningImage(),
+		Command:          []string{"python", examples.PyTorchJobScriptsMountPath + "/rendezvous_ip_family.py"},
+		Workers:          rendezvousWorkers,
+		CPU:              "250m",
+		Memory:           "1Gi",
+		ScriptsConfigMap: config.Name,
+		CrashCapture:     IsCrashCapture(),
+	})
+	result := SubmitAndWait(test, job, SubmitOptions{})
+	test.Expect(result.Succeeded).To(BeTrue(), result.String())
+
+	// Make sure the pods got addresses of the configured IP families
+	test.Expect(result.Pods).To(HaveLen(rendezvousWorkers + 1))
+	var records []rendezvousRecord
+	for _, summary := range result.Pods {
+		pod := GetPod(test, namespace.Name, summary.Name)
+		ExpectPodIPFamilies(test, *pod)
+
+		logs := string(GetPodLogs(test, pod, corev1.PodLogOptions{}))
+		for _, line := range strings.Split(logs, "\n") {
+			if payload, ok := strings.CutPrefix(line, "RENDEZVOUS "); ok {
+				record := rendezvousRecord{}
+				test.Expect(json.Unmarshal([]byte(payload), &record)).To(Succeed())
+				test.T().Logf("Rank %d resolved master %s to %v", record.Rank, record.MasterAddr, record.MasterAddresses)
+				records = append(records, record)
+			}
+		}
+	}
+	test.Expect(records).To(HaveLen(rendezvousWorkers + 1))
+
+	// Make sure the rendezvous went over IPv6 on IPv6-only clusters, the master may address itself as localhost
+	if mode == IPFamilyModeIPv6 {
+		for _, record := range records {
+			if record.Rank == 0 {
+				continue
+			}
+			test.Expect(record.MasterAddresses).NotTo(BeEmpty())
+			for _, address := range record.MasterAddresses {
+				test.Expect(IPFamilyOf(address)).To(Equal(corev1.IPv6Protocol), "Rank %d resolved master %s to %s", record.Rank, record.MasterAddr, address)
+			}
+		}
+	}
+}
@@ -0,0 +1,64 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kfto
+
+import (
+	"testing"
+
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+)
+
+// TestTrainingRuntimeImageTools makes sure the entrypoints and tools used by the training tests are present
+// in the training runtime image, so image build regressions are caught before running distributed training.
+func TestTrainingRuntimeImageTools(t *testing.T) {
+	Track(t, LabelTier1)
+	test := MustGather(WithHooks(t))
+
+	// Create a namespace
+	namespace := test.NewTestNamespace()
+
+	ExpectImageTools(test, namespace.Name, GetFmsHfTuningImage(),
+		ImageTool{
+			Name:    "torchrun",
+			Command: `torchrun --help > /dev/null && python -c "import torch; print(torch.__version__)"`,
+			Version: SemanticVersion,
+		},
+		ImageTool{
+			Name:    "accelerate",
+			Command: `accelerate --help > /dev/null && python -c "import accelerate; print(accelerate.__version__)"`,
+			Version: SemanticVersion,
+		},
+		ImageTool{
+			Name:    "deepspeed",
+			Command: `python -c "import deepspeed; print(deepspeed.__version__)"`,
+			Version: SemanticVersion,
+		},
+		ImageTool{
+			Name:    "cryptography",
+			Command: `python -c "import cryptography; print(cryptography.__version__)"`,
+			Version: SemanticVersion,
+		},
+		ImageTool{
+			Name:    "sft_trainer",
+			Command: `python -c "import importlib.metadata, tuning.sft_trainer; print(importlib.metadata.version('fms-hf-tuning'))"`,
+			Version: SemanticVersion,
+		},
+	)
+
+	// Make sure the versions of the packages in the image are compatible with each other
+	ExpectImageCompatibility(test, namespace.Name, GetFmsHfTuningImage())
+}
@@ -0,0 +1,60 @@
+import gzip
+import os
+import urllib.request
+
+import numpy as np
+import torch
+import torch.distributed as dist
+from torch import nn
+from torch.nn.parallel import DistributedDataParallel
+from torch.utils.data import DataLoader, TensorDataset
+from torch.utils.data.distributed import DistributedSampler
+
+mirror = os.environ.get("MNIST_MIRROR", "https://ossci-datasets.s3.amazonaws.com/mnist/")
+epochs = int(os.environ.get("EPOCHS", "1"))
+min_accuracy = float(os.environ.get("MIN_ACCURACY", "0.9"))
+
+
+def load(images_file, labels_file):
+    # The MNIST IDX files have a 16 bytes header for images and 8 bytes header for labels
+    with urllib.request.urlopen(mirror + images_file) as response:
+        images = np.frombuffer(gzip.decompress(response.read()), dtype=np.uint8, offset=16)
+    with urllib.request.urlopen(mirror + labels_file) as response:
+        labels = np.frombuffer(gzip.decompress(response.read()), dtype=np.uint8, offset=8)
+    images = (torch.tensor(images.reshape(-1, 28 * 28), dtype=torch.float32) / 255 - 0.1307) / 0.3081
+    return TensorDataset(images, torch.tensor(labels, dtype=torch.long))
+
+
+dist.init_process_group("gloo")
+rank = dist.get_rank()
+world_size = dist.get_world_size()
+print(f"torch {torch.__version__}, rank {rank} of {world_size}", flush=True)
+
+train_data = load("train-images-idx3-ubyte.gz", "train-labels-idx1-ubyte.gz")
+test_data = load("t10k-images-idx3-ubyte.gz", "t10k-labels-idx1-ubyte.gz")
+
+torch.manual_seed(42)
+model = DistributedDataParallel(nn.Sequential(nn.Linear(28 * 28, 128), nn.ReLU(), nn.Linear(128, 10)))
+optimizer = torch.optim.Adam(model.parameters(), lr=1e-3)
+loss_fn = nn.CrossEntropyLoss()
+
+sampler = DistributedSampler(train_data, shuffle=True, seed=42)
+for epoch in range(epochs):
+    sampler.set_epoch(epoch)
+    model.train()
+    for images, labels in DataLoader(train_data, batch_size=64, sampler=sampler):
+        optimizer.zero_grad()
+        loss = loss_fn(model(images), labels)
+        loss.backward()
+        optimizer.step()
+    print(f"rank {rank} epoch {epoch} loss: {loss.item():.4f}", flush=True)
+
+# Every rank evaluates the same replicated model on the whole test set
+model.eval()
+with torch.no_grad():
+    images, labels = test_data.tensors
+    accuracy = (model(images).argmax(dim=1) == labels).float().mean().item()
+print(f"MNIST rank={rank} world_size={world_size} accuracy={accuracy:.4f}", flush=True)
+assert accuracy >= min_accuracy, f"MNIST accuracy {accuracy:.4f} below {min_accuracy}"
+
+dist.destroy_process_group()
@@ -0,0 +1,42 @@
+# All-reduce benchmark across the local GPUs, on ROCm the PyTorch nccl backend runs on RCCL.
+# Reports the bus bandwidth computed the same way as rccl-tests all_reduce_perf.
+
+import os
+import time
+
+import torch
+import torch.distributed as dist
+
+message_size = int(os.environ.get("MESSAGE_SIZE", 256 * 1024 * 1024))
+iterations = int(os.environ.get("ITERATIONS", 20))
+
+dist.init_process_group("nccl")
+rank = dist.get_rank()
+world_size = dist.get_world_size()
+torch.cuda.set_device(int(os.environ["LOCAL_RANK"]))
+
+if rank == 0:
+    print(f"HIP version {torch.version.hip}, RCCL version {torch.cuda.nccl.version()}", flush=True)
+
+# Check the all-reduce result before measuring
+tensor = torch.ones(message_size // 4, dtype=torch.float32, device="cuda")
+dist.all_reduce(tensor)
+torch.cuda.synchronize()
+if not torch.all(tensor == world_size):
+    raise RuntimeError(f"Rank {rank} got incorrect all-reduce result")
+
+for _ in range(5):
+    dist.all_reduce(tensor)
+torch.cuda.synchronize()
+
+start = time.perf_counter()
+for _ in range(iterations):
+    dist.all_reduce(tensor)
+torch.cuda.synchronize()
+elapsed = (time.perf_counter() - start) / iterations
+
+bus_bandwidth = message_size / elapsed * 2 * (world_size - 1) / world_size / 1e9
+if rank == 0:
+    print(f"BUS_BANDWIDTH {bus_bandwidth:.2f}", flush=True)
+
+dist.destroy_process_group()
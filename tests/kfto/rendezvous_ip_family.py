@@ -0,0 +1,23 @@
+import json
+import os
+import socket
+
+import torch
+import torch.distributed as dist
+
+# Resolve the master service the same way the rendezvous does, the addresses are of the cluster service network IP families
+master_addr = os.environ["MASTER_ADDR"]
+master_port = int(os.environ["MASTER_PORT"])
+addresses = sorted({info[4][0] for info in socket.getaddrinfo(master_addr, master_port, proto=socket.IPPROTO_TCP)})
+
+dist.init_process_group("gloo")
+rank = dist.get_rank()
+world_size = dist.get_world_size()
+
+# Make sure the collectives work over the connections established by the rendezvous
+tensor = torch.tensor([rank + 1])
+dist.all_reduce(tensor)
+assert tensor.item() == world_size * (world_size + 1) // 2, f"Unexpected all-reduce result {tensor.item()}"
+
+print("RENDEZVOUS " + json.dumps({"rank": rank, "masterAddr": master_addr, "masterAddresses": addresses}), flush=True)
+dist.destroy_process_group()
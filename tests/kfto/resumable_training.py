@@ -0,0 +1,35 @@
+import os
+import time
+
+import torch
+
+checkpoint_path = os.path.join(os.environ.get("CHECKPOINT_DIR", "/tmp/out"), "checkpoint.pt")
+total_steps = int(os.environ.get("TOTAL_STEPS", "120"))
+step_duration = float(os.environ.get("STEP_DURATION", "2"))
+
+torch.manual_seed(0)
+model = torch.nn.Linear(16, 1)
+optimizer = torch.optim.SGD(model.parameters(), lr=0.01)
+start_step = 0
+
+if os.path.exists(checkpoint_path):
+    checkpoint = torch.load(checkpoint_path)
+    model.load_state_dict(checkpoint["model"])
+    optimizer.load_state_dict(checkpoint["optimizer"])
+    start_step = checkpoint["step"]
+    print(f"Resumed from step {start_step}", flush=True)
+
+for step in range(start_step + 1, total_steps + 1):
+    inputs = torch.randn(32, 16)
+    loss = (model(inputs) - inputs.sum(dim=1, keepdim=True)).pow(2).mean()
+    optimizer.zero_grad()
+    loss.backward()
+    optimizer.step()
+    time.sleep(step_duration)
+
+    # Write the checkpoint atomically, so it isn't corrupted when the pod is terminated
+    torch.save({"model": model.state_dict(), "optimizer": optimizer.state_dict(), "step": step}, checkpoint_path + ".tmp")
+    os.replace(checkpoint_path + ".tmp", checkpoint_path)
+    print(f"Step {step} loss {loss.item():.4f}", flush=True)
+
+print("Training completed", flush=True)
@@ -23,7 +23,7 @@ import (
 	"github.com/project-codeflare/codeflare-common/support"
 )
 
-//go:embed *.json
+//go:embed *.json *.py
 var files embed.FS
 
 func ReadFile(t support.Test, fileName string) []byte {
@@ -0,0 +1,43 @@
+import os
+import time
+
+import torch
+
+steps = int(os.environ.get("STEPS", "200"))
+# Minimum duration of the training in seconds, so the utilization of the GPU is sampled by the metrics exporter
+min_seconds = float(os.environ.get("MIN_SECONDS", "0"))
+hidden = int(os.environ.get("HIDDEN", "256"))
+batch = int(os.environ.get("BATCH", "128"))
+
+# The device the training runs on, cuda for both CUDA and ROCm GPUs, or cpu for the tests of the scheduling of workloads
+device = torch.device(os.environ.get("DEVICE", "cuda"))
+if device.type == "cuda":
+    if not torch.cuda.is_available():
+        raise SystemExit("No CUDA or ROCm GPU available to PyTorch")
+    print(f"Training on {torch.cuda.get_device_name(device)}", flush=True)
+else:
+    print(f"Training on {device}", flush=True)
+
+torch.manual_seed(0)
+model = torch.nn.Sequential(torch.nn.Linear(64, hidden), torch.nn.ReLU(), torch.nn.Linear(hidden, 1)).to(device)
+optimizer = torch.optim.Adam(model.parameters(), lr=0.001)
+
+first_loss = None
+start = time.monotonic()
+step = 0
+while step < steps or time.monotonic() - start < min_seconds:
+    step += 1
+    inputs = torch.randn(batch, 64, device=device)
+    loss = (model(inputs) - inputs.sum(dim=1, keepdim=True)).pow(2).mean()
+    optimizer.zero_grad()
+    loss.backward()
+    optimizer.step()
+    if first_loss is None:
+        first_loss = loss.item()
+    # Printing the loss synchronizes with the GPU, so the duration isn't measured while the steps are only queued
+    if step % 50 == 0:
+        print(f"Step {step}, loss {loss.item():.4f}", flush=True)
+
+if loss.item() >= first_loss:
+    raise SystemExit(f"Loss didn't decrease, from {first_loss:.4f} to {loss.item():.4f}")
+print("Training completed", flush=True)
@@ -0,0 +1,144 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kueue
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	. "github.com/project-codeflare/codeflare-common/support"
+	kueuev1beta1 "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// The admission window is defined in the timezone furthest ahead of UTC, so its day differs from the day in UTC
+	// for a large part of the day, as it does for the users of the clusters in other timezones
+	admissionWindowTimezone = "Pacific/Kiritimati"
+	admissionWindowDuration = 2 * time.Minute
+)
+
+// TestKueueTimeWindowedAdmission holds the ClusterQueue outside a daily admission window, as done to reserve
+// the GPUs for off-peak training, and makes sure the workloads queued before the window opens are admitted
+// once it opens, keep running once it closes, and the workloads queued after it closes wait for the next one.
+func TestKueueTimeWindowedAdmission(t *testing.T) {
+	Track(t, LabelKueue)
+	test := MustGather(WithHooks(t))
+
+	// Create a namespace
+	namespace := test.NewTestNamespace()
+
+	// Create Kueue resources
+	queues := CreateKueueQueues(test, namespace.Name, kueuev1beta1.ResourceFlavorSpec{}, corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("1"),
+		corev1.ResourceMemory: resource.MustParse("1Gi"),
+	})
+
+	// Schedule the daily admission window to open in two minutes on the wall clock of its timezone
+	location, err := time.LoadLocation(admissionWindowTimezone)
+	ExpectNoError(test, err, "loading", Ref("timezone", "", admissionWindowTimezone))
+	opening := time.Now().In(location).Truncate(time.Minute).Add(2 * time.Minute)
+	midnight := time.Date(opening.Year(), opening.Month(), opening.Day(), 0, 0, 0, 0, location)
+	schedule := ScheduleKueueAdmissionWindow(test, queues.ClusterQueue.Name, DailyAdmissionWindow{
+		Start:    opening.Sub(midnight),
+		Duration: admissionWindowDuration,
+		Location: location,
+	})
+	opens, closes := schedule.Window.Next(time.Now())
+	test.Expect(opens).To(BeTemporally("==", opening))
+
+	// Submit a Job running past the end of the window, and make sure it isn't admitted before the window opens
+	offPeak := createWindowedJob(test, namespace.Name, queues.LocalQueue.Name, "off-peak-", admissionWindowDuration+30*time.Second)
+	EventuallyOf(test, KueueWorkloadOwnedBy(test, namespace.Name, offPeak), TestTimeoutShort).
+		Should(Field(KueueWorkloadPending).Equal(true))
+	ConsistentlyOf(test, KueueWorkloadOwnedBy(test, namespace.Name, offPeak), time.Until(opens)-5*time.Second).
+		Should(Field(KueueWorkloadQuotaReserved).Equal(false))
+
+	// Make sure the Job is admitted once the window opens
+	EventuallyOf(test, KueueWorkloadOwnedBy(test, namespace.Name, offPeak), time.Until(opens)+TestTimeoutShort).
+		Should(Field(KueueWorkloadAdmitted).Equal(true))
+	admissionTime := KueueWorkloadAdmissionTime(GetKueueWorkloadOwnedBy(test, namespace.Name, offPeak))
+	test.Expect(admissionTime).NotTo(BeNil())
+	// The conditions have a second precision
+	test.Expect(admissionTime.Time).To(BeTemporally(">=", opens.Truncate(time.Second)), "Job admitted before the window opened at %s", opens)
+	test.Expect(schedule.Opened()).To(HaveLen(1))
+
+	// Make sure the Job keeps running once the window closes
+	test.Eventually(func() []time.Time { return schedule.Closed() }, time.Until(closes)+TestTimeoutShort).Should(HaveLen(1))
+	ExpectOf(test, GetKueueWorkloadOwnedBy(test, namespace.Name, offPeak)).
+		To(Field(KueueWorkloadEvicted).Equal(false))
+
+	// Make sure a Job submitted after the window closes isn't admitted until the next window
+	late := createWindowedJob(test, namespace.Name, queues.LocalQueue.Name, "late-", 0)
+	EventuallyOf(test, KueueWorkloadOwnedBy(test, namespace.Name, late), TestTimeoutShort).
+		Should(Field(KueueWorkloadPending).Equal(true))
+	ConsistentlyOf(test, KueueWorkloadOwnedBy(test, namespace.Name, late), TestTimeoutShort).
+		Should(Field(KueueWorkloadQuotaReserved).Equal(false))
+
+	// Make sure the Job admitted within the window completes
+	EventuallyOf(test, Job(test, namespace.Name, offPeak.Name), TestTimeoutMedium).
+		Should(Field(JobConditionStatus(batchv1.JobComplete)).Equal(corev1.ConditionTrue))
+	ExpectOf(test, GetKueueWorkloadOwnedBy(test, namespace.Name, late)).
+		To(Field(KueueWorkloadQuotaReserved).Equal(false))
+}
+
+func createWindowedJob(test Test, namespace, localQueueName, generateName string, duration time.Duration) *batchv1.Job {
+	test.T().Helper()
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: generateName,
+			Labels: map[string]string{
+				"kueue.x-k8s.io/queue-name": localQueueName,
+			},
+		},
+		Spec: batchv1.JobSpec{
+			Suspend:      Ptr(true),
+			BackoffLimit: Ptr(int32(0)),
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers: []corev1.Container{
+						{
+							Name:    "workload",
+							Image:   GetToolsImage(),
+							Command: []string{"sh", "-c", fmt.Sprintf("sleep %d", int(duration.Seconds()))},
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									corev1.ResourceCPU:    resource.MustParse("100m"),
+									corev1.ResourceMemory: resource.MustParse("128Mi"),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	job, err := test.Client().Core().BatchV1().Jobs(namespace).Create(test.Ctx(), job, metav1.CreateOptions{})
+	ExpectNoError(test, err, "creating", Ref("Job", namespace, generateName))
+	test.T().Logf("Created Job %s/%s successfully", job.Namespace, job.Name)
+
+	return job
+}
@@ -0,0 +1,143 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kueue
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	. "github.com/project-codeflare/codeflare-common/support"
+	kueuev1beta1 "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestGitOpsOwnedQueueConfiguration manages the ResourceFlavor and ClusterQueue with a simulated ArgoCD Application,
+// and makes sure neither Kueue, reconciling them while admitting a workload, nor the temporary patches the tests apply
+// to them, change the fields owned by the Application, so the GitOps controller doesn't fight them.
+func TestGitOpsOwnedQueueConfiguration(t *testing.T) {
+	Track(t, LabelKueue)
+	test := MustGather(WithHooks(t))
+
+	// Create a namespace
+	namespace := test.NewTestNamespace()
+
+	// Manage the Kueue resources with a GitOps Application
+	resourceFlavor := &kueuev1beta1.ResourceFlavor{
+		TypeMeta:   metav1.TypeMeta{APIVersion: kueuev1beta1.SchemeGroupVersion.String(), Kind: "ResourceFlavor"},
+		ObjectMeta: metav1.ObjectMeta{Name: "gitops-" + namespace.Name},
+	}
+	clusterQueue := &kueuev1beta1.ClusterQueue{
+		TypeMeta:   metav1.TypeMeta{APIVersion: kueuev1beta1.SchemeGroupVersion.String(), Kind: "ClusterQueue"},
+		ObjectMeta: metav1.ObjectMeta{Name: "gitops-" + namespace.Name},
+		Spec: kueuev1beta1.ClusterQueueSpec{
+			NamespaceSelector: &metav1.LabelSelector{},
+			ResourceGroups: []kueuev1beta1.ResourceGroup{KueueResourceGroup(resourceFlavor.Name, corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("1"),
+				corev1.ResourceMemory: resource.MustParse("1Gi"),
+			})},
+		},
+	}
+	application := ApplyGitOpsApplication(test, "queues-"+namespace.Name,
+		GitOpsResource{Resource: kueuev1beta1.SchemeGroupVersion.WithResource("resourceflavors"), Object: resourceFlavor},
+		GitOpsResource{Resource: kueuev1beta1.SchemeGroupVersion.WithResource("clusterqueues"), Object: clusterQueue},
+	)
+	EventuallyOf(test, KueueClusterQueue(test, clusterQueue.Name), TestTimeoutShort).
+		Should(Field(KueueClusterQueueActive).Equal(true))
+	localQueue := CreateKueueLocalQueue(test, namespace.Name, clusterQueue.Name)
+
+	// Hold the ClusterQueue with a temporary patch, as the tests do, the stop policy isn't managed by the Application
+	ownedFields := application.ManagedFields(test)
+	test.Expect(ownedFields).To(HaveKey(Ref("ClusterQueue", "", clusterQueue.Name)))
+	var job *batchv1.Job
+	WithPatched(test, kueuev1beta1.SchemeGroupVersion.WithResource("clusterqueues"), clusterQueue.Name,
+		[]byte(fmt.Sprintf(`{"spec":{"stopPolicy":%q}}`, kueuev1beta1.Hold)), func() {
+			// Submit a Job, and make sure the hold isn't reverted by the GitOps controller while it is queued
+			job = createGitOpsJob(test, namespace.Name, localQueue.Name)
+			EventuallyOf(test, KueueWorkloadOwnedBy(test, namespace.Name, job), TestTimeoutShort).
+				Should(Field(KueueWorkloadPending).Equal(true))
+			ConsistentlyOf(test, KueueWorkloadOwnedBy(test, namespace.Name, job), 3*GitOpsApplyInterval).
+				Should(Field(KueueWorkloadQuotaReserved).Equal(false))
+		})
+
+	// Make sure the restore didn't take over the fields owned by the GitOps controller
+	test.Expect(application.ManagedFields(test)).To(Equal(ownedFields), "Fields owned by the GitOps Application taken over by the restore of the patch")
+
+	// Make sure the hold is released by the restore, and the Job is admitted and completes
+	EventuallyOf(test, KueueWorkloadOwnedBy(test, namespace.Name, job), TestTimeoutMedium).
+		Should(Field(KueueWorkloadAdmitted).Equal(true))
+	EventuallyOf(test, Job(test, namespace.Name, job.Name), TestTimeoutMedium).
+		Should(Field(JobConditionStatus(batchv1.JobComplete)).Equal(corev1.ConditionTrue))
+
+	// Give the GitOps controller the time to re-apply the resources once Kueue reconciled them
+	time.Sleep(2 * GitOpsApplyInterval)
+
+	// Make sure the GitOps controller didn't have to revert any change of the fields it owns
+	test.Expect(application.Drifts()).To(BeEmpty(), "Fields owned by the GitOps Application changed by another field manager")
+	live := KueueClusterQueue(test, clusterQueue.Name)(test)
+	test.Expect(live).To(And(
+		HaveLabel("app.kubernetes.io/instance", application.Name),
+		HaveAnnotation("argocd.argoproj.io/tracking-id", application.Name+":kueue.x-k8s.io/ClusterQueue:/"+clusterQueue.Name),
+	))
+	test.Expect(live.Spec.ResourceGroups).To(BeComparableTo(clusterQueue.Spec.ResourceGroups))
+}
+
+// createGitOpsJob creates a Job queued in the LocalQueue of the ClusterQueue owned by the GitOps Application.
+func createGitOpsJob(test Test, namespace, localQueue string) *batchv1.Job {
+	test.T().Helper()
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "gitops-",
+			Labels: map[string]string{
+				"kueue.x-k8s.io/queue-name": localQueue,
+			},
+		},
+		Spec: batchv1.JobSpec{
+			Suspend:      Ptr(true),
+			BackoffLimit: Ptr(int32(0)),
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers: []corev1.Container{
+						{
+							Name:    "workload",
+							Image:   GetToolsImage(),
+							Command: []string{"sh", "-c", "echo 'Admitted by the GitOps managed queue'"},
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									corev1.ResourceCPU:    resource.MustParse("100m"),
+									corev1.ResourceMemory: resource.MustParse("128Mi"),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	generateName := job.GenerateName
+	job, err := test.Client().Core().BatchV1().Jobs(namespace).Create(test.Ctx(), job, metav1.CreateOptions{})
+	ExpectNoError(test, err, "creating", Ref("Job", namespace, generateName))
+	test.T().Logf("Created Job %s/%s successfully", job.Namespace, job.Name)
+	return job
+}
@@ -0,0 +1,122 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kueue
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	. "github.com/project-codeflare/codeflare-common/support"
+	prometheusv1 "github.com/prometheus/client_golang/api/prometheus/v1"
+	kueuev1beta1 "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/opendatahub-io/distributed-workloads/pkg/examples"
+)
+
+// TestKueueGpuTraining submits a GPU training PyTorchJob through a LocalQueue, and makes sure its Workload gets
+// admitted by the ClusterQueue with GPU quota and the training completes, having exercised the GPU when the
+// accelerator metrics are available.
+func TestKueueGpuTraining(t *testing.T) {
+	Track(t, LabelKueue, LabelGpu)
+	test := MustGather(WithHooks(t))
+
+	accelerator := GetAccelerator(test)
+	test.T().Logf("Training on %s GPUs", accelerator)
+	AcquireGpuLease(test)
+
+	// Create a namespace
+	namespace := test.NewTestNamespace()
+
+	// Create a ConfigMap with the training script
+	config := CreateConfigMap(test, namespace.Name, map[string][]byte{
+		"gpu_training.py": ReadFile(test, "gpu_training.py"),
+	})
+
+	// Create Kueue resources with GPU quota, the flavor lets the admitted pods tolerate the GPU nodes taint
+	quota := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("2"),
+		corev1.ResourceMemory: resource.MustParse("8Gi"),
+	}
+	for name, quantity := range accelerator.Resources(1) {
+		quota[name] = quantity
+	}
+	queues := CreateKueueQueues(test, namespace.Name, kueuev1beta1.ResourceFlavorSpec{
+		Tolerations: accelerator.Tolerations(),
+	}, quota)
+
+	// The utilization of the GPU is asserted when Prometheus is available, the training then runs long enough
+	// for the exporter to sample it
+	var prometheus prometheusv1.API
+	var env []corev1.EnvVar
+	if PrometheusAvailable(test) {
+		prometheus = NewPrometheusClient(test, namespace.Name)
+		env = []corev1.EnvVar{
+			{Name: "MIN_SECONDS", Value: "120"},
+			{Name: "HIDDEN", Value: "8192"},
+			{Name: "BATCH", Value: "16384"},
+		}
+	}
+
+	// Create the training PyTorch job queued in the LocalQueue, the utilization of the GPU is asserted over its lifetime
+	training := StartStopwatch()
+	job := examples.PyTorchJob(examples.PyTorchJobOptions{
+		GenerateName:     "kueue-gpu-training-",
+		Namespace:        namespace.Name,
+		Image:            accelerator.PyTorchImage(GetTrainingImage()),
+		Command:          []string{"python", examples.PyTorchJobScriptsMountPath + "/gpu_training.py"},
+		Env:              env,
+		CPU:              "1",
+		Memory:           "4Gi",
+		LocalQueue:       queues.LocalQueue.Name,
+		ScriptsConfigMap: config.Name,
+	})
+	job.Spec.PyTorchReplicaSpecs["Master"].Template.Spec.Containers[0].Resources.Limits = accelerator.Resources(1)
+	job, err := test.Client().Kubeflow().KubeflowV1().PyTorchJobs(namespace.Name).Create(test.Ctx(), job, metav1.CreateOptions{})
+	ExpectNoError(test, err, "creating", Ref("PyTorchJob", namespace.Name, job.GenerateName))
+	test.T().Logf("Created PytorchJob %s/%s successfully", job.Namespace, job.Name)
+
+	// Make sure the Workload is admitted by the ClusterQueue
+	EventuallyOfWithPolling(test, KueueWorkloadOwnedBy(test, namespace.Name, job), TestTimeoutMedium, PollingStrategyFor("Workload")).
+		Should(Field(KueueWorkloadAdmitted).Equal(true))
+	ExpectOf(test, GetKueueWorkloadOwnedBy(test, namespace.Name, job)).
+		To(Field(KueueWorkloadClusterQueue).Equal(queues.ClusterQueue.Name))
+	EventuallyOf(test, KueueClusterQueue(test, queues.ClusterQueue.Name), TestTimeoutShort).
+		Should(Field(KueueClusterQueueReservingWorkloads).Equal(int32(1)))
+
+	// Make sure the training completes and the Workload finishes
+	EventuallyOfWithPolling(test, KueueWorkloadOwnedBy(test, namespace.Name, job), TestTimeoutLong, PollingStrategyFor("Workload")).
+		Should(Field(KueueWorkloadFinished).Equal(true))
+	pods := GetPods(test, namespace.Name, metav1.ListOptions{LabelSelector: "training.kubeflow.org/job-name=" + job.Name})
+	test.Expect(pods).To(HaveLen(1))
+	logs := string(GetPodLogs(test, &pods[0], corev1.PodLogOptions{}))
+	ExpectOf(test, &pods[0]).To(Field(PodPhase).Equal(corev1.PodSucceeded), "Training failed, logs:\n%s", logs)
+	test.Expect(logs).To(ContainSubstring("Training completed"))
+
+	// Make sure the GPU was actually exercised by the training
+	if prometheus != nil {
+		ExpectGPUUtilizationAbove(test, prometheus, namespace.Name, 50, training.Elapsed())
+	}
+
+	// Make sure the quota is released once the Workload finished
+	EventuallyOf(test, KueueClusterQueue(test, queues.ClusterQueue.Name), TestTimeoutShort).
+		Should(Field(KueueClusterQueueReservingWorkloads).Equal(int32(0)))
+}
@@ -0,0 +1,108 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kueue
+
+import (
+	"os"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	. "github.com/project-codeflare/codeflare-common/support"
+	kueuev1beta1 "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/opendatahub-io/distributed-workloads/pkg/examples"
+)
+
+// TestMultiKueuePyTorchJobDispatch registers a worker cluster with MultiKueue, and makes sure a PyTorchJob submitted
+// on the manager cluster, the current context, is dispatched to the worker cluster and runs there, its status being
+// reported back to the manager cluster. It requires Kueue with MultiKueue support of Kubeflow jobs on both clusters.
+func TestMultiKueuePyTorchJobDispatch(t *testing.T) {
+	Track(t, LabelKueue)
+	test := MustGather(WithHooks(t))
+
+	kubeconfigPath, ok := GetMultiKueueWorkerKubeconfig()
+	if !ok {
+		test.T().Skip("MULTIKUEUE_WORKER_KUBECONFIG isn't set")
+	}
+	kubeconfig, err := os.ReadFile(kubeconfigPath)
+	ExpectNoError(test, err, "reading kubeconfig of", Ref("Cluster", "", kubeconfigPath))
+	worker := ClusterTest(test, kubeconfig)
+
+	quota := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("2"),
+		corev1.ResourceMemory: resource.MustParse("4Gi"),
+	}
+
+	// Create a namespace, mirrored in the worker cluster
+	namespace := test.NewTestNamespace()
+	CreateTestNamespaceMirror(worker, namespace.Name)
+
+	// Create Kueue resources of the worker cluster
+	workerQueues := CreateKueueQueues(worker, namespace.Name, kueuev1beta1.ResourceFlavorSpec{}, quota)
+
+	// Create Kueue resources of the manager cluster, dispatching the workloads to the worker cluster
+	admissionCheck := CreateMultiKueueAdmissionCheck(test, kubeconfig)
+	resourceFlavor := CreateTrackedKueueResourceFlavor(test, kueuev1beta1.ResourceFlavorSpec{})
+	defer test.Client().Kueue().KueueV1beta1().ResourceFlavors().Delete(test.Ctx(), resourceFlavor.Name, metav1.DeleteOptions{})
+	clusterQueue := CreateTrackedKueueClusterQueue(test, kueuev1beta1.ClusterQueueSpec{
+		NamespaceSelector: &metav1.LabelSelector{},
+		ResourceGroups:    []kueuev1beta1.ResourceGroup{KueueResourceGroup(resourceFlavor.Name, quota)},
+		AdmissionChecks:   []string{admissionCheck.Name},
+	})
+	defer test.Client().Kueue().KueueV1beta1().ClusterQueues().Delete(test.Ctx(), clusterQueue.Name, metav1.DeleteOptions{})
+	// MultiKueue queues the dispatched workloads in the LocalQueue with the same name in the worker cluster
+	localQueue := CreateKueueLocalQueueWithName(test, namespace.Name, workerQueues.LocalQueue.Name, clusterQueue.Name)
+
+	// Submit the PyTorch job on the manager cluster
+	job := examples.PyTorchJob(examples.PyTorchJobOptions{
+		Name:       "multikueue-dispatch",
+		Namespace:  namespace.Name,
+		Image:      GetTrainingImage(),
+		Command:    []string{"python", "-c", "import torch.distributed as dist; dist.init_process_group('gloo'); print(f'Rank {dist.get_rank()} of {dist.get_world_size()}')"},
+		Workers:    1,
+		CPU:        "250m",
+		Memory:     "512Mi",
+		LocalQueue: localQueue.Name,
+	})
+	created, err := test.Client().Kubeflow().KubeflowV1().PyTorchJobs(namespace.Name).Create(test.Ctx(), job, metav1.CreateOptions{})
+	ExpectNoError(test, err, "creating", Ref("PyTorchJob", namespace.Name, job.Name+job.GenerateName))
+	job = created
+	test.T().Logf("Created PytorchJob %s/%s successfully", job.Namespace, job.Name)
+
+	// Make sure the Workload is admitted once dispatched to the worker cluster
+	EventuallyOfWithPolling(test, KueueWorkloadOwnedBy(test, namespace.Name, job), TestTimeoutMedium, PollingStrategyFor("Workload")).
+		Should(Field(KueueWorkloadAdmissionCheckState(admissionCheck.Name)).Equal(kueuev1beta1.CheckStateReady).
+			And(Field(KueueWorkloadAdmitted).Equal(true)))
+
+	// Make sure the PyTorch job runs and succeeds in the worker cluster
+	EventuallyOfWithPolling(worker, pytorchJob(worker, namespace.Name, job.Name), TestTimeoutLong, PollingStrategyFor("PyTorchJob")).
+		Should(Field(pytorchJobSucceeded).Equal(true))
+	workerPods := GetPods(worker, namespace.Name, metav1.ListOptions{LabelSelector: "training.kubeflow.org/job-name=" + job.Name})
+	test.Expect(workerPods).To(HaveLen(2))
+
+	// Make sure the status is reported back to the manager cluster, where no pod is created
+	EventuallyOf(test, pytorchJob(test, namespace.Name, job.Name), TestTimeoutShort).
+		Should(Field(pytorchJobSucceeded).Equal(true))
+	EventuallyOf(test, KueueWorkloadOwnedBy(test, namespace.Name, job), TestTimeoutShort).
+		Should(Field(KueueWorkloadFinished).Equal(true))
+	test.Expect(GetPods(test, namespace.Name, metav1.ListOptions{LabelSelector: "training.kubeflow.org/job-name=" + job.Name})).To(BeEmpty())
+}
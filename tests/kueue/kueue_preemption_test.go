@@ -0,0 +1,132 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kueue
+
+import (
+	"testing"
+
+	kftov1 "github.com/kubeflow/training-operator/pkg/apis/kubeflow.org/v1"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	. "github.com/project-codeflare/codeflare-common/support"
+	kueuev1beta1 "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+	"github.com/opendatahub-io/distributed-workloads/pkg/examples"
+)
+
+// TestKueuePriorityPreemption fills the ClusterQueue with a low priority training PyTorchJob, then submits a high
+// priority one, and makes sure the low priority Workload is preempted for the high priority one to run, and is
+// re-admitted and trains to completion once the high priority one finishes.
+func TestKueuePriorityPreemption(t *testing.T) {
+	Track(t, LabelKueue)
+	test := MustGather(WithHooks(t))
+
+	// Create a namespace
+	namespace := test.NewTestNamespace()
+
+	// Create a ConfigMap with the training script
+	config := CreateConfigMap(test, namespace.Name, map[string][]byte{
+		"gpu_training.py": ReadFile(test, "gpu_training.py"),
+	})
+
+	// Create the low and high WorkloadPriorityClasses
+	lowPriority := CreateTrackedKueueWorkloadPriorityClass(test, 100)
+	defer test.Client().Kueue().KueueV1beta1().WorkloadPriorityClasses().Delete(test.Ctx(), lowPriority.Name, metav1.DeleteOptions{})
+	highPriority := CreateTrackedKueueWorkloadPriorityClass(test, 1000)
+	defer test.Client().Kueue().KueueV1beta1().WorkloadPriorityClasses().Delete(test.Ctx(), highPriority.Name, metav1.DeleteOptions{})
+
+	// Create Kueue resources with the quota of a single training, preempting the lower priority Workloads
+	resourceFlavor := CreateTrackedKueueResourceFlavor(test, kueuev1beta1.ResourceFlavorSpec{})
+	defer test.Client().Kueue().KueueV1beta1().ResourceFlavors().Delete(test.Ctx(), resourceFlavor.Name, metav1.DeleteOptions{})
+	clusterQueue := CreateTrackedKueueClusterQueue(test, kueuev1beta1.ClusterQueueSpec{
+		NamespaceSelector: &metav1.LabelSelector{},
+		ResourceGroups: []kueuev1beta1.ResourceGroup{KueueResourceGroup(resourceFlavor.Name, corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("2"),
+			corev1.ResourceMemory: resource.MustParse("4Gi"),
+		})},
+		Preemption: &kueuev1beta1.ClusterQueuePreemption{
+			WithinClusterQueue: kueuev1beta1.PreemptionPolicyLowerPriority,
+		},
+	})
+	defer test.Client().Kueue().KueueV1beta1().ClusterQueues().Delete(test.Ctx(), clusterQueue.Name, metav1.DeleteOptions{})
+	localQueue := CreateKueueLocalQueue(test, namespace.Name, clusterQueue.Name)
+
+	// Submit the low priority training, running long enough to be preempted, and make sure it fills the ClusterQueue
+	lowJob := createPriorityTrainingJob(test, namespace.Name, localQueue.Name, lowPriority.Name, config.Name, "low-priority-", "90")
+	EventuallyOfWithPolling(test, KueueWorkloadOwnedBy(test, namespace.Name, lowJob), TestTimeoutMedium, PollingStrategyFor("Workload")).
+		Should(Field(KueueWorkloadAdmitted).Equal(true))
+	ExpectOf(test, GetKueueWorkloadOwnedBy(test, namespace.Name, lowJob)).
+		To(Field(KueueWorkloadPriority).Equal(lowPriority.Value))
+	EventuallyOf(test, KueueClusterQueue(test, clusterQueue.Name), TestTimeoutShort).
+		Should(Field(KueueClusterQueueReservingWorkloads).Equal(int32(1)))
+
+	// Submit the high priority training
+	highJob := createPriorityTrainingJob(test, namespace.Name, localQueue.Name, highPriority.Name, config.Name, "high-priority-", "0")
+
+	// Make sure the low priority Workload is preempted and its PyTorchJob suspended
+	EventuallyOf(test, KueueWorkloadOwnedBy(test, namespace.Name, lowJob), TestTimeoutShort).
+		Should(Field(KueueWorkloadConditionReason(kueuev1beta1.WorkloadEvicted)).Equal(kueuev1beta1.WorkloadEvictedByPreemption).And(
+			Field(KueueWorkloadQuotaReserved).Equal(false),
+		))
+	EventuallyOf(test, pytorchJob(test, namespace.Name, lowJob.Name), TestTimeoutShort).
+		Should(Field(pytorchJobSuspended).Equal(true))
+
+	// Make sure the high priority Workload is admitted in place of the low priority one, and trains to completion
+	EventuallyOf(test, KueueWorkloadOwnedBy(test, namespace.Name, highJob), TestTimeoutShort).
+		Should(Field(KueueWorkloadAdmitted).Equal(true).And(
+			Field(KueueWorkloadPriority).Equal(highPriority.Value),
+		))
+	EventuallyOfWithPolling(test, pytorchJob(test, namespace.Name, highJob.Name), TestTimeoutLong, PollingStrategyFor("PyTorchJob")).
+		Should(Field(pytorchJobSucceeded).Equal(true))
+
+	// Make sure the low priority Workload is re-admitted once the quota is released, and trains to completion
+	EventuallyOf(test, KueueWorkloadOwnedBy(test, namespace.Name, lowJob), TestTimeoutMedium).
+		Should(Field(KueueWorkloadAdmitted).Equal(true).And(
+			Field(KueueWorkloadConditionStatus(kueuev1beta1.WorkloadEvicted)).Equal(metav1.ConditionFalse),
+		))
+	EventuallyOfWithPolling(test, pytorchJob(test, namespace.Name, lowJob.Name), TestTimeoutLong, PollingStrategyFor("PyTorchJob")).
+		Should(Field(pytorchJobSucceeded).Equal(true))
+	test.T().Logf("Low priority PyTorchJob %s/%s trained to completion once re-admitted", lowJob.Namespace, lowJob.Name)
+}
+
+// createPriorityTrainingJob creates a CPU training PyTorchJob queued with the priority class, training for at least
+// the seconds, which takes all the quota of the ClusterQueue.
+func createPriorityTrainingJob(test Test, namespace, localQueue, priorityClass, configMap, generateName, minSeconds string) *kftov1.PyTorchJob {
+	test.T().Helper()
+	job := examples.PyTorchJob(examples.PyTorchJobOptions{
+		GenerateName: generateName,
+		Namespace:    namespace,
+		Image:        GetTrainingImage(),
+		Command:      []string{"python", examples.PyTorchJobScriptsMountPath + "/gpu_training.py"},
+		Env: []corev1.EnvVar{
+			{Name: "DEVICE", Value: "cpu"},
+			{Name: "MIN_SECONDS", Value: minSeconds},
+		},
+		CPU:              "2",
+		Memory:           "4Gi",
+		LocalQueue:       localQueue,
+		PriorityClass:    priorityClass,
+		ScriptsConfigMap: configMap,
+	})
+	job, err := test.Client().Kubeflow().KubeflowV1().PyTorchJobs(namespace).Create(test.Ctx(), job, metav1.CreateOptions{})
+	ExpectNoError(test, err, "creating", Ref("PyTorchJob", namespace, generateName))
+	test.T().Logf("Created PytorchJob %s/%s with priority class %s successfully", job.Namespace, job.Name, priorityClass)
+	return job
+}
@@ -0,0 +1,60 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package kueue
+
+import (
+	"embed"
+
+	kftov1 "github.com/kubeflow/training-operator/pkg/apis/kubeflow.org/v1"
+	"github.com/onsi/gomega"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+//go:embed *.py
+var files embed.FS
+
+func ReadFile(t Test, fileName string) []byte {
+	t.T().Helper()
+	file, err := files.ReadFile(fileName)
+	t.Expect(err).NotTo(gomega.HaveOccurred())
+	return file
+}
+
+func pytorchJob(t Test, namespace, name string) func(g gomega.Gomega) *kftov1.PyTorchJob {
+	return func(g gomega.Gomega) *kftov1.PyTorchJob {
+		job, err := t.Client().Kubeflow().KubeflowV1().PyTorchJobs(namespace).Get(t.Ctx(), name, metav1.GetOptions{})
+		g.Expect(WrapError(err, "getting", Ref("PyTorchJob", namespace, name))).NotTo(gomega.HaveOccurred())
+		return job
+	}
+}
+
+func pytorchJobSucceeded(job *kftov1.PyTorchJob) bool {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == kftov1.JobSucceeded {
+			return condition.Status == corev1.ConditionTrue
+		}
+	}
+	return false
+}
+
+func pytorchJobSuspended(job *kftov1.PyTorchJob) bool {
+	return job.Spec.RunPolicy.Suspend != nil && *job.Spec.RunPolicy.Suspend
+}
@@ -0,0 +1,144 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package odh
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	. "github.com/project-codeflare/codeflare-common/support"
+	kueuev1beta1 "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestDistributedWorkloadAlerts checks the platform alerting rules fire in Alertmanager for workloads
+// that fail, or that are blocked from admission by Kueue.
+func TestDistributedWorkloadAlerts(t *testing.T) {
+	Track(t, LabelKueue, LabelLong)
+	test := MustGather(WithHooks(t))
+
+	// Create a namespace
+	namespace := test.NewTestNamespace()
+
+	prometheus := NewPrometheusClient(test, namespace.Name)
+	alertmanager := NewAlertmanagerClient(test, namespace.Name)
+
+	// Create Kueue resources with quota fitting the failing workload only
+	resourceFlavor := CreateTrackedKueueResourceFlavor(test, kueuev1beta1.ResourceFlavorSpec{})
+	defer test.Client().Kueue().KueueV1beta1().ResourceFlavors().Delete(test.Ctx(), resourceFlavor.Name, metav1.DeleteOptions{})
+	cqSpec := kueuev1beta1.ClusterQueueSpec{
+		NamespaceSelector: &metav1.LabelSelector{},
+		ResourceGroups: []kueuev1beta1.ResourceGroup{
+			{
+				CoveredResources: []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory},
+				Flavors: []kueuev1beta1.FlavorQuotas{
+					{
+						Name: kueuev1beta1.ResourceFlavorReference(resourceFlavor.Name),
+						Resources: []kueuev1beta1.ResourceQuota{
+							{
+								Name:         corev1.ResourceCPU,
+								NominalQuota: resource.MustParse("1"),
+							},
+							{
+								Name:         corev1.ResourceMemory,
+								NominalQuota: resource.MustParse("1Gi"),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	clusterQueue := CreateTrackedKueueClusterQueue(test, cqSpec)
+	defer test.Client().Kueue().KueueV1beta1().ClusterQueues().Delete(test.Ctx(), clusterQueue.Name, metav1.DeleteOptions{})
+	localQueue := CreateKueueLocalQueue(test, namespace.Name, clusterQueue.Name)
+
+	// Run a failing workload, and a workload exceeding the ClusterQueue quota
+	failingJob := createAlertingJob(test, namespace.Name, localQueue.Name, "alerts-failing-", "500m", "exit 1")
+	blockedJob := createAlertingJob(test, namespace.Name, localQueue.Name, "alerts-blocked-", "2", "sleep 60")
+	EventuallyOf(test, Job(test, namespace.Name, failingJob.Name), TestTimeoutMedium).
+		Should(Field(JobConditionStatus(batchv1.JobFailed)).Equal(corev1.ConditionTrue))
+	test.Expect(Job(test, namespace.Name, blockedJob.Name)(test).Spec.Suspend).To(Equal(Ptr(true)))
+
+	// Make sure the alerts fire, each alert is only asserted when its rule is loaded in Prometheus
+	expectAlertFiring := func(name string, labels map[string]string) {
+		test.T().Run(name, func(t *testing.T) {
+			rule, ok := PrometheusAlertingRule(test, prometheus, name)
+			if !ok {
+				t.Skipf("Alerting rule %s isn't loaded in Prometheus", name)
+			}
+			timeout := time.Duration(rule.Duration*float64(time.Second)) + TestTimeoutMedium
+			test.Eventually(FiringAlerts(alertmanager, name, labels), timeout).
+				ShouldNot(BeEmpty(), "Alert %s didn't fire in Alertmanager", name)
+		})
+	}
+	expectAlertFiring(GetJobFailureAlert(), map[string]string{"namespace": namespace.Name, "job_name": failingJob.Name})
+	expectAlertFiring(GetKueueAdmissionBlockedAlert(), map[string]string{"cluster_queue": clusterQueue.Name})
+}
+
+func createAlertingJob(test Test, namespace, localQueueName, generateName, cpu, command string) *batchv1.Job {
+	test.T().Helper()
+
+	job := &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: batchv1.SchemeGroupVersion.String(),
+			Kind:       "Job",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: generateName,
+			Labels: map[string]string{
+				"kueue.x-k8s.io/queue-name": localQueueName,
+			},
+		},
+		Spec: batchv1.JobSpec{
+			Parallelism:  Ptr(int32(1)),
+			Completions:  Ptr(int32(1)),
+			BackoffLimit: Ptr(int32(0)),
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers: []corev1.Container{
+						{
+							Name:    "job",
+							Image:   GetToolsImage(),
+							Command: []string{"sh", "-c", command},
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									corev1.ResourceCPU:    resource.MustParse(cpu),
+									corev1.ResourceMemory: resource.MustParse("64Mi"),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	created, err := test.Client().Core().BatchV1().Jobs(namespace).Create(test.Ctx(), job, metav1.CreateOptions{})
+	ExpectNoError(test, err, "creating", Ref("Job", namespace, job.Name+job.GenerateName))
+	job = created
+	test.T().Logf("Created Job %s/%s successfully", job.Namespace, job.Name)
+
+	return job
+}
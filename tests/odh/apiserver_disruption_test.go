@@ -0,0 +1,159 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package odh
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/opendatahub-io/distributed-workloads/pkg/examples"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	awv1beta2 "github.com/project-codeflare/appwrapper/api/v1beta2"
+	. "github.com/project-codeflare/codeflare-common/support"
+	kueuev1beta1 "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	apiServerDisruptionDuration = 30 * time.Second
+	// The OpenShift kube-apiserver is restarted on the control plane nodes one at a time, each taking several minutes
+	apiServerRolloutDuration = 30 * time.Minute
+)
+
+// TestAppWrapperAPIServerDisruption disrupts the API server the test connects through while an AppWrapper runs,
+// and makes sure the assertions of the test recover once the disruption ends and the workload isn't failed.
+func TestAppWrapperAPIServerDisruption(t *testing.T) {
+	Track(t, LabelKueue)
+	test := MustGather(WithHooks(t))
+
+	// Create a namespace
+	namespace := test.NewTestNamespace()
+
+	// Connect through the disrupted API server proxy
+	disruption := NewAPIServerDisruption(test)
+	disrupted := disruption.Test(test)
+
+	// Submit an AppWrapper running past the disruption
+	appWrapper := createDisruptedAppWrapper(test, namespace, 2*apiServerDisruptionDuration)
+	EventuallyOf(disrupted, AppWrapper(disrupted, namespace, appWrapper.Name), TestTimeoutMedium).
+		Should(Field(AppWrapperPhase).Equal(awv1beta2.AppWrapperRunning))
+
+	// Make sure the assertions polling the API server recover from the disruption
+	disruption.Disrupt(test, apiServerDisruptionDuration)
+	EventuallyOf(disrupted, AppWrapper(disrupted, namespace, appWrapper.Name), TestTimeoutMedium).
+		Should(Field(AppWrapperPhase).OneOf(awv1beta2.AppWrapperSucceeded, awv1beta2.AppWrapperFailed))
+	test.Expect(disruption.FailedRequests()).To(BeNumerically(">", 0), "No request failed by the API server disruption")
+
+	// Make sure the workload wasn't failed nor reset
+	expectAppWrapperUndisrupted(test, namespace, appWrapper)
+}
+
+// TestAppWrapperAPIServerRollout rolls out a new revision of the OpenShift kube-apiserver while an AppWrapper runs,
+// restarting the API server the controllers dispatching the workloads connect to, and makes sure they recover
+// without failing the workload. It disrupts the whole cluster, so it only runs when TEST_API_SERVER_ROLLOUT is set.
+func TestAppWrapperAPIServerRollout(t *testing.T) {
+	Track(t, LabelKueue, LabelLong)
+	test := MustGather(WithHooks(t))
+
+	if !IsAPIServerRollout() {
+		test.T().Skip("TEST_API_SERVER_ROLLOUT isn't set")
+	}
+	if !IsOpenShift(test) {
+		test.T().Skip("Rolling out the API server is only supported on OpenShift")
+	}
+
+	// Create a namespace
+	namespace := test.NewTestNamespace()
+
+	// Submit an AppWrapper running for about the duration of the rollout
+	appWrapper := createDisruptedAppWrapper(test, namespace, apiServerRolloutDuration)
+	EventuallyOf(test, AppWrapper(test, namespace, appWrapper.Name), TestTimeoutMedium).
+		Should(Field(AppWrapperPhase).Equal(awv1beta2.AppWrapperRunning))
+
+	// Roll out the API server, the requests failing while the API server restarts are retried by the assertions
+	revision := RollOutOpenShiftAPIServer(test)
+	test.Eventually(OpenShiftAPIServerRolledOut(test, revision), apiServerRolloutDuration).Should(BeTrue())
+	test.T().Logf("Rolled out kube-apiserver revision %d", revision)
+
+	// Make sure the workload completes without being failed nor reset
+	EventuallyOf(test, AppWrapper(test, namespace, appWrapper.Name), apiServerRolloutDuration).
+		Should(Field(AppWrapperPhase).OneOf(awv1beta2.AppWrapperSucceeded, awv1beta2.AppWrapperFailed))
+	expectAppWrapperUndisrupted(test, namespace, appWrapper)
+}
+
+func createDisruptedAppWrapper(test Test, namespace *corev1.Namespace, duration time.Duration) *awv1beta2.AppWrapper {
+	test.T().Helper()
+
+	queues := CreateKueueQueues(test, namespace.Name, kueuev1beta1.ResourceFlavorSpec{}, corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("1"),
+		corev1.ResourceMemory: resource.MustParse("1Gi"),
+	})
+	job := &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: batchv1.SchemeGroupVersion.String(),
+			Kind:       "Job",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "disrupted-job",
+			Namespace: namespace.Name,
+			Labels: map[string]string{
+				"kueue.x-k8s.io/queue-name": queues.LocalQueue.Name,
+			},
+		},
+		Spec: batchv1.JobSpec{
+			Parallelism:  Ptr(int32(1)),
+			Completions:  Ptr(int32(1)),
+			BackoffLimit: Ptr(int32(0)),
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers: []corev1.Container{
+						{
+							Name:    "job",
+							Image:   GetToolsImage(),
+							Command: []string{"sh", "-c", fmt.Sprintf("sleep %d", int(duration.Seconds()))},
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									corev1.ResourceCPU:    resource.MustParse("100m"),
+									corev1.ResourceMemory: resource.MustParse("64Mi"),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	appWrapper, err := examples.AppWrapperOf("disrupted", namespace.Name, job)
+	ExpectNoError(test, err, "wrapping", Ref("AppWrapper", namespace.Name, "disrupted"))
+	return CreateAppWrapper(test, appWrapper)
+}
+
+func expectAppWrapperUndisrupted(test Test, namespace *corev1.Namespace, appWrapper *awv1beta2.AppWrapper) {
+	test.T().Helper()
+	ExpectOf(test, AppWrapper(test, namespace, appWrapper.Name)(test)).
+		To(Field(AppWrapperPhase).Equal(awv1beta2.AppWrapperSucceeded).
+			And(Field(func(appWrapper *awv1beta2.AppWrapper) int32 { return appWrapper.Status.Retries }).Equal(int32(0))))
+	ExpectOf(test, GetKueueWorkloadOwnedBy(test, namespace.Name, appWrapper)).
+		To(Field(KueueWorkloadEvicted).Equal(false))
+}
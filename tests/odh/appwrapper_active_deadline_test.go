@@ -0,0 +1,152 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package odh
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	"github.com/opendatahub-io/distributed-workloads/pkg/examples"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	awv1beta2 "github.com/project-codeflare/appwrapper/api/v1beta2"
+	. "github.com/project-codeflare/codeflare-common/support"
+	kueuev1beta1 "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const appWrapperDeadlineSeconds = 30
+
+// TestAppWrapperActiveDeadline makes sure an AppWrapper whose Job runs longer than its activeDeadlineSeconds fails
+// without being retried and releases its Kueue quota. The v1beta2 AppWrapper API has no dispatch duration limit,
+// the time limit is set on the wrapped resources.
+func TestAppWrapperActiveDeadline(t *testing.T) {
+	Track(t, LabelKueue)
+	test := MustGather(WithHooks(t))
+
+	// Create a namespace
+	namespace := test.NewTestNamespace()
+
+	// Create Kueue resources
+	resourceFlavor := CreateTrackedKueueResourceFlavor(test, kueuev1beta1.ResourceFlavorSpec{})
+	defer test.Client().Kueue().KueueV1beta1().ResourceFlavors().Delete(test.Ctx(), resourceFlavor.Name, metav1.DeleteOptions{})
+	cqSpec := kueuev1beta1.ClusterQueueSpec{
+		NamespaceSelector: &metav1.LabelSelector{},
+		ResourceGroups: []kueuev1beta1.ResourceGroup{
+			{
+				CoveredResources: []corev1.ResourceName{corev1.ResourceName("cpu"), corev1.ResourceName("memory")},
+				Flavors: []kueuev1beta1.FlavorQuotas{
+					{
+						Name: kueuev1beta1.ResourceFlavorReference(resourceFlavor.Name),
+						Resources: []kueuev1beta1.ResourceQuota{
+							{
+								Name:         corev1.ResourceCPU,
+								NominalQuota: resource.MustParse("1"),
+							},
+							{
+								Name:         corev1.ResourceMemory,
+								NominalQuota: resource.MustParse("1Gi"),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	clusterQueue := CreateTrackedKueueClusterQueue(test, cqSpec)
+	defer test.Client().Kueue().KueueV1beta1().ClusterQueues().Delete(test.Ctx(), clusterQueue.Name, metav1.DeleteOptions{})
+	localQueue := CreateKueueLocalQueue(test, namespace.Name, clusterQueue.Name)
+
+	// Create AppWrapper wrapping a Job running past its deadline, failing without retries once the Job fails
+	job := newDeadlineJob(namespace.Name, localQueue.Name)
+	appWrapper, err := examples.AppWrapperOf("deadline", namespace.Name, job)
+	ExpectNoError(test, err, "wrapping", Ref("AppWrapper", namespace.Name, "deadline"))
+	appWrapper.Annotations = map[string]string{
+		awv1beta2.RetryLimitAnnotation:                 "0",
+		awv1beta2.FailureGracePeriodDurationAnnotation: "0s",
+	}
+	appWrapper = CreateAppWrapper(test, appWrapper)
+
+	EventuallyOf(test, AppWrapper(test, namespace, appWrapper.Name), TestTimeoutMedium).
+		Should(Field(AppWrapperPhase).Equal(awv1beta2.AppWrapperRunning))
+
+	// Make sure the Job is terminated once the deadline passes, with the deadline reported as the reason
+	EventuallyOf(test, Job(test, namespace.Name, job.Name), appWrapperDeadlineSeconds*time.Second+TestTimeoutShort).
+		Should(Field(JobConditionStatus(batchv1.JobFailed)).Equal(corev1.ConditionTrue))
+	test.Expect(jobConditionReason(GetJob(test, namespace.Name, job.Name), batchv1.JobFailed)).To(Equal(batchv1.JobReasonDeadlineExceeded))
+
+	// Make sure the AppWrapper fails instead of resetting the Job, and releases the quota promptly
+	EventuallyOf(test, AppWrapper(test, namespace, appWrapper.Name), TestTimeoutShort).
+		Should(Field(AppWrapperPhase).Equal(awv1beta2.AppWrapperFailed))
+	EventuallyOf(test, KueueWorkloadOwnedBy(test, namespace.Name, appWrapper), TestTimeoutShort).
+		Should(Field(KueueWorkloadFinished).Equal(true))
+	EventuallyOf(test, KueueClusterQueue(test, clusterQueue.Name), TestTimeoutShort).
+		Should(Field(KueueClusterQueueReservingWorkloads).Equal(int32(0)))
+}
+
+func newDeadlineJob(namespace, localQueueName string) *batchv1.Job {
+	return &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: batchv1.SchemeGroupVersion.String(),
+			Kind:       "Job",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "deadline-job",
+			Namespace: namespace,
+			Labels: map[string]string{
+				"kueue.x-k8s.io/queue-name": localQueueName,
+			},
+		},
+		Spec: batchv1.JobSpec{
+			Parallelism:           Ptr(int32(1)),
+			Completions:           Ptr(int32(1)),
+			BackoffLimit:          Ptr(int32(0)),
+			ActiveDeadlineSeconds: Ptr(int64(appWrapperDeadlineSeconds)),
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers: []corev1.Container{
+						{
+							Name:    "job",
+							Image:   GetToolsImage(),
+							Command: []string{"sh", "-c", "sleep 3600"},
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									corev1.ResourceCPU:    resource.MustParse("100m"),
+									corev1.ResourceMemory: resource.MustParse("64Mi"),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
+
+func jobConditionReason(job *batchv1.Job, conditionType batchv1.JobConditionType) string {
+	for _, condition := range job.Status.Conditions {
+		if condition.Type == conditionType {
+			return condition.Reason
+		}
+	}
+	return ""
+}
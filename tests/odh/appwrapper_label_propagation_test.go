@@ -0,0 +1,166 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package odh
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/opendatahub-io/distributed-workloads/pkg/examples"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	awv1beta2 "github.com/project-codeflare/appwrapper/api/v1beta2"
+	. "github.com/project-codeflare/codeflare-common/support"
+	kueuev1beta1 "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	costCenterLabel      = "example.com/cost-center"
+	costCenterAnnotation = "example.com/cost-center-owner"
+)
+
+func TestAppWrapperLabelPropagation(t *testing.T) {
+	Track(t, LabelKueue)
+	test := MustGather(WithHooks(t))
+
+	// Create a namespace
+	namespace := test.NewTestNamespace()
+
+	// Create Kueue resources
+	resourceFlavor := CreateTrackedKueueResourceFlavor(test, kueuev1beta1.ResourceFlavorSpec{})
+	defer test.Client().Kueue().KueueV1beta1().ResourceFlavors().Delete(test.Ctx(), resourceFlavor.Name, metav1.DeleteOptions{})
+	cqSpec := kueuev1beta1.ClusterQueueSpec{
+		NamespaceSelector: &metav1.LabelSelector{},
+		ResourceGroups: []kueuev1beta1.ResourceGroup{
+			{
+				CoveredResources: []corev1.ResourceName{corev1.ResourceName("cpu"), corev1.ResourceName("memory")},
+				Flavors: []kueuev1beta1.FlavorQuotas{
+					{
+						Name: kueuev1beta1.ResourceFlavorReference(resourceFlavor.Name),
+						Resources: []kueuev1beta1.ResourceQuota{
+							{
+								Name:         corev1.ResourceCPU,
+								NominalQuota: resource.MustParse("1"),
+							},
+							{
+								Name:         corev1.ResourceMemory,
+								NominalQuota: resource.MustParse("1Gi"),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	clusterQueue := CreateTrackedKueueClusterQueue(test, cqSpec)
+	defer test.Client().Kueue().KueueV1beta1().ClusterQueues().Delete(test.Ctx(), clusterQueue.Name, metav1.DeleteOptions{})
+	localQueue := CreateKueueLocalQueue(test, namespace.Name, clusterQueue.Name)
+
+	// Create AppWrapper wrapping a Job labeled for chargeback
+	job := newChargebackJob(namespace.Name, localQueue.Name)
+	appWrapper, err := examples.AppWrapperOf("chargeback", namespace.Name, job)
+	ExpectNoError(test, err, "wrapping", Ref("AppWrapper", namespace.Name, "chargeback"))
+	phases := RecordStates(test, awv1beta2.GroupVersion.WithResource("appwrappers"), "AppWrapper", namespace.Name, appWrapper.Name, StatusFieldState("status", "phase"))
+	appWrapper = CreateAppWrapper(test, appWrapper)
+	test.Expect(appWrapper).To(HaveLabel("kueue.x-k8s.io/queue-name", localQueue.Name))
+
+	EventuallyOf(test, AppWrapper(test, namespace, appWrapper.Name), TestTimeoutMedium).
+		Should(Field(AppWrapperPhase).Equal(awv1beta2.AppWrapperRunning))
+
+	// Make sure the wrapped Job keeps its labels and annotations and references the AppWrapper
+	wrappedJob := GetJob(test, namespace.Name, job.Name)
+	test.Expect(wrappedJob).To(And(
+		HaveLabel(AppWrapperNameLabel, appWrapper.Name),
+		HaveLabel(costCenterLabel, "ml-research"),
+		HaveAnnotation(costCenterAnnotation, "team-a"),
+	))
+	ExpectOf(test, wrappedJob.OwnerReferences).To(ContainElementOf(
+		Field(func(ownerReference metav1.OwnerReference) string { return ownerReference.Name }).Equal(appWrapper.Name),
+	))
+
+	// Make sure the pods get labeled with the AppWrapper and the chargeback metadata
+	test.Eventually(JobPods(test, namespace.Name, job.Name), TestTimeoutShort).Should(And(
+		Not(BeEmpty()),
+		HaveEach(And(
+			HaveLabel(AppWrapperNameLabel, appWrapper.Name),
+			HaveLabel(costCenterLabel, "ml-research"),
+			HaveAnnotation(costCenterAnnotation, "team-a"),
+		)),
+	))
+
+	// Make sure the AppWrapper completes with the Job, without being reset or suspended on the way
+	ExpectTransitions(test, phases, []string{
+		string(awv1beta2.AppWrapperSuspended),
+		string(awv1beta2.AppWrapperResuming),
+		string(awv1beta2.AppWrapperRunning),
+		string(awv1beta2.AppWrapperSucceeded),
+	}, TestTimeoutMedium)
+}
+
+func newChargebackJob(namespace, localQueueName string) *batchv1.Job {
+	chargebackMetadata := metav1.ObjectMeta{
+		Labels: map[string]string{
+			costCenterLabel:             "ml-research",
+			"kueue.x-k8s.io/queue-name": localQueueName,
+		},
+		Annotations: map[string]string{
+			costCenterAnnotation: "team-a",
+		},
+	}
+
+	job := &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: batchv1.SchemeGroupVersion.String(),
+			Kind:       "Job",
+		},
+		ObjectMeta: *chargebackMetadata.DeepCopy(),
+		Spec: batchv1.JobSpec{
+			Parallelism: Ptr(int32(1)),
+			Completions: Ptr(int32(1)),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels:      map[string]string{costCenterLabel: "ml-research"},
+					Annotations: map[string]string{costCenterAnnotation: "team-a"},
+				},
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers: []corev1.Container{
+						{
+							Name:    "job",
+							Image:   GetToolsImage(),
+							Command: []string{"sh", "-c", "sleep 30"},
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									corev1.ResourceCPU:    resource.MustParse("100m"),
+									corev1.ResourceMemory: resource.MustParse("64Mi"),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	job.Name = "chargeback-job"
+	job.Namespace = namespace
+
+	return job
+}
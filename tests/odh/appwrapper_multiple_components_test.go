@@ -0,0 +1,185 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package odh
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/opendatahub-io/distributed-workloads/pkg/examples"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	awv1beta2 "github.com/project-codeflare/appwrapper/api/v1beta2"
+	. "github.com/project-codeflare/codeflare-common/support"
+	kueuev1beta1 "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/intstr"
+)
+
+// TestAppWrapperMultipleComponents submits an AppWrapper wrapping a Job together with the Service and ConfigMap
+// it uses, as commonly done in the field. The Job is the only component with pod sets, so it alone determines
+// the completion of the AppWrapper, and all the components are deleted together with the AppWrapper.
+func TestAppWrapperMultipleComponents(t *testing.T) {
+	Track(t, LabelKueue)
+	test := MustGather(WithHooks(t))
+
+	// Create a namespace
+	namespace := test.NewTestNamespace()
+
+	// Create Kueue resources
+	queues := CreateKueueQueues(test, namespace.Name, kueuev1beta1.ResourceFlavorSpec{}, corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("1"),
+		corev1.ResourceMemory: resource.MustParse("1Gi"),
+	})
+
+	// Create AppWrapper wrapping the Job, the Service exposing its pods and the ConfigMap configuring them
+	configMap := newMultiComponentConfigMap(namespace.Name)
+	service := newMultiComponentService(namespace.Name)
+	job := newMultiComponentJob(namespace.Name, queues.LocalQueue.Name)
+	appWrapper, err := examples.AppWrapperOf("multi-component", namespace.Name, job, configMap, service)
+	ExpectNoError(test, err, "wrapping", Ref("AppWrapper", namespace.Name, "multi-component"))
+	appWrapper = CreateAppWrapper(test, appWrapper)
+
+	EventuallyOf(test, AppWrapper(test, namespace, appWrapper.Name), TestTimeoutMedium).
+		Should(Field(AppWrapperPhase).Equal(awv1beta2.AppWrapperRunning))
+
+	// Make sure all the components are created and labeled with the AppWrapper
+	test.Expect(GetJob(test, namespace.Name, job.Name)).To(HaveLabel(AppWrapperNameLabel, appWrapper.Name))
+	wrappedConfigMap, err := test.Client().Core().CoreV1().ConfigMaps(namespace.Name).Get(test.Ctx(), configMap.Name, metav1.GetOptions{})
+	ExpectNoError(test, err, "getting", Ref("ConfigMap", namespace.Name, configMap.Name))
+	test.Expect(wrappedConfigMap).To(HaveLabel(AppWrapperNameLabel, appWrapper.Name))
+	wrappedService, err := test.Client().Core().CoreV1().Services(namespace.Name).Get(test.Ctx(), service.Name, metav1.GetOptions{})
+	ExpectNoError(test, err, "getting", Ref("Service", namespace.Name, service.Name))
+	test.Expect(wrappedService).To(HaveLabel(AppWrapperNameLabel, appWrapper.Name))
+
+	// Make sure the AppWrapper keeps running while the Job runs, even though the other components are ready
+	EventuallyOf(test, JobPods(test, namespace.Name, job.Name), TestTimeoutShort).
+		Should(ContainElementOf(Field(func(pod corev1.Pod) corev1.PodPhase { return pod.Status.Phase }).Equal(corev1.PodRunning)))
+	ConsistentlyOf(test, AppWrapper(test, namespace, appWrapper.Name), TestTimeoutShort/2).
+		Should(Field(AppWrapperPhase).Equal(awv1beta2.AppWrapperRunning))
+
+	// Make sure the AppWrapper succeeds once the Job completes, the pod read the wrapped ConfigMap
+	EventuallyOf(test, Job(test, namespace.Name, job.Name), TestTimeoutMedium).
+		Should(Field(JobConditionStatus(batchv1.JobComplete)).Equal(corev1.ConditionTrue))
+	EventuallyOf(test, AppWrapper(test, namespace, appWrapper.Name), TestTimeoutShort).
+		Should(Field(AppWrapperPhase).Equal(awv1beta2.AppWrapperSucceeded))
+	pods := JobPods(test, namespace.Name, job.Name)(test)
+	test.Expect(pods).To(HaveLen(1))
+	test.Expect(string(GetPodLogs(test, &pods[0], corev1.PodLogOptions{}))).To(ContainSubstring("Greeting: hello"))
+
+	// Make sure all the components are deleted together with the AppWrapper
+	err = test.Client().Dynamic().Resource(awv1beta2.GroupVersion.WithResource("appwrappers")).Namespace(namespace.Name).
+		Delete(test.Ctx(), appWrapper.Name, metav1.DeleteOptions{})
+	ExpectNoError(test, err, "deleting", Ref("AppWrapper", namespace.Name, appWrapper.Name))
+	WaitForDeletion(test, awv1beta2.GroupVersion.WithResource("appwrappers"), appWrapper, TestTimeoutMedium)
+	WaitForDeletion(test, batchv1.SchemeGroupVersion.WithResource("jobs"), job, TestTimeoutShort)
+	WaitForDeletion(test, corev1.SchemeGroupVersion.WithResource("services"), service, TestTimeoutShort)
+	WaitForDeletion(test, corev1.SchemeGroupVersion.WithResource("configmaps"), configMap, TestTimeoutShort)
+}
+
+func newMultiComponentConfigMap(namespace string) *corev1.ConfigMap {
+	return &corev1.ConfigMap{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "ConfigMap",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "multi-component-config",
+			Namespace: namespace,
+		},
+		Data: map[string]string{
+			"greeting": "hello",
+		},
+	}
+}
+
+func newMultiComponentService(namespace string) *corev1.Service {
+	return &corev1.Service{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: corev1.SchemeGroupVersion.String(),
+			Kind:       "Service",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "multi-component",
+			Namespace: namespace,
+		},
+		Spec: corev1.ServiceSpec{
+			ClusterIP: corev1.ClusterIPNone,
+			Selector:  map[string]string{"job-name": "multi-component-job"},
+			Ports: []corev1.ServicePort{
+				{
+					Name:       "http",
+					Port:       8080,
+					TargetPort: intstr.FromInt32(8080),
+				},
+			},
+		},
+	}
+}
+
+func newMultiComponentJob(namespace, localQueueName string) *batchv1.Job {
+	return &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: batchv1.SchemeGroupVersion.String(),
+			Kind:       "Job",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "multi-component-job",
+			Namespace: namespace,
+			Labels: map[string]string{
+				"kueue.x-k8s.io/queue-name": localQueueName,
+			},
+		},
+		Spec: batchv1.JobSpec{
+			Parallelism:  Ptr(int32(1)),
+			Completions:  Ptr(int32(1)),
+			BackoffLimit: Ptr(int32(0)),
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers: []corev1.Container{
+						{
+							Name:    "job",
+							Image:   GetToolsImage(),
+							Command: []string{"sh", "-c", `echo "Greeting: $GREETING" && sleep 30`},
+							Env: []corev1.EnvVar{
+								{
+									Name: "GREETING",
+									ValueFrom: &corev1.EnvVarSource{
+										ConfigMapKeyRef: &corev1.ConfigMapKeySelector{
+											LocalObjectReference: corev1.LocalObjectReference{Name: "multi-component-config"},
+											Key:                  "greeting",
+										},
+									},
+								},
+							},
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									corev1.ResourceCPU:    resource.MustParse("100m"),
+									corev1.ResourceMemory: resource.MustParse("64Mi"),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
@@ -0,0 +1,213 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package odh
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	. "github.com/project-codeflare/codeflare-common/support"
+	kueuev1beta1 "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// The chargeback workload requests a known amount of resources for a known duration
+	chargebackJobDuration = 180 * time.Second
+	chargebackJobCPU      = 0.5
+	chargebackJobGPU      = 1
+	// Resolution of the subqueries integrating the resource series over time
+	chargebackMetricsStep = 10 * time.Second
+)
+
+// TestChargebackMetrics checks the Kueue and kube-state-metrics series consumed by chargeback reports
+// reflect the resources requested by a workload of known size, and the time it ran for.
+func TestChargebackMetrics(t *testing.T) {
+	Track(t, LabelKueue)
+	test := MustGather(WithHooks(t))
+
+	// Create a namespace
+	namespace := test.NewTestNamespace()
+
+	prometheus := NewPrometheusClient(test, namespace.Name)
+	tolerance := GetMetricsTolerance(test)
+
+	// Charge the GPU-seconds as well when there are GPUs in the cluster
+	accelerator, withGpu := LookupAccelerator(test)
+	if withGpu {
+		AcquireGpuLease(test)
+	}
+
+	// Create Kueue resources
+	resourceFlavor := CreateTrackedKueueResourceFlavor(test, kueuev1beta1.ResourceFlavorSpec{})
+	defer test.Client().Kueue().KueueV1beta1().ResourceFlavors().Delete(test.Ctx(), resourceFlavor.Name, metav1.DeleteOptions{})
+	coveredResources := []corev1.ResourceName{corev1.ResourceCPU, corev1.ResourceMemory}
+	quotas := []kueuev1beta1.ResourceQuota{
+		{
+			Name:         corev1.ResourceCPU,
+			NominalQuota: resource.MustParse("1"),
+		},
+		{
+			Name:         corev1.ResourceMemory,
+			NominalQuota: resource.MustParse("1Gi"),
+		},
+	}
+	if withGpu {
+		coveredResources = append(coveredResources, accelerator.Resource)
+		quotas = append(quotas, kueuev1beta1.ResourceQuota{
+			Name:         accelerator.Resource,
+			NominalQuota: resource.MustParse(fmt.Sprint(chargebackJobGPU)),
+		})
+	}
+	cqSpec := kueuev1beta1.ClusterQueueSpec{
+		NamespaceSelector: &metav1.LabelSelector{},
+		ResourceGroups: []kueuev1beta1.ResourceGroup{
+			{
+				CoveredResources: coveredResources,
+				Flavors: []kueuev1beta1.FlavorQuotas{
+					{
+						Name:      kueuev1beta1.ResourceFlavorReference(resourceFlavor.Name),
+						Resources: quotas,
+					},
+				},
+			},
+		},
+	}
+	clusterQueue := CreateTrackedKueueClusterQueue(test, cqSpec)
+	defer test.Client().Kueue().KueueV1beta1().ClusterQueues().Delete(test.Ctx(), clusterQueue.Name, metav1.DeleteOptions{})
+	localQueue := CreateKueueLocalQueue(test, namespace.Name, clusterQueue.Name)
+
+	// Run the workload of known size
+	stopwatch := StartStopwatch()
+	job := createChargebackMetricsJob(test, namespace.Name, localQueue.Name, accelerator, withGpu)
+	EventuallyOf(test, JobPods(test, namespace.Name, job.Name), TestTimeoutMedium).
+		Should(ContainElementOf(Field(func(pod corev1.Pod) corev1.PodPhase { return pod.Status.Phase }).Equal(corev1.PodRunning)))
+
+	// Make sure the running workload is reported as admitted and its requests are reported as used quota
+	test.Eventually(PrometheusQueryValue(test, prometheus,
+		fmt.Sprintf(`kueue_admitted_active_workloads{cluster_queue=%q}`, clusterQueue.Name)), TestTimeoutShort).
+		Should(Equal(1.0))
+	test.Eventually(PrometheusQueryValue(test, prometheus,
+		fmt.Sprintf(`kueue_cluster_queue_resource_usage{cluster_queue=%q,resource="cpu"}`, clusterQueue.Name)), TestTimeoutShort).
+		Should(BeNumerically("~", chargebackJobCPU, chargebackJobCPU*tolerance),
+			"ClusterQueue resource metrics must be enabled in Kueue configuration")
+	test.Eventually(PrometheusQueryValue(test, prometheus,
+		fmt.Sprintf(`sum(kube_pod_container_resource_requests{namespace=%q,resource="cpu"})`, namespace.Name)), TestTimeoutShort).
+		Should(BeNumerically("~", chargebackJobCPU, chargebackJobCPU*tolerance))
+
+	// Wait for the workload to complete and be released from the ClusterQueue
+	EventuallyOf(test, Job(test, namespace.Name, job.Name), TestTimeoutMedium).
+		Should(Field(JobConditionStatus(batchv1.JobComplete)).Equal(corev1.ConditionTrue))
+	test.Eventually(PrometheusQueryValue(test, prometheus,
+		fmt.Sprintf(`kueue_admitted_active_workloads{cluster_queue=%q}`, clusterQueue.Name)), TestTimeoutShort).
+		Should(Equal(0.0))
+
+	// Make sure the resource-seconds integrated from the series match the workload size, the series are
+	// scraped periodically, so they may take a while to cover the end of the workload
+	window := stopwatch.Elapsed() + time.Minute
+	expectResourceSeconds := func(resourceName, stateResourceName string, quantity float64) {
+		expected := quantity * chargebackJobDuration.Seconds()
+
+		kueueQuery := fmt.Sprintf(`sum(sum_over_time(kueue_cluster_queue_resource_usage{cluster_queue=%q,resource=%q}[%s:%s])) * %v`,
+			clusterQueue.Name, resourceName, promDuration(window), promDuration(chargebackMetricsStep), chargebackMetricsStep.Seconds())
+		test.Eventually(PrometheusQueryValue(test, prometheus, kueueQuery), TestTimeoutShort).
+			Should(BeNumerically("~", expected, expected*tolerance), "Kueue %s-seconds don't match the workload", resourceName)
+
+		stateQuery := fmt.Sprintf(`sum(sum_over_time((sum(kube_pod_container_resource_requests{namespace=%q,resource=%q} * on(namespace, pod) group_left() (kube_pod_status_phase{namespace=%q,phase="Running"} == 1)))[%s:%s])) * %v`,
+			namespace.Name, stateResourceName, namespace.Name, promDuration(window), promDuration(chargebackMetricsStep), chargebackMetricsStep.Seconds())
+		test.Eventually(PrometheusQueryValue(test, prometheus, stateQuery), TestTimeoutShort).
+			Should(BeNumerically("~", expected, expected*tolerance), "kube-state-metrics %s-seconds don't match the workload", resourceName)
+	}
+	expectResourceSeconds("cpu", "cpu", chargebackJobCPU)
+	if withGpu {
+		// kube-state-metrics sanitizes the resource names into label values, i.e. nvidia_com_gpu
+		expectResourceSeconds(accelerator.Resource.String(), strings.NewReplacer(".", "_", "/", "_").Replace(accelerator.Resource.String()), chargebackJobGPU)
+	}
+}
+
+func createChargebackMetricsJob(test Test, namespace, localQueueName string, accelerator Accelerator, withGpu bool) *batchv1.Job {
+	test.T().Helper()
+
+	resources := corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    *resource.NewMilliQuantity(int64(chargebackJobCPU*1000), resource.DecimalSI),
+			corev1.ResourceMemory: resource.MustParse("64Mi"),
+		},
+		Limits: corev1.ResourceList{},
+	}
+	if withGpu {
+		for name, quantity := range accelerator.Resources(chargebackJobGPU) {
+			resources.Requests[name] = quantity
+			resources.Limits[name] = quantity
+		}
+	}
+
+	job := &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: batchv1.SchemeGroupVersion.String(),
+			Kind:       "Job",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "chargeback-metrics-",
+			Labels: map[string]string{
+				"kueue.x-k8s.io/queue-name": localQueueName,
+			},
+		},
+		Spec: batchv1.JobSpec{
+			Parallelism: Ptr(int32(1)),
+			Completions: Ptr(int32(1)),
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers: []corev1.Container{
+						{
+							Name:      "job",
+							Image:     GetToolsImage(),
+							Command:   []string{"sh", "-c", fmt.Sprintf("sleep %d", int(chargebackJobDuration.Seconds()))},
+							Resources: resources,
+						},
+					},
+				},
+			},
+		},
+	}
+
+	if withGpu {
+		job.Spec.Template.Spec.Tolerations = accelerator.Tolerations()
+	}
+
+	created, err := test.Client().Core().BatchV1().Jobs(namespace).Create(test.Ctx(), job, metav1.CreateOptions{})
+	ExpectNoError(test, err, "creating", Ref("Job", namespace, job.Name+job.GenerateName))
+	job = created
+	test.T().Logf("Created Job %s/%s successfully", job.Namespace, job.Name)
+
+	return job
+}
+
+// promDuration formats the duration in whole seconds, as accepted by PromQL range selectors.
+func promDuration(duration time.Duration) string {
+	return fmt.Sprintf("%ds", int(math.Ceil(duration.Seconds())))
+}
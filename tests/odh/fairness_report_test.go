@@ -0,0 +1,96 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package odh
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+// Simulated day replayed in five minutes
+const fairnessTimeScale = 288
+
+// TestMultiTenantFairnessReport replays a synthetic day of submissions of three teams sharing the cluster through the
+// queue manager, and reports the wait times and usage of each team, so quotas and weights are tuned from evidence.
+// The trace requests CPUs rather than GPUs, so it replays on any cluster, the CPU-hours standing for GPU-hours.
+func TestMultiTenantFairnessReport(t *testing.T) {
+	Track(t, LabelLong)
+	test := MustGather(WithHooks(t))
+
+	// Create a namespace per team, with its share of the cluster
+	request := corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("100m"),
+		corev1.ResourceMemory: resource.MustParse("32Mi"),
+	}
+	teams := []struct {
+		tenant SyntheticTenant
+		share  float64
+		quota  corev1.ResourceList
+	}{
+		{
+			tenant: SyntheticTenant{Name: "research", Submissions: 12, PeakHour: 10, MeanRuntime: 2 * time.Hour, Request: request},
+			share:  0.5,
+			quota:  corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("500m"), corev1.ResourceMemory: resource.MustParse("512Mi")},
+		},
+		{
+			tenant: SyntheticTenant{Name: "production", Submissions: 8, PeakHour: 14, MeanRuntime: time.Hour, Request: request},
+			share:  0.3,
+			quota:  corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("300m"), corev1.ResourceMemory: resource.MustParse("512Mi")},
+		},
+		{
+			tenant: SyntheticTenant{Name: "interns", Submissions: 8, PeakHour: 16, MeanRuntime: 30 * time.Minute, Request: request},
+			share:  0.2,
+			quota:  corev1.ResourceList{corev1.ResourceCPU: resource.MustParse("200m"), corev1.ResourceMemory: resource.MustParse("512Mi")},
+		},
+	}
+	quotas := map[string]corev1.ResourceList{}
+	namespaces := map[string]string{}
+	var synthetic []SyntheticTenant
+	for _, team := range teams {
+		namespace := test.NewTestNamespace()
+		quotas[namespace.Name] = team.quota
+		namespaces[team.tenant.Name] = namespace.Name
+		synthetic = append(synthetic, team.tenant)
+	}
+
+	// Create the queue managers the teams share the cluster through
+	queues := NewSharedQueueManagers(test, quotas)
+	var tenants []FairnessTenant
+	for _, team := range teams {
+		namespace := namespaces[team.tenant.Name]
+		tenants = append(tenants, FairnessTenant{Name: team.tenant.Name, Namespace: namespace, Queue: queues[namespace], Share: team.share})
+	}
+
+	// Replay the day and report the service each team got
+	trace := SyntheticDayTrace(1, synthetic...)
+	report := ReplayTrace(test, tenants, trace, fairnessTimeScale, corev1.ResourceCPU, TestTimeoutLong)
+	report.Record(test)
+
+	// Make sure every submission of every team was served
+	test.Expect(report.Tenants).To(HaveLen(len(teams)))
+	for i, fairness := range report.Tenants {
+		test.Expect(fairness.Submissions).To(Equal(teams[i].tenant.Submissions), "Submissions of %s not all replayed", fairness.Tenant)
+		test.Expect(fairness.ResourceHours).To(BeNumerically(">", 0), "%s got no CPU-hours", fairness.Tenant)
+	}
+}
@@ -0,0 +1,101 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package odh
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Label opting the namespace into the Kueue configuration managed by the platform
+const kueueManagedNamespaceLabel = "kueue.openshift.io/managed"
+
+func TestKueueDefaultLocalQueue(t *testing.T) {
+	Track(t, LabelKueue)
+	test := MustGather(WithHooks(t))
+
+	// Make sure the platform manages the Kueue configuration
+	_, err := test.Client().Kueue().KueueV1beta1().ClusterQueues().Get(test.Ctx(), GetKueueDefaultClusterQueue(), metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		test.T().Skipf("ClusterQueue %s not found, Kueue configuration isn't managed by the platform", GetKueueDefaultClusterQueue())
+	}
+	ExpectNoError(test, err, "getting", Ref("ClusterQueue", "", GetKueueDefaultClusterQueue()))
+
+	// Create a namespace managed by the platform
+	namespace := test.NewTestNamespace()
+	patch := fmt.Sprintf(`{"metadata":{"labels":{%q:"true"}}}`, kueueManagedNamespaceLabel)
+	namespace, err = test.Client().Core().CoreV1().Namespaces().Patch(test.Ctx(), namespace.Name, types.MergePatchType, []byte(patch), metav1.PatchOptions{})
+	ExpectNoError(test, err, "patching", Ref("Namespace", "", namespace.Name))
+
+	// Make sure the default LocalQueue is created, pointing to the default ClusterQueue
+	EventuallyOf(test, KueueLocalQueue(test, namespace.Name, GetKueueDefaultLocalQueue()), TestTimeoutShort).
+		Should(Field(KueueLocalQueueClusterQueue).Equal(GetKueueDefaultClusterQueue()))
+
+	// Submit a workload labeled with the default queue
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "default-queue-",
+			Labels: map[string]string{
+				"kueue.x-k8s.io/queue-name": GetKueueDefaultLocalQueue(),
+			},
+		},
+		Spec: batchv1.JobSpec{
+			Suspend:      Ptr(true),
+			BackoffLimit: Ptr(int32(0)),
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers: []corev1.Container{
+						{
+							Name:    "workload",
+							Image:   GetNotebookImage(),
+							Command: []string{"sh", "-c", "echo 'Admitted by the default queue'"},
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									corev1.ResourceCPU:    resource.MustParse("100m"),
+									corev1.ResourceMemory: resource.MustParse("128Mi"),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	created, err := test.Client().Core().BatchV1().Jobs(namespace.Name).Create(test.Ctx(), job, metav1.CreateOptions{})
+	ExpectNoError(test, err, "creating", Ref("Job", namespace.Name, job.Name+job.GenerateName))
+	job = created
+	test.T().Logf("Created Job %s/%s successfully", job.Namespace, job.Name)
+
+	// Make sure the workload is admitted by the default ClusterQueue and runs to completion
+	EventuallyOf(test, KueueWorkloadOwnedBy(test, namespace.Name, job), TestTimeoutMedium).
+		Should(Field(KueueWorkloadAdmitted).Equal(true).And(
+			Field(KueueWorkloadClusterQueue).Equal(GetKueueDefaultClusterQueue()),
+		))
+	EventuallyOf(test, Job(test, namespace.Name, job.Name), TestTimeoutMedium).
+		Should(Field(JobConditionStatus(batchv1.JobComplete)).Equal(corev1.ConditionTrue))
+}
@@ -0,0 +1,162 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package odh
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	. "github.com/project-codeflare/codeflare-common/support"
+	kueuev1beta1 "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// Label of the team owning the namespace, the ClusterQueue dedicated to a team selects its namespaces by it
+const kueueTeamNamespaceLabel = "distributed-workloads.opendatahub.io/test-team"
+
+// TestKueueNamespaceSelectorRouting checks the ClusterQueue namespace selector only admits workloads from the LocalQueues
+// of the selected namespaces, as admins configure to dedicate GPU pools to specific teams.
+func TestKueueNamespaceSelectorRouting(t *testing.T) {
+	Track(t, LabelKueue)
+	test := MustGather(WithHooks(t))
+
+	// Create namespaces of two teams
+	teamNamespace := newTeamNamespace(test, "team-a")
+	otherNamespace := newTeamNamespace(test, "team-b")
+
+	// Create Kueue resources, the ClusterQueue is dedicated to the first team
+	resourceFlavor := CreateTrackedKueueResourceFlavor(test, kueuev1beta1.ResourceFlavorSpec{})
+	defer test.Client().Kueue().KueueV1beta1().ResourceFlavors().Delete(test.Ctx(), resourceFlavor.Name, metav1.DeleteOptions{})
+	cqSpec := kueuev1beta1.ClusterQueueSpec{
+		NamespaceSelector: &metav1.LabelSelector{
+			MatchLabels: map[string]string{kueueTeamNamespaceLabel: "team-a"},
+		},
+		ResourceGroups: []kueuev1beta1.ResourceGroup{
+			{
+				CoveredResources: []corev1.ResourceName{corev1.ResourceName("cpu"), corev1.ResourceName("memory")},
+				Flavors: []kueuev1beta1.FlavorQuotas{
+					{
+						Name: kueuev1beta1.ResourceFlavorReference(resourceFlavor.Name),
+						Resources: []kueuev1beta1.ResourceQuota{
+							{
+								Name:         corev1.ResourceCPU,
+								NominalQuota: resource.MustParse("1"),
+							},
+							{
+								Name:         corev1.ResourceMemory,
+								NominalQuota: resource.MustParse("1Gi"),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	clusterQueue := CreateTrackedKueueClusterQueue(test, cqSpec)
+	defer test.Client().Kueue().KueueV1beta1().ClusterQueues().Delete(test.Ctx(), clusterQueue.Name, metav1.DeleteOptions{})
+	teamQueue := CreateKueueLocalQueue(test, teamNamespace.Name, clusterQueue.Name)
+	otherQueue := CreateKueueLocalQueue(test, otherNamespace.Name, clusterQueue.Name)
+
+	// Submit a workload in each namespace
+	teamJob := createQueuedJob(test, teamNamespace.Name, teamQueue.Name)
+	otherJob := createQueuedJob(test, otherNamespace.Name, otherQueue.Name)
+
+	// Make sure the workload of the selected namespace is admitted and runs to completion
+	EventuallyOf(test, KueueWorkloadOwnedBy(test, teamNamespace.Name, teamJob), TestTimeoutMedium).
+		Should(Field(KueueWorkloadAdmitted).Equal(true).And(
+			Field(KueueWorkloadClusterQueue).Equal(clusterQueue.Name),
+		))
+	EventuallyOf(test, Job(test, teamNamespace.Name, teamJob.Name), TestTimeoutMedium).
+		Should(Field(JobConditionStatus(batchv1.JobComplete)).Equal(corev1.ConditionTrue))
+
+	// Make sure the workload of the other namespace is rejected, even though the ClusterQueue has free quota
+	EventuallyOf(test, KueueWorkloadOwnedBy(test, otherNamespace.Name, otherJob), TestTimeoutShort).
+		Should(Field(KueueWorkloadPendingMessage).Matches(ContainSubstring("namespace doesn't match ClusterQueue selector")))
+	ConsistentlyOf(test, KueueWorkloadOwnedBy(test, otherNamespace.Name, otherJob), TestTimeoutShort).
+		Should(Field(KueueWorkloadQuotaReserved).Equal(false))
+	test.Expect(GetJob(test, otherNamespace.Name, otherJob.Name).Spec.Suspend).To(Equal(Ptr(true)))
+
+	// Move the other namespace to the team, its pending workload gets admitted without being resubmitted
+	setTeamNamespaceLabel(test, otherNamespace.Name, "team-a")
+	EventuallyOf(test, KueueWorkloadOwnedBy(test, otherNamespace.Name, otherJob), TestTimeoutMedium).
+		Should(Field(KueueWorkloadAdmitted).Equal(true).And(
+			Field(KueueWorkloadClusterQueue).Equal(clusterQueue.Name),
+		))
+	EventuallyOf(test, Job(test, otherNamespace.Name, otherJob.Name), TestTimeoutMedium).
+		Should(Field(JobConditionStatus(batchv1.JobComplete)).Equal(corev1.ConditionTrue))
+}
+
+func newTeamNamespace(test Test, team string) *corev1.Namespace {
+	test.T().Helper()
+	namespace := test.NewTestNamespace()
+	return setTeamNamespaceLabel(test, namespace.Name, team)
+}
+
+func setTeamNamespaceLabel(test Test, namespace, team string) *corev1.Namespace {
+	test.T().Helper()
+	patch := fmt.Sprintf(`{"metadata":{"labels":{%q:%q}}}`, kueueTeamNamespaceLabel, team)
+	ns, err := test.Client().Core().CoreV1().Namespaces().Patch(test.Ctx(), namespace, types.MergePatchType, []byte(patch), metav1.PatchOptions{})
+	ExpectNoError(test, err, "labeling", Ref("Namespace", "", namespace))
+	return ns
+}
+
+// createQueuedJob creates a suspended Job queued in the LocalQueue, which Kueue unsuspends once admitted.
+func createQueuedJob(test Test, namespace, localQueue string) *batchv1.Job {
+	test.T().Helper()
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "queued-",
+			Labels: map[string]string{
+				"kueue.x-k8s.io/queue-name": localQueue,
+			},
+		},
+		Spec: batchv1.JobSpec{
+			Suspend:      Ptr(true),
+			BackoffLimit: Ptr(int32(0)),
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers: []corev1.Container{
+						{
+							Name:    "workload",
+							Image:   GetToolsImage(),
+							Command: []string{"sh", "-c", "echo 'Admitted by ClusterQueue'"},
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									corev1.ResourceCPU:    resource.MustParse("100m"),
+									corev1.ResourceMemory: resource.MustParse("128Mi"),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	created, err := test.Client().Core().BatchV1().Jobs(namespace).Create(test.Ctx(), job, metav1.CreateOptions{})
+	ExpectNoError(test, err, "creating", Ref("Job", namespace, job.Name+job.GenerateName))
+	job = created
+	test.T().Logf("Created Job %s/%s successfully", job.Namespace, job.Name)
+	return job
+}
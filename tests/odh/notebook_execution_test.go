@@ -0,0 +1,62 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package odh
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+func TestNotebookExecutionThroughOAuthProxy(t *testing.T) {
+	Track(t, LabelNotebook)
+	test := MustGather(WithHooks(t))
+
+	if !IsOpenShift(test) {
+		test.T().Skip("The OAuth proxy is only injected into Notebooks on OpenShift")
+	}
+
+	// Create a namespace
+	namespace := test.NewTestNamespace()
+
+	// Create the Notebook with the OAuth proxy in front of its Jupyter server
+	workspacePvc := CreatePersistentVolumeClaimWithStorageClass(test, namespace.Name, "1Gi", GetStorageClass(), corev1.ReadWriteOnce)
+	notebook := CreateNotebookWithOAuth(test, namespace.Name, "notebook-execution", newNotebookContainer(), workspacePvc.Name)
+	test.Eventually(NotebookPods(test, namespace.Name, notebook.GetName()), TestTimeoutLong).
+		Should(And(HaveLen(1), ContainElement(Satisfy(PodRunningAndReady))))
+
+	// Upload the notebook through the Route, the OAuth proxy may take a while to accept the token
+	jupyter := NotebookOAuthJupyterClient(test, namespace.Name, notebook.GetName())
+	test.Eventually(func() error {
+		return jupyter.UploadNotebook("notebook_execution.ipynb", ReadFile(test, "notebook_execution.ipynb"))
+	}, TestTimeoutMedium).Should(Succeed())
+
+	// Run the notebook cells with a kernel, any cell error fails the test
+	kernelID := startKernel(test, jupyter)
+	results := RunJupyterNotebook(test, jupyter, kernelID, "notebook_execution.ipynb", TestTimeoutMedium)
+
+	hyperparameters := GetNotebookHyperparameters(test)
+	test.Expect(results).To(HaveLen(3))
+	test.Expect(results[0].Stdout).To(Equal(fmt.Sprintf("epochs=%d\n", hyperparameters.Epochs)))
+	test.Expect(results[1].Stdout).To(ContainSubstring(fmt.Sprintf("epoch %d loss=", hyperparameters.Epochs)))
+	test.Expect(results[2].Stdout).To(Equal("NOTEBOOK EXECUTION DONE\n"))
+}
@@ -0,0 +1,157 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package odh
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	. "github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestNotebookIdleRayClusterGpuRelease leaves a RayCluster with GPUs created from a notebook idle, and makes sure the
+// idle notification or auto-down path configured in the cluster releases its GPUs and quota once the configured
+// period elapses, and emits the configured events.
+func TestNotebookIdleRayClusterGpuRelease(t *testing.T) {
+	Track(t, LabelNotebook, LabelGpu)
+	test := MustGather(WithHooks(t))
+
+	period, ok := GetIdleRayClusterPeriod(test)
+	if !ok {
+		test.T().Skip("IDLE_RAYCLUSTER_PERIOD isn't set")
+	}
+	if len(GetNvidiaGpuNodes(test)) == 0 {
+		test.T().Skip("No NVIDIA GPU node available in the cluster")
+	}
+	AcquireGpuLease(test)
+
+	// Create a namespace
+	namespace := test.NewTestNamespace()
+	events := RecordEvents(test, namespace.Name)
+
+	// Create a service account the SDK authenticates with
+	token := createSdkUserToken(test, namespace.Name)
+
+	// Create the Notebook and start a kernel in it
+	jupyter, kernelID := startNotebookKernel(test, namespace.Name, "notebook-idle")
+
+	// Log in and bring up a cluster with a GPU worker, then leave it idle
+	result, err := jupyter.Execute(kernelID, fmt.Sprintf(`
+from codeflare_sdk import Cluster, ClusterConfiguration, TokenAuthentication
+auth = TokenAuthentication(token=%q, server=%q, skip_tls=True)
+auth.login()
+cluster = Cluster(ClusterConfiguration(
+    name="idle",
+    namespace=%q,
+    num_workers=1,
+    min_cpus=1,
+    max_cpus=1,
+    min_memory=4,
+    max_memory=4,
+    num_gpus=1,
+    image=%q,
+    write_to_file=False,
+))
+cluster.up()
+`, token, GetClusterApiUrl(test), namespace.Name, GetRayImage()), TestTimeoutMedium)
+	ExpectNoError(test, err, "executing step in", Ref("Jupyter kernel", "", kernelID))
+	test.Expect(result.Error).To(BeNil(), "cluster.up() failed: %v", result.Error)
+
+	EventuallyOf(test, RayCluster(test, namespace.Name, "idle"), TestTimeoutLong).
+		Should(Field(RayClusterState).Equal(rayv1.Ready))
+	test.Expect(gpuPods(test, namespace.Name)).NotTo(BeEmpty(), "RayCluster is ready without pods holding GPUs")
+	idleSince := time.Now()
+	test.T().Logf("RayCluster %s/idle is ready, leaving it idle for %s", namespace.Name, period)
+
+	// Make sure the cluster isn't released long before the configured period elapses
+	test.Consistently(rayClusterReleased(test, namespace.Name, "idle"), period/2).Should(BeFalse(),
+		"RayCluster was released before the idle period of %s elapsed", period)
+
+	// Make sure the cluster is released once the period elapses
+	test.Eventually(rayClusterReleased(test, namespace.Name, "idle"), period/2+TestTimeoutLong).Should(BeTrue(),
+		"RayCluster wasn't released after being idle for %s", period)
+	test.T().Logf("RayCluster %s/idle released after being idle for %s", namespace.Name, time.Since(idleSince).Round(time.Second))
+
+	// Make sure the GPUs and the quota held by the cluster are released
+	test.Eventually(func() []string { return gpuPods(test, namespace.Name) }, TestTimeoutMedium).Should(BeEmpty())
+	test.Eventually(func(g Gomega) []string {
+		workloads, err := test.Client().Kueue().KueueV1beta1().Workloads(namespace.Name).List(test.Ctx(), metav1.ListOptions{})
+		g.Expect(WrapError(err, "listing", Ref("Workloads", namespace.Name, ""))).NotTo(HaveOccurred())
+		var admitted []string
+		for i := range workloads.Items {
+			if KueueWorkloadAdmitted(&workloads.Items[i]) {
+				admitted = append(admitted, workloads.Items[i].Name)
+			}
+		}
+		return admitted
+	}, TestTimeoutMedium).Should(BeEmpty(), "Workloads of the idle RayCluster still hold quota")
+
+	// Make sure the release is reported with the configured events
+	for _, reason := range GetIdleRayClusterEvents() {
+		test.Eventually(events.Events, TestTimeoutShort).Should(HaveEvent(reason),
+			"Event %s wasn't emitted when releasing the idle RayCluster", reason)
+	}
+}
+
+// rayClusterReleased returns whether the RayCluster doesn't hold its workers anymore, either being deleted,
+// suspended or scaled down.
+func rayClusterReleased(t Test, namespace, name string) func(g Gomega) bool {
+	return func(g Gomega) bool {
+		cluster, err := t.Client().Ray().RayV1().RayClusters(namespace).Get(t.Ctx(), name, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			return true
+		}
+		g.Expect(WrapError(err, "getting", Ref("RayCluster", namespace, name))).NotTo(HaveOccurred())
+		if cluster.Spec.Suspend != nil && *cluster.Spec.Suspend || cluster.Status.State == rayv1.Suspended {
+			return true
+		}
+		for _, group := range cluster.Spec.WorkerGroupSpecs {
+			if group.Replicas == nil || *group.Replicas > 0 {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// gpuPods returns the names of the running or pending pods in the namespace requesting NVIDIA GPUs.
+func gpuPods(t Test, namespace string) []string {
+	t.T().Helper()
+	pods, err := t.Client().Core().CoreV1().Pods(namespace).List(t.Ctx(), metav1.ListOptions{})
+	ExpectNoError(t, err, "listing", Ref("Pods", namespace, ""))
+	var names []string
+	for _, pod := range pods.Items {
+		if pod.Status.Phase == corev1.PodSucceeded || pod.Status.Phase == corev1.PodFailed {
+			continue
+		}
+		for _, container := range pod.Spec.Containers {
+			if gpus := container.Resources.Limits[NvidiaGpuResource]; !gpus.IsZero() {
+				names = append(names, pod.Name)
+				break
+			}
+		}
+	}
+	return names
+}
@@ -0,0 +1,120 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package odh
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+)
+
+func TestNotebookKernelExecution(t *testing.T) {
+	Track(t, LabelNotebook)
+	test := MustGather(WithHooks(t))
+
+	// Create a namespace
+	namespace := test.NewTestNamespace()
+
+	// Create the Notebook and start a kernel in it
+	jupyter, kernelID := startNotebookKernel(test, namespace.Name, "notebook-kernel")
+
+	// Execute the notebook steps one by one, the kernel state is kept between the steps
+	result, err := jupyter.Execute(kernelID, "answer = 21", TestTimeoutShort)
+	ExpectNoError(test, err, "executing step in", Ref("Jupyter kernel", "", kernelID))
+	test.Expect(result.Error).To(BeNil())
+
+	result, err = jupyter.Execute(kernelID, "print(answer * 2)", TestTimeoutShort)
+	ExpectNoError(test, err, "executing step in", Ref("Jupyter kernel", "", kernelID))
+	test.Expect(result.Stdout).To(Equal("42\n"))
+
+	result, err = jupyter.Execute(kernelID, "import os\nos.getcwd()", TestTimeoutShort)
+	ExpectNoError(test, err, "executing step in", Ref("Jupyter kernel", "", kernelID))
+	test.Expect(result.Result).To(Equal(fmt.Sprintf("'%s'", NotebookWorkspaceMountPath)))
+
+	// Make sure the training hyperparameters are injected into the notebook
+	hyperparameters := GetNotebookHyperparameters(test)
+	result, err = jupyter.Execute(kernelID, "print(os.environ['EPOCHS'], os.environ['BATCH_SIZE'])", TestTimeoutShort)
+	ExpectNoError(test, err, "executing step in", Ref("Jupyter kernel", "", kernelID))
+	test.Expect(result.Stdout).To(Equal(fmt.Sprintf("%d %d\n", hyperparameters.Epochs, hyperparameters.BatchSize)))
+
+	// Make sure errors raised by a step are reported
+	result, err = jupyter.Execute(kernelID, "answer / 0", TestTimeoutShort)
+	ExpectNoError(test, err, "executing step in", Ref("Jupyter kernel", "", kernelID))
+	test.Expect(result.Error).NotTo(BeNil())
+	test.Expect(result.Error.Name).To(Equal("ZeroDivisionError"))
+}
+
+// startNotebookKernel creates a Notebook with the environment variables, exposes its Jupyter server and starts a Python kernel in it.
+// The kernel is shut down when the test finishes.
+func startNotebookKernel(test Test, namespace, name string, env ...corev1.EnvVar) (JupyterClient, string) {
+	test.T().Helper()
+
+	workspacePvc := CreatePersistentVolumeClaimWithStorageClass(test, namespace, "1Gi", GetStorageClass(), corev1.ReadWriteOnce)
+	notebook := CreateNotebook(test, namespace, name, newNotebookContainer(env...), workspacePvc.Name)
+	test.Eventually(NotebookPods(test, namespace, notebook.GetName()), TestTimeoutLong).
+		Should(And(HaveLen(1), ContainElement(Satisfy(PodRunningAndReady))))
+
+	// Expose the Jupyter server and start a kernel
+	jupyterURL := ExposeService(test, name, namespace, notebook.GetName(), "http-"+notebook.GetName())
+	jupyterURL.Path = fmt.Sprintf("/notebook/%s/%s", namespace, notebook.GetName())
+	jupyter := NewJupyterClient(jupyterURL, "")
+
+	return jupyter, startKernel(test, jupyter)
+}
+
+func newNotebookContainer(env ...corev1.EnvVar) corev1.Container {
+	return corev1.Container{
+		Image: GetNotebookImage(),
+		Env:   env,
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("500m"),
+				corev1.ResourceMemory: resource.MustParse("1Gi"),
+			},
+			Limits: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("1"),
+				corev1.ResourceMemory: resource.MustParse("2Gi"),
+			},
+		},
+	}
+}
+
+// startKernel starts a Python kernel in the Jupyter server, once it's ready, the kernel is shut down when the test finishes.
+func startKernel(test Test, jupyter JupyterClient) string {
+	test.T().Helper()
+
+	var kernelID string
+	test.Eventually(func(g Gomega) {
+		var err error
+		kernelID, err = jupyter.StartKernel("python3")
+		g.Expect(WrapError(err, "starting", Ref("Jupyter kernel", "", "python3"))).NotTo(HaveOccurred())
+	}, TestTimeoutMedium).Should(Succeed())
+	test.T().Logf("Started kernel %s", kernelID)
+	test.T().Cleanup(func() {
+		if err := jupyter.ShutdownKernel(kernelID); err != nil {
+			test.T().Logf("Error shutting down kernel %s: %v", kernelID, err)
+		}
+	})
+
+	return kernelID
+}
@@ -0,0 +1,123 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package odh
+
+import (
+	"fmt"
+	"regexp"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	. "github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Output of the SDK reporting the cluster already exists
+var alreadyExistsRegexp = regexp.MustCompile(`(?i)already exists|conflict|409`)
+
+// TestNotebookSdkDoubleClusterUp calls cluster.up() twice from a notebook, a common user mistake, and makes sure
+// the second call either is a no-op or reports a clear error, leaving a single complete cluster behind.
+func TestNotebookSdkDoubleClusterUp(t *testing.T) {
+	Track(t, LabelNotebook)
+	test := MustGather(WithHooks(t))
+
+	// Create a namespace
+	namespace := test.NewTestNamespace()
+
+	// Create a service account the SDK authenticates with
+	token := createSdkUserToken(test, namespace.Name)
+
+	// Create the Notebook and start a kernel in it, with the SDK under test installed from a local package index
+	// when its wheels are set, instead of the SDK shipped in the notebook image
+	pypi, withSdkUnderTest := DeployCodeFlareSdkIndex(test, namespace.Name)
+	jupyter, kernelID := startNotebookKernel(test, namespace.Name, "notebook-sdk", pypi.Env()...)
+	if withSdkUnderTest {
+		result, err := jupyter.Execute(kernelID, "%pip install --quiet --upgrade "+GetCodeFlareSdkPackage(), TestTimeoutMedium)
+		ExpectNoError(test, err, "executing step in", Ref("Jupyter kernel", "", kernelID))
+		test.Expect(result.Error).To(BeNil(), "Installing the SDK under test failed: %v\n%s", result.Error, result.Stderr)
+	}
+
+	// Log in and bring the cluster up
+	result, err := jupyter.Execute(kernelID, fmt.Sprintf(`
+from codeflare_sdk import Cluster, ClusterConfiguration, TokenAuthentication
+auth = TokenAuthentication(token=%q, server=%q, skip_tls=True)
+auth.login()
+cluster = Cluster(ClusterConfiguration(
+    name="double-up",
+    namespace=%q,
+    num_workers=1,
+    min_cpus=1,
+    max_cpus=1,
+    min_memory=2,
+    max_memory=2,
+    image=%q,
+    write_to_file=False,
+))
+cluster.up()
+`, token, GetClusterApiUrl(test), namespace.Name, GetRayImage()), TestTimeoutMedium)
+	ExpectNoError(test, err, "executing step in", Ref("Jupyter kernel", "", kernelID))
+	test.Expect(result.Error).To(BeNil(), "First cluster.up() failed: %v", result.Error)
+
+	test.Eventually(RayCluster(test, namespace.Name, "double-up"), TestTimeoutMedium).ShouldNot(BeNil())
+	rayClusterUID := GetRayCluster(test, namespace.Name, "double-up").UID
+	appWrapperUID := AppWrapperUID(test, namespace.Name, "double-up")
+
+	// Call cluster.up() again, twice, to check the outcome is the same each time
+	outcomes := make([]string, 2)
+	for i := range outcomes {
+		result, err := jupyter.Execute(kernelID, "cluster.up()", TestTimeoutMedium)
+		ExpectNoError(test, err, "executing step in", Ref("Jupyter kernel", "", kernelID))
+		outcomes[i] = doubleUpOutcome(result)
+		test.T().Logf("Repeated cluster.up() outcome: %s\nstdout: %s\nstderr: %s", outcomes[i], result.Stdout, result.Stderr)
+		if result.Error != nil {
+			test.Expect(result.Error.Value).To(MatchRegexp(alreadyExistsRegexp.String()),
+				"Repeated cluster.up() failed with unclear error: %v", result.Error)
+		}
+	}
+	test.Expect(outcomes[1]).To(Equal(outcomes[0]), "Repeated cluster.up() isn't deterministic")
+
+	// Make sure the original resources are kept, with no half-created duplicates
+	rayClusters, err := test.Client().Ray().RayV1().RayClusters(namespace.Name).List(test.Ctx(), metav1.ListOptions{})
+	ExpectNoError(test, err, "listing", Ref("RayClusters", namespace.Name, ""))
+	test.Expect(rayClusters.Items).To(HaveLen(1))
+	test.Expect(rayClusters.Items[0].UID).To(Equal(rayClusterUID), "RayCluster was recreated by repeated cluster.up()")
+	test.Expect(AppWrapperUID(test, namespace.Name, "double-up")).To(Equal(appWrapperUID), "AppWrapper was recreated by repeated cluster.up()")
+
+	// Make sure the cluster gets ready and is torn down cleanly
+	EventuallyOf(test, RayCluster(test, namespace.Name, "double-up"), TestTimeoutLong).
+		Should(Field(RayClusterState).Equal(rayv1.Ready))
+
+	result, err = jupyter.Execute(kernelID, "cluster.down()", TestTimeoutMedium)
+	ExpectNoError(test, err, "executing step in", Ref("Jupyter kernel", "", kernelID))
+	test.Expect(result.Error).To(BeNil(), "cluster.down() failed: %v", result.Error)
+	WaitForDeletionOf(test, rayv1.SchemeGroupVersion.WithResource("rayclusters"), namespace.Name, metav1.ListOptions{}, TestTimeoutMedium)
+}
+
+// doubleUpOutcome classifies the result of a repeated cluster.up() call.
+func doubleUpOutcome(result *JupyterExecutionResult) string {
+	switch {
+	case result.Error != nil:
+		return "raised " + result.Error.Name
+	case alreadyExistsRegexp.MatchString(result.Stdout + result.Stderr):
+		return "reported already exists"
+	default:
+		return "no-op"
+	}
+}
@@ -0,0 +1,185 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package odh
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const notebookStartsLog = "notebook-starts.log"
+
+func TestNotebookUpdateWithoutDataLoss(t *testing.T) {
+	Track(t, LabelNotebook)
+	test := MustGather(WithHooks(t))
+
+	// Create a namespace
+	namespace := test.NewTestNamespace()
+
+	// Create the Notebook with workspace PVC, each Notebook start is recorded into the workspace
+	workspacePvc := CreatePersistentVolumeClaimWithStorageClass(test, namespace.Name, "1Gi", GetStorageClass(), corev1.ReadWriteOnce)
+	container := corev1.Container{
+		Image: GetNotebookImage(),
+		Resources: corev1.ResourceRequirements{
+			Requests: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("500m"),
+				corev1.ResourceMemory: resource.MustParse("1Gi"),
+			},
+			Limits: corev1.ResourceList{
+				corev1.ResourceCPU:    resource.MustParse("500m"),
+				corev1.ResourceMemory: resource.MustParse("1Gi"),
+			},
+		},
+		Lifecycle: &corev1.Lifecycle{
+			PostStart: &corev1.LifecycleHandler{
+				Exec: &corev1.ExecAction{
+					Command: []string{"sh", "-c", fmt.Sprintf("date >> %s/%s", NotebookWorkspaceMountPath, notebookStartsLog)},
+				},
+			},
+		},
+	}
+	notebook := CreateNotebook(test, namespace.Name, "notebook-update", container, workspacePvc.Name)
+
+	test.Eventually(NotebookPods(test, namespace.Name, notebook.GetName()), TestTimeoutLong).
+		Should(And(HaveLen(1), ContainElement(Satisfy(PodRunningAndReady))))
+	notebookPod := NotebookPods(test, namespace.Name, notebook.GetName())(test)[0]
+
+	// Submit a workload from the Notebook namespace, it must not be affected by the Notebook update
+	workload := createSleepingJob(test, namespace.Name)
+	test.Eventually(JobPods(test, namespace.Name, workload.Name), TestTimeoutMedium).
+		Should(And(HaveLen(1), ContainElement(Satisfy(PodRunningAndReady))))
+	workloadPod := JobPods(test, namespace.Name, workload.Name)(test)[0]
+
+	// Resize the Notebook and change its image
+	patch := fmt.Sprintf(`[
+		{"op": "replace", "path": "/spec/template/spec/containers/0/image", "value": %q},
+		{"op": "replace", "path": "/spec/template/spec/containers/0/resources", "value": {"requests": {"cpu": "1", "memory": "2Gi"}, "limits": {"cpu": "1", "memory": "2Gi"}}}
+	]`, GetNotebookUpdateImage())
+	PatchNotebook(test, namespace.Name, notebook.GetName(), []byte(patch))
+
+	// Make sure the Notebook pod is restarted with the updated specification
+	EventuallyOf(test, NotebookPods(test, namespace.Name, notebook.GetName()), TestTimeoutLong).
+		Should(HaveLenOf[corev1.Pod](1).And(ContainElementOf(
+			Field(PodRunningAndReady).Equal(true).
+				And(Field(podUID).Matches(Not(Equal(podUID(notebookPod))))),
+		)))
+	updatedPod := NotebookPods(test, namespace.Name, notebook.GetName())(test)[0]
+	test.Expect(updatedPod.Spec.Containers[0].Image).To(Equal(GetNotebookUpdateImage()))
+	test.Expect(updatedPod.Spec.Containers[0].Resources.Limits.Memory().String()).To(Equal("2Gi"))
+	test.Expect(updatedPod.Spec.Containers[0].Resources.Limits.Cpu().String()).To(Equal("1"))
+
+	// Make sure the in-flight workload kept running
+	ExpectOf(test, GetPod(test, namespace.Name, workloadPod.Name)).
+		To(Field(func(pod *corev1.Pod) bool { return PodRunningAndReady(*pod) }).Equal(true).
+			And(Field(func(pod *corev1.Pod) int32 { return pod.Status.ContainerStatuses[0].RestartCount }).Equal(0)))
+
+	// Stop the Notebook and verify the workspace content persisted across the restart
+	DeleteNotebook(test, namespace.Name, notebook.GetName())
+	WaitForDeletionOf(test, corev1.SchemeGroupVersion.WithResource("pods"), namespace.Name, metav1.ListOptions{LabelSelector: NotebookNameLabel + "=" + notebook.GetName()}, TestTimeoutMedium)
+
+	logs := readWorkspaceFile(test, namespace.Name, workspacePvc.Name, notebookStartsLog)
+	test.Expect(strings.Split(strings.TrimSpace(logs), "\n")).To(HaveLen(2), "Expected workspace to record both Notebook starts, got:\n%s", logs)
+}
+
+func podUID(pod corev1.Pod) string {
+	return string(pod.UID)
+}
+
+func createSleepingJob(test Test, namespace string) *batchv1.Job {
+	test.T().Helper()
+
+	job := &batchv1.Job{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "workload-",
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: Ptr(int32(0)),
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers: []corev1.Container{
+						{
+							Name:    "workload",
+							Image:   GetNotebookImage(),
+							Command: []string{"sleep", "3600"},
+						},
+					},
+				},
+			},
+		},
+	}
+
+	created, err := test.Client().Core().BatchV1().Jobs(namespace).Create(test.Ctx(), job, metav1.CreateOptions{})
+	ExpectNoError(test, err, "creating", Ref("Job", namespace, job.Name+job.GenerateName))
+	job = created
+	test.T().Logf("Created Job %s/%s successfully", job.Namespace, job.Name)
+
+	return job
+}
+
+// readWorkspaceFile returns content of the file stored in the workspace PVC, the PVC must not be used by the Notebook.
+func readWorkspaceFile(test Test, namespace, pvcName, fileName string) string {
+	test.T().Helper()
+
+	pod := CreatePod(test, &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "workspace-reader-",
+			Namespace:    namespace,
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			Containers: []corev1.Container{
+				{
+					Name:    "reader",
+					Image:   GetNotebookImage(),
+					Command: []string{"cat", "/workspace/" + fileName},
+					VolumeMounts: []corev1.VolumeMount{
+						{
+							Name:      "workspace",
+							MountPath: "/workspace",
+						},
+					},
+				},
+			},
+			Volumes: []corev1.Volume{
+				{
+					Name: "workspace",
+					VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+							ClaimName: pvcName,
+						},
+					},
+				},
+			},
+		},
+	})
+
+	EventuallyOf(test, Pod(test, namespace, pod.Name), TestTimeoutMedium).
+		Should(Field(PodPhase).Equal(corev1.PodSucceeded))
+
+	return string(GetPodLogs(test, pod, corev1.PodLogOptions{}))
+}
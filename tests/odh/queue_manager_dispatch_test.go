@@ -0,0 +1,89 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package odh
+
+import (
+	"testing"
+
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestQueuedJobDispatch submits Jobs through the queue manager of the cluster, Kueue or MCAD as set with QUEUE_MANAGER,
+// and checks the Job fitting the quota is dispatched to completion while the Job exceeding it stays queued.
+func TestQueuedJobDispatch(t *testing.T) {
+	Track(t)
+	test := MustGather(WithHooks(t))
+
+	// Create a namespace
+	namespace := test.NewTestNamespace()
+
+	// Create the queue manager dispatching the workloads of the namespace
+	queueManager := NewQueueManager(test, namespace.Name, corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("1"),
+		corev1.ResourceMemory: resource.MustParse("1Gi"),
+	})
+	test.T().Logf("Dispatching workloads with %s", queueManager.Name())
+
+	// Make sure the Job fitting the quota is admitted and runs to completion
+	fitting := queueManager.Submit(test, newDispatchJob(namespace.Name, "fitting-", "500m"))
+	queueManager.WaitAdmitted(test, fitting, TestTimeoutMedium)
+	queueManager.WaitCompleted(test, fitting, TestTimeoutMedium)
+
+	// Make sure the Job exceeding the quota, and the capacity of any cluster, stays queued
+	oversized := queueManager.Submit(test, newDispatchJob(namespace.Name, "oversized-", "1000"))
+	queueManager.ExpectQueued(test, oversized)
+}
+
+func newDispatchJob(namespace, generateName, cpu string) *batchv1.Job {
+	return &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: batchv1.SchemeGroupVersion.String(),
+			Kind:       "Job",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: generateName,
+			Namespace:    namespace,
+		},
+		Spec: batchv1.JobSpec{
+			BackoffLimit: Ptr(int32(0)),
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					Containers: []corev1.Container{
+						{
+							Name:    "job",
+							Image:   GetToolsImage(),
+							Command: []string{"sh", "-c", "echo 'Dispatched by the queue manager'"},
+							Resources: corev1.ResourceRequirements{
+								Requests: corev1.ResourceList{
+									corev1.ResourceCPU:    resource.MustParse(cpu),
+									corev1.ResourceMemory: resource.MustParse("128Mi"),
+								},
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+}
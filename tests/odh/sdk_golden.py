@@ -0,0 +1,38 @@
+import json
+import os
+
+import yaml
+from codeflare_sdk import Cluster, ClusterConfiguration, TokenAuthentication
+
+auth = TokenAuthentication(token=os.environ["TOKEN"], server=os.environ["SERVER"], skip_tls=True)
+auth.login()
+
+
+def generate(appwrapper):
+    # Fixed configuration, so the generated resources only change with the SDK
+    cluster = Cluster(ClusterConfiguration(
+        name="sdk-golden",
+        namespace=os.environ["NAMESPACE"],
+        num_workers=2,
+        min_cpus=1,
+        max_cpus=2,
+        min_memory=2,
+        max_memory=4,
+        num_gpus=0,
+        image="quay.io/project-codeflare/ray:golden",
+        local_queue=os.environ["LOCAL_QUEUE"],
+        appwrapper=appwrapper,
+        write_to_file=False,
+    ))
+    # The generated resource is exposed as resource_yaml by recent SDK versions, and as app_wrapper_yaml before
+    resource = getattr(cluster, "resource_yaml", None) or cluster.app_wrapper_yaml
+    if isinstance(resource, str):
+        if os.path.exists(resource):
+            with open(resource) as f:
+                return yaml.safe_load(f)
+        return yaml.safe_load(resource)
+    return resource
+
+
+for kind, appwrapper in [("appwrapper", True), ("raycluster", False)]:
+    print(f"SDK_GOLDEN {kind} " + json.dumps(generate(appwrapper)), flush=True)
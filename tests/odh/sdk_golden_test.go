@@ -0,0 +1,69 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package odh
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// Resources generated by sdk_golden.py, compared with the golden files of the same name
+var sdkGoldenKinds = []string{"appwrapper", "raycluster"}
+
+// TestCodeFlareSdkGoldenSpecs compares the AppWrapper and RayCluster the CodeFlare SDK generates from a fixed
+// ClusterConfiguration with golden files, so spec changes coming with SDK bumps are reviewed instead of discovered
+// at runtime. Run it with UPDATE_GOLDEN_FILES=true to accept the changes.
+func TestCodeFlareSdkGoldenSpecs(t *testing.T) {
+	Track(t)
+	test := MustGather(WithHooks(t))
+
+	// Create a namespace
+	namespace := test.NewTestNamespace()
+
+	// Create a local queue the SDK labels the resources with, it doesn't have to admit anything
+	localQueue := CreateKueueLocalQueue(test, namespace.Name, "sdk-golden")
+
+	// Generate the resources with the SDK, without creating them
+	pod := runSdkScript(test, namespace.Name, "sdk_golden.py", corev1.EnvVar{Name: "LOCAL_QUEUE", Value: localQueue.Name})
+	logs := string(GetPodLogs(test, pod, corev1.PodLogOptions{}))
+	ExpectOf(test, pod).To(Field(PodPhase).Equal(corev1.PodSucceeded), "Generating resources with the SDK failed, logs:\n%s", logs)
+
+	specs := map[string]string{}
+	for _, line := range strings.Split(logs, "\n") {
+		if payload, ok := strings.CutPrefix(line, "SDK_GOLDEN "); ok {
+			kind, spec, _ := strings.Cut(payload, " ")
+			specs[kind] = spec
+		}
+	}
+
+	// The test namespace and local queue are random, they are replaced with placeholders
+	replacements := map[string]string{
+		"NAMESPACE":   namespace.Name,
+		"LOCAL_QUEUE": localQueue.Name,
+	}
+	for _, kind := range sdkGoldenKinds {
+		test.Expect(specs).To(HaveKey(kind), "SDK didn't generate %s, logs:\n%s", kind, logs)
+		ExpectGoldenSpec(test, filepath.Join("testdata", "sdk-golden", kind+".yaml"), []byte(specs[kind]), replacements)
+	}
+}
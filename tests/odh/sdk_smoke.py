@@ -0,0 +1,49 @@
+import os
+import sys
+import time
+
+from codeflare_sdk import Cluster, ClusterConfiguration, TokenAuthentication
+from ray.job_submission import JobStatus
+
+
+def step(name):
+    print(f"SDK_SMOKE {name} OK", flush=True)
+
+
+auth = TokenAuthentication(token=os.environ["TOKEN"], server=os.environ["SERVER"], skip_tls=True)
+auth.login()
+step("login")
+
+cluster = Cluster(ClusterConfiguration(
+    name="sdk-smoke",
+    namespace=os.environ["NAMESPACE"],
+    num_workers=1,
+    min_cpus=1,
+    max_cpus=1,
+    min_memory=2,
+    max_memory=2,
+    image=os.environ["RAY_IMAGE"],
+    write_to_file=False,
+))
+
+timeout = int(os.environ["TIMEOUT_SECONDS"])
+try:
+    cluster.up()
+    step("up")
+    cluster.wait_ready(timeout=timeout)
+    step("ready")
+
+    client = cluster.job_client
+    submission_id = client.submit_job(entrypoint="python -c 'import ray; ray.init(); print(ray.cluster_resources())'")
+    deadline = time.monotonic() + timeout
+    status = client.get_job_status(submission_id)
+    while not status.is_terminal() and time.monotonic() < deadline:
+        time.sleep(5)
+        status = client.get_job_status(submission_id)
+    print(client.get_job_logs(submission_id), flush=True)
+    if status != JobStatus.SUCCEEDED:
+        sys.exit(f"Ray job {submission_id} finished with status {status}")
+    step("job")
+finally:
+    cluster.down()
+    step("down")
@@ -0,0 +1,58 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package odh
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	. "github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Steps reported by sdk_smoke.py once they succeed
+var sdkSmokeSteps = []string{"login", "up", "ready", "job", "down"}
+
+// TestCodeFlareSdkSmoke runs the CodeFlare SDK from a plain pod to create a tiny Ray cluster, submit a job to it
+// and tear it down. It doesn't need a Notebook, so it's the quickest signal of SDK and operator compatibility.
+func TestCodeFlareSdkSmoke(t *testing.T) {
+	Track(t, LabelTier1)
+	test := MustGather(WithHooks(t))
+
+	// Create a namespace
+	namespace := test.NewTestNamespace()
+
+	// Run the script creating a Ray cluster with the SDK, submitting a job to it and tearing it down
+	pod := runSdkScript(test, namespace.Name, "sdk_smoke.py",
+		corev1.EnvVar{Name: "RAY_IMAGE", Value: GetRayImage()},
+		corev1.EnvVar{Name: "TIMEOUT_SECONDS", Value: fmt.Sprint(int(TestTimeoutMedium.Seconds()))})
+
+	// Make sure the script completes all the steps
+	logs := string(GetPodLogs(test, pod, corev1.PodLogOptions{}))
+	for _, step := range sdkSmokeSteps {
+		test.Expect(logs).To(ContainSubstring("SDK_SMOKE "+step+" OK"), "SDK step %q didn't succeed, logs:\n%s", step, logs)
+	}
+	ExpectOf(test, GetPod(test, namespace.Name, pod.Name)).To(Field(PodPhase).Equal(corev1.PodSucceeded))
+
+	// Make sure cluster.down() removed the RayCluster
+	WaitForDeletionOf(test, rayv1.SchemeGroupVersion.WithResource("rayclusters"), namespace.Name, metav1.ListOptions{}, TestTimeoutMedium)
+}
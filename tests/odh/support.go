@@ -0,0 +1,153 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package odh
+
+import (
+	"embed"
+	"strings"
+
+	"github.com/onsi/gomega"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	. "github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+//go:embed *.py *.ipynb
+var files embed.FS
+
+func ReadFile(t Test, fileName string) []byte {
+	t.T().Helper()
+	file, err := files.ReadFile(fileName)
+	t.Expect(err).NotTo(gomega.HaveOccurred())
+	return file
+}
+
+// createSdkUserToken creates a service account with the permissions CodeFlare SDK needs to manage Ray clusters
+// in the namespace, and returns its token the SDK authenticates with.
+func createSdkUserToken(t Test, namespace string) string {
+	t.T().Helper()
+
+	serviceAccount := CreateServiceAccount(t, namespace)
+	role := CreateRole(t, namespace, []rbacv1.PolicyRule{
+		{
+			Verbs:     []string{"get", "list", "watch", "create", "update", "patch", "delete"},
+			APIGroups: []string{rayv1.GroupVersion.Group},
+			Resources: []string{"rayclusters", "rayclusters/status"},
+		},
+		{
+			Verbs:     []string{"get", "list", "watch", "create", "update", "patch", "delete"},
+			APIGroups: []string{"workload.codeflare.dev"},
+			Resources: []string{"appwrappers"},
+		},
+		{
+			Verbs:     []string{"get", "list", "watch"},
+			APIGroups: []string{"kueue.x-k8s.io"},
+			Resources: []string{"localqueues", "workloads"},
+		},
+		{
+			Verbs:     []string{"get", "list", "watch", "create", "delete"},
+			APIGroups: []string{"route.openshift.io", "networking.k8s.io"},
+			Resources: []string{"routes", "ingresses"},
+		},
+		{
+			Verbs:     []string{"get", "list", "watch"},
+			APIGroups: []string{""},
+			Resources: []string{"pods", "services", "secrets"},
+		},
+	})
+	CreateRoleBinding(t, namespace, serviceAccount, role)
+	return CreateTrackedToken(t, namespace, serviceAccount)
+}
+
+// runSdkScript runs the script in a pod from the Ray image, with the CodeFlare SDK under test installed on top of the
+// Ray version shipped in the image. The script authenticates with the TOKEN and SERVER environment variables, and
+// manages Ray clusters in the NAMESPACE one. It returns the pod once the script finishes.
+func runSdkScript(t Test, namespace, script string, env ...corev1.EnvVar) *corev1.Pod {
+	t.T().Helper()
+
+	// Create a service account the SDK authenticates with
+	token := createSdkUserToken(t, namespace)
+
+	// Create a ConfigMap with the SDK script
+	config := CreateConfigMap(t, namespace, map[string][]byte{
+		script: ReadFile(t, script),
+	})
+
+	// Install the SDK under test from a local package index when its wheels are set
+	pipEnv := []corev1.EnvVar{{Name: "PIP_INDEX_URL", Value: GetPipIndexURL()}}
+	if pypi, ok := DeployCodeFlareSdkIndex(t, namespace); ok {
+		pipEnv = pypi.Env()
+	}
+
+	pod := CreatePod(t, &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: strings.ReplaceAll(strings.TrimSuffix(script, ".py"), "_", "-") + "-",
+			Namespace:    namespace,
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			Containers: []corev1.Container{
+				{
+					Name:  "sdk",
+					Image: GetRayImage(),
+					Command: []string{"sh", "-c", "pip install --quiet --user \"$CODEFLARE_SDK_PACKAGE\" && " +
+						"python /opt/scripts/" + script},
+					Env: append(append([]corev1.EnvVar{
+						{Name: "HOME", Value: "/tmp"},
+						{Name: "CODEFLARE_SDK_PACKAGE", Value: GetCodeFlareSdkPackage()},
+						{Name: "TOKEN", Value: token},
+						{Name: "SERVER", Value: GetClusterApiUrl(t)},
+						{Name: "NAMESPACE", Value: namespace},
+					}, pipEnv...), env...),
+					VolumeMounts: []corev1.VolumeMount{
+						{
+							Name:      "scripts",
+							MountPath: "/opt/scripts",
+						},
+					},
+					Resources: corev1.ResourceRequirements{
+						Requests: corev1.ResourceList{
+							corev1.ResourceCPU:    resource.MustParse("250m"),
+							corev1.ResourceMemory: resource.MustParse("512Mi"),
+						},
+					},
+				},
+			},
+			Volumes: []corev1.Volume{
+				{
+					Name: "scripts",
+					VolumeSource: corev1.VolumeSource{
+						ConfigMap: &corev1.ConfigMapVolumeSource{
+							LocalObjectReference: corev1.LocalObjectReference{
+								Name: config.Name,
+							},
+						},
+					},
+				},
+			},
+		},
+	})
+
+	EventuallyOf(t, Pod(t, namespace, pod.Name), TestTimeoutLong).
+		Should(Field(PodPhase).OneOf(corev1.PodSucceeded, corev1.PodFailed))
+	return GetPod(t, namespace, pod.Name)
+}
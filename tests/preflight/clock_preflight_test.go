@@ -0,0 +1,51 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	. "github.com/project-codeflare/codeflare-common/support"
+)
+
+// TestNodeClockSkew makes sure the node clocks are in sync with the test machine, skewed clocks break
+// token validity checks and make the resource timestamps misleading.
+func TestNodeClockSkew(t *testing.T) {
+	Track(t)
+	test := MustGather(WithHooks(t))
+
+	// Create a namespace
+	namespace := test.NewTestNamespace()
+
+	maxSkew := GetClockMaxSkew(test)
+	var skewed []string
+	for _, node := range GetNodes(test) {
+		if node.Spec.Unschedulable {
+			continue
+		}
+		skew := NodeClockSkew(test, namespace.Name, node)
+		test.T().Logf("Node %s clock skew is %s", node.Name, skew)
+		if skew.Abs() > maxSkew {
+			skewed = append(skewed, node.Name+": "+skew.Round(time.Millisecond).String())
+		}
+	}
+
+	test.Expect(skewed).To(BeEmpty(), "Nodes with clock skew exceeding %s", maxSkew)
+}
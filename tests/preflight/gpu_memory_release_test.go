@@ -0,0 +1,169 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// Free GPU memory, in MiB, the second pod may miss compared to the first one, i.e. allocations of the driver
+	gpuMemoryReleaseTolerance = 64
+
+	gpuMemoryQuery  = "nvidia-smi --query-gpu=uuid,memory.free,memory.total --format=csv,noheader,nounits"
+	gpuProcessQuery = "nvidia-smi --query-compute-apps=gpu_uuid,pid,used_memory --format=csv,noheader,nounits"
+)
+
+type gpuMemory struct {
+	free  int64
+	total int64
+}
+
+// TestNvidiaGpuMemoryRelease runs two GPU pods back-to-back on the same node, and makes sure the second one sees the
+// GPU memory of the first one fully released, catching GPU memory leaked by the driver or by MPS that degrades shared
+// clusters over the day.
+func TestNvidiaGpuMemoryRelease(t *testing.T) {
+	Track(t, LabelGpu)
+	test := MustGather(WithHooks(t))
+
+	// Pick the node with the fewest GPUs, as the second pod requests all of them to see the GPU of the first one
+	var node *corev1.Node
+	for _, gpuNode := range GetNvidiaGpuNodes(test) {
+		allocatable := gpuNode.Status.Allocatable[NvidiaGpuResource]
+		if allocatable.IsZero() {
+			continue
+		}
+		if node == nil || allocatable.Cmp(node.Status.Allocatable[NvidiaGpuResource]) < 0 {
+			node = gpuNode.DeepCopy()
+		}
+	}
+	if node == nil {
+		test.T().Skip("No node with allocatable NVIDIA GPU available in the cluster")
+	}
+	AcquireGpuLease(test)
+	gpus := node.Status.Allocatable[NvidiaGpuResource]
+	test.T().Logf("Running GPU pods on node %s with %d GPUs", node.Name, gpus.Value())
+
+	// Create a namespace
+	namespace := test.NewTestNamespace()
+
+	// Run the first pod, recording the free memory of its GPU before running the CUDA vectorAdd sample
+	first := runGpuMemoryProbe(test, namespace.Name, node, resource.MustParse("1"),
+		gpuMemoryQuery+" | sed 's/^/memory: /' && /cuda-samples/vectorAdd")
+	test.Expect(first).To(ContainSubstring("Test PASSED"))
+	before := parseGpuMemory(test, first)
+	test.Expect(before).To(HaveLen(1), "Expected the memory of a single GPU, got:\n%s", first)
+
+	// Run the second pod on all the GPUs of the node, once the first one terminated
+	second := runGpuMemoryProbe(test, namespace.Name, node, gpus,
+		gpuMemoryQuery+" | sed 's/^/memory: /' && "+gpuProcessQuery+" | sed 's/^/process: /'")
+	after := parseGpuMemory(test, second)
+
+	for uuid, memory := range before {
+		test.Expect(after).To(HaveKey(uuid), "GPU %s of the first pod isn't visible to the second pod on node %s", uuid, node.Name)
+		test.T().Logf("GPU %s had %d MiB free of %d MiB before the first pod, and %d MiB after", uuid, memory.free, memory.total, after[uuid].free)
+		test.Expect(after[uuid].free).To(BeNumerically(">=", memory.free-gpuMemoryReleaseTolerance),
+			"GPU memory isn't released on node %s, GPU %s has %d MiB free after the first pod terminated, out of %d MiB free before",
+			node.Name, uuid, after[uuid].free, memory.free)
+	}
+
+	// Make sure no process of another pod is left on the GPUs
+	var processes []string
+	for _, line := range strings.Split(second, "\n") {
+		if process, ok := strings.CutPrefix(line, "process: "); ok {
+			processes = append(processes, process)
+		}
+	}
+	test.Expect(processes).To(BeEmpty(), "Processes left on the GPUs of node %s", node.Name)
+}
+
+// runGpuMemoryProbe runs the script in a pod with the GPUs on the node, and returns its logs once it succeeded.
+func runGpuMemoryProbe(test Test, namespace string, node *corev1.Node, gpus resource.Quantity, script string) string {
+	test.T().Helper()
+	pod := CreatePod(test, &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "gpu-memory-",
+			Namespace:    namespace,
+		},
+		Spec: corev1.PodSpec{
+			NodeSelector: map[string]string{
+				corev1.LabelHostname: node.Labels[corev1.LabelHostname],
+			},
+			RestartPolicy: corev1.RestartPolicyNever,
+			Containers: []corev1.Container{
+				{
+					Name:    "gpu-memory",
+					Image:   GetCudaVectorAddImage(),
+					Command: []string{"sh", "-c", script},
+					Env: []corev1.EnvVar{
+						// Mounts nvidia-smi into the container
+						{Name: "NVIDIA_DRIVER_CAPABILITIES", Value: "compute,utility"},
+					},
+					Resources: corev1.ResourceRequirements{
+						Limits: corev1.ResourceList{
+							NvidiaGpuResource: gpus,
+						},
+					},
+				},
+			},
+			Tolerations: []corev1.Toleration{
+				{
+					Key:      string(NvidiaGpuResource),
+					Operator: corev1.TolerationOpExists,
+				},
+			},
+		},
+	})
+
+	EventuallyOf(test, Pod(test, namespace, pod.Name), TestTimeoutMedium).
+		Should(Field(PodPhase).OneOf(corev1.PodSucceeded, corev1.PodFailed))
+	pod = GetPod(test, namespace, pod.Name)
+	logs := string(GetPodLogs(test, pod, corev1.PodLogOptions{}))
+	ExpectOf(test, pod).To(Field(PodPhase).Equal(corev1.PodSucceeded), "GPU memory probe failed, logs:\n%s", logs)
+	return logs
+}
+
+// parseGpuMemory returns the memory of the GPUs reported by the probe, by GPU UUID.
+func parseGpuMemory(test Test, logs string) map[string]gpuMemory {
+	test.T().Helper()
+	memory := map[string]gpuMemory{}
+	for _, line := range strings.Split(logs, "\n") {
+		values, ok := strings.CutPrefix(line, "memory: ")
+		if !ok {
+			continue
+		}
+		fields := strings.Split(values, ",")
+		test.Expect(fields).To(HaveLen(3), "Unexpected nvidia-smi output %q", values)
+		free, err := strconv.ParseInt(strings.TrimSpace(fields[1]), 10, 64)
+		test.Expect(err).NotTo(HaveOccurred(), "Unexpected free memory in nvidia-smi output %q", values)
+		total, err := strconv.ParseInt(strings.TrimSpace(fields[2]), 10, 64)
+		test.Expect(err).NotTo(HaveOccurred(), "Unexpected total memory in nvidia-smi output %q", values)
+		memory[strings.TrimSpace(fields[0])] = gpuMemory{free: free, total: total}
+	}
+	test.Expect(memory).NotTo(BeEmpty(), "No GPU memory reported by nvidia-smi, logs:\n%s", logs)
+	return memory
+}
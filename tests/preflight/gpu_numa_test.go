@@ -0,0 +1,118 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const numaAlignedGpus = 2
+
+// TestNvidiaGpuNumaAlignment makes sure multi-GPU pods get GPUs attached to the same NUMA node on the nodes
+// with the single-numa-node topology manager policy, as performance-sensitive training expects.
+func TestNvidiaGpuNumaAlignment(t *testing.T) {
+	Track(t, LabelGpu)
+	test := MustGather(WithHooks(t))
+
+	var nodes []corev1.Node
+	for _, node := range GetNvidiaGpuNodes(test) {
+		allocatable := node.Status.Allocatable[NvidiaGpuResource]
+		if allocatable.Value() < numaAlignedGpus {
+			continue
+		}
+		policy := GetTopologyManagerPolicy(test, node)
+		test.T().Logf("Node %s has topology manager policy %q", node.Name, policy)
+		if policy == SingleNumaNodeTopologyPolicy {
+			nodes = append(nodes, node)
+		}
+	}
+	if len(nodes) == 0 {
+		test.T().Skipf("No node with at least %d NVIDIA GPUs and %s topology manager policy available in the cluster", numaAlignedGpus, SingleNumaNodeTopologyPolicy)
+	}
+	AcquireGpuLease(test)
+
+	// Create a namespace
+	namespace := test.NewTestNamespace()
+
+	for _, node := range nodes {
+		// Create a pod requesting multiple GPUs on the node, the kubelet rejects it when it can't align the GPUs
+		pod := CreatePod(test, &corev1.Pod{
+			ObjectMeta: metav1.ObjectMeta{
+				GenerateName: "numa-aligned-",
+				Namespace:    namespace.Name,
+			},
+			Spec: corev1.PodSpec{
+				NodeSelector: map[string]string{
+					corev1.LabelHostname: node.Labels[corev1.LabelHostname],
+				},
+				RestartPolicy: corev1.RestartPolicyNever,
+				Containers: []corev1.Container{
+					{
+						Name:    "gpus",
+						Image:   GetCudaVectorAddImage(),
+						Command: []string{"sleep", "3600"},
+						Resources: corev1.ResourceRequirements{
+							Limits: corev1.ResourceList{
+								NvidiaGpuResource: *resource.NewQuantity(numaAlignedGpus, resource.DecimalSI),
+							},
+						},
+					},
+				},
+				Tolerations: []corev1.Toleration{
+					{
+						Key:      string(NvidiaGpuResource),
+						Operator: corev1.TolerationOpExists,
+					},
+				},
+			},
+		})
+		EventuallyOf(test, Pod(test, namespace.Name, pod.Name), TestTimeoutMedium).
+			Should(Field(PodPhase).OneOf(corev1.PodRunning, corev1.PodFailed))
+		pod = GetPod(test, namespace.Name, pod.Name)
+		ExpectOf(test, pod).To(Field(PodPhase).Equal(corev1.PodRunning),
+			"Pod with %d GPUs rejected on node %s: %s", numaAlignedGpus, node.Name, pod.Status.Message)
+
+		// Make sure the GPUs assigned to the pod are attached to a single NUMA node
+		var gpus []ContainerDevices
+		for _, devices := range GetContainerDevices(test, pod, "gpus") {
+			if devices.ResourceName == string(NvidiaGpuResource) {
+				gpus = append(gpus, devices)
+			}
+		}
+		test.Expect(gpus).NotTo(BeEmpty(), "No GPU reported by the kubelet pod resources API for pod %s/%s", pod.Namespace, pod.Name)
+
+		numaNodes := map[int64][]string{}
+		var deviceIDs []string
+		for _, devices := range gpus {
+			deviceIDs = append(deviceIDs, devices.DeviceIDs...)
+			for _, numaNode := range devices.NumaNodes {
+				numaNodes[numaNode] = append(numaNodes[numaNode], devices.DeviceIDs...)
+			}
+		}
+		test.T().Logf("Pod on node %s got GPUs %v on NUMA nodes %v", node.Name, deviceIDs, numaNodes)
+		test.Expect(deviceIDs).To(HaveLen(numaAlignedGpus))
+		test.Expect(numaNodes).To(HaveLen(1), "GPUs of pod on node %s aren't aligned to a single NUMA node", node.Name)
+	}
+}
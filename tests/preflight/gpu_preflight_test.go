@@ -0,0 +1,38 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight
+
+import (
+	"testing"
+
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+)
+
+func TestNvidiaGpuPreflight(t *testing.T) {
+	Track(t, LabelGpu)
+	test := MustGather(WithHooks(t))
+
+	if len(GetNvidiaGpuNodes(test)) == 0 {
+		test.T().Skip("No NVIDIA GPU node available in the cluster")
+	}
+	AcquireGpuLease(test)
+
+	// Create a namespace
+	namespace := test.NewTestNamespace()
+
+	NvidiaGpuPreflight(test, namespace.Name)
+}
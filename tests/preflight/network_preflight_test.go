@@ -0,0 +1,90 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestNetworkBandwidth measures the bandwidth and latency between pods on the nodes designated for multi-node
+// training, so slow fabric is reported as a cluster problem instead of slow or timing out training tests.
+func TestNetworkBandwidth(t *testing.T) {
+	Track(t)
+	test := MustGather(WithHooks(t))
+
+	nodes := networkPreflightNodes(test)
+	if len(nodes) < 2 {
+		test.T().Skipf("Network pre-flight needs at least 2 nodes designated for multi-node training, found %d", len(nodes))
+	}
+
+	// Create a namespace
+	namespace := test.NewTestNamespace()
+
+	// Monitor the load of the other namespaces, contaminating the measurements
+	MonitorNoisyNeighbors(test, namespace.Name)
+
+	minBandwidth, withMinBandwidth := GetNetworkMinBandwidth(test)
+	maxLatency, withMaxLatency := GetNetworkMaxLatency(test)
+
+	// Measure each pair of consecutive nodes, so every node is probed both as server and client
+	var slow []string
+	for i, server := range nodes {
+		client := nodes[(i+1)%len(nodes)]
+		result := MeasureNetworkBandwidth(test, namespace.Name, server, client)
+		pair := fmt.Sprintf("%s->%s", client.Name, server.Name)
+		test.T().Logf("Network from node %s to node %s: %.2f Gbit/s, mean RTT %s", client.Name, server.Name, result.Gbps(), result.MeanRTT)
+		RecordMeasurement(test, pair+"/bandwidth_gbps", result.Gbps())
+		RecordMeasurement(test, pair+"/mean_rtt_ms", float64(result.MeanRTT.Microseconds())/1000)
+
+		if withMinBandwidth && result.Gbps() < minBandwidth {
+			slow = append(slow, fmt.Sprintf("%s: %.2f Gbit/s", pair, result.Gbps()))
+		}
+		if withMaxLatency && result.MeanRTT > maxLatency {
+			slow = append(slow, fmt.Sprintf("%s: mean RTT %s", pair, result.MeanRTT))
+		}
+	}
+	if !withMinBandwidth && !withMaxLatency {
+		test.T().Logf("Warning: NETWORK_MIN_BANDWIDTH and NETWORK_MAX_LATENCY aren't set, the measured network is only reported")
+	}
+
+	test.Expect(slow).To(BeEmpty(), "Network pre-flight: cluster problem, the fabric can't support multi-node training (min %.2f Gbit/s, max RTT %s)",
+		minBandwidth, maxLatency)
+}
+
+// networkPreflightNodes returns the nodes designated for multi-node training, defaulting to the nodes with NVIDIA GPUs.
+func networkPreflightNodes(test Test) []corev1.Node {
+	test.T().Helper()
+	names := GetNetworkPreflightNodes()
+	if len(names) == 0 {
+		return GetNvidiaGpuNodes(test)
+	}
+	var nodes []corev1.Node
+	for _, name := range names {
+		node, err := test.Client().Core().CoreV1().Nodes().Get(test.Ctx(), name, metav1.GetOptions{})
+		ExpectNoError(test, err, "getting", Ref("Node", "", name))
+		nodes = append(nodes, *node)
+	}
+	return nodes
+}
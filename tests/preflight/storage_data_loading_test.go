@@ -0,0 +1,211 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// The dataset is made of small files, like image classification datasets
+	dataLoadingSamples    = 2000
+	dataLoadingSampleSize = 256 * 1024
+	dataLoadingWorkers    = 4
+	dataLoadingPodLabel   = "distributed-workloads.opendatahub.io/data-loader"
+)
+
+var dataLoadingReadRegexp = regexp.MustCompile(`READ ([0-9]+) ([0-9.]+) ([0-9.]+)`)
+
+// TestRwxDataLoadingThroughput measures the samples/sec concurrent workers read a dataset at from the RWX storage classes,
+// so training slowness can be attributed to storage rather than compute.
+func TestRwxDataLoadingThroughput(t *testing.T) {
+	Track(t)
+
+	storageClasses := GetRwxStorageClasses()
+	if len(storageClasses) == 0 {
+		t.Skip("No RWX storage classes configured")
+	}
+	for _, storageClass := range storageClasses {
+		t.Run(storageClass, func(t *testing.T) {
+			measureDataLoadingThroughput(MustGather(WithHooks(t)), storageClass)
+		})
+	}
+}
+
+func measureDataLoadingThroughput(test Test, storageClass string) {
+	// Create a namespace
+	namespace := test.NewTestNamespace()
+
+	// Monitor the load of the other namespaces, contaminating the measurements
+	MonitorNoisyNeighbors(test, namespace.Name)
+
+	// Write the dataset into a RWX PVC
+	pvc := CreatePersistentVolumeClaimWithStorageClass(test, namespace.Name, "1Gi", storageClass, corev1.ReadWriteMany)
+	writer := CreatePod(test, &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "dataset-writer-",
+			Namespace:    namespace.Name,
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			Containers: []corev1.Container{
+				{
+					Name:  "writer",
+					Image: GetToolsImage(),
+					Command: []string{"sh", "-c", fmt.Sprintf(
+						"mkdir -p /data/dataset && for i in $(seq 0 %d); do head -c %d /dev/urandom > /data/dataset/sample-$i || exit 1; done",
+						dataLoadingSamples-1, dataLoadingSampleSize)},
+					VolumeMounts: []corev1.VolumeMount{{Name: "data", MountPath: "/data"}},
+				},
+			},
+			Volumes: []corev1.Volume{dataLoadingVolume(pvc.Name)},
+		},
+	})
+	EventuallyOf(test, Pod(test, namespace.Name, writer.Name), TestTimeoutMedium).
+		Should(Field(PodPhase).Equal(corev1.PodSucceeded))
+
+	// Read the dataset with concurrent workers, each reading its shard of the samples
+	job := createDataLoadingJob(test, namespace.Name, pvc.Name)
+	EventuallyOf(test, Job(test, namespace.Name, job.Name), TestTimeoutMedium).
+		Should(Field(JobConditionStatus(batchv1.JobComplete)).Equal(corev1.ConditionTrue))
+
+	// The rates are measured by each worker on its own node clock and summed, as the workers read concurrently
+	pods := JobPods(test, namespace.Name, job.Name)(test)
+	test.Expect(pods).To(HaveLen(dataLoadingWorkers))
+	samples := 0
+	samplesPerSecond := 0.0
+	for i := range pods {
+		logs := string(GetPodLogs(test, &pods[i], corev1.PodLogOptions{}))
+		match := dataLoadingReadRegexp.FindStringSubmatch(logs)
+		test.Expect(match).To(HaveLen(4), "Unexpected data loader output:\n%s", logs)
+		count, err := strconv.Atoi(match[1])
+		ExpectNoError(test, err, "parsing data loader output of", Ref("Pod", namespace.Name, pods[i].Name))
+		start, err := strconv.ParseFloat(match[2], 64)
+		ExpectNoError(test, err, "parsing data loader output of", Ref("Pod", namespace.Name, pods[i].Name))
+		end, err := strconv.ParseFloat(match[3], 64)
+		ExpectNoError(test, err, "parsing data loader output of", Ref("Pod", namespace.Name, pods[i].Name))
+		test.Expect(end).To(BeNumerically(">", start))
+		test.T().Logf("Worker %s read %d samples in %.1fs on node %s", pods[i].Name, count, end-start, pods[i].Spec.NodeName)
+		samples += count
+		samplesPerSecond += float64(count) / (end - start)
+	}
+	test.Expect(samples).To(Equal(dataLoadingSamples))
+	test.T().Logf("Storage class %q data loading throughput is %.0f samples/s (%.1f MB/s) with %d workers",
+		storageClass, samplesPerSecond, samplesPerSecond*dataLoadingSampleSize/1024/1024, dataLoadingWorkers)
+
+	if baseline, ok := GetRwxDataLoadingBaseline(test, storageClass); ok {
+		test.Expect(samplesPerSecond).To(BeNumerically(">=", baseline),
+			"Data loading throughput of storage class %q is below the baseline", storageClass)
+	}
+}
+
+func createDataLoadingJob(test Test, namespace, pvcName string) *batchv1.Job {
+	test.T().Helper()
+
+	// The workers wait for each other using marker files in the shared volume, so they read concurrently
+	script := fmt.Sprintf(`
+touch /data/ready-$JOB_COMPLETION_INDEX
+while [ $(ls /data/ready-* | wc -l) -lt %[1]d ]; do sleep 1; done
+count=0
+start=$(date +%%s.%%N)
+for i in $(seq $JOB_COMPLETION_INDEX %[1]d %[2]d); do
+  cat /data/dataset/sample-$i > /dev/null || exit 1
+  count=$((count + 1))
+done
+end=$(date +%%s.%%N)
+echo "READ $count $start $end"
+`, dataLoadingWorkers, dataLoadingSamples-1)
+
+	job := &batchv1.Job{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: batchv1.SchemeGroupVersion.String(),
+			Kind:       "Job",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "data-loader-",
+		},
+		Spec: batchv1.JobSpec{
+			Parallelism:    Ptr(int32(dataLoadingWorkers)),
+			Completions:    Ptr(int32(dataLoadingWorkers)),
+			CompletionMode: Ptr(batchv1.IndexedCompletion),
+			BackoffLimit:   Ptr(int32(0)),
+			Template: corev1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{dataLoadingPodLabel: "true"},
+				},
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					NodeSelector:  ArchitectureNodeSelector(),
+					// Spread the workers across nodes, as distributed training workers are
+					Affinity: &corev1.Affinity{
+						PodAntiAffinity: &corev1.PodAntiAffinity{
+							PreferredDuringSchedulingIgnoredDuringExecution: []corev1.WeightedPodAffinityTerm{
+								{
+									Weight: 100,
+									PodAffinityTerm: corev1.PodAffinityTerm{
+										LabelSelector: &metav1.LabelSelector{
+											MatchLabels: map[string]string{dataLoadingPodLabel: "true"},
+										},
+										TopologyKey: corev1.LabelHostname,
+									},
+								},
+							},
+						},
+					},
+					Containers: []corev1.Container{
+						{
+							Name:         "loader",
+							Image:        GetToolsImage(),
+							Command:      []string{"sh", "-c", script},
+							VolumeMounts: []corev1.VolumeMount{{Name: "data", MountPath: "/data"}},
+						},
+					},
+					Volumes: []corev1.Volume{dataLoadingVolume(pvcName)},
+				},
+			},
+		},
+	}
+
+	created, err := test.Client().Core().BatchV1().Jobs(namespace).Create(test.Ctx(), job, metav1.CreateOptions{})
+	ExpectNoError(test, err, "creating", Ref("Job", namespace, job.Name+job.GenerateName))
+	job = created
+	test.T().Logf("Created Job %s/%s successfully", job.Namespace, job.Name)
+
+	return job
+}
+
+func dataLoadingVolume(pvcName string) corev1.Volume {
+	return corev1.Volume{
+		Name: "data",
+		VolumeSource: corev1.VolumeSource{
+			PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+				ClaimName: pvcName,
+			},
+		},
+	}
+}
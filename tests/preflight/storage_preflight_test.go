@@ -0,0 +1,120 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package preflight
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	. "github.com/project-codeflare/codeflare-common/support"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Size of the file written to measure the write throughput
+const storageWriteSizeMB = 256
+
+var ddDurationRegexp = regexp.MustCompile(`copied, ([0-9.]+) s`)
+
+func TestStorageClassMatrix(t *testing.T) {
+	Track(t)
+
+	for _, storageClass := range GetStorageClasses() {
+		t.Run(storageClassTestName(storageClass, corev1.ReadWriteOnce), func(t *testing.T) {
+			validateStorageClass(MustGather(WithHooks(t)), storageClass, corev1.ReadWriteOnce)
+		})
+	}
+	for _, storageClass := range GetRwxStorageClasses() {
+		t.Run(storageClassTestName(storageClass, corev1.ReadWriteMany), func(t *testing.T) {
+			validateStorageClass(MustGather(WithHooks(t)), storageClass, corev1.ReadWriteMany)
+		})
+	}
+}
+
+// validateStorageClass provisions a PVC and checks its binding latency and write throughput against the configured thresholds.
+func validateStorageClass(test Test, storageClass string, accessMode corev1.PersistentVolumeAccessMode) {
+	// Create a namespace
+	namespace := test.NewTestNamespace()
+
+	// Create the PVC and a pod writing into it, the pod is needed for storage classes binding on first consumer
+	stopwatch := StartStopwatch()
+	pvc := CreatePersistentVolumeClaimWithStorageClass(test, namespace.Name, "1Gi", storageClass, accessMode)
+	pod := CreatePod(test, &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			GenerateName: "storage-writer-",
+			Namespace:    namespace.Name,
+		},
+		Spec: corev1.PodSpec{
+			RestartPolicy: corev1.RestartPolicyNever,
+			Containers: []corev1.Container{
+				{
+					Name:    "writer",
+					Image:   GetToolsImage(),
+					Command: []string{"sh", "-c", fmt.Sprintf("dd if=/dev/zero of=/data/test bs=1M count=%d conv=fsync 2>&1", storageWriteSizeMB)},
+					VolumeMounts: []corev1.VolumeMount{
+						{
+							Name:      "data",
+							MountPath: "/data",
+						},
+					},
+				},
+			},
+			Volumes: []corev1.Volume{
+				{
+					Name: "data",
+					VolumeSource: corev1.VolumeSource{
+						PersistentVolumeClaim: &corev1.PersistentVolumeClaimVolumeSource{
+							ClaimName: pvc.Name,
+						},
+					},
+				},
+			},
+		},
+	})
+
+	// Make sure the PVC gets bound within the threshold
+	maxBindingLatency := GetStorageMaxBindingLatency(test)
+	EventuallyOf(test, PersistentVolumeClaim(test, namespace.Name, pvc.Name), maxBindingLatency).
+		Should(Field(PersistentVolumeClaimPhase).Equal(corev1.ClaimBound),
+			"PersistentVolumeClaim with storage class %q isn't bound within %s", storageClass, maxBindingLatency)
+	test.T().Logf("PersistentVolumeClaim with storage class %q bound in %s", storageClass, stopwatch.Elapsed())
+
+	// Make sure the write throughput is above the threshold
+	EventuallyOf(test, Pod(test, namespace.Name, pod.Name), TestTimeoutMedium).
+		Should(Field(PodPhase).Equal(corev1.PodSucceeded))
+	logs := string(GetPodLogs(test, pod, corev1.PodLogOptions{}))
+	match := ddDurationRegexp.FindStringSubmatch(logs)
+	test.Expect(match).To(HaveLen(2), "Unexpected dd output:\n%s", logs)
+	seconds, err := strconv.ParseFloat(match[1], 64)
+	ExpectNoError(test, err, "parsing dd output of", Ref("Pod", namespace.Name, pod.Name))
+	throughput := storageWriteSizeMB / seconds
+	test.T().Logf("PersistentVolumeClaim with storage class %q write throughput is %.1f MB/s", storageClass, throughput)
+	test.Expect(throughput).To(BeNumerically(">=", GetStorageMinWriteThroughput(test)),
+		"Write throughput of storage class %q is below the threshold", storageClass)
+}
+
+func storageClassTestName(storageClass string, accessMode corev1.PersistentVolumeAccessMode) string {
+	if storageClass == "" {
+		storageClass = "default"
+	}
+	return fmt.Sprintf("%s/%s", storageClass, accessMode)
+}
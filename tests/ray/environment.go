@@ -0,0 +1,66 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ray
+
+import (
+	"os"
+	"strconv"
+	"time"
+
+	"github.com/onsi/gomega"
+	"github.com/project-codeflare/codeflare-common/support"
+)
+
+const (
+	// The environment variable for number of workers of the RayCluster created by scale tests
+	rayScaleWorkersEnvVar = "RAY_SCALE_WORKERS"
+	// The environment variable for baseline duration of the scaled RayCluster to get all its pods ready
+	rayScaleReadyBaselineEnvVar = "RAY_SCALE_READY_BASELINE"
+	// The environment variable for baseline duration of the scaled RayCluster to get all its pods deleted
+	rayScaleTeardownBaselineEnvVar = "RAY_SCALE_TEARDOWN_BASELINE"
+)
+
+func GetRayScaleWorkers(t support.Test) int32 {
+	t.T().Helper()
+	workers, err := strconv.ParseInt(lookupEnvOrDefault(rayScaleWorkersEnvVar, "100"), 10, 32)
+	t.Expect(err).NotTo(gomega.HaveOccurred(), "Error parsing %s", rayScaleWorkersEnvVar)
+	return int32(workers)
+}
+
+func GetRayScaleReadyBaseline(t support.Test) time.Duration {
+	t.T().Helper()
+	return parseDurationEnv(t, rayScaleReadyBaselineEnvVar, "10m")
+}
+
+func GetRayScaleTeardownBaseline(t support.Test) time.Duration {
+	t.T().Helper()
+	return parseDurationEnv(t, rayScaleTeardownBaselineEnvVar, "5m")
+}
+
+func parseDurationEnv(t support.Test, key, value string) time.Duration {
+	t.T().Helper()
+	duration, err := time.ParseDuration(lookupEnvOrDefault(key, value))
+	t.Expect(err).NotTo(gomega.HaveOccurred(), "Error parsing %s", key)
+	return duration
+}
+
+func lookupEnvOrDefault(key, value string) string {
+	if v, ok := os.LookupEnv(key); ok {
+		return v
+	}
+	return value
+}
@@ -0,0 +1,17 @@
+import json
+
+import ray
+
+ray.init()
+
+
+@ray.remote(num_cpus=1)
+def node_ip():
+    return ray.util.get_node_ip_address()
+
+
+# Run a task on a worker, so the worker has to reach the GCS and the head through the addresses of the cluster network
+print("IP_FAMILY " + json.dumps({
+    "gcsAddress": ray.get_runtime_context().gcs_address,
+    "workerIP": ray.get(node_ip.remote()),
+}), flush=True)
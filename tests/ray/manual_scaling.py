@@ -0,0 +1,33 @@
+import os
+import time
+
+import ray
+
+ray.init()
+
+expected_workers = int(os.environ["EXPECTED_WORKERS"])
+
+
+@ray.remote(num_cpus=1)
+def work():
+    time.sleep(1)
+    return ray.get_runtime_context().get_node_id()
+
+
+def alive_workers():
+    # The head doesn't provide any CPU, so the nodes with CPUs are the workers
+    return [node for node in ray.nodes() if node["Alive"] and node["Resources"].get("CPU", 0) > 0]
+
+
+# Keep running tasks on the available workers until the workers added by scaling the RayCluster join
+print(f"Running on {len(alive_workers())} workers, waiting for {expected_workers} workers", flush=True)
+deadline = time.time() + 600
+while len(alive_workers()) < expected_workers:
+    assert time.time() < deadline, f"Only {len(alive_workers())} of {expected_workers} workers joined the Ray cluster"
+    ray.get([work.remote() for _ in range(len(alive_workers()))])
+print(f"Ray cluster scaled to {len(alive_workers())} workers", flush=True)
+
+# Make sure the tasks get scheduled on the new workers
+nodes = set(ray.get([work.options(scheduling_strategy="SPREAD").remote() for _ in range(4 * expected_workers)]))
+print(f"Tasks ran on {len(nodes)} workers", flush=True)
+assert len(nodes) == expected_workers, f"Tasks ran on {len(nodes)} of {expected_workers} workers"
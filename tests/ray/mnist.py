@@ -0,0 +1,45 @@
+import os
+
+import ray
+
+ray.init()
+
+epochs = int(os.environ.get("EPOCHS", "1"))
+min_accuracy = float(os.environ.get("MIN_ACCURACY", "0.9"))
+
+
+@ray.remote(num_cpus=1)
+def train():
+    import torch
+    from torch import nn
+    from torch.utils.data import DataLoader
+    from torchvision import datasets, transforms
+
+    transform = transforms.Compose([transforms.ToTensor(), transforms.Normalize((0.1307,), (0.3081,))])
+    train_data = datasets.MNIST("/tmp/mnist", train=True, download=True, transform=transform)
+    test_data = datasets.MNIST("/tmp/mnist", train=False, download=True, transform=transform)
+
+    model = nn.Sequential(nn.Flatten(), nn.Linear(28 * 28, 128), nn.ReLU(), nn.Linear(128, 10))
+    optimizer = torch.optim.Adam(model.parameters(), lr=1e-3)
+    loss_fn = nn.CrossEntropyLoss()
+
+    model.train()
+    for epoch in range(epochs):
+        for images, labels in DataLoader(train_data, batch_size=64, shuffle=True):
+            optimizer.zero_grad()
+            loss = loss_fn(model(images), labels)
+            loss.backward()
+            optimizer.step()
+        print(f"Epoch {epoch} loss: {loss.item():.4f}", flush=True)
+
+    model.eval()
+    correct = 0
+    with torch.no_grad():
+        for images, labels in DataLoader(test_data, batch_size=1000):
+            correct += (model(images).argmax(dim=1) == labels).sum().item()
+    return correct / len(test_data)
+
+
+accuracy = ray.get(train.remote())
+print(f"MNIST accuracy: {accuracy:.4f}", flush=True)
+assert accuracy >= min_accuracy, f"MNIST accuracy {accuracy:.4f} below {min_accuracy}"
@@ -0,0 +1,40 @@
+import ray
+from ray.util.placement_group import placement_group, remove_placement_group
+from ray.util.scheduling_strategies import PlacementGroupSchedulingStrategy
+
+ray.init()
+
+
+@ray.remote(num_cpus=1)
+def node_ip():
+    return ray.util.get_node_ip_address()
+
+
+def bundle_nodes(pg):
+    return ray.get([
+        node_ip.options(
+            scheduling_strategy=PlacementGroupSchedulingStrategy(placement_group=pg, placement_group_bundle_index=i)
+        ).remote()
+        for i in range(pg.bundle_count)
+    ])
+
+
+# Remove the placement groups left by a previous run when the RayCluster is reused
+for name in ["strict-spread", "strict-pack"]:
+    try:
+        remove_placement_group(ray.util.get_placement_group(name))
+    except ValueError:
+        pass
+
+# Placement groups are detached, so they can be inspected through the dashboard API once the job is finished
+spread = placement_group([{"CPU": 1}, {"CPU": 1}], strategy="STRICT_SPREAD", name="strict-spread", lifetime="detached")
+pack = placement_group([{"CPU": 1}, {"CPU": 1}], strategy="STRICT_PACK", name="strict-pack", lifetime="detached")
+ray.get([spread.ready(), pack.ready()], timeout=300)
+
+spread_nodes = bundle_nodes(spread)
+pack_nodes = bundle_nodes(pack)
+print(f"STRICT_SPREAD bundles ran on nodes: {spread_nodes}")
+print(f"STRICT_PACK bundles ran on nodes: {pack_nodes}")
+
+assert len(set(spread_nodes)) == len(spread_nodes), "STRICT_SPREAD bundles must run on different nodes"
+assert len(set(pack_nodes)) == 1, "STRICT_PACK bundles must run on the same node"
@@ -0,0 +1,52 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ray
+
+import (
+	"testing"
+
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+)
+
+// FuzzRayClusterBuilder renders RayClusters with randomized-but-valid names, worker counts, CPUs, GPUs of either
+// accelerator and local queues, and asserts the API server accepts them with server-side dry-run.
+func FuzzRayClusterBuilder(f *testing.F) {
+	f.Add("raycluster", uint8(1), uint16(1000), uint8(0), false, "")
+	f.Add("RayCluster.With_Upper-Case", uint8(0), uint16(0), uint8(1), false, "local-queue")
+	f.Add("a-very-long-name-exceeding-the-length-of-the-service-names-kuberay-derives", uint8(255), uint16(65535), uint8(255), true, "-")
+	f.Fuzz(func(t *testing.T, name string, workers uint8, milliCPUs uint16, gpus uint8, amd bool, localQueue string) {
+		Track(t)
+		test := WithHooks(t)
+
+		accelerator := NvidiaAccelerator
+		if amd {
+			accelerator = AmdAccelerator
+		}
+		builder := NewRayClusterBuilder().
+			WithName(FuzzNamespace, FuzzName(name, 40)).
+			WithWorkers(FuzzCount(workers, 0, 8)).
+			WithWorkerCPUs(FuzzMilliCPUs(milliCPUs)).
+			WithAccelerator(accelerator, FuzzCount(gpus, 0, 8))
+		if localQueue != "" {
+			builder.WithLocalQueue(FuzzName(localQueue, 63))
+		}
+		rayCluster := builder.Build()
+
+		_, err := test.Client().Ray().RayV1().RayClusters(FuzzNamespace).Create(test.Ctx(), rayCluster, DryRunCreate)
+		ExpectDryRunValid(test, rayCluster, err)
+	})
+}
@@ -0,0 +1,152 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ray
+
+import (
+	"fmt"
+	"net/url"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	. "github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// Annotation of Services getting a serving certificate Secret generated by OpenShift service CA operator
+	servingCertSecretAnnotation = "service.beta.openshift.io/serving-cert-secret-name"
+	// The Ray job running across the certificate rotation
+	certRotationJobDuration = 4 * time.Minute
+)
+
+// TestRayDashboardCertRotation rotates the serving certificates of the Ray dashboard OAuth proxy while a Ray job runs,
+// and checks the dashboard route keeps serving the Ray job API used by CodeFlare SDK. It requires the dashboard to be
+// exposed through an OAuth proxy with a service CA serving certificate, as CodeFlare operator does on OpenShift.
+func TestRayDashboardCertRotation(t *testing.T) {
+	Track(t)
+	test := MustGather(WithHooks(t))
+
+	if !IsOpenShift(test) {
+		test.T().Skip("Serving certificates are rotated by OpenShift service CA operator")
+	}
+
+	// Create a namespace
+	namespace := test.NewTestNamespace()
+
+	// Create the RayCluster
+	rayCluster := createRayCluster(test, namespace.Name, "cert-rotation", "", "", 1, "1")
+	EventuallyOf(test, RayCluster(test, namespace.Name, rayCluster.Name), TestTimeoutMedium).
+		Should(Field(RayClusterState).Equal(rayv1.Ready))
+
+	// Make sure the dashboard is exposed through the OAuth proxy with a serving certificate, the test is skipped otherwise
+	route, err := test.Client().Route().RouteV1().Routes(namespace.Name).Get(test.Ctx(), "ray-dashboard-"+rayCluster.Name, metav1.GetOptions{})
+	if errors.IsNotFound(err) {
+		test.T().Skipf("RayCluster %s/%s dashboard isn't exposed through a Route", namespace.Name, rayCluster.Name)
+	}
+	ExpectNoError(test, err, "getting", Ref("Route", namespace.Name, "ray-dashboard-"+rayCluster.Name))
+	servingCertSecrets := rayClusterServingCertSecrets(test, namespace.Name)
+	if len(servingCertSecrets) == 0 {
+		test.T().Skipf("RayCluster %s/%s isn't served with service CA serving certificates", namespace.Name, rayCluster.Name)
+	}
+
+	// Authenticate to the OAuth proxy with a service account allowed to access the RayCluster
+	serviceAccount := CreateServiceAccount(test, namespace.Name)
+	role := CreateRole(test, namespace.Name, []rbacv1.PolicyRule{
+		{
+			Verbs:     []string{"get", "list"},
+			APIGroups: []string{""},
+			Resources: []string{"pods", "services"},
+		},
+	})
+	CreateRoleBinding(test, namespace.Name, serviceAccount, role)
+	dashboard := NewRayDashboardClient(url.URL{Scheme: "https", Host: route.Spec.Host}, CreateTrackedToken(test, namespace.Name, serviceAccount))
+
+	// Start a long-running Ray job
+	var longJob *RayJobResponse
+	test.Eventually(func(g Gomega) {
+		longJob, err = dashboard.CreateJob(&RayJobSetup{
+			EntryPoint: `python -c "import time; [(print(i, flush=True), time.sleep(1)) for i in range(` +
+				fmt.Sprint(int(certRotationJobDuration.Seconds())) + `)]"`,
+			RuntimeEnv: map[string]any{},
+		})
+		g.Expect(WrapError(err, "submitting Ray job to", Ref("dashboard", "", route.Spec.Host))).NotTo(HaveOccurred())
+	}, TestTimeoutShort).Should(Succeed())
+	test.Eventually(rayJobStatus(dashboard, longJob.JobID), TestTimeoutMedium).Should(Equal("RUNNING"))
+
+	// Rotate the serving certificates by deleting their Secrets, service CA operator regenerates them
+	for _, secret := range servingCertSecrets {
+		err := test.Client().Core().CoreV1().Secrets(namespace.Name).Delete(test.Ctx(), secret.Name, metav1.DeleteOptions{})
+		ExpectNoError(test, err, "deleting", Ref("Secret", namespace.Name, secret.Name))
+		test.T().Logf("Deleted serving certificate Secret %s/%s to rotate it", namespace.Name, secret.Name)
+	}
+	for _, secret := range servingCertSecrets {
+		test.Eventually(func(g Gomega) []byte {
+			rotated, err := test.Client().Core().CoreV1().Secrets(namespace.Name).Get(test.Ctx(), secret.Name, metav1.GetOptions{})
+			g.Expect(WrapError(err, "getting", Ref("Secret", namespace.Name, secret.Name))).NotTo(HaveOccurred())
+			return rotated.Data[corev1.TLSCertKey]
+		}, TestTimeoutShort).Should(And(Not(BeEmpty()), Not(Equal(secret.Data[corev1.TLSCertKey]))), "Serving certificate Secret %s/%s isn't regenerated", namespace.Name, secret.Name)
+	}
+
+	// Make sure the route keeps serving the running job, which survives the rotation
+	test.Consistently(rayJobStatus(dashboard, longJob.JobID), TestTimeoutShort).Should(Equal("RUNNING"))
+	test.Eventually(rayJobStatus(dashboard, longJob.JobID), certRotationJobDuration+TestTimeoutShort).Should(Equal("SUCCEEDED"))
+
+	// Make sure new jobs are still accepted after the rotation
+	shortJob, err := dashboard.CreateJob(&RayJobSetup{
+		EntryPoint: `python -c "import ray; ray.init(); print(ray.cluster_resources())"`,
+		RuntimeEnv: map[string]any{},
+	})
+	ExpectNoError(test, err, "submitting Ray job to", Ref("dashboard", "", route.Spec.Host))
+	test.Eventually(rayJobStatus(dashboard, shortJob.JobID), TestTimeoutMedium).Should(Equal("SUCCEEDED"))
+}
+
+// rayClusterServingCertSecrets returns the serving certificate Secrets of the Services in the namespace.
+func rayClusterServingCertSecrets(test Test, namespace string) []corev1.Secret {
+	test.T().Helper()
+
+	services, err := test.Client().Core().CoreV1().Services(namespace).List(test.Ctx(), metav1.ListOptions{})
+	ExpectNoError(test, err, "listing", Ref("Service", namespace, ""))
+	var secrets []corev1.Secret
+	for _, service := range services.Items {
+		secretName, ok := service.Annotations[servingCertSecretAnnotation]
+		if !ok {
+			continue
+		}
+		var secret *corev1.Secret
+		test.Eventually(func(g Gomega) {
+			secret, err = test.Client().Core().CoreV1().Secrets(namespace).Get(test.Ctx(), secretName, metav1.GetOptions{})
+			g.Expect(WrapError(err, "getting", Ref("Secret", namespace, secretName))).NotTo(HaveOccurred())
+		}, TestTimeoutShort).Should(Succeed())
+		secrets = append(secrets, *secret)
+	}
+	return secrets
+}
+
+func rayJobStatus(client RayClusterClient, jobID string) func(g Gomega) string {
+	return func(g Gomega) string {
+		details, err := client.GetJobDetails(jobID)
+		g.Expect(WrapError(err, "getting details of", Ref("Ray job", "", jobID))).NotTo(HaveOccurred())
+		return details.Status
+	}
+}
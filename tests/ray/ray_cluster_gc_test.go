@@ -0,0 +1,57 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ray
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	. "github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestRayClusterDependentsGarbageCollected records the Secrets, Routes, Services and ServiceAccounts created
+// for a RayCluster and makes sure all of them are removed once the RayCluster is deleted, as leaked credential
+// Secrets are security findings.
+func TestRayClusterDependentsGarbageCollected(t *testing.T) {
+	Track(t)
+	test := MustGather(WithHooks(t))
+
+	// Create a namespace
+	namespace := test.NewTestNamespace()
+	objects := NamespaceCredentialObjects(test, namespace.Name)
+	before := objects(test)
+
+	// Create the RayCluster and record the objects created for it
+	states := RecordStates(test, rayv1.GroupVersion.WithResource("rayclusters"), "RayCluster", namespace.Name, "gc", StatusFieldState("status", "state"))
+	rayCluster := createRayCluster(test, namespace.Name, "gc", "", "", 1, "1")
+	EventuallyOf(test, RayCluster(test, namespace.Name, rayCluster.Name), TestTimeoutMedium).
+		Should(Field(RayClusterState).Equal(rayv1.Ready))
+	created := ObjectsAdded(before, objects(test))
+	test.T().Logf("Objects created for RayCluster %s/%s: %v", namespace.Name, rayCluster.Name, created)
+	test.Expect(created).To(ContainElement(HaveField("Kind", "Service")), "Head Service of the RayCluster not found")
+
+	// Delete the RayCluster and make sure none of the recorded objects is left behind
+	err := test.Client().Ray().RayV1().RayClusters(namespace.Name).Delete(test.Ctx(), rayCluster.Name, metav1.DeleteOptions{})
+	ExpectNoError(test, err, "deleting", Ref("RayCluster", namespace.Name, rayCluster.Name))
+	ExpectTransitions(test, states, []string{string(rayv1.Ready), DeletedState}, TestTimeoutMedium)
+	test.Eventually(objects, TestTimeoutMedium).ShouldNot(ContainElement(BeElementOf(created)),
+		"Objects created for RayCluster %s/%s aren't garbage collected", namespace.Name, rayCluster.Name)
+}
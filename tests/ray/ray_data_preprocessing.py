@@ -0,0 +1,75 @@
+import json
+import os
+
+import numpy as np
+import pandas as pd
+import pyarrow.fs
+import ray
+
+ray.init()
+
+records = int(os.environ["RECORDS"])
+input_files = int(os.environ["INPUT_FILES"])
+output_files = int(os.environ["OUTPUT_FILES"])
+prefix = f'{os.environ["AWS_STORAGE_BUCKET"]}/{os.environ["S3_PREFIX"]}'
+input_path = f"{prefix}/input"
+output_path = f"{prefix}/output"
+
+endpoint = os.environ["AWS_DEFAULT_ENDPOINT"]
+fs = pyarrow.fs.S3FileSystem(
+    endpoint_override=endpoint.split("://")[-1],
+    scheme="https" if endpoint.startswith("https://") else "http",
+    access_key=os.environ["AWS_ACCESS_KEY_ID"],
+    secret_key=os.environ["AWS_SECRET_ACCESS_KEY"],
+    region=os.environ.get("AWS_DEFAULT_REGION", "us-east-1"),
+)
+
+
+def raw_records(batch):
+    ids = batch["id"]
+    return pd.DataFrame({
+        "id": ids,
+        "text": [" ".join(["token"] * int(i % 17 + 1)) for i in ids],
+        "value": ids.astype(np.float64) % 1000,
+    })
+
+
+def preprocess(batch):
+    return pd.DataFrame({
+        "id": batch["id"],
+        "text_length": batch["text"].str.len().astype(np.int64),
+        "tokens": batch["text"].str.split().str.len().astype(np.int64),
+        "value_normalized": batch["value"] / 1000,
+        # Records the node each batch is transformed on, to check the pipeline is distributed across the workers
+        "processed_by": ray.get_runtime_context().get_node_id(),
+    })
+
+
+try:
+    # Stage the input dataset into the bucket
+    ray.data.range(records, parallelism=input_files) \
+        .map_batches(raw_records, batch_format="pandas") \
+        .write_parquet(input_path, filesystem=fs)
+    print(f"Staged {records} records into s3://{input_path}", flush=True)
+
+    # Read the input, transform it in batches on the workers and write the output back
+    ray.data.read_parquet(input_path, filesystem=fs) \
+        .map_batches(preprocess, batch_format="pandas", batch_size=1000) \
+        .repartition(output_files) \
+        .write_parquet(output_path, filesystem=fs)
+    print(f"Preprocessed s3://{input_path} into s3://{output_path}", flush=True)
+
+    # Report the written objects and the schema of the output, verified by the test
+    objects = [info for info in fs.get_file_info(pyarrow.fs.FileSelector(output_path, recursive=True))
+               if info.type == pyarrow.fs.FileType.File]
+    output = ray.data.read_parquet(output_path, filesystem=fs)
+    schema = output.schema()
+    nodes = output.unique("processed_by")
+    print("RAY DATA OUTPUT " + json.dumps({
+        "objects": len(objects),
+        "rows": output.count(),
+        "schema": {name: str(type) for name, type in zip(schema.names, schema.types)},
+        "nodes": len(nodes),
+    }), flush=True)
+finally:
+    fs.delete_dir_contents(prefix, missing_dir_ok=True)
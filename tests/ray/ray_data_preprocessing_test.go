@@ -0,0 +1,122 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ray
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/opendatahub-io/distributed-workloads/pkg/examples"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	. "github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+)
+
+const (
+	rayDataRecords     = 200000
+	rayDataInputFiles  = 24
+	rayDataOutputFiles = 6
+	rayDataWorkers     = 3
+)
+
+// rayDataOutput is the summary of the output of the preprocessing pipeline printed by the Ray job.
+type rayDataOutput struct {
+	Objects int               `json:"objects"`
+	Rows    int               `json:"rows"`
+	Schema  map[string]string `json:"schema"`
+	Nodes   int               `json:"nodes"`
+}
+
+var rayDataOutputPattern = regexp.MustCompile(`RAY DATA OUTPUT (\{.*\})`)
+
+// TestRayDataPreprocessing runs a Ray Data pipeline reading a dataset from S3, transforming it with map_batches
+// across the workers of a RayCluster and writing the result back to S3, as customers preprocess their data
+// before training, and checks the written objects and the schema of the output.
+func TestRayDataPreprocessing(t *testing.T) {
+	Track(t)
+	test := MustGather(WithHooks(t))
+
+	// Create a namespace
+	namespace := test.NewTestNamespace()
+
+	// Use the configured S3 bucket, or deploy MinIO
+	bucket := GetOrDeployS3Bucket(test, namespace.Name)
+
+	// Create a ConfigMap with the Ray job script
+	scripts := CreateConfigMap(test, namespace.Name, map[string][]byte{
+		"ray_data_preprocessing.py": ReadFile(test, "ray_data_preprocessing.py"),
+	})
+
+	// Create RayCluster with several workers, the head doesn't provide any CPU so the pipeline runs on workers only
+	rayCluster := createRayCluster(test, namespace.Name, "ray-data", "", scripts.Name, rayDataWorkers, "2")
+	EventuallyOf(test, RayCluster(test, namespace.Name, rayCluster.Name), TestTimeoutLong).
+		Should(Field(RayClusterState).Equal(rayv1.Ready))
+
+	// Submit the Ray job running the pipeline, the data is stored under a prefix unique to the test namespace
+	dashboardURL := ExposeService(test, "ray-dashboard", namespace.Name, rayCluster.Name+"-head-svc", "dashboard")
+	rayClient := NewRayClusterClient(dashboardURL)
+	var jobID string
+	test.Eventually(func(g Gomega) {
+		response, err := rayClient.CreateJob(&RayJobSetup{
+			EntryPoint: "python " + examples.RayClusterScriptsMountPath + "/ray_data_preprocessing.py",
+			RuntimeEnv: map[string]any{
+				"env_vars": map[string]string{
+					"RECORDS":               fmt.Sprint(rayDataRecords),
+					"INPUT_FILES":           fmt.Sprint(rayDataInputFiles),
+					"OUTPUT_FILES":          fmt.Sprint(rayDataOutputFiles),
+					"S3_PREFIX":             "ray-data/" + namespace.Name,
+					"AWS_DEFAULT_ENDPOINT":  bucket.Endpoint,
+					"AWS_ACCESS_KEY_ID":     bucket.AccessKeyID,
+					"AWS_SECRET_ACCESS_KEY": bucket.SecretAccessKey,
+					"AWS_STORAGE_BUCKET":    bucket.Bucket,
+					"AWS_DEFAULT_REGION":    bucket.Region,
+				},
+			},
+		})
+		g.Expect(WrapError(err, "submitting Ray job to", Ref("dashboard", "", dashboardURL.Host))).NotTo(HaveOccurred())
+		jobID = response.JobID
+	}, TestTimeoutShort).Should(Succeed())
+	test.T().Logf("Submitted Ray job %s", jobID)
+
+	test.Eventually(rayJobStatus(rayClient, jobID), TestTimeoutLong).
+		Should(Or(Equal("SUCCEEDED"), Equal("FAILED"), Equal("STOPPED")))
+	WriteRayJobAPILogs(test, rayClient, jobID)
+	test.Expect(rayJobStatus(rayClient, jobID)(test)).To(Equal("SUCCEEDED"))
+
+	// Make sure all the records are written into the expected objects, with the schema of the transformed records
+	logs, err := rayClient.GetJobLogs(jobID)
+	ExpectNoError(test, err, "getting logs of", Ref("Ray job", "", jobID))
+	match := rayDataOutputPattern.FindStringSubmatch(logs)
+	test.Expect(match).NotTo(BeNil(), "Ray job didn't report the output of the pipeline")
+	output := rayDataOutput{}
+	test.Expect(json.Unmarshal([]byte(match[1]), &output)).To(Succeed())
+	test.T().Logf("Ray Data pipeline wrote %d records into %d objects, transformed on %d nodes", output.Rows, output.Objects, output.Nodes)
+
+	test.Expect(output.Rows).To(Equal(rayDataRecords))
+	test.Expect(output.Objects).To(Equal(rayDataOutputFiles))
+	test.Expect(output.Schema).To(Equal(map[string]string{
+		"id":               "int64",
+		"text_length":      "int64",
+		"tokens":           "int64",
+		"value_normalized": "double",
+		"processed_by":     "string",
+	}))
+	test.Expect(output.Nodes).To(BeNumerically(">", 1), "Ray Data pipeline wasn't distributed across the workers")
+}
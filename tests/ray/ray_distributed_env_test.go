@@ -0,0 +1,61 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ray
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	. "github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+)
+
+// TestRayClusterDistributedEnv checks the GCS address environment KubeRay injects into the head and worker pods of
+// a RayCluster, and that the NCCL tuning of the worker group is passed through unchanged.
+func TestRayClusterDistributedEnv(t *testing.T) {
+	Track(t)
+	test := MustGather(WithHooks(t))
+
+	// Create a namespace
+	namespace := test.NewTestNamespace()
+
+	// Create RayCluster with NCCL tuning of the workers
+	rayCluster := NewRayClusterBuilder().
+		WithName(namespace.Name, "distributed-env").
+		WithWorkers(1).
+		WithWorkerCPUs("1").
+		Build()
+	worker := &rayCluster.Spec.WorkerGroupSpecs[0].Template.Spec.Containers[0]
+	worker.Env = append(worker.Env,
+		corev1.EnvVar{Name: "NCCL_DEBUG", Value: "INFO"},
+		corev1.EnvVar{Name: "NCCL_IB_DISABLE", Value: "1"},
+	)
+	rayCluster = CreateWarmStandbyRayCluster(test, rayCluster)
+	EventuallyOf(test, RayCluster(test, namespace.Name, rayCluster.Name), TestTimeoutMedium).
+		Should(Field(RayClusterState).Equal(rayv1.Ready))
+
+	// Make sure the head and the worker get exactly the expected environment
+	pods := rayClusterPods(test, namespace.Name, rayCluster.Name)(test)
+	test.Expect(pods).To(HaveLen(2))
+	for _, pod := range pods {
+		test.T().Logf("Pod %s distributed environment: %v", pod.Name, PodDistributedEnv(pod))
+		ExpectOf(test, pod).To(HaveDistributedEnv(RayClusterDistributedEnv(rayCluster, pod)))
+	}
+}
@@ -0,0 +1,135 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ray
+
+import (
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/opendatahub-io/distributed-workloads/pkg/examples"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	. "github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ipFamilyRecord is the line logged by ip_family.py with the addresses Ray uses.
+type ipFamilyRecord struct {
+	GcsAddress string `json:"gcsAddress"`
+	WorkerIP   string `json:"workerIP"`
+}
+
+// TestRayClusterIPFamily checks Ray GCS, the dashboard and its route work on IPv6-only and dual-stack clusters,
+// as configured with TEST_IP_FAMILY.
+func TestRayClusterIPFamily(t *testing.T) {
+	Track(t)
+	test := MustGather(WithHooks(t))
+
+	mode := GetIPFamilyMode()
+	if mode == IPFamilyModeIPv4 {
+		test.T().Skip("IPv4-only cluster, set TEST_IP_FAMILY to IPv6 or DualStack to run the IP family tests")
+	}
+
+	// Create a namespace
+	namespace := test.NewTestNamespace()
+
+	// Create a ConfigMap with the Ray job script
+	scripts := CreateConfigMap(test, namespace.Name, map[string][]byte{
+		"ip_family.py": ReadFile(test, "ip_family.py"),
+	})
+
+	// Create RayCluster with a single worker, the dashboard listens on all the IP families
+	rayCluster := createRayCluster(test, namespace.Name, "ip-family", "", scripts.Name, 1, "1")
+	EventuallyOf(test, RayCluster(test, namespace.Name, rayCluster.Name), TestTimeoutMedium).
+		Should(Field(RayClusterState).Equal(rayv1.Ready))
+
+	// Make sure the head and worker pods get addresses of the configured IP families
+	pods, err := test.Client().Core().CoreV1().Pods(namespace.Name).List(test.Ctx(), metav1.ListOptions{LabelSelector: "ray.io/cluster=" + rayCluster.Name})
+	ExpectNoError(test, err, "listing pods of", Ref("RayCluster", namespace.Name, rayCluster.Name))
+	test.Expect(pods.Items).To(HaveLen(2))
+	var head corev1.Pod
+	for _, pod := range pods.Items {
+		ExpectPodIPFamilies(test, pod)
+		if pod.Labels["ray.io/node-type"] == "head" {
+			head = pod
+		}
+	}
+	if mode == IPFamilyModeIPv6 {
+		service, err := test.Client().Core().CoreV1().Services(namespace.Name).Get(test.Ctx(), rayCluster.Name+"-head-svc", metav1.GetOptions{})
+		ExpectNoError(test, err, "getting", Ref("Service", namespace.Name, rayCluster.Name+"-head-svc"))
+		test.Expect(service.Spec.IPFamilies).To(Equal([]corev1.IPFamily{corev1.IPv6Protocol}))
+	}
+
+	// Submit the Ray job through the dashboard route, it runs a task on the worker connected to the GCS
+	dashboardURL := ExposeService(test, "ray-dashboard", namespace.Name, rayCluster.Name+"-head-svc", "dashboard")
+	rayClient := NewRayClusterClient(dashboardURL)
+	var jobID string
+	test.Eventually(func(g Gomega) {
+		response, err := rayClient.CreateJob(&RayJobSetup{EntryPoint: "python " + examples.RayClusterScriptsMountPath + "/ip_family.py"})
+		g.Expect(WrapError(err, "submitting Ray job to", Ref("dashboard", "", dashboardURL.Host))).NotTo(HaveOccurred())
+		jobID = response.JobID
+	}, TestTimeoutShort).Should(Succeed())
+	test.Eventually(rayJobStatus(rayClient, jobID), TestTimeoutMedium).
+		Should(Or(Equal("SUCCEEDED"), Equal("FAILED"), Equal("STOPPED")))
+	WriteRayJobAPILogs(test, rayClient, jobID)
+	test.Expect(rayJobStatus(rayClient, jobID)(test)).To(Equal("SUCCEEDED"))
+
+	logs, err := rayClient.GetJobLogs(jobID)
+	ExpectNoError(test, err, "getting logs of Ray job "+jobID+" from", Ref("RayCluster", namespace.Name, rayCluster.Name))
+	record := ipFamilyRecord{}
+	for _, line := range strings.Split(logs, "\n") {
+		if payload, ok := strings.CutPrefix(line, "IP_FAMILY "); ok {
+			test.Expect(json.Unmarshal([]byte(payload), &record)).To(Succeed())
+		}
+	}
+	test.T().Logf("Ray GCS address %s, worker address %s", record.GcsAddress, record.WorkerIP)
+	test.Expect(record.WorkerIP).NotTo(BeEmpty(), "Ray job didn't log its addresses")
+
+	// Make sure both Ray nodes are registered with the GCS, over IPv6 addresses on IPv6-only clusters
+	nodes := GetRayNodes(test, dashboardURL)
+	ExpectOf(test, nodes).To(HaveLenOf[RayNode](2).And(HaveEachOf(Field(func(node RayNode) string { return node.State }).Equal("ALIVE"))))
+	if mode == IPFamilyModeIPv6 {
+		test.Expect(IPFamilyOf(rayAddressHost(record.GcsAddress))).To(Equal(corev1.IPv6Protocol))
+		test.Expect(IPFamilyOf(record.WorkerIP)).To(Equal(corev1.IPv6Protocol))
+		for _, node := range nodes {
+			test.Expect(IPFamilyOf(node.NodeIP)).To(Equal(corev1.IPv6Protocol), "Ray node %s registered with address %s", node.NodeID, node.NodeIP)
+		}
+	}
+
+	// Make sure the dashboard is served over the IPv6 address of the head pod as well
+	headIP, ok := PodIP(head, corev1.IPv6Protocol)
+	test.Expect(ok).To(BeTrue(), "Ray head pod %s/%s has no IPv6 address", head.Namespace, head.Name)
+	dashboard := HTTPURL(headIP, 8265)
+	dashboard.Path = "/api/version"
+	ExpectReachableFromPod(test, namespace.Name, dashboard)
+}
+
+// rayAddressHost returns the host of the address Ray reports, which doesn't enclose IPv6 addresses in brackets.
+func rayAddressHost(address string) string {
+	if host, _, err := net.SplitHostPort(address); err == nil {
+		return host
+	}
+	if i := strings.LastIndex(address, ":"); i >= 0 {
+		return strings.Trim(address[:i], "[]")
+	}
+	return address
+}
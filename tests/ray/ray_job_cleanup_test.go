@@ -0,0 +1,156 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ray
+
+import (
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	. "github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	batchv1 "k8s.io/api/batch/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// KubeRay runs the submitter as a Job with a fixed backoff limit, so the submission is attempted three times at most
+const rayJobSubmitterBackoffLimit = 2
+
+func TestRayJobTTLAfterFinished(t *testing.T) {
+	Track(t)
+	test := MustGather(WithHooks(t))
+
+	// Create a namespace
+	namespace := test.NewTestNamespace()
+
+	// Create RayJob tearing down its RayCluster once finished, after the TTL elapses
+	ttl := 30 * time.Second
+	rayJob := &rayv1.RayJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ray-job-ttl",
+			Namespace: namespace.Name,
+		},
+		Spec: rayv1.RayJobSpec{
+			Entrypoint:               `python -c "import ray; ray.init(); print(ray.cluster_resources())"`,
+			ShutdownAfterJobFinishes: true,
+			TTLSecondsAfterFinished:  int32(ttl.Seconds()),
+			RayClusterSpec:           newRayClusterSpec("", 1, "1"),
+		},
+	}
+	rayJob = createRayJob(test, rayJob)
+
+	EventuallyOf(test, RayJob(test, namespace.Name, rayJob.Name), TestTimeoutLong).
+		Should(Field(RayJobStatus).Equal(rayv1.JobStatusSucceeded))
+	rayJob = GetRayJob(test, namespace.Name, rayJob.Name)
+	rayClusterName := rayJob.Status.RayClusterName
+	test.Expect(rayClusterName).NotTo(BeEmpty())
+
+	// Make sure the RayCluster and submitter are kept while within the TTL
+	test.Consistently(rayClusterExists(test, namespace.Name, rayClusterName), ttl/2).Should(BeTrue())
+	test.Expect(JobPods(test, namespace.Name, rayJob.Name)(test)).NotTo(BeEmpty())
+
+	// Make sure the RayCluster, its pods and the submitter pod are deleted once the TTL elapses
+	WaitForDeletion(test, rayv1.SchemeGroupVersion.WithResource("rayclusters"), &metav1.ObjectMeta{Namespace: namespace.Name, Name: rayClusterName}, ttl+TestTimeoutShort)
+	WaitForDeletionOf(test, corev1.SchemeGroupVersion.WithResource("pods"), namespace.Name, metav1.ListOptions{LabelSelector: "ray.io/cluster=" + rayClusterName}, TestTimeoutMedium)
+	WaitForDeletionOf(test, corev1.SchemeGroupVersion.WithResource("pods"), namespace.Name, metav1.ListOptions{LabelSelector: "job-name=" + rayJob.Name}, TestTimeoutMedium)
+
+	// The RayJob itself is kept to report the result
+	ExpectOf(test, GetRayJob(test, namespace.Name, rayJob.Name)).
+		To(Field(RayJobStatus).Equal(rayv1.JobStatusSucceeded))
+}
+
+func TestRayJobSubmitterBackoff(t *testing.T) {
+	Track(t)
+	test := MustGather(WithHooks(t))
+
+	// Create a namespace
+	namespace := test.NewTestNamespace()
+
+	// Create RayJob with submitter failing on every attempt
+	rayJob := &rayv1.RayJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ray-job-backoff",
+			Namespace: namespace.Name,
+		},
+		Spec: rayv1.RayJobSpec{
+			Entrypoint:     `python -c "print('never submitted')"`,
+			RayClusterSpec: newRayClusterSpec("", 1, "1"),
+			SubmitterPodTemplate: &corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					RestartPolicy: corev1.RestartPolicyNever,
+					NodeSelector:  ArchitectureNodeSelector(),
+					Containers: []corev1.Container{
+						{
+							Name:    "ray-job-submitter",
+							Image:   GetRayImageForArchitecture(),
+							Command: []string{"sh", "-c", "echo 'Submission failed' && exit 1"},
+						},
+					},
+				},
+			},
+		},
+	}
+	rayJob = createRayJob(test, rayJob)
+
+	// Make sure the submitter is retried as configured and the submission eventually gives up
+	EventuallyOf(test, Job(test, namespace.Name, rayJob.Name), TestTimeoutLong).
+		Should(Field(JobConditionStatus(batchv1.JobFailed)).Equal(corev1.ConditionTrue))
+	submitter := GetJob(test, namespace.Name, rayJob.Name)
+	test.Expect(submitter.Spec.BackoffLimit).To(Equal(Ptr(int32(rayJobSubmitterBackoffLimit))))
+	test.Expect(submitter.Status.Failed).To(Equal(int32(rayJobSubmitterBackoffLimit + 1)))
+	ExpectOf(test, JobPods(test, namespace.Name, rayJob.Name)(test)).
+		To(HaveLenOf[corev1.Pod](rayJobSubmitterBackoffLimit + 1).
+			And(HaveEachOf(Field(func(pod corev1.Pod) corev1.PodPhase { return pod.Status.Phase }).Equal(corev1.PodFailed))))
+
+	// Make sure the RayJob doesn't report the job as succeeded
+	ConsistentlyOf(test, RayJob(test, namespace.Name, rayJob.Name), TestTimeoutShort).
+		Should(Field(RayJobStatus).Matches(Not(Equal(rayv1.JobStatusSucceeded))))
+}
+
+func createRayJob(test Test, rayJob *rayv1.RayJob) *rayv1.RayJob {
+	test.T().Helper()
+
+	created, err := test.Client().Ray().RayV1().RayJobs(rayJob.Namespace).Create(test.Ctx(), rayJob, metav1.CreateOptions{})
+	ExpectNoError(test, err, "creating", Ref("RayJob", rayJob.Namespace, rayJob.Name+rayJob.GenerateName))
+	rayJob = created
+	test.T().Logf("Created RayJob %s/%s successfully", rayJob.Namespace, rayJob.Name)
+
+	return rayJob
+}
+
+func rayClusterExists(test Test, namespace, name string) func(g Gomega) bool {
+	return func(g Gomega) bool {
+		_, err := test.Client().Ray().RayV1().RayClusters(namespace).Get(test.Ctx(), name, metav1.GetOptions{})
+		if errors.IsNotFound(err) {
+			return false
+		}
+		g.Expect(WrapError(err, "getting", Ref("RayCluster", namespace, name))).NotTo(HaveOccurred())
+		return true
+	}
+}
+
+func rayClusterPods(test Test, namespace, name string) func(g Gomega) []corev1.Pod {
+	return func(g Gomega) []corev1.Pod {
+		pods, err := test.Client().Core().CoreV1().Pods(namespace).List(test.Ctx(), metav1.ListOptions{LabelSelector: "ray.io/cluster=" + name})
+		g.Expect(WrapError(err, "listing pods of", Ref("RayCluster", namespace, name))).NotTo(HaveOccurred())
+		return pods.Items
+	}
+}
@@ -0,0 +1,84 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ray
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/opendatahub-io/distributed-workloads/pkg/examples"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	. "github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// The packages installed in the runtime environment of the MNIST training, PyTorch CPU wheels avoid pulling the CUDA libraries
+const mnistRuntimeEnv = `
+pip:
+  - --extra-index-url https://download.pytorch.org/whl/cpu
+  - torch
+  - torchvision
+env_vars:
+  EPOCHS: "1"
+`
+
+// TestRayJobMnist submits a RayJob running the MNIST training, owning its RayCluster, rather than a RayCluster
+// wrapped in an AppWrapper, and checks the RayCluster is torn down once the job finishes.
+func TestRayJobMnist(t *testing.T) {
+	Track(t)
+	test := MustGather(WithHooks(t))
+
+	// Create a namespace
+	namespace := test.NewTestNamespace()
+
+	// Create a ConfigMap with the training script
+	scripts := CreateConfigMap(test, namespace.Name, map[string][]byte{
+		"mnist.py": ReadFile(test, "mnist.py"),
+	})
+
+	// Create RayJob shutting down its RayCluster as soon as the job finishes
+	rayJob := &rayv1.RayJob{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ray-job-mnist",
+			Namespace: namespace.Name,
+		},
+		Spec: rayv1.RayJobSpec{
+			Entrypoint:               "python " + examples.RayClusterScriptsMountPath + "/mnist.py",
+			RuntimeEnvYAML:           mnistRuntimeEnv,
+			ShutdownAfterJobFinishes: true,
+			RayClusterSpec:           newRayClusterSpec(scripts.Name, 1, "1"),
+		},
+	}
+	rayJob = createRayJob(test, rayJob)
+
+	// Make sure the RayJob deployment completes, as it does whether the job succeeds or fails, then check the job succeeded
+	EventuallyOf(test, RayJob(test, namespace.Name, rayJob.Name), TestTimeoutLong).
+		Should(Field(RayJobDeploymentStatus).Equal(rayv1.JobDeploymentStatusComplete))
+	rayJob = GetRayJob(test, namespace.Name, rayJob.Name)
+	for _, pod := range JobPods(test, namespace.Name, rayJob.Name)(test) {
+		test.T().Logf("Submitter pod %s logs:\n%s", pod.Name, GetPodLogs(test, &pod, corev1.PodLogOptions{}))
+	}
+	test.Expect(RayJobSucceeded(rayJob)).To(BeTrue(), "RayJob %s/%s didn't succeed: %s", namespace.Name, rayJob.Name, rayJob.Status.Message)
+	test.Expect(rayJob.Status.RayClusterName).NotTo(BeEmpty())
+
+	// Make sure the RayCluster and its pods are torn down
+	WaitForDeletion(test, rayv1.SchemeGroupVersion.WithResource("rayclusters"), &metav1.ObjectMeta{Namespace: namespace.Name, Name: rayJob.Status.RayClusterName}, TestTimeoutMedium)
+	WaitForDeletionOf(test, corev1.SchemeGroupVersion.WithResource("pods"), namespace.Name, metav1.ListOptions{LabelSelector: "ray.io/cluster=" + rayJob.Status.RayClusterName}, TestTimeoutMedium)
+}
@@ -0,0 +1,99 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ray
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	. "github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestRayJobSuspendResume suspends a running RayJob through the queue manager of the cluster, Kueue or MCAD as set with
+// QUEUE_MANAGER, and checks its ephemeral RayCluster is torn down and its quota released. Once resumed, KubeRay
+// provisions a new RayCluster and submits the job again, as documented, which must run to completion.
+func TestRayJobSuspendResume(t *testing.T) {
+	Track(t)
+	test := MustGather(WithHooks(t))
+
+	// Create a namespace
+	namespace := test.NewTestNamespace()
+
+	// Create the queue manager dispatching the workloads of the namespace
+	queueManager := NewQueueManager(test, namespace.Name, corev1.ResourceList{
+		corev1.ResourceCPU:    resource.MustParse("3"),
+		corev1.ResourceMemory: resource.MustParse("8Gi"),
+	})
+	test.T().Logf("Dispatching workloads with %s", queueManager.Name())
+
+	// Submit the RayJob and wait for the job to run on its ephemeral RayCluster
+	rayJob := &rayv1.RayJob{
+		TypeMeta: metav1.TypeMeta{
+			APIVersion: rayv1.GroupVersion.String(),
+			Kind:       "RayJob",
+		},
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "ray-job-suspend",
+			Namespace: namespace.Name,
+		},
+		Spec: rayv1.RayJobSpec{
+			Entrypoint:               `python -c "import time; print('Job started', flush=True); time.sleep(90); print('Job finished')"`,
+			ShutdownAfterJobFinishes: true,
+			RayClusterSpec:           newRayClusterSpec("", 1, "1"),
+		},
+	}
+	workload := queueManager.Submit(test, rayJob)
+	queueManager.WaitAdmitted(test, workload, TestTimeoutMedium)
+
+	EventuallyOf(test, RayJob(test, namespace.Name, rayJob.Name), TestTimeoutLong).
+		Should(Field(RayJobStatus).Equal(rayv1.JobStatusRunning))
+	rayClusterName := GetRayJob(test, namespace.Name, rayJob.Name).Status.RayClusterName
+	test.Expect(rayClusterName).NotTo(BeEmpty())
+	rayClusterUID := GetRayCluster(test, namespace.Name, rayClusterName).UID
+
+	// Suspend the RayJob and make sure its RayCluster and pods are torn down
+	queueManager.Suspend(test, workload)
+	EventuallyOf(test, RayJob(test, namespace.Name, rayJob.Name), TestTimeoutMedium).
+		Should(Field(RayJobDeploymentStatus).Equal(rayv1.JobDeploymentStatusSuspended))
+	WaitForDeletion(test, rayv1.SchemeGroupVersion.WithResource("rayclusters"), &metav1.ObjectMeta{Namespace: namespace.Name, Name: rayClusterName}, TestTimeoutMedium)
+	WaitForDeletionOf(test, corev1.SchemeGroupVersion.WithResource("pods"), namespace.Name, metav1.ListOptions{LabelSelector: "ray.io/cluster=" + rayClusterName}, TestTimeoutMedium)
+
+	// Make sure the RayJob stays suspended without a RayCluster until resumed
+	ConsistentlyOf(test, RayJob(test, namespace.Name, rayJob.Name), TestTimeoutShort).
+		Should(Field(RayJobDeploymentStatus).Equal(rayv1.JobDeploymentStatusSuspended))
+	rayClusters, err := test.Client().Ray().RayV1().RayClusters(namespace.Name).List(test.Ctx(), metav1.ListOptions{})
+	ExpectNoError(test, err, "listing", Ref("RayClusters", namespace.Name, ""))
+	test.Expect(rayClusters.Items).To(BeEmpty())
+
+	// Resume the RayJob and make sure it's re-provisioned on a new RayCluster and runs to completion
+	queueManager.Resume(test, workload)
+	queueManager.WaitAdmitted(test, workload, TestTimeoutMedium)
+	test.Eventually(func(g Gomega) string {
+		return RayJob(test, namespace.Name, rayJob.Name)(g).Status.RayClusterName
+	}, TestTimeoutMedium).ShouldNot(BeEmpty())
+	resumedRayClusterName := GetRayJob(test, namespace.Name, rayJob.Name).Status.RayClusterName
+	test.Expect(GetRayCluster(test, namespace.Name, resumedRayClusterName).UID).NotTo(Equal(rayClusterUID),
+		"RayJob resumed without provisioning a new RayCluster")
+
+	queueManager.WaitCompleted(test, workload, TestTimeoutLong)
+}
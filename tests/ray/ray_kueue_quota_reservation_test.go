@@ -0,0 +1,112 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ray
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	. "github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+	kueuev1beta1 "sigs.k8s.io/kueue/apis/kueue/v1beta1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// TestMultiStageQuotaReservation mimics a notebook chaining RayClusters, i.e. a training cluster followed by an evaluation cluster.
+// Kueue reserves quota for a cluster at admission time, clusters which don't fit into the quota are queued, never rejected.
+func TestMultiStageQuotaReservation(t *testing.T) {
+	Track(t, LabelKueue)
+	test := MustGather(WithHooks(t))
+
+	// Create a namespace
+	namespace := test.NewTestNamespace()
+
+	// Create Kueue resources, the quota fits exactly two RayClusters with one worker
+	resourceFlavor := CreateTrackedKueueResourceFlavor(test, kueuev1beta1.ResourceFlavorSpec{})
+	defer test.Client().Kueue().KueueV1beta1().ResourceFlavors().Delete(test.Ctx(), resourceFlavor.Name, metav1.DeleteOptions{})
+	cqSpec := kueuev1beta1.ClusterQueueSpec{
+		NamespaceSelector: &metav1.LabelSelector{},
+		ResourceGroups: []kueuev1beta1.ResourceGroup{
+			{
+				CoveredResources: []corev1.ResourceName{corev1.ResourceName("cpu"), corev1.ResourceName("memory")},
+				Flavors: []kueuev1beta1.FlavorQuotas{
+					{
+						Name: kueuev1beta1.ResourceFlavorReference(resourceFlavor.Name),
+						Resources: []kueuev1beta1.ResourceQuota{
+							{
+								Name:         corev1.ResourceCPU,
+								NominalQuota: resource.MustParse("1200m"),
+							},
+							{
+								Name:         corev1.ResourceMemory,
+								NominalQuota: resource.MustParse("4G"),
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	clusterQueue := CreateTrackedKueueClusterQueue(test, cqSpec)
+	defer test.Client().Kueue().KueueV1beta1().ClusterQueues().Delete(test.Ctx(), clusterQueue.Name, metav1.DeleteOptions{})
+	localQueue := CreateKueueLocalQueue(test, namespace.Name, clusterQueue.Name)
+
+	// Create the first stage RayCluster
+	training := createRayCluster(test, namespace.Name, "training", localQueue.Name, "", 1, "1")
+	EventuallyOf(test, RayCluster(test, namespace.Name, training.Name), TestTimeoutLong).
+		Should(Field(RayClusterState).Equal(rayv1.Ready))
+
+	// Reserve quota for the second stage while the first stage is running, the RayCluster is admitted straight away
+	evaluation := createRayCluster(test, namespace.Name, "evaluation", localQueue.Name, "", 1, "1")
+	EventuallyOf(test, KueueWorkloadOwnedBy(test, namespace.Name, evaluation), TestTimeoutShort).
+		Should(Field(KueueWorkloadAdmitted).Equal(true))
+	EventuallyOf(test, RayCluster(test, namespace.Name, evaluation.Name), TestTimeoutLong).
+		Should(Field(RayClusterState).Equal(rayv1.Ready))
+
+	// Create a third stage RayCluster, the quota is reserved by the previous stages so it is queued, not rejected
+	followUp := createRayCluster(test, namespace.Name, "follow-up", localQueue.Name, "", 1, "1")
+	EventuallyOf(test, KueueWorkloadOwnedBy(test, namespace.Name, followUp), TestTimeoutShort).
+		Should(Field(KueueWorkloadPending).Equal(true).And(
+			Field(KueueWorkloadFinished).Equal(false),
+		))
+	test.Expect(GetRayCluster(test, namespace.Name, followUp.Name).Spec.Suspend).To(Equal(Ptr(true)))
+
+	// Create a RayCluster exceeding the whole ClusterQueue quota, it is queued as well and can never be admitted
+	oversized := createRayCluster(test, namespace.Name, "oversized", localQueue.Name, "", 4, "1")
+	EventuallyOf(test, KueueWorkloadOwnedBy(test, namespace.Name, oversized), TestTimeoutShort).
+		Should(Field(KueueWorkloadPending).Equal(true).And(
+			Field(KueueWorkloadFinished).Equal(false),
+		))
+
+	// Tear down the first stage, its quota is released to the queued stage
+	err := test.Client().Ray().RayV1().RayClusters(namespace.Name).Delete(test.Ctx(), training.Name, metav1.DeleteOptions{})
+	ExpectNoError(test, err, "deleting", Ref("RayCluster", namespace.Name, training.Name))
+	EventuallyOf(test, KueueWorkloadOwnedBy(test, namespace.Name, followUp), TestTimeoutMedium).
+		Should(Field(KueueWorkloadAdmitted).Equal(true))
+	EventuallyOf(test, RayCluster(test, namespace.Name, followUp.Name), TestTimeoutLong).
+		Should(Field(RayClusterState).Equal(rayv1.Ready))
+
+	// Make sure the admitted stages were left untouched and the oversized RayCluster is still queued
+	ExpectOf(test, GetKueueWorkloadOwnedBy(test, namespace.Name, evaluation)).
+		To(Field(KueueWorkloadEvicted).Equal(false))
+	ExpectOf(test, GetKueueWorkloadOwnedBy(test, namespace.Name, oversized)).
+		To(Field(KueueWorkloadPending).Equal(true))
+}
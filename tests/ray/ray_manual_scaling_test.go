@@ -0,0 +1,98 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ray
+
+import (
+	"fmt"
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/opendatahub-io/distributed-workloads/pkg/examples"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	. "github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	manualScalingInitialWorkers = 1
+	manualScalingScaledWorkers  = 3
+)
+
+// TestRayClusterManualScaling scales the worker group of a running RayCluster by patching its replicas while
+// a Ray job runs, as users do when autoscaling is disabled, and checks the job gets the new workers.
+func TestRayClusterManualScaling(t *testing.T) {
+	Track(t)
+	test := MustGather(WithHooks(t))
+
+	// Create a namespace
+	namespace := test.NewTestNamespace()
+
+	// Create a ConfigMap with the Ray job script
+	scripts := CreateConfigMap(test, namespace.Name, map[string][]byte{
+		"manual_scaling.py": ReadFile(test, "manual_scaling.py"),
+	})
+
+	// Create RayCluster with a single worker
+	rayCluster := createRayCluster(test, namespace.Name, "manual-scaling", "", scripts.Name, manualScalingInitialWorkers, "1")
+	EventuallyOf(test, RayCluster(test, namespace.Name, rayCluster.Name), TestTimeoutMedium).
+		Should(Field(RayClusterState).Equal(rayv1.Ready))
+
+	// Submit the Ray job waiting for the workers to join
+	dashboardURL := ExposeService(test, "ray-dashboard", namespace.Name, rayCluster.Name+"-head-svc", "dashboard")
+	rayClient := NewRayClusterClient(dashboardURL)
+	var jobID string
+	test.Eventually(func(g Gomega) {
+		response, err := rayClient.CreateJob(&RayJobSetup{
+			EntryPoint: "python " + examples.RayClusterScriptsMountPath + "/manual_scaling.py",
+			RuntimeEnv: map[string]any{
+				"env_vars": map[string]string{"EXPECTED_WORKERS": fmt.Sprint(manualScalingScaledWorkers)},
+			},
+		})
+		g.Expect(WrapError(err, "submitting Ray job to", Ref("dashboard", "", dashboardURL.Host))).NotTo(HaveOccurred())
+		jobID = response.JobID
+	}, TestTimeoutShort).Should(Succeed())
+	test.Eventually(rayJobStatus(rayClient, jobID), TestTimeoutMedium).Should(Equal("RUNNING"))
+	test.T().Logf("Ray job %s is running", jobID)
+
+	// Scale the worker group while the job runs, the max replicas bound the replicas when autoscaling is disabled
+	patch := fmt.Sprintf(`[{"op":"replace","path":"/spec/workerGroupSpecs/0/replicas","value":%[1]d},{"op":"replace","path":"/spec/workerGroupSpecs/0/maxReplicas","value":%[1]d}]`,
+		manualScalingScaledWorkers)
+	_, err := test.Client().Ray().RayV1().RayClusters(namespace.Name).Patch(test.Ctx(), rayCluster.Name, types.JSONPatchType, []byte(patch), metav1.PatchOptions{})
+	ExpectNoError(test, err, "scaling", Ref("RayCluster", namespace.Name, rayCluster.Name))
+	test.T().Logf("Scaled RayCluster %s/%s to %d workers", namespace.Name, rayCluster.Name, manualScalingScaledWorkers)
+
+	// Make sure the new workers are created and the job continues to complete on all of them
+	test.Eventually(rayClusterWorkerPods(test, namespace.Name, rayCluster.Name), TestTimeoutMedium).
+		Should(And(HaveLen(manualScalingScaledWorkers), HaveEach(Satisfy(PodRunningAndReady))))
+	test.Eventually(rayJobStatus(rayClient, jobID), TestTimeoutMedium).
+		Should(Or(Equal("SUCCEEDED"), Equal("FAILED"), Equal("STOPPED")))
+	WriteRayJobAPILogs(test, rayClient, jobID)
+	test.Expect(rayJobStatus(rayClient, jobID)(test)).To(Equal("SUCCEEDED"))
+	test.Expect(GetRayCluster(test, namespace.Name, rayCluster.Name).Status.AvailableWorkerReplicas).To(Equal(int32(manualScalingScaledWorkers)))
+}
+
+func rayClusterWorkerPods(test Test, namespace, name string) func(g Gomega) []corev1.Pod {
+	return func(g Gomega) []corev1.Pod {
+		pods, err := test.Client().Core().CoreV1().Pods(namespace).List(test.Ctx(), metav1.ListOptions{LabelSelector: "ray.io/node-type=worker,ray.io/cluster=" + name})
+		g.Expect(WrapError(err, "listing worker pods of", Ref("RayCluster", namespace, name))).NotTo(HaveOccurred())
+		return pods.Items
+	}
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ray
+
+import (
+	"testing"
+
+	. "github.com/onsi/gomega"
+	"github.com/opendatahub-io/distributed-workloads/pkg/examples"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	. "github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+)
+
+func TestRayPlacementGroupScheduling(t *testing.T) {
+	Track(t)
+	test := MustGather(WithHooks(t))
+
+	// Create a namespace
+	namespace := NewWarmStandbyNamespace(test)
+
+	// Create a ConfigMap with the Ray job script
+	scripts := CreateWarmStandbyConfigMap(test, namespace.Name, map[string][]byte{
+		"placement_groups.py": ReadFile(test, "placement_groups.py"),
+	})
+
+	// Create RayCluster with two workers, the head doesn't provide any CPU so bundles are placed on workers only
+	rayCluster := createRayCluster(test, namespace.Name, "raycluster", "", scripts.Name, 2, "3")
+	EventuallyOf(test, RayCluster(test, namespace.Name, rayCluster.Name), TestTimeoutLong).
+		Should(Field(RayClusterState).Equal(rayv1.Ready))
+
+	// Submit the Ray job creating STRICT_SPREAD and STRICT_PACK placement groups
+	dashboardURL := ExposeWarmStandbyService(test, "ray-dashboard", namespace.Name, rayCluster.Name+"-head-svc", "dashboard")
+	rayClient := NewRayClusterClient(dashboardURL)
+	var jobID string
+	test.Eventually(func(g Gomega) {
+		response, err := rayClient.CreateJob(&RayJobSetup{EntryPoint: "python " + examples.RayClusterScriptsMountPath + "/placement_groups.py"})
+		g.Expect(WrapError(err, "submitting Ray job to", Ref("dashboard", "", dashboardURL.Host))).NotTo(HaveOccurred())
+		jobID = response.JobID
+	}, TestTimeoutShort).Should(Succeed())
+	test.T().Logf("Submitted Ray job %s", jobID)
+
+	EventuallyOf(test, RayJobAPIDetails(test, rayClient, jobID), TestTimeoutMedium).
+		Should(Field(GetRayJobAPIDetailsStatus).OneOf("SUCCEEDED", "FAILED", "STOPPED"))
+	WriteRayJobAPILogs(test, rayClient, jobID)
+	ExpectOf(test, GetRayJobAPIDetails(test, rayClient, jobID)).
+		To(Field(GetRayJobAPIDetailsStatus).Equal("SUCCEEDED"))
+
+	// Verify bundles placement through the Ray dashboard API
+	placementGroups := map[string]RayPlacementGroup{}
+	for _, placementGroup := range GetRayPlacementGroups(test, dashboardURL) {
+		// Placement groups removed by previous runs are listed as well when the RayCluster is reused
+		if _, ok := placementGroups[placementGroup.Name]; !ok || placementGroup.State == "CREATED" {
+			placementGroups[placementGroup.Name] = placementGroup
+		}
+	}
+	test.Expect(placementGroups).To(HaveKey("strict-spread"))
+	test.Expect(placementGroups).To(HaveKey("strict-pack"))
+
+	spreadNodes := RayPlacementGroupBundleNodes(placementGroups["strict-spread"])
+	test.Expect(placementGroups["strict-spread"].State).To(Equal("CREATED"))
+	test.Expect(spreadNodes).To(HaveLen(2))
+	test.Expect(spreadNodes[0]).NotTo(Equal(spreadNodes[1]), "STRICT_SPREAD bundles are placed on the same node")
+
+	packNodes := RayPlacementGroupBundleNodes(placementGroups["strict-pack"])
+	test.Expect(placementGroups["strict-pack"].State).To(Equal("CREATED"))
+	test.Expect(packNodes).To(HaveLen(2))
+	test.Expect(packNodes[0]).To(Equal(packNodes[1]), "STRICT_PACK bundles are placed on different nodes")
+}
+
+// createRayCluster creates a RayCluster, queued in the local queue when set, or reuses it in warm standby mode.
+func createRayCluster(test Test, namespace, name, localQueueName, scriptsConfigMapName string, workers int32, workerCPUs string) *rayv1.RayCluster {
+	test.T().Helper()
+
+	rayCluster := NewRayClusterBuilder().
+		WithName(namespace, name).
+		WithWorkers(workers).
+		WithWorkerCPUs(workerCPUs).
+		WithLocalQueue(localQueueName).
+		WithScriptsConfigMap(scriptsConfigMapName).
+		Build()
+
+	return CreateWarmStandbyRayCluster(test, rayCluster)
+}
+
+// newRayClusterSpec returns RayCluster specification with the scripts ConfigMap mounted into the head when set.
+func newRayClusterSpec(scriptsConfigMapName string, workers int32, workerCPUs string) *rayv1.RayClusterSpec {
+	return NewRayClusterBuilder().
+		WithWorkers(workers).
+		WithWorkerCPUs(workerCPUs).
+		WithScriptsConfigMap(scriptsConfigMapName).
+		BuildSpec()
+}
@@ -0,0 +1,51 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ray
+
+import (
+	"regexp"
+	"testing"
+
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	. "github.com/project-codeflare/codeflare-common/support"
+)
+
+// TestRayRuntimeImageTools makes sure the Ray CLI in the Ray runtime image matches the configured Ray version,
+// so image build regressions are caught before creating RayClusters.
+func TestRayRuntimeImageTools(t *testing.T) {
+	Track(t, LabelTier1)
+	test := MustGather(WithHooks(t))
+
+	// Create a namespace
+	namespace := test.NewTestNamespace()
+
+	ExpectImageTools(test, namespace.Name, GetRayImageForArchitecture(),
+		ImageTool{
+			Name:    "ray",
+			Command: "ray --version",
+			Version: `ray, version ` + regexp.QuoteMeta(GetRayVersion()),
+		},
+		ImageTool{
+			Name:    "python",
+			Command: `python -c "import ray; print(ray.__version__)"`,
+			Version: regexp.QuoteMeta(GetRayVersion()),
+		},
+	)
+
+	// Make sure the versions of the packages in the image are compatible with each other
+	ExpectImageCompatibility(test, namespace.Name, GetRayImageForArchitecture())
+}
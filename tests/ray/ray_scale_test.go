@@ -0,0 +1,133 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package ray
+
+import (
+	"fmt"
+	"testing"
+	"time"
+
+	. "github.com/onsi/gomega"
+	. "github.com/opendatahub-io/distributed-workloads/tests/common/support"
+	. "github.com/project-codeflare/codeflare-common/support"
+	rayv1 "github.com/ray-project/kuberay/ray-operator/apis/ray/v1"
+
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var (
+	scaleWorkerCPU    = resource.MustParse("100m")
+	scaleWorkerMemory = resource.MustParse("512Mi")
+)
+
+func TestRayClusterScale(t *testing.T) {
+	Track(t, LabelScale, LabelLong)
+	test := MustGather(WithHooks(t))
+
+	// Make sure the cluster has enough capacity for all the workers, skip otherwise
+	workers := GetRayScaleWorkers(test)
+	requiredCPU, requiredMemory := scaleWorkerCPU.DeepCopy(), scaleWorkerMemory.DeepCopy()
+	for i := int32(1); i < workers; i++ {
+		requiredCPU.Add(scaleWorkerCPU)
+		requiredMemory.Add(scaleWorkerMemory)
+	}
+	availableCPU, availableMemory := schedulableCapacity(test)
+	if availableCPU.Cmp(requiredCPU) < 0 || availableMemory.Cmp(requiredMemory) < 0 {
+		test.T().Skipf("Cluster capacity %s CPU / %s memory is not sufficient for %d workers requiring %s CPU / %s memory",
+			availableCPU.String(), availableMemory.String(), workers, requiredCPU.String(), requiredMemory.String())
+	}
+
+	// Create a namespace
+	namespace := test.NewTestNamespace()
+
+	// Monitor the load of the other namespaces, contaminating the measurements
+	MonitorNoisyNeighbors(test, namespace.Name)
+
+	// Create RayCluster with small CPU workers
+	rayClusterSpec := newRayClusterSpec("", workers, "1")
+	rayClusterSpec.WorkerGroupSpecs[0].Template.Spec.Containers[0].Resources = corev1.ResourceRequirements{
+		Requests: corev1.ResourceList{
+			corev1.ResourceCPU:    scaleWorkerCPU,
+			corev1.ResourceMemory: scaleWorkerMemory,
+		},
+		Limits: corev1.ResourceList{
+			corev1.ResourceCPU:    resource.MustParse("1"),
+			corev1.ResourceMemory: scaleWorkerMemory,
+		},
+	}
+	rayCluster := &rayv1.RayCluster{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "raycluster-scale",
+			Namespace: namespace.Name,
+		},
+		Spec: *rayClusterSpec,
+	}
+	stopwatch := StartStopwatch()
+	created, err := test.Client().Ray().RayV1().RayClusters(namespace.Name).Create(test.Ctx(), rayCluster, metav1.CreateOptions{})
+	ExpectNoError(test, err, "creating", Ref("RayCluster", namespace.Name, rayCluster.Name+rayCluster.GenerateName))
+	rayCluster = created
+	test.T().Logf("Created RayCluster %s/%s with %d workers successfully", rayCluster.Namespace, rayCluster.Name, workers)
+
+	// Measure the time for the head and all the workers to get ready
+	readyBaseline := GetRayScaleReadyBaseline(test)
+	EventuallyOfWithPolling(test, rayClusterPods(test, namespace.Name, rayCluster.Name), 2*readyBaseline, ExponentialPolling(time.Second, 5*time.Second, 2)).
+		Should(HaveLenOf[corev1.Pod](int(workers) + 1).And(HaveEachOf(Field(PodRunningAndReady).Equal(true))))
+	readyDuration := stopwatch.Elapsed()
+	EventuallyOf(test, RayCluster(test, namespace.Name, rayCluster.Name), TestTimeoutMedium).
+		Should(Field(RayClusterState).Equal(rayv1.Ready))
+	test.T().Logf("RayCluster %s/%s got all %d pods ready in %s", rayCluster.Namespace, rayCluster.Name, workers+1, readyDuration)
+
+	// Measure the time for all the pods to get deleted
+	stopwatch = StartStopwatch()
+	err = test.Client().Ray().RayV1().RayClusters(namespace.Name).Delete(test.Ctx(), rayCluster.Name, metav1.DeleteOptions{})
+	ExpectNoError(test, err, "deleting", Ref("RayCluster", namespace.Name, rayCluster.Name))
+	teardownBaseline := GetRayScaleTeardownBaseline(test)
+	EventuallyWithPolling(test, rayClusterPods(test, namespace.Name, rayCluster.Name), 2*teardownBaseline, ExponentialPolling(time.Second, 5*time.Second, 2)).
+		Should(BeEmpty())
+	WaitForDeletion(test, rayv1.SchemeGroupVersion.WithResource("rayclusters"), rayCluster, TestTimeoutMedium)
+	teardownDuration := stopwatch.Elapsed()
+	test.T().Logf("RayCluster %s/%s got all pods deleted in %s", rayCluster.Namespace, rayCluster.Name, teardownDuration)
+
+	WriteToOutputDir(test, "ray-scale-timings", Log,
+		[]byte(fmt.Sprintf("workers: %d\nready: %s\nteardown: %s\n", workers, readyDuration, teardownDuration)))
+
+	// Compare the timings against the baselines
+	test.Expect(readyDuration).To(BeNumerically("<=", readyBaseline),
+		"RayCluster with %d workers took %s to get ready, baseline is %s", workers, readyDuration, readyBaseline)
+	test.Expect(teardownDuration).To(BeNumerically("<=", teardownBaseline),
+		"RayCluster with %d workers took %s to tear down, baseline is %s", workers, teardownDuration, teardownBaseline)
+}
+
+// schedulableCapacity returns allocatable CPU and memory summed over the schedulable worker nodes.
+func schedulableCapacity(test Test) (resource.Quantity, resource.Quantity) {
+	test.T().Helper()
+
+	var cpu, memory resource.Quantity
+	for _, node := range GetNodes(test) {
+		if node.Spec.Unschedulable {
+			continue
+		}
+		if _, controlPlane := node.Labels["node-role.kubernetes.io/control-plane"]; controlPlane {
+			continue
+		}
+		cpu.Add(node.Status.Allocatable[corev1.ResourceCPU])
+		memory.Add(node.Status.Allocatable[corev1.ResourceMemory])
+	}
+	return cpu, memory
+}
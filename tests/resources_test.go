@@ -0,0 +1,185 @@
+/*
+Copyright 2024.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package tests
+
+import (
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// testPackage holds the resources of a test package, i.e. scripts, notebooks or datasets stored next to its Go
+// sources, and what its Go sources reference.
+type testPackage struct {
+	dir       string
+	resources []string
+	// String literals of the Go sources and the files named by go:embed directives
+	references map[string]bool
+	// Patterns of the go:embed directives
+	embedPatterns []string
+	// Resources read with ReadFile, keyed by their position in the Go sources
+	reads map[string]string
+}
+
+// TestResourcesReferenced flags the resources of the test packages which aren't referenced by any Go source of their
+// package or aren't embedded, and the resources read by the tests which don't exist, so the resources don't silently
+// rot as suites are added and removed. Resources in testdata directories are loaded from paths built at runtime,
+// i.e. golden files, and aren't checked.
+func TestResourcesReferenced(t *testing.T) {
+	packages, err := scanTestPackages(".")
+	if err != nil {
+		t.Fatalf("Error scanning test packages: %v", err)
+	}
+
+	for _, pkg := range packages {
+		for _, resource := range pkg.resources {
+			if !pkg.isReferenced(resource) {
+				t.Errorf("%s isn't referenced by any Go source of its package, remove it if no test uses it anymore", filepath.Join(pkg.dir, resource))
+			}
+			if !pkg.isEmbedded(resource) {
+				t.Errorf("%s isn't embedded by any go:embed directive of its package, so tests can't read it", filepath.Join(pkg.dir, resource))
+			}
+		}
+
+		positions := make([]string, 0, len(pkg.reads))
+		for position := range pkg.reads {
+			positions = append(positions, position)
+		}
+		sort.Strings(positions)
+		for _, position := range positions {
+			if _, err := os.Stat(filepath.Join(pkg.dir, pkg.reads[position])); err != nil {
+				t.Errorf("%s: resource %s read by the test doesn't exist", position, pkg.reads[position])
+			}
+		}
+	}
+}
+
+func (p *testPackage) isReferenced(resource string) bool {
+	base := path.Base(resource)
+	for reference := range p.references {
+		if reference == resource || reference == base || strings.HasSuffix(reference, "/"+base) {
+			return true
+		}
+	}
+	return false
+}
+
+func (p *testPackage) isEmbedded(resource string) bool {
+	for _, pattern := range p.embedPatterns {
+		if matched, _ := path.Match(pattern, resource); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// scanTestPackages returns the packages under the directory with their resources and references.
+func scanTestPackages(root string) ([]*testPackage, error) {
+	packages := map[string]*testPackage{}
+	var resources []string
+	err := filepath.WalkDir(root, func(file string, entry fs.DirEntry, err error) error {
+		switch {
+		case err != nil:
+			return err
+		case entry.IsDir() && (entry.Name() == "testdata" || entry.Name() == "__pycache__"):
+			return filepath.SkipDir
+		case entry.IsDir():
+			return nil
+		case strings.HasSuffix(file, ".go"):
+			dir := filepath.Dir(file)
+			if packages[dir] == nil {
+				packages[dir] = &testPackage{dir: dir, references: map[string]bool{}, reads: map[string]string{}}
+			}
+			return packages[dir].scanGoFile(file)
+		default:
+			resources = append(resources, file)
+			return nil
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, resource := range resources {
+		if pkg := packages[filepath.Dir(resource)]; pkg != nil {
+			pkg.resources = append(pkg.resources, filepath.Base(resource))
+		}
+	}
+
+	var result []*testPackage
+	for _, pkg := range packages {
+		result = append(result, pkg)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].dir < result[j].dir })
+	return result, nil
+}
+
+func (p *testPackage) scanGoFile(file string) error {
+	fileSet := token.NewFileSet()
+	parsed, err := parser.ParseFile(fileSet, file, nil, parser.ParseComments)
+	if err != nil {
+		return err
+	}
+
+	for _, group := range parsed.Comments {
+		for _, comment := range group.List {
+			if patterns, ok := strings.CutPrefix(comment.Text, "//go:embed "); ok {
+				for _, pattern := range strings.Fields(patterns) {
+					p.embedPatterns = append(p.embedPatterns, pattern)
+					p.references[pattern] = true
+				}
+			}
+		}
+	}
+
+	ast.Inspect(parsed, func(node ast.Node) bool {
+		switch node := node.(type) {
+		case *ast.BasicLit:
+			if value, err := strconv.Unquote(node.Value); node.Kind == token.STRING && err == nil {
+				p.references[value] = true
+			}
+		case *ast.CallExpr:
+			if name, ok := readFileArgument(node); ok {
+				p.reads[fileSet.Position(node.Pos()).String()] = name
+			}
+		}
+		return true
+	})
+	return nil
+}
+
+// readFileArgument returns the name of the resource read by a ReadFile(test, "name") call with a constant name.
+func readFileArgument(call *ast.CallExpr) (string, bool) {
+	ident, ok := call.Fun.(*ast.Ident)
+	if !ok || ident.Name != "ReadFile" || len(call.Args) != 2 {
+		return "", false
+	}
+	literal, ok := call.Args[1].(*ast.BasicLit)
+	if !ok || literal.Kind != token.STRING {
+		return "", false
+	}
+	name, err := strconv.Unquote(literal.Value)
+	return name, err == nil
+}